@@ -1,14 +1,18 @@
 package main
 
 import (
+	"activities-api/internal/clients"
 	"activities-api/internal/config"
 	"activities-api/internal/controllers"
+	"activities-api/internal/domain"
 	"activities-api/internal/middleware"
 	"activities-api/internal/repository"
 	"activities-api/internal/services"
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"users-api/pkg/usersclient"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,24 +31,158 @@ func main() {
 	// Crear repositorio de inscripciones (comparte la misma DB)
 	inscripcionesRepo := repository.NewMySQLInscripcionesRepository(actividadesRepo.GetDB())
 
-	// TODO: Cuando el equipo implemente Sucursales:
-	// sucursalesRepo := repository.NewMySQLSucursalesRepository(actividadesRepo.GetDB())
+	// Crear repositorio de sucursales (comparte la misma DB, crea la FK de actividades.sucursal_id)
+	sucursalesRepo := repository.NewMySQLSucursalesRepository(actividadesRepo.GetDB())
+
+	// Crear repositorio de lista de espera (comparte la misma DB)
+	listaEsperaRepo := repository.NewMySQLListaEsperaRepository(actividadesRepo.GetDB())
+
+	// Crear repositorio de sesiones fechadas y recurrencias (comparte la misma DB)
+	sesionesRepo := repository.NewMySQLSesionesRepository(actividadesRepo.GetDB(), cfg.Sesiones.Location)
+
+	// Crear repositorio de asistencias y suspensiones (comparte la misma DB, después de las sesiones)
+	asistenciasRepo := repository.NewMySQLAsistenciasRepository(actividadesRepo.GetDB(), cfg.Sesiones.Location)
+
+	// Crear outbox de eventos y transactor (comparten la misma DB): los servicios encolan los eventos
+	// en la misma transacción que el cambio que los origina
+	outboxRepo := repository.NewMySQLOutboxRepository(actividadesRepo.GetDB())
+	transactor := repository.NewMySQLTransactor(actividadesRepo.GetDB())
+
+	// Crear repositorio de tokens de los calendarios ICS de los socios
+	calendarioTokensRepo := repository.NewMySQLCalendarioTokensRepository(actividadesRepo.GetDB())
+
+	// ========== PUBLICACIÓN DE EVENTOS ==========
+	// El relay publica el outbox (activity.*, inscription.*, sucursal.*, waitlist.*, session.*) en el exchange compartido
+	var outboxRelay *services.OutboxRelay
+	rabbitPublisher, err := clients.NewRabbitMQEventPublisher(cfg.RabbitMQ.URL, cfg.RabbitMQ.Exchange)
+	if err != nil {
+		log.Printf("⚠️  Warning: No se pudo conectar a RabbitMQ: %v", err)
+		log.Println("⚠️  Los eventos quedan en el outbox hasta que el servicio arranque con RabbitMQ disponible")
+	} else {
+		defer rabbitPublisher.Close()
+		outboxRelay = services.NewOutboxRelay(outboxRepo, rabbitPublisher, services.OutboxRelayPolicy{
+			Interval:  cfg.Outbox.RelayInterval,
+			BatchSize: cfg.Outbox.BatchSize,
+			Retention: cfg.Outbox.Retention,
+		})
+	}
+
+	// Validaciones de inscripción: usuario (API interna de users-api) y suscripción/plan (subscriptions-api)
+	usersClient := usersclient.New(usersclient.Config{
+		BaseURL:       cfg.UsersAPI.URL,
+		ServiceName:   cfg.UsersAPI.ServiceName,
+		ServiceSecret: cfg.UsersAPI.ServiceSecret,
+	})
+	usersValidator := clients.NewUsersAPIValidator(usersClient)
+	subscriptionsClient := clients.NewSubscriptionsAPIClient(
+		cfg.SubscriptionsAPI.URL,
+		cfg.SubscriptionsAPI.ServiceName,
+		cfg.SubscriptionsAPI.ServiceSecret,
+		cfg.SubscriptionsAPI.Timeout,
+	)
 
 	// ========== CAPA DE NEGOCIO (SERVICES) ==========
 	// Crear servicios con dependency injection
-	actividadesService := services.NewActividadesService(actividadesRepo)
-	inscripcionesService := services.NewInscripcionesService(inscripcionesRepo, actividadesRepo)
-	// TODO: sucursalesService := services.NewSucursalesService(sucursalesRepo)
+	// Asistencias primero: suspende las inscripciones por inasistencias (en todos los servicios que inscriben)
+	asistenciasService := services.NewAsistenciasService(
+		asistenciasRepo,
+		sesionesRepo,
+		actividadesRepo,
+		services.AsistenciasPolicy{
+			CheckinAntes:       cfg.Asistencias.CheckinAntes,
+			NoShowLimite:       cfg.Asistencias.NoShowLimite,
+			NoShowVentana:      cfg.Asistencias.NoShowVentana,
+			SuspensionDuracion: cfg.Asistencias.SuspensionDuracion,
+			Location:           cfg.Sesiones.Location,
+		},
+	)
+	listaEsperaService := services.NewListaEsperaService(
+		listaEsperaRepo,
+		actividadesRepo,
+		usersValidator,
+		subscriptionsClient,
+		asistenciasService,
+		transactor,
+		outboxRepo,
+		services.ListaEsperaPolicy{
+			ConfirmationWindow: cfg.ListaEspera.ConfirmationWindow,
+			SweepInterval:      cfg.ListaEspera.SweepInterval,
+		},
+	)
+	actividadesService := services.NewActividadesService(actividadesRepo, sucursalesRepo, listaEsperaService, transactor, outboxRepo)
+	inscripcionesService := services.NewInscripcionesService(inscripcionesRepo, actividadesRepo, usersValidator, subscriptionsClient, asistenciasService, listaEsperaService, transactor, outboxRepo)
+	privacyService := services.NewPrivacyService(inscripcionesRepo, actividadesRepo, listaEsperaRepo, sesionesRepo, asistenciasRepo, calendarioTokensRepo, transactor, outboxRepo)
+	sucursalesService := services.NewSucursalesService(sucursalesRepo, outboxRepo)
+	sesionesService := services.NewSesionesService(
+		sesionesRepo,
+		actividadesRepo,
+		sucursalesRepo,
+		usersValidator,
+		subscriptionsClient,
+		asistenciasService,
+		transactor,
+		outboxRepo,
+		services.SesionesPolicy{
+			HorizonteDias:      cfg.Sesiones.HorizonteDias,
+			GenerationInterval: cfg.Sesiones.GenerationInterval,
+			Location:           cfg.Sesiones.Location,
+		},
+	)
+	calendarioService := services.NewCalendarioService(
+		calendarioTokensRepo,
+		inscripcionesRepo,
+		actividadesRepo,
+		sesionesRepo,
+		sucursalesRepo,
+		services.CalendarioPolicy{Location: cfg.Sesiones.Location},
+	)
+
+	// Vencimiento de promociones de la lista de espera no confirmadas a tiempo
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listaEsperaService.Start(ctx)
 
-	// TODO: Cuando el equipo implemente RabbitMQ:
-	// rabbitmqClient := clients.NewRabbitMQClient(cfg.RabbitMQ)
-	// inscripcionesService := services.NewInscripcionesService(inscripcionesRepo, actividadesRepo, rabbitmqClient)
+	// Generación de sesiones de las recurrencias hasta el horizonte configurado
+	sesionesService.Start(ctx)
+
+	// Publicación de los eventos del outbox
+	if outboxRelay != nil {
+		outboxRelay.Start(ctx)
+	}
 
 	// ========== CAPA DE PRESENTACIÓN (CONTROLLERS) ==========
 	// Crear controllers con dependency injection
 	actividadesController := controllers.NewActividadesController(actividadesService)
 	inscripcionesController := controllers.NewInscripcionesController(inscripcionesService)
-	// TODO: sucursalesController := controllers.NewSucursalesController(sucursalesService)
+	sucursalesController := controllers.NewSucursalesController(sucursalesService)
+	listaEsperaController := controllers.NewListaEsperaController(listaEsperaService)
+	sesionesController := controllers.NewSesionesController(sesionesService)
+	asistenciasController := controllers.NewAsistenciasController(asistenciasService)
+	calendarioController := controllers.NewCalendarioController(calendarioService)
+
+	// ========== CLIENTES EXTERNOS ==========
+	// Claves públicas de firma JWT publicadas por users-api (JWKS)
+	jwtKeys := usersclient.NewJWKSKeyResolver(cfg.UsersAPI.URL, cfg.JWT.JWKSCacheTTL)
+
+	// Chequeo de revocación de sesiones contra users-api (logout / reuso de refresh token)
+	sessionChecker := usersclient.NewSessionChecker(cfg.UsersAPI.URL, cfg.UsersAPI.SessionCacheTTL)
+
+	// Consumidor de pedidos de exportación/borrado de datos de socios (coordinados por users-api)
+	privacyConsumer, err := clients.NewRabbitMQPrivacyConsumer(
+		cfg.RabbitMQ.URL,
+		cfg.RabbitMQ.Exchange,
+		cfg.RabbitMQ.PrivacyQueue,
+		privacyService,
+	)
+	if err != nil {
+		log.Printf("⚠️  Warning: No se pudo conectar a RabbitMQ: %v", err)
+		log.Println("⚠️  Los pedidos de privacidad no se procesarán")
+	} else {
+		defer privacyConsumer.Close()
+		if err := privacyConsumer.Start(); err != nil {
+			log.Printf("⚠️  Warning: Error iniciando consumidor: %v", err)
+		}
+	}
 
 	// ========== CONFIGURACIÓN DE GIN ==========
 	router := gin.Default()
@@ -58,34 +196,93 @@ func main() {
 	router.GET("/actividades", actividadesController.List)
 	router.GET("/actividades/buscar", actividadesController.Search)
 	router.GET("/actividades/:id", actividadesController.GetByID)
+	router.GET("/actividades/:id/recurrencias", sesionesController.ListReglas)
+
+	// Sesiones fechadas (solo lectura sin auth)
+	router.GET("/sesiones", sesionesController.List)
+	router.GET("/sesiones/:id", sesionesController.GetByID)
 
-	// TODO: Sucursales (solo lectura sin auth)
-	// router.GET("/sucursales", sucursalesController.List)
-	// router.GET("/sucursales/:id", sucursalesController.GetByID)
+	// Sucursales (solo lectura sin auth)
+	router.GET("/sucursales", sucursalesController.List)
+	router.GET("/sucursales/:id", sucursalesController.GetByID)
+
+	// Calendarios ICS (el feed del socio se autentica con su token en la URL)
+	router.GET("/calendario/actividades.ics", calendarioController.PublicFeed)
+	router.GET("/calendario/socios/:token", calendarioController.MemberFeed)
 
 	// ========== RUTAS PROTEGIDAS (REQUIEREN JWT) ==========
 	protected := router.Group("/")
-	protected.Use(middleware.JWTAuthMiddleware(cfg.JWT.Secret))
+	protected.Use(middleware.JWTAuthMiddleware(jwtKeys, sessionChecker))
 	{
 		// Inscripciones (requieren autenticación)
 		protected.GET("/inscripciones", inscripcionesController.List)
 		protected.POST("/inscripciones", inscripcionesController.Create)
 		protected.DELETE("/inscripciones", inscripcionesController.Deactivate)
+
+		// Lista de espera de actividades completas
+		protected.GET("/lista-espera", listaEsperaController.List)
+		protected.POST("/lista-espera", listaEsperaController.Join)
+		protected.POST("/lista-espera/confirmar", listaEsperaController.Confirm)
+		protected.DELETE("/lista-espera", listaEsperaController.Leave)
+
+		// Inscripciones a sesiones puntuales
+		protected.GET("/inscripciones/sesiones", sesionesController.ListInscripciones)
+		protected.POST("/sesiones/:id/inscripcion", sesionesController.Enroll)
+		protected.DELETE("/sesiones/:id/inscripcion", sesionesController.Unenroll)
+
+		// Asistencia del socio (check-in con el QR de la sesión)
+		protected.GET("/asistencias", asistenciasController.ListMine)
+		protected.POST("/sesiones/:id/checkin", asistenciasController.CheckIn)
+		protected.POST("/sesiones/:id/checkout", asistenciasController.CheckOut)
+
+		// Token del calendario ICS del socio
+		protected.POST("/calendario/token", calendarioController.CreateToken)
+		protected.DELETE("/calendario/token", calendarioController.RevokeToken)
 	}
 
-	// ========== RUTAS DE ADMIN (REQUIEREN JWT + ADMIN) ==========
-	adminOnly := protected.Group("/")
-	adminOnly.Use(middleware.AdminOnlyMiddleware())
+	// ========== RUTAS DE GESTIÓN (REQUIEREN JWT + PERMISOS) ==========
+	// Los permisos con alcance (sucursal / propias) se verifican en el service
+	manageActividades := middleware.RequirePermission(
+		domain.PermissionActivitiesManage,
+		domain.PermissionActivitiesManageSucursal,
+	)
+	updateActividades := middleware.RequirePermission(
+		domain.PermissionActivitiesManage,
+		domain.PermissionActivitiesManageSucursal,
+		domain.PermissionActivitiesUpdateOwn,
+	)
+	manageAllActividades := middleware.RequirePermission(domain.PermissionActivitiesManage)
 	{
-		// Actividades (CRUD completo solo admin)
-		adminOnly.POST("/actividades", actividadesController.Create)
-		adminOnly.PUT("/actividades/:id", actividadesController.Update)
-		adminOnly.DELETE("/actividades/:id", actividadesController.Delete)
-
-		// TODO: Sucursales (CRUD completo solo admin)
-		// adminOnly.POST("/sucursales", sucursalesController.Create)
-		// adminOnly.PUT("/sucursales/:id", sucursalesController.Update)
-		// adminOnly.DELETE("/sucursales/:id", sucursalesController.Delete)
+		protected.POST("/actividades", manageActividades, actividadesController.Create)
+		protected.PUT("/actividades/:id", updateActividades, actividadesController.Update)
+		protected.PATCH("/actividades/:id", updateActividades, actividadesController.Patch)
+		protected.DELETE("/actividades/:id", manageActividades, actividadesController.Delete)
+		protected.GET("/actividades/:id/conflictos", manageActividades, actividadesController.ListConflictosForzados)
+		protected.POST("/actividades/importar", manageActividades, actividadesController.Import)
+		protected.GET("/actividades/exportar", manageActividades, actividadesController.Export)
+
+		// Sucursales (alta y baja solo activities:manage; el gerente puede modificar las suyas)
+		protected.POST("/sucursales", manageActividades, sucursalesController.Create)
+		protected.PUT("/sucursales/:id", manageActividades, sucursalesController.Update)
+		protected.DELETE("/sucursales/:id", manageActividades, sucursalesController.Delete)
+
+		// Recurrencias y sesiones (el instructor puede reprogramar o cancelar sesiones de sus actividades)
+		protected.POST("/actividades/:id/recurrencias", manageActividades, sesionesController.CreateRegla)
+		protected.DELETE("/recurrencias/:id", manageActividades, sesionesController.DeleteRegla)
+		protected.POST("/sesiones", manageActividades, sesionesController.Create)
+		protected.PUT("/sesiones/:id", updateActividades, sesionesController.Update)
+		protected.POST("/sesiones/:id/cancelar", updateActividades, sesionesController.Cancel)
+
+		// Asistencia (el instructor toma lista de las sesiones de sus actividades)
+		protected.GET("/sesiones/:id/codigo-checkin", updateActividades, asistenciasController.CodigoCheckin)
+		protected.GET("/sesiones/:id/asistencias", updateActividades, asistenciasController.ListBySesion)
+		protected.POST("/sesiones/:id/asistencias", updateActividades, asistenciasController.RollCall)
+		protected.POST("/sesiones/:id/asistencias/:usuario_id/checkout", updateActividades, asistenciasController.RollCallCheckOut)
+		protected.GET("/actividades/:id/asistencias", updateActividades, asistenciasController.ListByActividad)
+
+		// Historial de asistencia y suspensiones de cualquier socio
+		protected.GET("/usuarios/:id/asistencias", manageAllActividades, asistenciasController.ListByUser)
+		protected.DELETE("/usuarios/:id/suspension", manageAllActividades, asistenciasController.LiftSuspension)
 	}
 
 	// ========== INICIAR SERVIDOR ==========
@@ -94,14 +291,52 @@ func main() {
 	log.Printf("📋 Endpoints disponibles:")
 	log.Printf("   GET    /healthz")
 	log.Printf("   GET    /actividades")
-	log.Printf("   GET    /actividades/buscar?id=&titulo=&horario=&categoria=")
+	log.Printf("   GET    /actividades/buscar?titulo=&categoria=&dia=&instructor_id=&sucursal_id=&desde=&hasta=&min_lugares=&orden=&limit=&offset=")
 	log.Printf("   GET    /actividades/:id")
-	log.Printf("   POST   /actividades (admin)")
-	log.Printf("   PUT    /actividades/:id (admin)")
-	log.Printf("   DELETE /actividades/:id (admin)")
+	log.Printf("   POST   /actividades (activities:manage[:sucursal])")
+	log.Printf("   PUT    /actividades/:id (activities:manage[:sucursal] | activities:update:own)")
+	log.Printf("   PATCH  /actividades/:id (activities:manage[:sucursal] | activities:update:own, If-Match opcional)")
+	log.Printf("   DELETE /actividades/:id (activities:manage[:sucursal])")
+	log.Printf("   GET    /actividades/:id/conflictos (activities:manage[:sucursal])")
+	log.Printf("   POST   /actividades/importar?modo=atomico|parcial&dry_run=&sucursal_id= (activities:manage[:sucursal])")
+	log.Printf("   GET    /actividades/exportar?formato=csv|json&sucursal_id= (activities:manage[:sucursal])")
+	log.Printf("   GET    /actividades/:id/recurrencias")
+	log.Printf("   POST   /actividades/:id/recurrencias (activities:manage[:sucursal])")
+	log.Printf("   DELETE /recurrencias/:id (activities:manage[:sucursal])")
+	log.Printf("   GET    /sesiones?desde=&hasta=&actividad_id=&sucursal_id=&estado=")
+	log.Printf("   GET    /sesiones/:id")
+	log.Printf("   POST   /sesiones (activities:manage[:sucursal])")
+	log.Printf("   PUT    /sesiones/:id (activities:manage[:sucursal] | activities:update:own)")
+	log.Printf("   POST   /sesiones/:id/cancelar (activities:manage[:sucursal] | activities:update:own)")
+	log.Printf("   GET    /sesiones/:id/codigo-checkin (activities:manage[:sucursal] | activities:update:own)")
+	log.Printf("   GET    /sesiones/:id/asistencias (activities:manage[:sucursal] | activities:update:own)")
+	log.Printf("   POST   /sesiones/:id/asistencias (activities:manage[:sucursal] | activities:update:own)")
+	log.Printf("   POST   /sesiones/:id/asistencias/:usuario_id/checkout (activities:manage[:sucursal] | activities:update:own)")
+	log.Printf("   GET    /actividades/:id/asistencias?desde=&hasta= (activities:manage[:sucursal] | activities:update:own)")
+	log.Printf("   GET    /usuarios/:id/asistencias?desde=&hasta= (activities:manage)")
+	log.Printf("   DELETE /usuarios/:id/suspension (activities:manage)")
+	log.Printf("   GET    /sucursales")
+	log.Printf("   GET    /sucursales/:id")
+	log.Printf("   POST   /sucursales (activities:manage)")
+	log.Printf("   PUT    /sucursales/:id (activities:manage[:sucursal])")
+	log.Printf("   DELETE /sucursales/:id (activities:manage)")
 	log.Printf("   GET    /inscripciones (auth)")
 	log.Printf("   POST   /inscripciones (auth)")
 	log.Printf("   DELETE /inscripciones (auth)")
+	log.Printf("   GET    /lista-espera (auth)")
+	log.Printf("   POST   /lista-espera (auth)")
+	log.Printf("   POST   /lista-espera/confirmar (auth)")
+	log.Printf("   DELETE /lista-espera (auth)")
+	log.Printf("   GET    /inscripciones/sesiones (auth)")
+	log.Printf("   POST   /sesiones/:id/inscripcion (auth)")
+	log.Printf("   DELETE /sesiones/:id/inscripcion (auth)")
+	log.Printf("   GET    /asistencias?desde=&hasta= (auth)")
+	log.Printf("   POST   /sesiones/:id/checkin (auth)")
+	log.Printf("   POST   /sesiones/:id/checkout (auth)")
+	log.Printf("   POST   /calendario/token (auth)")
+	log.Printf("   DELETE /calendario/token (auth)")
+	log.Printf("   GET    /calendario/socios/:token.ics")
+	log.Printf("   GET    /calendario/actividades.ics?sucursal_id=&categoria=")
 
 	if err := router.Run(fmt.Sprintf(":%s", port)); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
@@ -6,8 +6,10 @@ require (
 	github.com/gin-gonic/gin v1.10.0
 	github.com/golang-jwt/jwt/v4 v4.5.2
 	github.com/joho/godotenv v1.5.1
+	github.com/streadway/amqp v1.1.0
 	gorm.io/driver/mysql v1.5.7
 	gorm.io/gorm v1.26.1
+	users-api/pkg/usersclient v0.0.0-00010101000000-000000000000
 )
 
 require (
@@ -40,3 +42,6 @@ require (
 	google.golang.org/protobuf v1.36.6 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+// Cliente de users-api (mismo repositorio)
+replace users-api/pkg/usersclient => ../users-api/pkg/usersclient
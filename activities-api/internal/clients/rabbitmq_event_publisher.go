@@ -0,0 +1,106 @@
+package clients
+
+import (
+	"activities-api/internal/domain"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// RabbitMQEventPublisher implementa services.EventPublisher con RabbitMQ
+type RabbitMQEventPublisher struct {
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	exchange string
+	mu       sync.Mutex // amqp.Channel no es seguro para publicar desde varias goroutines
+}
+
+// NewRabbitMQEventPublisher crea el publisher y declara el exchange
+func NewRabbitMQEventPublisher(url, exchange string) (*RabbitMQEventPublisher, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error creando canal: %w", err)
+	}
+
+	// Declarar exchange
+	err = channel.ExchangeDeclare(
+		exchange, // name
+		"topic",  // type
+		true,     // durable
+		false,    // auto-deleted
+		false,    // internal
+		false,    // no-wait
+		nil,      // arguments
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando exchange: %w", err)
+	}
+
+	log.Printf("✅ Conectado a RabbitMQ (Exchange: %s)\n", exchange)
+
+	return &RabbitMQEventPublisher{
+		conn:     conn,
+		channel:  channel,
+		exchange: exchange,
+	}, nil
+}
+
+// PublishEvent publica el evento con routing key <type>.<action>
+func (r *RabbitMQEventPublisher) PublishEvent(eventType, action, id string, data map[string]interface{}) error {
+	body, err := json.Marshal(rabbitMQEvent{
+		Action:    action,
+		Type:      eventType,
+		ID:        id,
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: error serializando evento: %v", domain.ErrEventoInvalido, err)
+	}
+
+	routingKey := eventType + "." + action
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err = r.channel.Publish(
+		r.exchange, // exchange
+		routingKey, // routing key
+		false,      // mandatory
+		false,      // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("error publicando evento: %w", err)
+	}
+
+	log.Printf("📤 Evento publicado: %s (ID: %s)\n", routingKey, id)
+	return nil
+}
+
+// Close cierra las conexiones
+func (r *RabbitMQEventPublisher) Close() error {
+	if r.channel != nil {
+		r.channel.Close()
+	}
+	if r.conn != nil {
+		return r.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package clients
+
+import (
+	"activities-api/internal/domain"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SubscriptionsAPIClient implementa services.SubscriptionsClient con la API interna de subscriptions-api
+// Se autentica con credenciales de servicio (HTTP Basic), configuradas en subscriptions-api con SERVICE_CREDENTIALS
+type SubscriptionsAPIClient struct {
+	baseURL       string
+	serviceName   string
+	serviceSecret string
+	httpClient    *http.Client
+}
+
+// activeSubscriptionResponse es la respuesta de GET /internal/subscriptions/active/:user_id
+type activeSubscriptionResponse struct {
+	Subscription struct {
+		ID               string    `json:"id"`
+		PlanID           string    `json:"plan_id"`
+		FechaVencimiento time.Time `json:"fecha_vencimiento"`
+	} `json:"subscription"`
+	Plan struct {
+		Nombre                string   `json:"nombre"`
+		TipoAcceso            string   `json:"tipo_acceso"`
+		ActividadesPermitidas []string `json:"actividades_permitidas"`
+	} `json:"plan"`
+}
+
+// NewSubscriptionsAPIClient crea una nueva instancia del cliente
+func NewSubscriptionsAPIClient(baseURL, serviceName, serviceSecret string, timeout time.Duration) *SubscriptionsAPIClient {
+	return &SubscriptionsAPIClient{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		serviceName:   serviceName,
+		serviceSecret: serviceSecret,
+		httpClient:    &http.Client{Timeout: timeout},
+	}
+}
+
+// GetActiveSubscription obtiene la suscripción activa del usuario y su plan
+// Devuelve domain.ErrNoActiveSubscription si no tiene
+func (c *SubscriptionsAPIClient) GetActiveSubscription(ctx context.Context, usuarioID uint) (domain.SuscripcionActiva, error) {
+	endpoint := c.baseURL + "/internal/subscriptions/active/" + strconv.FormatUint(uint64(usuarioID), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return domain.SuscripcionActiva{}, fmt.Errorf("error creando request: %w", err)
+	}
+	req.SetBasicAuth(c.serviceName, c.serviceSecret)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return domain.SuscripcionActiva{}, fmt.Errorf("error consultando subscriptions-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return domain.SuscripcionActiva{}, domain.ErrNoActiveSubscription
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return domain.SuscripcionActiva{}, fmt.Errorf("subscriptions-api respondió %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var active activeSubscriptionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&active); err != nil {
+		return domain.SuscripcionActiva{}, fmt.Errorf("error decodificando respuesta de subscriptions-api: %w", err)
+	}
+
+	return domain.SuscripcionActiva{
+		ID:                    active.Subscription.ID,
+		PlanID:                active.Subscription.PlanID,
+		PlanNombre:            active.Plan.Nombre,
+		TipoAcceso:            active.Plan.TipoAcceso,
+		ActividadesPermitidas: active.Plan.ActividadesPermitidas,
+		FechaVencimiento:      active.Subscription.FechaVencimiento,
+	}, nil
+}
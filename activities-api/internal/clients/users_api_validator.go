@@ -0,0 +1,34 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"users-api/pkg/usersclient"
+)
+
+// UsersAPIValidator implementa services.UserValidator con la API interna de users-api
+type UsersAPIValidator struct {
+	client *usersclient.Client
+}
+
+// NewUsersAPIValidator crea una nueva instancia del validador
+func NewUsersAPIValidator(client *usersclient.Client) *UsersAPIValidator {
+	return &UsersAPIValidator{
+		client: client,
+	}
+}
+
+// UserExists indica si existe un usuario activo con ese ID
+func (u *UsersAPIValidator) UserExists(ctx context.Context, usuarioID uint) (bool, error) {
+	exists, err := u.client.Exists(ctx, usuarioID)
+	if err != nil {
+		if errors.Is(err, usersclient.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error consultando users-api: %w", err)
+	}
+
+	return exists, nil
+}
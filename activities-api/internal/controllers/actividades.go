@@ -2,14 +2,22 @@ package controllers
 
 import (
 	"activities-api/internal/domain"
+	"activities-api/internal/middleware"
 	"activities-api/internal/services"
+	"bytes"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxImportBytes limita el tamaño del archivo de POST /actividades/importar
+const maxImportBytes = 5 << 20
+
 // ActividadesController maneja las peticiones HTTP relacionadas con actividades
 // Migrado de backend/controllers/actividad/actividad_controller.go con dependency injection
 type ActividadesController struct {
@@ -23,10 +31,20 @@ func NewActividadesController(service services.ActividadesService) *ActividadesC
 	}
 }
 
-// List obtiene todas las actividades
-// GET /actividades
+// List obtiene todas las actividades (array, como siempre)
+// Con limit u offset responde una página con el mismo formato que la búsqueda
+// GET /actividades, GET /actividades?limit=20&offset=0
 // Migrado de backend/controllers/actividad/actividad_controller.go:29
 func (c *ActividadesController) List(ctx *gin.Context) {
+	if _, hasLimit := ctx.GetQuery("limit"); hasLimit {
+		c.listPage(ctx)
+		return
+	}
+	if _, hasOffset := ctx.GetQuery("offset"); hasOffset {
+		c.listPage(ctx)
+		return
+	}
+
 	actividades, err := c.service.List(ctx.Request.Context())
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al buscar actividades"})
@@ -36,24 +54,150 @@ func (c *ActividadesController) List(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, actividades)
 }
 
-// Search busca actividades por parámetros
-// GET /actividades/buscar?id=1&titulo=yoga&horario=10:00&categoria=fitness
+// listPage responde GET /actividades paginado (la búsqueda sin filtros)
+func (c *ActividadesController) listPage(ctx *gin.Context) {
+	var busqueda domain.ActividadBusqueda
+	if !queryPaginacion(ctx, &busqueda) {
+		return
+	}
+
+	page, err := c.service.Search(ctx.Request.Context(), busqueda)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al buscar actividades"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// Search busca actividades por filtros con orden y paginación
+// GET /actividades/buscar?titulo=yoga&categoria=yoga,pilates&dia=Lunes&dia=Martes&desde=09:00&hasta=12:00&min_lugares=1&orden=horario&limit=20&offset=0
+// Los filtros con varios valores se repiten o se separan con comas
 // Migrado de backend/controllers/actividad/actividad_controller.go:15
 func (c *ActividadesController) Search(ctx *gin.Context) {
-	params := map[string]interface{}{
-		"id":        ctx.Query("id"),
-		"titulo":    ctx.Query("titulo"),
-		"horario":   ctx.Query("horario"),
-		"categoria": ctx.Query("categoria"),
+	busqueda := domain.ActividadBusqueda{
+		Titulo:       strings.TrimSpace(ctx.Query("titulo")),
+		Categorias:   queryList(ctx, "categoria"),
+		Dias:         queryList(ctx, "dia"),
+		Instructores: queryList(ctx, "instructor"),
+		Orden:        ctx.DefaultQuery("orden", domain.ActividadOrdenID),
+	}
+
+	var ok bool
+	if busqueda.InstructorIDs, ok = queryUintList(ctx, "instructor_id"); !ok {
+		return
+	}
+	if busqueda.SucursalIDs, ok = queryUintList(ctx, "sucursal_id"); !ok {
+		return
+	}
+	if id := ctx.Query("id"); id != "" {
+		idActividad, err := strconv.Atoi(id)
+		if err != nil || idActividad <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "id debe ser un número"})
+			return
+		}
+		idUint := uint(idActividad)
+		busqueda.ID = &idUint
+	}
+
+	// Horarios "HH:MM": un instante (horario) o una ventana (desde/hasta)
+	for param, dst := range map[string]*string{"horario": &busqueda.Horario, "desde": &busqueda.Desde, "hasta": &busqueda.Hasta} {
+		value := strings.TrimSpace(ctx.Query(param))
+		if value == "" {
+			continue
+		}
+		hora, err := time.Parse("15:04", value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " debe tener formato HH:MM"})
+			return
+		}
+		*dst = hora.Format("15:04")
+	}
+	if busqueda.Desde != "" && busqueda.Hasta != "" && busqueda.Desde >= busqueda.Hasta {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "desde debe ser anterior a hasta"})
+		return
+	}
+
+	if minLugares := ctx.Query("min_lugares"); minLugares != "" {
+		value, err := strconv.ParseUint(minLugares, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "min_lugares debe ser un número"})
+			return
+		}
+		busqueda.MinLugares = uint(value)
 	}
 
-	actividades, err := c.service.Search(ctx.Request.Context(), params)
+	switch busqueda.Orden {
+	case domain.ActividadOrdenID, domain.ActividadOrdenHorario, domain.ActividadOrdenPopularidad, domain.ActividadOrdenLugares, domain.ActividadOrdenTitulo:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "orden debe ser id, horario, popularidad, lugares o titulo"})
+		return
+	}
+	switch ctx.Query("dir") {
+	case "", "asc":
+	case "desc":
+		busqueda.Desc = true
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "dir debe ser asc o desc"})
+		return
+	}
+
+	if !queryPaginacion(ctx, &busqueda) {
+		return
+	}
+
+	page, err := c.service.Search(ctx.Request.Context(), busqueda)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al buscar actividades"})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, actividades)
+	ctx.JSON(http.StatusOK, page)
+}
+
+// queryPaginacion lee limit y offset; si alguno es inválido responde 400 y devuelve false
+// Sin limit el servicio usa DefaultSearchLimit (y nunca más de MaxSearchLimit)
+func queryPaginacion(ctx *gin.Context, busqueda *domain.ActividadBusqueda) bool {
+	for param, dst := range map[string]*int{"limit": &busqueda.Limit, "offset": &busqueda.Offset} {
+		value := ctx.Query(param)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " debe ser un número positivo"})
+			return false
+		}
+		*dst = n
+	}
+	return true
+}
+
+// queryList lee un query param con varios valores (repetido o separado por comas)
+func queryList(ctx *gin.Context, name string) []string {
+	var values []string
+	for _, raw := range ctx.QueryArray(name) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// queryUintList lee un query param con varios IDs; si alguno es inválido responde 400 y devuelve false
+func queryUintList(ctx *gin.Context, name string) ([]uint, bool) {
+	var ids []uint
+	for _, value := range queryList(ctx, name) {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || id == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": name + " debe ser un número (o varios separados por comas)"})
+			return nil, false
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, true
 }
 
 // GetByID obtiene una actividad por ID
@@ -72,11 +216,12 @@ func (c *ActividadesController) GetByID(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, actividad.Version)
 	ctx.JSON(http.StatusOK, actividad)
 }
 
 // Create crea una nueva actividad
-// POST /actividades (admin only)
+// POST /actividades (activities:manage o activities:manage:sucursal)
 // Migrado de backend/controllers/actividad/actividad_controller.go:56
 func (c *ActividadesController) Create(ctx *gin.Context) {
 	var actividadCreate domain.ActividadCreate
@@ -85,17 +230,30 @@ func (c *ActividadesController) Create(ctx *gin.Context) {
 		return
 	}
 
-	createdActividad, err := c.service.Create(ctx.Request.Context(), actividadCreate)
+	createdActividad, err := c.service.Create(ctx.Request.Context(), middleware.ActorFromContext(ctx), actividadCreate)
 	if err != nil {
+		if respondConflictoHorario(ctx, err) {
+			return
+		}
+		if strings.HasPrefix(err.Error(), "forbidden") {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if isSucursalError(err) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear la actividad", "details": err.Error()})
 		return
 	}
 
+	setETag(ctx, createdActividad.Version)
 	ctx.JSON(http.StatusCreated, createdActividad)
 }
 
 // Update actualiza una actividad existente
-// PUT /actividades/:id (admin only)
+// PUT /actividades/:id (activities:manage, activities:manage:sucursal o activities:update:own)
+// Con el header If-Match (ETag de GET /actividades/:id) responde 412 si otro la modificó antes
 // Migrado de backend/controllers/actividad/actividad_controller.go:73
 func (c *ActividadesController) Update(ctx *gin.Context) {
 	idActividad, err := strconv.Atoi(ctx.Param("id"))
@@ -103,33 +261,83 @@ func (c *ActividadesController) Update(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
 		return
 	}
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
 
 	var actividadUpdate domain.ActividadUpdate
 	if err := ctx.ShouldBindJSON(&actividadUpdate); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
 		return
 	}
+	actividadUpdate.Version = version
 
-	updatedActividad, err := c.service.Update(ctx.Request.Context(), uint(idActividad), actividadUpdate)
+	updatedActividad, err := c.service.Update(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idActividad), actividadUpdate)
 	if err != nil {
-		errString := err.Error()
+		respondUpdateError(ctx, err)
+		return
+	}
 
-		// Detectar errores específicos del hook BeforeUpdate
-		if strings.Contains(errString, "inscripciones activas que superan el nuevo límite") {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		} else if strings.Contains(errString, "not found") {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Actividad no encontrada"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+	setETag(ctx, updatedActividad.Version)
+	ctx.JSON(http.StatusOK, updatedActividad)
+}
+
+// Patch actualiza solo los campos enviados de una actividad
+// PATCH /actividades/:id (mismos permisos que PUT; If-Match opcional)
+func (c *ActividadesController) Patch(ctx *gin.Context) {
+	idActividad, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
 		return
 	}
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
+	var patch domain.ActividadPatch
+	if err := ctx.ShouldBindJSON(&patch); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
+		return
+	}
+	patch.Version = version
 
+	updatedActividad, err := c.service.Patch(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idActividad), patch)
+	if err != nil {
+		respondUpdateError(ctx, err)
+		return
+	}
+
+	setETag(ctx, updatedActividad.Version)
 	ctx.JSON(http.StatusOK, updatedActividad)
 }
 
+// respondUpdateError traduce los errores de PUT y PATCH a códigos HTTP
+func respondUpdateError(ctx *gin.Context, err error) {
+	if respondConflictoHorario(ctx, err) {
+		return
+	}
+	if errors.Is(err, domain.ErrVersionConflict) {
+		ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": domain.ErrVersionConflict.Error(), "code": "VERSION_CONFLICT"})
+		return
+	}
+	errString := err.Error()
+
+	// Detectar errores específicos del hook BeforeUpdate
+	if strings.HasPrefix(errString, "forbidden") {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errString})
+	} else if strings.Contains(errString, "inscripciones activas que superan el nuevo límite") || isSucursalError(err) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	} else if strings.Contains(errString, "not found") {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Actividad no encontrada"})
+	} else {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // Delete elimina una actividad
-// DELETE /actividades/:id (admin only)
+// DELETE /actividades/:id (activities:manage o activities:manage:sucursal)
 // Migrado de backend/controllers/actividad/actividad_controller.go:109
 func (c *ActividadesController) Delete(ctx *gin.Context) {
 	idActividad, err := strconv.Atoi(ctx.Param("id"))
@@ -138,9 +346,11 @@ func (c *ActividadesController) Delete(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.Delete(ctx.Request.Context(), uint(idActividad))
+	err = c.service.Delete(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idActividad))
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if strings.HasPrefix(err.Error(), "forbidden") {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "not found") {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Actividad no encontrada"})
 		} else {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -150,3 +360,183 @@ func (c *ActividadesController) Delete(ctx *gin.Context) {
 
 	ctx.Status(http.StatusNoContent)
 }
+
+// ListConflictosForzados obtiene las superposiciones de horario que se forzaron al guardar la actividad
+// GET /actividades/:id/conflictos (activities:manage o activities:manage:sucursal)
+func (c *ActividadesController) ListConflictosForzados(ctx *gin.Context) {
+	idActividad, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	conflictos, err := c.service.ListConflictosForzados(ctx.Request.Context(), uint(idActividad))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Actividad no encontrada"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al buscar los conflictos", "details": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conflictos)
+}
+
+// Import da de alta un catálogo de actividades desde un CSV (Content-Type: text/csv) o un array JSON
+// POST /actividades/importar?modo=atomico|parcial&dry_run=true&sucursal_id= (activities:manage o activities:manage:sucursal)
+func (c *ActividadesController) Import(ctx *gin.Context) {
+	opts := domain.ActividadImportOptions{Modo: ctx.DefaultQuery("modo", domain.ImportModoAtomico)}
+	if opts.Modo != domain.ImportModoAtomico && opts.Modo != domain.ImportModoParcial {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "modo debe ser atomico o parcial"})
+		return
+	}
+	if dryRun := ctx.Query("dry_run"); dryRun != "" {
+		value, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "dry_run debe ser true o false"})
+			return
+		}
+		opts.DryRun = value
+	}
+	sucursalID, ok := querySucursalID(ctx)
+	if !ok {
+		return
+	}
+	opts.SucursalID = sucursalID
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBytes)
+	var filas []domain.ActividadImportFila
+	var err error
+	if ctx.ContentType() == "text/csv" {
+		filas, err = services.ParseActividadesCSV(body)
+	} else {
+		filas, err = services.ParseActividadesJSON(body)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Archivo con formato incorrecto", "details": err.Error()})
+		return
+	}
+	if len(filas) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No hay actividades para importar"})
+		return
+	}
+	if len(filas) > services.MaxImportFilas {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Se pueden importar hasta %d actividades por archivo", services.MaxImportFilas)})
+		return
+	}
+
+	result, err := c.service.Import(ctx.Request.Context(), middleware.ActorFromContext(ctx), filas, opts)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al importar las actividades", "details": err.Error()})
+		return
+	}
+
+	switch {
+	case result.DryRun:
+		ctx.JSON(http.StatusOK, result)
+	case result.Creadas == 0:
+		ctx.JSON(http.StatusUnprocessableEntity, result)
+	default:
+		ctx.JSON(http.StatusCreated, result)
+	}
+}
+
+// Export descarga el catálogo de actividades en CSV o JSON (importable con POST /actividades/importar)
+// GET /actividades/exportar?formato=csv|json&sucursal_id= (activities:manage o activities:manage:sucursal)
+func (c *ActividadesController) Export(ctx *gin.Context) {
+	formato := ctx.DefaultQuery("formato", "json")
+	if formato != "csv" && formato != "json" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "formato debe ser csv o json"})
+		return
+	}
+	sucursalID, ok := querySucursalID(ctx)
+	if !ok {
+		return
+	}
+
+	catalogo, err := c.service.Export(ctx.Request.Context(), sucursalID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al exportar las actividades", "details": err.Error()})
+		return
+	}
+
+	filename := "actividades"
+	if sucursalID != nil {
+		filename = fmt.Sprintf("actividades-sucursal-%d", *sucursalID)
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, formato))
+
+	if formato == "json" {
+		ctx.JSON(http.StatusOK, catalogo)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteActividadesCSV(&buf, catalogo); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al exportar las actividades", "details": err.Error()})
+		return
+	}
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// querySucursalID lee el query param opcional sucursal_id; si es inválido responde 400 y devuelve false
+func querySucursalID(ctx *gin.Context) (*uint, bool) {
+	sucursalID := ctx.Query("sucursal_id")
+	if sucursalID == "" {
+		return nil, true
+	}
+
+	id, err := strconv.Atoi(sucursalID)
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "sucursal_id debe ser un número"})
+		return nil, false
+	}
+	idSucursal := uint(id)
+	return &idSucursal, true
+}
+
+// respondConflictoHorario responde 409 con las actividades superpuestas si el error es un conflicto de horario
+func respondConflictoHorario(ctx *gin.Context, err error) bool {
+	var conflictoErr *domain.ConflictoHorarioError
+	if !errors.As(err, &conflictoErr) {
+		return false
+	}
+
+	ctx.JSON(http.StatusConflict, gin.H{
+		"error":      "La actividad se superpone con otras del mismo instructor o sala",
+		"code":       "SCHEDULE_CONFLICT",
+		"details":    conflictoErr.Error(),
+		"conflictos": conflictoErr.Conflictos,
+	})
+	return true
+}
+
+// setETag informa la versión de la actividad (se devuelve en If-Match para PUT y PATCH)
+func setETag(ctx *gin.Context, version uint) {
+	ctx.Header("ETag", strconv.Quote(strconv.FormatUint(uint64(version), 10)))
+}
+
+// ifMatchVersion lee la versión del header If-Match ("3" o W/"3"); 0 si no vino o es "*"
+// Si el header es inválido responde 400 y devuelve false
+func ifMatchVersion(ctx *gin.Context) (uint, bool) {
+	ifMatch := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, true
+	}
+
+	version, err := strconv.ParseUint(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`), 10, 32)
+	if err != nil || version == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "If-Match inválido (debe ser el ETag de la actividad)"})
+		return 0, false
+	}
+
+	return uint(version), true
+}
+
+// isSucursalError indica si el error es de validación de la sucursal de la actividad
+func isSucursalError(err error) bool {
+	errString := err.Error()
+	return (strings.Contains(errString, "la sucursal") && strings.Contains(errString, "no existe")) ||
+		strings.Contains(errString, "supera la capacidad de la sucursal")
+}
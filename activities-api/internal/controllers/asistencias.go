@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/middleware"
+	"activities-api/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AsistenciasController maneja las peticiones HTTP de asistencia a sesiones
+type AsistenciasController struct {
+	service services.AsistenciasService
+}
+
+// NewAsistenciasController crea una nueva instancia del controller
+func NewAsistenciasController(service services.AsistenciasService) *AsistenciasController {
+	return &AsistenciasController{
+		service: service,
+	}
+}
+
+// CheckIn registra la asistencia del usuario autenticado con el código (QR) de la sesión
+// POST /sesiones/:id/checkin {"codigo": "..."} (requiere JWT)
+func (c *AsistenciasController) CheckIn(ctx *gin.Context) {
+	userID, exists := ctx.Get("id_usuario")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	idSesion, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	var checkIn domain.AsistenciaCheckIn
+	if err := ctx.ShouldBindJSON(&checkIn); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
+		return
+	}
+
+	asistencia, err := c.service.CheckIn(ctx.Request.Context(), userID.(uint), uint(idSesion), checkIn.Codigo)
+	if err != nil {
+		respondAsistenciaError(ctx, err, "Error al registrar la asistencia")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, asistencia)
+}
+
+// CheckOut registra la salida del usuario autenticado
+// POST /sesiones/:id/checkout (requiere JWT)
+func (c *AsistenciasController) CheckOut(ctx *gin.Context) {
+	userID, exists := ctx.Get("id_usuario")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	idSesion, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	asistencia, err := c.service.CheckOut(ctx.Request.Context(), userID.(uint), uint(idSesion))
+	if err != nil {
+		respondAsistenciaError(ctx, err, "Error al registrar la salida")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, asistencia)
+}
+
+// ListMine obtiene el historial de asistencia del usuario autenticado
+// GET /asistencias?desde=YYYY-MM-DD&hasta=YYYY-MM-DD (requiere JWT)
+func (c *AsistenciasController) ListMine(ctx *gin.Context) {
+	userID, exists := ctx.Get("id_usuario")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	c.listByUser(ctx, userID.(uint))
+}
+
+// ListByUser obtiene el historial de asistencia de un socio
+// GET /usuarios/:id/asistencias?desde=&hasta= (activities:manage)
+func (c *AsistenciasController) ListByUser(ctx *gin.Context) {
+	idUsuario, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	c.listByUser(ctx, uint(idUsuario))
+}
+
+// CodigoCheckin obtiene el código de la sesión para mostrar como QR
+// GET /sesiones/:id/codigo-checkin (activities:manage[:sucursal] | activities:update:own)
+func (c *AsistenciasController) CodigoCheckin(ctx *gin.Context) {
+	idSesion, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	codigo, err := c.service.CodigoCheckin(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idSesion))
+	if err != nil {
+		respondAsistenciaError(ctx, err, "Error al obtener el código de check-in")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, codigo)
+}
+
+// ListBySesion obtiene la planilla de asistencia de una sesión
+// GET /sesiones/:id/asistencias (activities:manage[:sucursal] | activities:update:own)
+func (c *AsistenciasController) ListBySesion(ctx *gin.Context) {
+	idSesion, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	planilla, err := c.service.ListBySesion(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idSesion))
+	if err != nil {
+		respondAsistenciaError(ctx, err, "Error al buscar la asistencia")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, planilla)
+}
+
+// RollCall registra la asistencia de un socio al tomar lista
+// POST /sesiones/:id/asistencias {"usuario_id": 5} (activities:manage[:sucursal] | activities:update:own)
+func (c *AsistenciasController) RollCall(ctx *gin.Context) {
+	idSesion, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	var rollCall domain.AsistenciaRollCall
+	if err := ctx.ShouldBindJSON(&rollCall); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
+		return
+	}
+
+	asistencia, err := c.service.RollCall(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idSesion), rollCall.UsuarioID)
+	if err != nil {
+		respondAsistenciaError(ctx, err, "Error al registrar la asistencia")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, asistencia)
+}
+
+// RollCallCheckOut registra la salida de un socio desde la planilla
+// POST /sesiones/:id/asistencias/:usuario_id/checkout (activities:manage[:sucursal] | activities:update:own)
+func (c *AsistenciasController) RollCallCheckOut(ctx *gin.Context) {
+	idSesion, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+	idUsuario, err := strconv.Atoi(ctx.Param("usuario_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "usuario_id debe ser un número"})
+		return
+	}
+
+	asistencia, err := c.service.RollCallCheckOut(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idSesion), uint(idUsuario))
+	if err != nil {
+		respondAsistenciaError(ctx, err, "Error al registrar la salida")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, asistencia)
+}
+
+// ListByActividad resume la asistencia de las sesiones de una actividad
+// GET /actividades/:id/asistencias?desde=&hasta= (activities:manage[:sucursal] | activities:update:own)
+func (c *AsistenciasController) ListByActividad(ctx *gin.Context) {
+	idActividad, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	filtro := domain.AsistenciaFiltro{
+		FechaDesde: ctx.Query("desde"),
+		FechaHasta: ctx.Query("hasta"),
+	}
+
+	resumenes, err := c.service.ListByActividad(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idActividad), filtro)
+	if err != nil {
+		respondAsistenciaError(ctx, err, "Error al buscar la asistencia")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resumenes)
+}
+
+// LiftSuspension levanta la suspensión por inasistencias de un socio
+// DELETE /usuarios/:id/suspension (activities:manage)
+func (c *AsistenciasController) LiftSuspension(ctx *gin.Context) {
+	idUsuario, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	suspension, err := c.service.LiftSuspension(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idUsuario))
+	if err != nil {
+		respondAsistenciaError(ctx, err, "Error al levantar la suspensión")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, suspension)
+}
+
+// listByUser responde el historial de asistencia de un socio con el rango de la query
+func (c *AsistenciasController) listByUser(ctx *gin.Context, usuarioID uint) {
+	filtro := domain.AsistenciaFiltro{
+		FechaDesde: ctx.Query("desde"),
+		FechaHasta: ctx.Query("hasta"),
+	}
+
+	historial, err := c.service.ListByUser(ctx.Request.Context(), usuarioID, filtro)
+	if err != nil {
+		respondAsistenciaError(ctx, err, "Error al buscar la asistencia")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, historial)
+}
+
+// respondAsistenciaError traduce los errores del servicio de asistencia a códigos HTTP
+func respondAsistenciaError(ctx *gin.Context, err error, msg string) {
+	errString := err.Error()
+
+	if strings.HasPrefix(errString, "forbidden") || strings.Contains(errString, "no está inscripto") {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errString})
+	} else if strings.Contains(errString, "not found") {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No encontrado", "details": errString})
+	} else if strings.Contains(errString, "ya registró") || strings.Contains(errString, "cancelada") {
+		ctx.JSON(http.StatusConflict, gin.H{"error": errString})
+	} else if strings.HasPrefix(errString, "error ") {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg, "details": errString})
+	} else {
+		// Errores de validación del servicio (código, ventana de check-in, fechas)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errString})
+	}
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// icsContentType es el tipo de los feeds iCalendar
+const icsContentType = "text/calendar; charset=utf-8"
+
+// CalendarioController maneja los feeds ICS y el token del feed personal
+type CalendarioController struct {
+	service services.CalendarioService
+}
+
+// NewCalendarioController crea una nueva instancia del controller
+func NewCalendarioController(service services.CalendarioService) *CalendarioController {
+	return &CalendarioController{
+		service: service,
+	}
+}
+
+// CreateToken genera (o regenera, revocando el anterior) el token del feed del usuario autenticado
+// POST /calendario/token (requiere JWT)
+func (c *CalendarioController) CreateToken(ctx *gin.Context) {
+	userID, exists := ctx.Get("id_usuario")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	token, err := c.service.CreateToken(ctx.Request.Context(), userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al generar el token del calendario", "details": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, token)
+}
+
+// RevokeToken revoca el token del feed del usuario autenticado
+// DELETE /calendario/token (requiere JWT)
+func (c *CalendarioController) RevokeToken(ctx *gin.Context) {
+	userID, exists := ctx.Get("id_usuario")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	if err := c.service.RevokeToken(ctx.Request.Context(), userID.(uint)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "No hay un token de calendario activo"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al revocar el token del calendario", "details": err.Error()})
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// MemberFeed devuelve el calendario de las clases del socio dueño del token
+// GET /calendario/socios/:token (el token va en la URL: las apps de calendario no mandan el Bearer)
+func (c *CalendarioController) MemberFeed(ctx *gin.Context) {
+	token := strings.TrimSuffix(ctx.Param("token"), ".ics")
+
+	feed, err := c.service.MemberFeed(ctx.Request.Context(), token)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Calendario no encontrado (el token no existe o fue revocado)"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al generar el calendario", "details": err.Error()})
+		}
+		return
+	}
+
+	ctx.Header("Cache-Control", "private, max-age=900")
+	ctx.Data(http.StatusOK, icsContentType, feed)
+}
+
+// PublicFeed devuelve el calendario de horarios del gimnasio
+// GET /calendario/actividades.ics?sucursal_id=&categoria=
+func (c *CalendarioController) PublicFeed(ctx *gin.Context) {
+	sucursalID, ok := querySucursalID(ctx)
+	if !ok {
+		return
+	}
+	filtro := domain.CalendarioFiltro{
+		SucursalID: sucursalID,
+		Categoria:  strings.TrimSpace(ctx.Query("categoria")),
+	}
+
+	feed, err := c.service.PublicFeed(ctx.Request.Context(), filtro)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al generar el calendario", "details": err.Error()})
+		return
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=900")
+	ctx.Data(http.StatusOK, icsContentType, feed)
+}
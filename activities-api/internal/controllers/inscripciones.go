@@ -3,6 +3,7 @@ package controllers
 import (
 	"activities-api/internal/domain"
 	"activities-api/internal/services"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -64,22 +65,7 @@ func (c *InscripcionesController) Create(ctx *gin.Context) {
 
 	createdInscripcion, err := c.service.Create(ctx.Request.Context(), userID.(uint), inscripcionCreate.ActividadID)
 	if err != nil {
-		errString := strings.ToLower(err.Error())
-
-		// Detectar errores específicos del hook BeforeCreate
-		if strings.Contains(errString, "ya está inscripto") || strings.Contains(errString, "ya esta inscripto") {
-			ctx.JSON(http.StatusConflict, gin.H{"error": "El usuario ya está inscripto a esta actividad"})
-		} else if strings.Contains(errString, "cupo de la actividad ha sido alcanzado") {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "No se puede inscribir, el cupo de la actividad ha sido alcanzado"})
-		} else if strings.Contains(errString, "actividad no encontrada") || strings.Contains(errString, "not found") {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "La actividad no existe"})
-		} else if strings.Contains(errString, "no tiene suscripción activa") {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "Debe tener una suscripción activa para inscribirse"})
-		} else if strings.Contains(errString, "requiere plan premium") {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "Esta actividad requiere un plan premium"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al inscribir el usuario", "details": err.Error()})
-		}
+		respondInscripcionError(ctx, err, "Error al inscribir el usuario")
 		return
 	}
 
@@ -120,3 +106,40 @@ func (c *InscripcionesController) Deactivate(ctx *gin.Context) {
 
 	ctx.Status(http.StatusNoContent)
 }
+
+// respondInscripcionError responde los rechazos con código legible por máquina (ver domain.InscripcionErr*)
+// y cualquier otro error como 500
+func respondInscripcionError(ctx *gin.Context, err error, msg string) {
+	var inscripcionErr *domain.InscripcionError
+	if errors.As(err, &inscripcionErr) {
+		response := gin.H{"error": inscripcionErr.Message, "code": inscripcionErr.Code}
+		if inscripcionErr.Err != nil {
+			response["details"] = inscripcionErr.Err.Error()
+		}
+		ctx.JSON(inscripcionErrorStatus(inscripcionErr.Code), response)
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg, "details": err.Error()})
+}
+
+// inscripcionErrorStatus traduce el código de rechazo de una inscripción a HTTP
+func inscripcionErrorStatus(code string) int {
+	switch code {
+	case domain.InscripcionErrUserNotFound, domain.InscripcionErrActivityNotFound,
+		domain.InscripcionErrNotWaitlisted, domain.InscripcionErrNoPendingPromotion,
+		domain.InscripcionErrSessionNotFound, domain.InscripcionErrNotEnrolled:
+		return http.StatusNotFound
+	case domain.InscripcionErrNoActiveSubscription, domain.InscripcionErrPlanNotCovering,
+		domain.InscripcionErrSuspended:
+		return http.StatusForbidden
+	case domain.InscripcionErrAlreadyEnrolled, domain.InscripcionErrAlreadyWaitlisted, domain.InscripcionErrActivityHasSpots,
+		domain.InscripcionErrWaitlistPending, domain.InscripcionErrSessionNotAvailable:
+		return http.StatusConflict
+	case domain.InscripcionErrActivityFull:
+		return http.StatusBadRequest
+	case domain.InscripcionErrEntitlementsUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"activities-api/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListaEsperaController maneja las peticiones HTTP de la lista de espera
+type ListaEsperaController struct {
+	service services.ListaEsperaService
+}
+
+// NewListaEsperaController crea una nueva instancia del controller
+func NewListaEsperaController(service services.ListaEsperaService) *ListaEsperaController {
+	return &ListaEsperaController{
+		service: service,
+	}
+}
+
+// listaEsperaRequest es el body de las operaciones sobre la lista de espera
+type listaEsperaRequest struct {
+	ActividadID uint `json:"actividad_id" binding:"required"`
+}
+
+// List obtiene las listas de espera vigentes del usuario autenticado con su posición
+// GET /lista-espera (requiere JWT)
+func (c *ListaEsperaController) List(ctx *gin.Context) {
+	userID, exists := ctx.Get("id_usuario")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	entries, err := c.service.ListByUser(ctx.Request.Context(), userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al procesar la consulta"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// Join anota al usuario autenticado en la lista de espera de una actividad completa
+// POST /lista-espera {"actividad_id": 1} (requiere JWT)
+func (c *ListaEsperaController) Join(ctx *gin.Context) {
+	userID, exists := ctx.Get("id_usuario")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request listaEsperaRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
+		return
+	}
+
+	entry, err := c.service.Join(ctx.Request.Context(), userID.(uint), request.ActividadID)
+	if err != nil {
+		respondInscripcionError(ctx, err, "Error al anotar al usuario en la lista de espera")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+// Confirm confirma el lugar asignado al usuario autenticado desde la lista de espera
+// POST /lista-espera/confirmar {"actividad_id": 1} (requiere JWT)
+func (c *ListaEsperaController) Confirm(ctx *gin.Context) {
+	userID, exists := ctx.Get("id_usuario")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request listaEsperaRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
+		return
+	}
+
+	entry, err := c.service.Confirm(ctx.Request.Context(), userID.(uint), request.ActividadID)
+	if err != nil {
+		respondInscripcionError(ctx, err, "Error al confirmar el lugar")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entry)
+}
+
+// Leave saca al usuario autenticado de la lista de espera (o rechaza el lugar asignado)
+// DELETE /lista-espera {"actividad_id": 1} (requiere JWT)
+func (c *ListaEsperaController) Leave(ctx *gin.Context) {
+	userID, exists := ctx.Get("id_usuario")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request listaEsperaRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
+		return
+	}
+
+	if err := c.service.Leave(ctx.Request.Context(), userID.(uint), request.ActividadID); err != nil {
+		respondInscripcionError(ctx, err, "Error al salir de la lista de espera")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
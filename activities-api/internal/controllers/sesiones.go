@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/middleware"
+	"activities-api/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SesionesController maneja las peticiones HTTP de sesiones fechadas y sus reglas de recurrencia
+type SesionesController struct {
+	service services.SesionesService
+}
+
+// NewSesionesController crea una nueva instancia del controller
+func NewSesionesController(service services.SesionesService) *SesionesController {
+	return &SesionesController{
+		service: service,
+	}
+}
+
+// List obtiene las sesiones de un rango de fechas
+// GET /sesiones?desde=YYYY-MM-DD&hasta=YYYY-MM-DD&actividad_id=&sucursal_id=&estado=
+func (c *SesionesController) List(ctx *gin.Context) {
+	filtro := domain.SesionFiltro{
+		FechaDesde: ctx.Query("desde"),
+		FechaHasta: ctx.Query("hasta"),
+		Estado:     ctx.Query("estado"),
+	}
+
+	if actividadID := ctx.Query("actividad_id"); actividadID != "" {
+		id, err := strconv.Atoi(actividadID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "actividad_id debe ser un número"})
+			return
+		}
+		idActividad := uint(id)
+		filtro.ActividadID = &idActividad
+	}
+	if sucursalID := ctx.Query("sucursal_id"); sucursalID != "" {
+		id, err := strconv.Atoi(sucursalID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "sucursal_id debe ser un número"})
+			return
+		}
+		idSucursal := uint(id)
+		filtro.SucursalID = &idSucursal
+	}
+	if filtro.Estado != "" && filtro.Estado != domain.SesionProgramada && filtro.Estado != domain.SesionCancelada {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "estado debe ser programada o cancelada"})
+		return
+	}
+
+	sesiones, err := c.service.List(ctx.Request.Context(), filtro)
+	if err != nil {
+		respondSesionError(ctx, err, "Error al buscar sesiones")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sesiones)
+}
+
+// GetByID obtiene una sesión por ID
+// GET /sesiones/:id
+func (c *SesionesController) GetByID(ctx *gin.Context) {
+	idSesion, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	sesion, err := c.service.GetByID(ctx.Request.Context(), uint(idSesion))
+	if err != nil {
+		respondSesionError(ctx, err, "Error al buscar la sesión")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sesion)
+}
+
+// Create crea una sesión suelta
+// POST /sesiones (activities:manage[:sucursal])
+func (c *SesionesController) Create(ctx *gin.Context) {
+	var sesionCreate domain.SesionCreate
+	if err := ctx.ShouldBindJSON(&sesionCreate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
+		return
+	}
+
+	createdSesion, err := c.service.Create(ctx.Request.Context(), middleware.ActorFromContext(ctx), sesionCreate)
+	if err != nil {
+		respondSesionError(ctx, err, "Error al crear la sesión")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createdSesion)
+}
+
+// Update reprograma una sesión y/o cambia su cupo
+// PUT /sesiones/:id (activities:manage[:sucursal] | activities:update:own)
+func (c *SesionesController) Update(ctx *gin.Context) {
+	idSesion, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	var sesionUpdate domain.SesionUpdate
+	if err := ctx.ShouldBindJSON(&sesionUpdate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
+		return
+	}
+
+	updatedSesion, err := c.service.Update(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idSesion), sesionUpdate)
+	if err != nil {
+		respondSesionError(ctx, err, "Error al actualizar la sesión")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updatedSesion)
+}
+
+// Cancel cancela una sesión puntual
+// POST /sesiones/:id/cancelar {"motivo": "..."} (activities:manage[:sucursal] | activities:update:own)
+func (c *SesionesController) Cancel(ctx *gin.Context) {
+	idSesion, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	// El motivo es opcional: el body puede venir vacío
+	var sesionCancel domain.SesionCancel
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&sesionCancel); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
+			return
+		}
+	}
+
+	cancelledSesion, err := c.service.Cancel(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idSesion), sesionCancel.Motivo)
+	if err != nil {
+		respondSesionError(ctx, err, "Error al cancelar la sesión")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cancelledSesion)
+}
+
+// ListReglas obtiene las reglas de recurrencia de una actividad
+// GET /actividades/:id/recurrencias
+func (c *SesionesController) ListReglas(ctx *gin.Context) {
+	idActividad, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	reglas, err := c.service.ListReglas(ctx.Request.Context(), uint(idActividad))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al buscar las recurrencias"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reglas)
+}
+
+// CreateRegla crea una regla de recurrencia y genera sus sesiones
+// POST /actividades/:id/recurrencias (activities:manage[:sucursal])
+func (c *SesionesController) CreateRegla(ctx *gin.Context) {
+	idActividad, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	var reglaCreate domain.ReglaRecurrenciaCreate
+	if err := ctx.ShouldBindJSON(&reglaCreate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
+		return
+	}
+
+	createdRegla, err := c.service.CreateRegla(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idActividad), reglaCreate)
+	if err != nil {
+		respondSesionError(ctx, err, "Error al crear la recurrencia")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createdRegla)
+}
+
+// DeleteRegla borra una regla y cancela sus sesiones futuras
+// DELETE /recurrencias/:id (activities:manage[:sucursal])
+func (c *SesionesController) DeleteRegla(ctx *gin.Context) {
+	idRegla, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	if err := c.service.DeleteRegla(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idRegla)); err != nil {
+		respondSesionError(ctx, err, "Error al eliminar la recurrencia")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Enroll inscribe al usuario autenticado en una sesión
+// POST /sesiones/:id/inscripcion (requiere JWT)
+func (c *SesionesController) Enroll(ctx *gin.Context) {
+	userID, exists := ctx.Get("id_usuario")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	idSesion, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	inscripcion, err := c.service.Enroll(ctx.Request.Context(), userID.(uint), uint(idSesion))
+	if err != nil {
+		respondInscripcionError(ctx, err, "Error al inscribir el usuario en la sesión")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, inscripcion)
+}
+
+// Unenroll desinscribe al usuario autenticado de una sesión
+// DELETE /sesiones/:id/inscripcion (requiere JWT)
+func (c *SesionesController) Unenroll(ctx *gin.Context) {
+	userID, exists := ctx.Get("id_usuario")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	idSesion, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	if err := c.service.Unenroll(ctx.Request.Context(), userID.(uint), uint(idSesion)); err != nil {
+		respondInscripcionError(ctx, err, "Error al desinscribir al usuario de la sesión")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListInscripciones obtiene las inscripciones del usuario autenticado a sesiones desde hoy
+// GET /inscripciones/sesiones (requiere JWT)
+func (c *SesionesController) ListInscripciones(ctx *gin.Context) {
+	userID, exists := ctx.Get("id_usuario")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	inscripciones, err := c.service.ListInscripcionesByUser(ctx.Request.Context(), userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al procesar la consulta"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, inscripciones)
+}
+
+// respondSesionError traduce los errores del servicio de sesiones a códigos HTTP
+func respondSesionError(ctx *gin.Context, err error, msg string) {
+	errString := err.Error()
+
+	if strings.HasPrefix(errString, "forbidden") {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errString})
+	} else if strings.Contains(errString, "not found") {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No encontrado", "details": errString})
+	} else if strings.Contains(errString, "cancelada") {
+		ctx.JSON(http.StatusConflict, gin.H{"error": errString})
+	} else if strings.HasPrefix(errString, "error ") {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg, "details": errString})
+	} else {
+		// Errores de validación del servicio (fechas, horarios, cupo)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errString})
+	}
+}
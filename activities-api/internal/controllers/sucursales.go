@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/middleware"
+	"activities-api/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SucursalesController maneja las peticiones HTTP relacionadas con sucursales
+type SucursalesController struct {
+	service services.SucursalesService
+}
+
+// NewSucursalesController crea una nueva instancia del controller
+func NewSucursalesController(service services.SucursalesService) *SucursalesController {
+	return &SucursalesController{
+		service: service,
+	}
+}
+
+// List obtiene todas las sucursales
+// GET /sucursales
+func (c *SucursalesController) List(ctx *gin.Context) {
+	sucursales, err := c.service.List(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al buscar sucursales"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sucursales)
+}
+
+// GetByID obtiene una sucursal por ID
+// GET /sucursales/:id
+func (c *SucursalesController) GetByID(ctx *gin.Context) {
+	idSucursal, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	sucursal, err := c.service.GetByID(ctx.Request.Context(), uint(idSucursal))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "La sucursal no existe"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al buscar la sucursal"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sucursal)
+}
+
+// Create crea una nueva sucursal
+// POST /sucursales (activities:manage)
+func (c *SucursalesController) Create(ctx *gin.Context) {
+	var sucursalCreate domain.SucursalCreate
+	if err := ctx.ShouldBindJSON(&sucursalCreate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
+		return
+	}
+
+	createdSucursal, err := c.service.Create(ctx.Request.Context(), middleware.ActorFromContext(ctx), sucursalCreate)
+	if err != nil {
+		respondSucursalError(ctx, err, "Error al crear la sucursal")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createdSucursal)
+}
+
+// Update actualiza una sucursal existente
+// PUT /sucursales/:id (activities:manage o activities:manage:sucursal)
+func (c *SucursalesController) Update(ctx *gin.Context) {
+	idSucursal, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	var sucursalUpdate domain.SucursalUpdate
+	if err := ctx.ShouldBindJSON(&sucursalUpdate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto", "details": err.Error()})
+		return
+	}
+
+	updatedSucursal, err := c.service.Update(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idSucursal), sucursalUpdate)
+	if err != nil {
+		respondSucursalError(ctx, err, "Error al actualizar la sucursal")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updatedSucursal)
+}
+
+// Delete da de baja una sucursal
+// DELETE /sucursales/:id (activities:manage)
+func (c *SucursalesController) Delete(ctx *gin.Context) {
+	idSucursal, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El id debe ser un número"})
+		return
+	}
+
+	err = c.service.Delete(ctx.Request.Context(), middleware.ActorFromContext(ctx), uint(idSucursal))
+	if err != nil {
+		respondSucursalError(ctx, err, "Error al eliminar la sucursal")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// respondSucursalError traduce los errores del servicio de sucursales a códigos HTTP
+func respondSucursalError(ctx *gin.Context, err error, msg string) {
+	errString := err.Error()
+
+	if strings.HasPrefix(errString, "forbidden") {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errString})
+	} else if strings.Contains(errString, "not found") {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Sucursal no encontrada"})
+	} else if strings.Contains(errString, "actividades asignadas") {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "No se puede eliminar una sucursal con actividades asignadas", "details": errString})
+	} else if strings.HasPrefix(errString, "error ") {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg, "details": errString})
+	} else {
+		// Errores de validación del servicio (nombre, horarios, coordenadas, capacidad)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errString})
+	}
+}
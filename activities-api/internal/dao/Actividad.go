@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Actividad representa el modelo de base de datos con tags de GORM
@@ -20,8 +21,11 @@ type Actividad struct {
 	HorarioFinal  time.Time `gorm:"column:horario_final;type:time;not null"`
 	FotoUrl       string    `gorm:"column:foto_url;type:varchar(511);not null"`
 	Instructor    string    `gorm:"type:varchar(50);not null"`
+	InstructorID  *uint     `gorm:"column:instructor_id;index"` // Usuario instructor (referencia lógica a users-api)
 	Categoria     string    `gorm:"type:varchar(40);not null"`
-	SucursalID    *uint     `gorm:"column:sucursal_id;index"` // TODO: Agregar FK cuando se cree Sucursal
+	SucursalID    *uint     `gorm:"column:sucursal_id;index"` // FK a sucursales.id (ver dao.Sucursal)
+	Sala          string    `gorm:"type:varchar(50);not null;default:''"`
+	Version       uint      `gorm:"not null;default:1"` // Concurrencia optimista (ETag / If-Match)
 	CreatedAt     time.Time `gorm:"autoCreateTime"`
 	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
 
@@ -39,6 +43,15 @@ func (Actividad) TableName() string {
 func (ac *Actividad) BeforeUpdate(tx *gorm.DB) (err error) {
 	var inscActivas int64
 
+	// Bloquear la actividad como al inscribir, para que no entre una inscripción entre el conteo y el update
+	err = tx.Session(&gorm.Session{NewDB: true}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id_actividad").
+		First(&Actividad{}, "id_actividad = ?", ac.ID).Error
+	if err != nil {
+		return err
+	}
+
 	err = tx.Model(&Inscripcion{}).
 		Where("actividad_id = ? AND is_activa = ?", ac.ID, true).
 		Count(&inscActivas).Error
@@ -65,8 +78,11 @@ func (a Actividad) ToDomain() domain.Actividad {
 		HorarioFinal:  a.HorarioFinal.Format("15:04"),
 		FotoUrl:       a.FotoUrl,
 		Instructor:    a.Instructor,
+		InstructorID:  a.InstructorID,
 		Categoria:     a.Categoria,
 		SucursalID:    a.SucursalID,
+		Sala:          a.Sala,
+		Version:       a.Version,
 		CreatedAt:     a.CreatedAt,
 		UpdatedAt:     a.UpdatedAt,
 	}
@@ -84,8 +100,10 @@ func ActividadFromDomain(domainAct domain.Actividad, horaInicio, horaFin time.Ti
 		HorarioFinal:  horaFin,
 		FotoUrl:       domainAct.FotoUrl,
 		Instructor:    domainAct.Instructor,
+		InstructorID:  domainAct.InstructorID,
 		Categoria:     domainAct.Categoria,
 		SucursalID:    domainAct.SucursalID,
+		Sala:          domainAct.Sala,
 	}
 }
 
@@ -101,9 +119,12 @@ type ActividadVista struct {
 	HorarioFinal  time.Time `gorm:"column:horario_final;type:time"`
 	FotoUrl       string    `gorm:"column:foto_url;type:varchar(511)"`
 	Instructor    string    `gorm:"type:varchar(50)"`
+	InstructorID  *uint     `gorm:"column:instructor_id"`
 	Categoria     string    `gorm:"type:varchar(40)"`
 	Lugares       uint      `gorm:"column:lugares"` // Campo calculado de la vista
 	SucursalID    *uint     `gorm:"column:sucursal_id"`
+	Sala          string    `gorm:"type:varchar(50)"`
+	Version       uint      `gorm:"column:version"`
 }
 
 // TableName especifica el nombre de la vista
@@ -123,8 +144,52 @@ func (av ActividadVista) ToDomain() domain.Actividad {
 		HorarioFinal:  av.HorarioFinal.Format("15:04"),
 		FotoUrl:       av.FotoUrl,
 		Instructor:    av.Instructor,
+		InstructorID:  av.InstructorID,
 		Categoria:     av.Categoria,
 		Lugares:       av.Lugares, // Incluye cupos disponibles
 		SucursalID:    av.SucursalID,
+		Sala:          av.Sala,
+		Version:       av.Version,
+	}
+}
+
+// ActividadDia tiene una fila por día de la semana: se bloquea para serializar las altas y ediciones
+// de actividades del mismo día mientras se controlan las superposiciones
+type ActividadDia struct {
+	Dia string `gorm:"column:dia;type:varchar(20);primaryKey"`
+}
+
+// TableName especifica el nombre de la tabla
+func (ActividadDia) TableName() string {
+	return "actividades_dias"
+}
+
+// ConflictoForzado registra una superposición de horarios que un administrador decidió forzar
+type ConflictoForzado struct {
+	ID             uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	ActividadID    uint      `gorm:"column:actividad_id;not null;index"`
+	ConflictoConID uint      `gorm:"column:conflicto_con_id;not null"`
+	Motivo         string    `gorm:"type:enum('instructor','sala');not null"`
+	ForzadoPor     uint      `gorm:"column:forzado_por;not null"` // Referencia lógica a users-api
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+
+	// Relaciones
+	Actividad Actividad `gorm:"foreignKey:ActividadID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica el nombre de la tabla
+func (ConflictoForzado) TableName() string {
+	return "conflictos_horario_forzados"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (c ConflictoForzado) ToDomain() domain.ConflictoForzado {
+	return domain.ConflictoForzado{
+		ID:             c.ID,
+		ActividadID:    c.ActividadID,
+		ConflictoConID: c.ConflictoConID,
+		Motivo:         c.Motivo,
+		ForzadoPor:     c.ForzadoPor,
+		CreatedAt:      c.CreatedAt,
 	}
 }
@@ -0,0 +1,85 @@
+package dao
+
+import (
+	"activities-api/internal/domain"
+	"time"
+)
+
+// Asistencia representa el modelo de base de datos con tags de GORM
+// (sesion_id, usuario_id) es único: un socio registra una sola asistencia por sesión
+type Asistencia struct {
+	ID            uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	SesionID      uint       `gorm:"column:sesion_id;not null;uniqueIndex:idx_asistencias_sesion_usuario"`
+	UsuarioID     uint       `gorm:"column:usuario_id;not null;index;uniqueIndex:idx_asistencias_sesion_usuario"`
+	Metodo        string     `gorm:"type:enum('qr','manual');not null"`
+	RegistradoPor *uint      `gorm:"column:registrado_por"` // Referencia lógica a users-api
+	CheckInAt     time.Time  `gorm:"column:check_in_at;type:datetime;not null"`
+	CheckOutAt    *time.Time `gorm:"column:check_out_at;type:datetime"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+
+	// Relaciones
+	Sesion Sesion `gorm:"foreignKey:SesionID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Asistencia) TableName() string {
+	return "asistencias"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (a Asistencia) ToDomain() domain.Asistencia {
+	return domain.Asistencia{
+		ID:            a.ID,
+		SesionID:      a.SesionID,
+		UsuarioID:     a.UsuarioID,
+		Metodo:        a.Metodo,
+		RegistradoPor: a.RegistradoPor,
+		CheckInAt:     a.CheckInAt,
+		CheckOutAt:    a.CheckOutAt,
+	}
+}
+
+// AsistenciaFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func AsistenciaFromDomain(asistencia domain.Asistencia) Asistencia {
+	return Asistencia{
+		ID:            asistencia.ID,
+		SesionID:      asistencia.SesionID,
+		UsuarioID:     asistencia.UsuarioID,
+		Metodo:        asistencia.Metodo,
+		RegistradoPor: asistencia.RegistradoPor,
+		CheckInAt:     asistencia.CheckInAt,
+		CheckOutAt:    asistencia.CheckOutAt,
+	}
+}
+
+// Suspension representa el modelo de base de datos con tags de GORM
+type Suspension struct {
+	ID           uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	UsuarioID    uint       `gorm:"column:usuario_id;not null;index"`
+	Desde        time.Time  `gorm:"column:desde;type:datetime;not null"`
+	Hasta        time.Time  `gorm:"column:hasta;type:datetime;not null"`
+	NoShows      int64      `gorm:"column:no_shows;not null"`
+	LevantadaPor *uint      `gorm:"column:levantada_por"` // Referencia lógica a users-api
+	LevantadaAt  *time.Time `gorm:"column:levantada_at;type:datetime"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Suspension) TableName() string {
+	return "suspensiones"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (s Suspension) ToDomain() domain.Suspension {
+	return domain.Suspension{
+		ID:           s.ID,
+		UsuarioID:    s.UsuarioID,
+		Desde:        s.Desde,
+		Hasta:        s.Hasta,
+		NoShows:      s.NoShows,
+		LevantadaPor: s.LevantadaPor,
+		LevantadaAt:  s.LevantadaAt,
+	}
+}
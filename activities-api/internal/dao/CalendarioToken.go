@@ -0,0 +1,16 @@
+package dao
+
+import "time"
+
+// CalendarioToken representa el modelo de base de datos con tags de GORM
+// Un token por socio: regenerarlo reemplaza (revoca) el anterior
+type CalendarioToken struct {
+	UsuarioID uint      `gorm:"column:usuario_id;primaryKey;autoIncrement:false"`
+	TokenHash string    `gorm:"column:token_hash;type:char(64);not null;uniqueIndex"` // SHA-256 del token
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName especifica el nombre de la tabla
+func (CalendarioToken) TableName() string {
+	return "calendario_tokens"
+}
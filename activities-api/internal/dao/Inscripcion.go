@@ -12,11 +12,11 @@ import (
 // Migrado de backend/model/inscripcion.go
 type Inscripcion struct {
 	ID               uint       `gorm:"column:id;primaryKey;autoIncrement"` // Cambio: PK simple en vez de compuesta
-	UsuarioID        uint       `gorm:"column:usuario_id;not null;index"`
-	ActividadID      uint       `gorm:"column:actividad_id;not null;index"`
+	UsuarioID        uint       `gorm:"column:usuario_id;not null;uniqueIndex:idx_inscripciones_usuario_actividad"`
+	ActividadID      uint       `gorm:"column:actividad_id;not null;index;uniqueIndex:idx_inscripciones_usuario_actividad"` // Una sola fila por usuario y actividad (se reactiva)
 	FechaInscripcion time.Time  `gorm:"column:fecha_inscripcion;type:timestamp;default:CURRENT_TIMESTAMP;not null"`
 	IsActiva         bool       `gorm:"column:is_activa;default:true;not null"`
-	SuscripcionID    *string    `gorm:"column:suscripcion_id;type:varchar(50);index"` // TODO: Referencia a subscriptions-api
+	SuscripcionID    *string    `gorm:"column:suscripcion_id;type:varchar(50);index"` // Referencia lógica a subscriptions-api
 	CreatedAt        time.Time  `gorm:"autoCreateTime"`
 	UpdatedAt        time.Time  `gorm:"autoUpdateTime"`
 	DeletedAt        *time.Time `gorm:"index"` // Soft delete
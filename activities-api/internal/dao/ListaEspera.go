@@ -0,0 +1,44 @@
+package dao
+
+import (
+	"activities-api/internal/domain"
+	"time"
+)
+
+// ListaEspera representa el modelo de base de datos con tags de GORM
+// El orden de la cola es el ID (FIFO por actividad)
+type ListaEspera struct {
+	ID            uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	UsuarioID     uint       `gorm:"column:usuario_id;not null;index"`
+	ActividadID   uint       `gorm:"column:actividad_id;not null;index:idx_lista_espera_actividad_estado"`
+	Estado        string     `gorm:"type:enum('esperando','promovido','confirmado','vencido','cancelado');not null;default:'esperando';index:idx_lista_espera_actividad_estado"`
+	SuscripcionID *string    `gorm:"column:suscripcion_id;type:varchar(50)"` // Suscripción validada al anotarse
+	InscripcionID *uint      `gorm:"column:inscripcion_id"`
+	PromovidoAt   *time.Time `gorm:"column:promovido_at"`
+	ExpiraAt      *time.Time `gorm:"column:expira_at;index"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+
+	// Relaciones
+	Actividad Actividad `gorm:"foreignKey:ActividadID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica el nombre de la tabla
+func (ListaEspera) TableName() string {
+	return "lista_espera"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (l ListaEspera) ToDomain() domain.ListaEspera {
+	return domain.ListaEspera{
+		ID:            l.ID,
+		UsuarioID:     l.UsuarioID,
+		ActividadID:   l.ActividadID,
+		Estado:        l.Estado,
+		SuscripcionID: l.SuscripcionID,
+		InscripcionID: l.InscripcionID,
+		PromovidoAt:   l.PromovidoAt,
+		ExpiraAt:      l.ExpiraAt,
+		CreatedAt:     l.CreatedAt,
+	}
+}
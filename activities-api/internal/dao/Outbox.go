@@ -0,0 +1,46 @@
+package dao
+
+import (
+	"activities-api/internal/domain"
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvento representa el modelo de base de datos con tags de GORM
+// Se inserta en la misma transacción que el cambio que origina el evento; el relay lo publica y marca publicado_at
+// Si agota los intentos el relay lo marca descartado_at (dead-letter) y deja de reintentarlo
+type OutboxEvento struct {
+	ID           uint64     `gorm:"column:id;primaryKey;autoIncrement"`
+	EventType    string     `gorm:"column:event_type;type:varchar(50);not null"`
+	Action       string     `gorm:"column:action;type:varchar(50);not null"`
+	AggregateID  string     `gorm:"column:aggregate_id;type:varchar(64);not null"`
+	Payload      string     `gorm:"column:payload;type:json;not null"` // Campo data del sobre
+	Intentos     int        `gorm:"column:intentos;not null;default:0"`
+	UltimoError  *string    `gorm:"column:ultimo_error;type:text"`
+	PublicadoAt  *time.Time `gorm:"column:publicado_at;type:datetime;index"`
+	DescartadoAt *time.Time `gorm:"column:descartado_at;type:datetime"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+}
+
+// TableName especifica el nombre de la tabla
+func (OutboxEvento) TableName() string {
+	return "outbox_eventos"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (o OutboxEvento) ToDomain() (domain.OutboxEvento, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(o.Payload), &data); err != nil {
+		return domain.OutboxEvento{}, err
+	}
+
+	return domain.OutboxEvento{
+		ID:          o.ID,
+		EventType:   o.EventType,
+		Action:      o.Action,
+		AggregateID: o.AggregateID,
+		Data:        data,
+		Intentos:    o.Intentos,
+		CreatedAt:   o.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,198 @@
+package dao
+
+import (
+	"activities-api/internal/domain"
+	"encoding/json"
+	"time"
+)
+
+// ReglaRecurrencia representa el modelo de base de datos con tags de GORM
+// Las fechas sin hora se guardan como texto YYYY-MM-DD para no depender de la zona horaria de la conexión
+type ReglaRecurrencia struct {
+	ID               uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	ActividadID      uint      `gorm:"column:actividad_id;not null;index"`
+	Dias             string    `gorm:"column:dias;type:text;not null"` // JSON con los días de la semana
+	HorarioInicio    string    `gorm:"column:horario_inicio;type:varchar(5);not null"`
+	HorarioFinal     string    `gorm:"column:horario_final;type:varchar(5);not null"`
+	FechaDesde       string    `gorm:"column:fecha_desde;type:varchar(10);not null"`
+	FechaHasta       *string   `gorm:"column:fecha_hasta;type:varchar(10)"`
+	IntervaloSemanas uint      `gorm:"column:intervalo_semanas;not null;default:1"`
+	Excepciones      string    `gorm:"column:excepciones;type:text"` // JSON con las fechas sin clase
+	Cupo             *uint     `gorm:"column:cupo"`
+	GeneradaHasta    *string   `gorm:"column:generada_hasta;type:varchar(10)"` // Última fecha con sesiones generadas
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+
+	// Relaciones
+	Actividad Actividad `gorm:"foreignKey:ActividadID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica el nombre de la tabla
+func (ReglaRecurrencia) TableName() string {
+	return "reglas_recurrencia"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (r ReglaRecurrencia) ToDomain() domain.ReglaRecurrencia {
+	dias := []string{}
+	if r.Dias != "" {
+		_ = json.Unmarshal([]byte(r.Dias), &dias)
+	}
+	excepciones := []string{}
+	if r.Excepciones != "" {
+		_ = json.Unmarshal([]byte(r.Excepciones), &excepciones)
+	}
+
+	return domain.ReglaRecurrencia{
+		ID:               r.ID,
+		ActividadID:      r.ActividadID,
+		Dias:             dias,
+		HorarioInicio:    r.HorarioInicio,
+		HorarioFinal:     r.HorarioFinal,
+		FechaDesde:       r.FechaDesde,
+		FechaHasta:       r.FechaHasta,
+		IntervaloSemanas: r.IntervaloSemanas,
+		Excepciones:      excepciones,
+		Cupo:             r.Cupo,
+		GeneradaHasta:    r.GeneradaHasta,
+		CreatedAt:        r.CreatedAt,
+	}
+}
+
+// ReglaRecurrenciaFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func ReglaRecurrenciaFromDomain(regla domain.ReglaRecurrencia) ReglaRecurrencia {
+	if regla.Excepciones == nil {
+		regla.Excepciones = []string{}
+	}
+	dias, _ := json.Marshal(regla.Dias)
+	excepciones, _ := json.Marshal(regla.Excepciones)
+
+	return ReglaRecurrencia{
+		ID:               regla.ID,
+		ActividadID:      regla.ActividadID,
+		Dias:             string(dias),
+		HorarioInicio:    regla.HorarioInicio,
+		HorarioFinal:     regla.HorarioFinal,
+		FechaDesde:       regla.FechaDesde,
+		FechaHasta:       regla.FechaHasta,
+		IntervaloSemanas: regla.IntervaloSemanas,
+		Excepciones:      string(excepciones),
+		Cupo:             regla.Cupo,
+		GeneradaHasta:    regla.GeneradaHasta,
+	}
+}
+
+// Sesion representa el modelo de base de datos con tags de GORM
+// (regla_id, fecha_original) es único para que regenerar una regla no duplique sesiones
+type Sesion struct {
+	ID                uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	ActividadID       uint      `gorm:"column:actividad_id;not null;index"`
+	ReglaID           *uint     `gorm:"column:regla_id;uniqueIndex:idx_sesiones_regla_fecha"`
+	FechaOriginal     string    `gorm:"column:fecha_original;type:varchar(10);not null;uniqueIndex:idx_sesiones_regla_fecha"` // Fecha generada (no cambia al reprogramar)
+	Inicio            time.Time `gorm:"column:inicio;type:datetime;not null;index"`
+	Fin               time.Time `gorm:"column:fin;type:datetime;not null"`
+	Cupo              uint      `gorm:"column:cupo;type:int;not null"`
+	Estado            string    `gorm:"type:enum('programada','cancelada');not null;default:'programada'"`
+	MotivoCancelacion string    `gorm:"column:motivo_cancelacion;type:varchar(255)"`
+	Reprogramada      bool      `gorm:"column:reprogramada;not null;default:false"`
+	CodigoCheckin     *string   `gorm:"column:codigo_checkin;type:varchar(32)"` // Código del QR de asistencia (se genera al pedirlo)
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `gorm:"autoUpdateTime"`
+
+	// Relaciones
+	Actividad Actividad         `gorm:"foreignKey:ActividadID;constraint:OnDelete:CASCADE"`
+	Regla     *ReglaRecurrencia `gorm:"foreignKey:ReglaID;constraint:OnDelete:SET NULL"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Sesion) TableName() string {
+	return "sesiones"
+}
+
+// SesionDetalle es una sesión con los datos de su actividad y la cantidad de inscriptos
+type SesionDetalle struct {
+	Sesion          `gorm:"embedded"`
+	ActividadTitulo string `gorm:"column:actividad_titulo"`
+	SucursalID      *uint  `gorm:"column:sucursal_id"`
+	Inscriptos      uint   `gorm:"column:inscriptos"`
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio); la fecha se expresa en loc
+func (s SesionDetalle) ToDomain(loc *time.Location) domain.Sesion {
+	lugares := uint(0)
+	if s.Cupo > s.Inscriptos {
+		lugares = s.Cupo - s.Inscriptos
+	}
+
+	return domain.Sesion{
+		ID:                s.ID,
+		ActividadID:       s.ActividadID,
+		ActividadTitulo:   s.ActividadTitulo,
+		SucursalID:        s.SucursalID,
+		ReglaID:           s.ReglaID,
+		Fecha:             s.Inicio.In(loc).Format(domain.FormatoFecha),
+		Inicio:            s.Inicio.In(loc),
+		Fin:               s.Fin.In(loc),
+		Cupo:              s.Cupo,
+		Inscriptos:        s.Inscriptos,
+		Lugares:           lugares,
+		Estado:            s.Estado,
+		MotivoCancelacion: s.MotivoCancelacion,
+		Reprogramada:      s.Reprogramada,
+		CreatedAt:         s.CreatedAt,
+		UpdatedAt:         s.UpdatedAt,
+	}
+}
+
+// SesionFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func SesionFromDomain(sesion domain.Sesion) Sesion {
+	estado := sesion.Estado
+	if estado == "" {
+		estado = domain.SesionProgramada
+	}
+
+	return Sesion{
+		ID:                sesion.ID,
+		ActividadID:       sesion.ActividadID,
+		ReglaID:           sesion.ReglaID,
+		FechaOriginal:     sesion.Fecha,
+		Inicio:            sesion.Inicio,
+		Fin:               sesion.Fin,
+		Cupo:              sesion.Cupo,
+		Estado:            estado,
+		MotivoCancelacion: sesion.MotivoCancelacion,
+		Reprogramada:      sesion.Reprogramada,
+	}
+}
+
+// InscripcionSesion representa el modelo de base de datos con tags de GORM
+type InscripcionSesion struct {
+	ID               uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	SesionID         uint      `gorm:"column:sesion_id;not null;uniqueIndex:idx_inscripciones_sesion_sesion_usuario"`
+	UsuarioID        uint      `gorm:"column:usuario_id;not null;index;uniqueIndex:idx_inscripciones_sesion_sesion_usuario"`
+	FechaInscripcion time.Time `gorm:"column:fecha_inscripcion;type:timestamp;default:CURRENT_TIMESTAMP;not null"`
+	IsActiva         bool      `gorm:"column:is_activa;default:true;not null"`
+	SuscripcionID    *string   `gorm:"column:suscripcion_id;type:varchar(50)"` // Referencia lógica a subscriptions-api
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+
+	// Relaciones
+	Sesion Sesion `gorm:"foreignKey:SesionID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica el nombre de la tabla
+func (InscripcionSesion) TableName() string {
+	return "inscripciones_sesion"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (i InscripcionSesion) ToDomain() domain.InscripcionSesion {
+	return domain.InscripcionSesion{
+		ID:               i.ID,
+		SesionID:         i.SesionID,
+		UsuarioID:        i.UsuarioID,
+		FechaInscripcion: i.FechaInscripcion,
+		IsActiva:         i.IsActiva,
+		SuscripcionID:    i.SuscripcionID,
+	}
+}
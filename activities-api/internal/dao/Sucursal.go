@@ -2,22 +2,28 @@ package dao
 
 import (
 	"activities-api/internal/domain"
+	"encoding/json"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Sucursal representa el modelo de base de datos con tags de GORM
-// TODO: Para que los compañeros implementen
 type Sucursal struct {
-	ID        uint       `gorm:"column:id;primaryKey;autoIncrement"`
-	Nombre    string     `gorm:"type:varchar(100);not null"`
-	Direccion string     `gorm:"type:varchar(255);not null"`
-	Telefono  string     `gorm:"type:varchar(20);not null"`
-	CreatedAt time.Time  `gorm:"autoCreateTime"`
-	UpdatedAt time.Time  `gorm:"autoUpdateTime"`
-	DeletedAt *time.Time `gorm:"index"` // Soft delete
-
-	// Relación con Actividades
-	Actividades []Actividad `gorm:"foreignKey:SucursalID"`
+	ID        uint           `gorm:"column:id;primaryKey;autoIncrement"`
+	Nombre    string         `gorm:"type:varchar(100);not null"`
+	Direccion string         `gorm:"type:varchar(255);not null"`
+	Telefono  string         `gorm:"type:varchar(20);not null"`
+	Horarios  string         `gorm:"column:horarios;type:text"` // JSON con los horarios de apertura
+	Capacidad uint           `gorm:"type:int;not null;default:0"`
+	Latitud   *float64       `gorm:"type:decimal(9,6)"`
+	Longitud  *float64       `gorm:"type:decimal(9,6)"`
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"` // Soft delete
+
+	// Relación con Actividades (FK actividades.sucursal_id -> sucursales.id)
+	Actividades []Actividad `gorm:"foreignKey:SucursalID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
 }
 
 // TableName especifica el nombre de la tabla
@@ -27,11 +33,20 @@ func (Sucursal) TableName() string {
 
 // ToDomain convierte de DAO (MySQL) a Domain (negocio)
 func (s Sucursal) ToDomain() domain.Sucursal {
+	horarios := []domain.HorarioSucursal{}
+	if s.Horarios != "" {
+		_ = json.Unmarshal([]byte(s.Horarios), &horarios)
+	}
+
 	return domain.Sucursal{
 		ID:        s.ID,
 		Nombre:    s.Nombre,
 		Direccion: s.Direccion,
 		Telefono:  s.Telefono,
+		Horarios:  horarios,
+		Capacidad: s.Capacidad,
+		Latitud:   s.Latitud,
+		Longitud:  s.Longitud,
 		CreatedAt: s.CreatedAt,
 		UpdatedAt: s.UpdatedAt,
 	}
@@ -39,12 +54,19 @@ func (s Sucursal) ToDomain() domain.Sucursal {
 
 // FromDomain convierte de Domain (negocio) a DAO (MySQL)
 func SucursalFromDomain(domainSuc domain.Sucursal) Sucursal {
+	if domainSuc.Horarios == nil {
+		domainSuc.Horarios = []domain.HorarioSucursal{}
+	}
+	horarios, _ := json.Marshal(domainSuc.Horarios)
+
 	return Sucursal{
 		ID:        domainSuc.ID,
 		Nombre:    domainSuc.Nombre,
 		Direccion: domainSuc.Direccion,
 		Telefono:  domainSuc.Telefono,
+		Horarios:  string(horarios),
+		Capacidad: domainSuc.Capacidad,
+		Latitud:   domainSuc.Latitud,
+		Longitud:  domainSuc.Longitud,
 	}
 }
-
-// TODO: Los compañeros deben crear el repository completo
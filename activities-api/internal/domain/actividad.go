@@ -1,6 +1,20 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Eventos de actividades (routing key activity.<action> en gym_events)
+// search-api indexa el data del evento; los lugares libres se vuelven a publicar (update) al inscribirse o desinscribirse
+const (
+	ActividadEventType    = "activity"
+	ActividadEventCreated = "create"
+	ActividadEventUpdated = "update"
+	ActividadEventDeleted = "delete"
+)
 
 // Actividad representa la entidad de negocio Actividad
 // Independiente de la base de datos
@@ -14,9 +28,12 @@ type Actividad struct {
 	HorarioFinal  string    `json:"horario_final"`  // Formato "HH:MM"
 	FotoUrl       string    `json:"foto_url"`
 	Instructor    string    `json:"instructor"`
+	InstructorID  *uint     `json:"instructor_id,omitempty"` // Usuario instructor (permiso activities:update:own)
 	Categoria     string    `json:"categoria"`
-	SucursalID    *uint     `json:"sucursal_id,omitempty"` // TODO: Agregar cuando se cree entidad Sucursal
+	SucursalID    *uint     `json:"sucursal_id,omitempty"` // Debe existir en sucursales
+	Sala          string    `json:"sala,omitempty"`        // Sala de la sucursal (vacía = sin sala asignada)
 	Lugares       uint      `json:"lugares,omitempty"`     // Campo calculado (cupos disponibles)
+	Version       uint      `json:"version"`               // Se incrementa en cada actualización (ETag)
 	CreatedAt     time.Time `json:"created_at,omitempty"`
 	UpdatedAt     time.Time `json:"updated_at,omitempty"`
 }
@@ -31,8 +48,11 @@ type ActividadCreate struct {
 	HorarioFinal  string `json:"horario_final" binding:"required"`  // "HH:MM"
 	FotoUrl       string `json:"foto_url" binding:"required"`
 	Instructor    string `json:"instructor" binding:"required"`
+	InstructorID  *uint  `json:"instructor_id,omitempty"`
 	Categoria     string `json:"categoria" binding:"required"`
-	SucursalID    *uint  `json:"sucursal_id,omitempty"` // TODO: Validar que existe
+	SucursalID    *uint  `json:"sucursal_id,omitempty"` // Se valida que exista
+	Sala          string `json:"sala" binding:"max=50"`
+	Forzar        bool   `json:"forzar_conflictos"` // Solo activities:manage: crea aunque se superponga con otras
 }
 
 // ActividadUpdate representa los datos para actualizar una actividad
@@ -45,10 +65,100 @@ type ActividadUpdate struct {
 	HorarioFinal  string `json:"horario_final" binding:"required"`
 	FotoUrl       string `json:"foto_url" binding:"required"`
 	Instructor    string `json:"instructor" binding:"required"`
+	InstructorID  *uint  `json:"instructor_id,omitempty"`
 	Categoria     string `json:"categoria" binding:"required"`
 	SucursalID    *uint  `json:"sucursal_id,omitempty"`
+	Sala          string `json:"sala" binding:"max=50"`
+	Forzar        bool   `json:"forzar_conflictos"` // Solo activities:manage: guarda aunque se superponga con otras
+	Version       uint   `json:"-"`                 // Versión del If-Match (0 = se usa la versión leída)
+}
+
+// ActividadPatch representa una actualización parcial (PATCH): solo cambian los campos presentes en el JSON
+// instructor_id y sucursal_id en null desasignan el instructor o la sucursal
+type ActividadPatch struct {
+	Titulo        *string      `json:"titulo" binding:"omitempty,min=1"`
+	Descripcion   *string      `json:"descripcion"`
+	Cupo          *uint        `json:"cupo" binding:"omitempty,min=1"`
+	Dia           *string      `json:"dia" binding:"omitempty,min=1"`
+	HorarioInicio *string      `json:"horario_inicio" binding:"omitempty,min=1"`
+	HorarioFinal  *string      `json:"horario_final" binding:"omitempty,min=1"`
+	FotoUrl       *string      `json:"foto_url" binding:"omitempty,min=1"`
+	Instructor    *string      `json:"instructor" binding:"omitempty,min=1"`
+	InstructorID  NullableUint `json:"instructor_id"`
+	Categoria     *string      `json:"categoria" binding:"omitempty,min=1"`
+	SucursalID    NullableUint `json:"sucursal_id"`
+	Sala          *string      `json:"sala" binding:"omitempty,max=50"`
+	Forzar        bool         `json:"forzar_conflictos"`
+	Version       uint         `json:"-"` // Versión del If-Match (0 = se usa la versión leída)
+}
+
+// NullableUint distingue en un PATCH un campo ausente (Set en false) de uno enviado en null (Set en true, Value nil)
+type NullableUint struct {
+	Set   bool
+	Value *uint
+}
+
+// UnmarshalJSON solo se invoca si el campo está presente (también cuando es null)
+func (n *NullableUint) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	return json.Unmarshal(data, &n.Value)
+}
+
+// ToUpdate devuelve los datos actuales de la actividad como una actualización completa
+func (a Actividad) ToUpdate() ActividadUpdate {
+	return ActividadUpdate{
+		Titulo:        a.Titulo,
+		Descripcion:   a.Descripcion,
+		Cupo:          a.Cupo,
+		Dia:           a.Dia,
+		HorarioInicio: a.HorarioInicio,
+		HorarioFinal:  a.HorarioFinal,
+		FotoUrl:       a.FotoUrl,
+		Instructor:    a.Instructor,
+		InstructorID:  a.InstructorID,
+		Categoria:     a.Categoria,
+		SucursalID:    a.SucursalID,
+		Sala:          a.Sala,
+	}
+}
+
+// Apply combina el PATCH con la actividad actual (base): los campos ausentes conservan su valor
+func (p ActividadPatch) Apply(base ActividadUpdate) ActividadUpdate {
+	merged := base
+	setString(&merged.Titulo, p.Titulo)
+	setString(&merged.Descripcion, p.Descripcion)
+	setString(&merged.Dia, p.Dia)
+	setString(&merged.HorarioInicio, p.HorarioInicio)
+	setString(&merged.HorarioFinal, p.HorarioFinal)
+	setString(&merged.FotoUrl, p.FotoUrl)
+	setString(&merged.Instructor, p.Instructor)
+	setString(&merged.Categoria, p.Categoria)
+	setString(&merged.Sala, p.Sala)
+	if p.Cupo != nil {
+		merged.Cupo = *p.Cupo
+	}
+	if p.InstructorID.Set {
+		merged.InstructorID = p.InstructorID.Value
+	}
+	if p.SucursalID.Set {
+		merged.SucursalID = p.SucursalID.Value
+	}
+	merged.Forzar = p.Forzar
+	merged.Version = p.Version
+
+	return merged
+}
+
+// setString pisa el destino solo si el campo vino en el PATCH
+func setString(dst *string, value *string) {
+	if value != nil {
+		*dst = *value
+	}
 }
 
+// ErrVersionConflict indica que la actividad cambió desde la versión del If-Match (el controller responde 412)
+var ErrVersionConflict = errors.New("la actividad fue modificada por otro usuario, volvé a cargarla")
+
 // ActividadResponse representa la respuesta HTTP de una actividad
 type ActividadResponse struct {
 	ID            uint   `json:"id"`
@@ -60,9 +170,12 @@ type ActividadResponse struct {
 	HorarioFinal  string `json:"horario_final"`  // "HH:MM"
 	FotoUrl       string `json:"foto_url"`
 	Instructor    string `json:"instructor"`
+	InstructorID  *uint  `json:"instructor_id,omitempty"`
 	Categoria     string `json:"categoria"`
 	SucursalID    *uint  `json:"sucursal_id,omitempty"`
+	Sala          string `json:"sala,omitempty"`
 	Lugares       uint   `json:"lugares"` // Campo calculado de cupos disponibles
+	Version       uint   `json:"version"` // También en el header ETag
 }
 
 // ToResponse convierte de Actividad a ActividadResponse
@@ -77,8 +190,83 @@ func (a Actividad) ToResponse() ActividadResponse {
 		HorarioFinal:  a.HorarioFinal,
 		FotoUrl:       a.FotoUrl,
 		Instructor:    a.Instructor,
+		InstructorID:  a.InstructorID,
 		Categoria:     a.Categoria,
 		SucursalID:    a.SucursalID,
+		Sala:          a.Sala,
 		Lugares:       a.Lugares,
+		Version:       a.Version,
 	}
 }
+
+// Órdenes de la búsqueda de actividades
+const (
+	ActividadOrdenID          = "id"
+	ActividadOrdenHorario     = "horario"     // Día de la semana y hora de inicio
+	ActividadOrdenPopularidad = "popularidad" // Inscriptos activos (cupo - lugares)
+	ActividadOrdenLugares     = "lugares"
+	ActividadOrdenTitulo      = "titulo"
+)
+
+// ActividadBusqueda filtra, ordena y pagina la búsqueda de actividades (GET /actividades/buscar)
+// Los filtros con varios valores matchean cualquiera de ellos; los distintos filtros se combinan con AND
+type ActividadBusqueda struct {
+	ID            *uint
+	Titulo        string   // LIKE
+	Categorias    []string // LIKE
+	Dias          []string
+	Instructores  []string // LIKE sobre el nombre
+	InstructorIDs []uint
+	SucursalIDs   []uint
+	Horario       string // "HH:MM": actividades en curso a esa hora
+	Desde         string // "HH:MM": actividades que terminan después (ventana desde-hasta superpuesta)
+	Hasta         string // "HH:MM": actividades que empiezan antes
+	MinLugares    uint   // 0 = sin filtro
+	Orden         string // id | horario | popularidad | lugares | titulo
+	Desc          bool
+	Limit         int
+	Offset        int
+}
+
+// ActividadesPage es una página de resultados de la búsqueda con el total de coincidencias
+type ActividadesPage struct {
+	Results    []ActividadResponse `json:"results"`
+	TotalCount int64               `json:"total_count"`
+	Limit      int                 `json:"limit"`
+	Offset     int                 `json:"offset"`
+}
+
+// Motivos de superposición de horarios entre actividades
+const (
+	ConflictoInstructor = "instructor" // El mismo instructor en dos clases a la vez
+	ConflictoSala       = "sala"       // La misma sala de la misma sucursal en dos clases a la vez
+)
+
+// ConflictoHorario es una actividad que se superpone (mismo día, horarios solapados) con la que se guarda
+type ConflictoHorario struct {
+	ActividadID   uint   `json:"actividad_id"`
+	Titulo        string `json:"titulo"`
+	Dia           string `json:"dia"`
+	HorarioInicio string `json:"horario_inicio"`
+	HorarioFinal  string `json:"horario_final"`
+	Motivo        string `json:"motivo"` // instructor | sala
+}
+
+// ConflictoHorarioError rechaza una actividad que se superpone con otras (el controller responde 409 con la lista)
+type ConflictoHorarioError struct {
+	Conflictos []ConflictoHorario
+}
+
+func (e *ConflictoHorarioError) Error() string {
+	return fmt.Sprintf("la actividad se superpone con %d actividades", len(e.Conflictos))
+}
+
+// ConflictoForzado registra quién guardó una actividad a pesar de una superposición
+type ConflictoForzado struct {
+	ID             uint      `json:"id"`
+	ActividadID    uint      `json:"actividad_id"`
+	ConflictoConID uint      `json:"conflicto_con_id"`
+	Motivo         string    `json:"motivo"`
+	ForzadoPor     uint      `json:"forzado_por"`
+	CreatedAt      time.Time `json:"created_at"`
+}
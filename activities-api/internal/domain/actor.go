@@ -0,0 +1,43 @@
+package domain
+
+// Permisos (emitidos por users-api en el claim "permissions") que usa este servicio
+const (
+	PermissionAll                      = "*"
+	PermissionActivitiesManage         = "activities:manage"          // Cualquier actividad
+	PermissionActivitiesManageSucursal = "activities:manage:sucursal" // Solo actividades de sus sucursales
+	PermissionActivitiesUpdateOwn      = "activities:update:own"      // Solo actividades donde es instructor
+)
+
+// Actor representa al usuario autenticado que ejecuta una operación (a partir de los claims del JWT)
+type Actor struct {
+	UsuarioID   uint
+	IsAdmin     bool
+	Permissions []string
+	SucursalIDs []uint // Sucursales donde aplican los permisos ":sucursal"
+}
+
+// Can indica si el actor tiene el permiso (is_admin y "*" otorgan todos)
+func (a Actor) Can(permission string) bool {
+	if a.IsAdmin {
+		return true
+	}
+	for _, p := range a.Permissions {
+		if p == PermissionAll || p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// InSucursal indica si la sucursal está entre las asignadas al actor
+func (a Actor) InSucursal(sucursalID *uint) bool {
+	if sucursalID == nil {
+		return false
+	}
+	for _, id := range a.SucursalIDs {
+		if id == *sucursalID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package domain
+
+import "time"
+
+// Métodos de registro de una asistencia
+const (
+	AsistenciaMetodoQR     = "qr"     // El socio escaneó el código de la sesión
+	AsistenciaMetodoManual = "manual" // El instructor tomó lista
+)
+
+// Origen de la inscripción por la que se espera a un socio en una sesión
+const (
+	EsperadoPorSesion    = "sesion"    // Inscripto a la sesión puntual
+	EsperadoPorActividad = "actividad" // Inscripto a la actividad (todas sus sesiones)
+)
+
+// Asistencia es el registro de que un socio se presentó a una sesión
+type Asistencia struct {
+	ID            uint       `json:"id"`
+	SesionID      uint       `json:"sesion_id"`
+	UsuarioID     uint       `json:"usuario_id"`
+	Metodo        string     `json:"metodo"`
+	RegistradoPor *uint      `json:"registrado_por,omitempty"` // Instructor/gerente que tomó lista (nil = el propio socio)
+	CheckInAt     time.Time  `json:"check_in_at"`
+	CheckOutAt    *time.Time `json:"check_out_at,omitempty"`
+	Sesion        *Sesion    `json:"sesion,omitempty"`
+}
+
+// AsistenciaCheckIn representa el código que escanea el socio al llegar
+type AsistenciaCheckIn struct {
+	Codigo string `json:"codigo" binding:"required"`
+}
+
+// AsistenciaRollCall representa al socio presente al tomar lista
+type AsistenciaRollCall struct {
+	UsuarioID uint `json:"usuario_id" binding:"required"`
+}
+
+// CodigoCheckin es el código de una sesión que se muestra como QR en la puerta
+type CodigoCheckin struct {
+	SesionID    uint      `json:"sesion_id"`
+	Codigo      string    `json:"codigo"`
+	ValidoDesde time.Time `json:"valido_desde"`
+	ValidoHasta time.Time `json:"valido_hasta"`
+}
+
+// AsistenciaSesion es una fila de la planilla de una sesión: un socio esperado o presente
+type AsistenciaSesion struct {
+	UsuarioID  uint        `json:"usuario_id"`
+	Esperado   string      `json:"esperado,omitempty"` // sesion | actividad | vacío (se presentó sin inscripción)
+	Presente   bool        `json:"presente"`
+	NoShow     bool        `json:"no_show"` // Inscripto, la sesión terminó y no se presentó
+	Asistencia *Asistencia `json:"asistencia,omitempty"`
+}
+
+// ResumenSesion resume la asistencia de una sesión
+type ResumenSesion struct {
+	Sesion    Sesion `json:"sesion"`
+	Esperados int    `json:"esperados"`
+	Presentes int    `json:"presentes"`
+	NoShows   int    `json:"no_shows"`
+}
+
+// AsistenciaFiltro filtra por rango de fechas (YYYY-MM-DD, ambas inclusive)
+type AsistenciaFiltro struct {
+	FechaDesde string // Vacío = 4 semanas antes de FechaHasta
+	FechaHasta string // Vacío = hoy
+}
+
+// Suspension bloquea nuevas inscripciones de un socio por inasistencias reiteradas
+type Suspension struct {
+	ID           uint       `json:"id"`
+	UsuarioID    uint       `json:"usuario_id"`
+	Desde        time.Time  `json:"desde"`
+	Hasta        time.Time  `json:"hasta"`
+	NoShows      int64      `json:"no_shows"`
+	LevantadaPor *uint      `json:"levantada_por,omitempty"`
+	LevantadaAt  *time.Time `json:"levantada_at,omitempty"`
+}
+
+// Activa indica si la suspensión sigue vigente en el momento indicado
+func (s Suspension) Activa(now time.Time) bool {
+	return s.LevantadaAt == nil && s.Hasta.After(now)
+}
+
+// AsistenciasUsuario es el historial de asistencia de un socio con su conteo de inasistencias
+type AsistenciasUsuario struct {
+	UsuarioID   uint         `json:"usuario_id"`
+	Asistencias []Asistencia `json:"asistencias"`
+	NoShows     int64        `json:"no_shows"` // Inasistencias en el rango consultado
+	Suspension  *Suspension  `json:"suspension,omitempty"`
+}
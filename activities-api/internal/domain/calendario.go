@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// CalendarioToken es el token del feed ICS personal de un socio
+// Las apps de calendario no pueden mandar el Bearer: el token va en la URL y se puede revocar o regenerar
+type CalendarioToken struct {
+	UsuarioID uint      `json:"usuario_id"`
+	Token     string    `json:"token"` // Solo se informa al generarlo (se guarda su hash)
+	URL       string    `json:"url"`   // Ruta del feed para suscribirse desde el calendario
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CalendarioFiltro filtra el feed público de horarios
+type CalendarioFiltro struct {
+	SucursalID *uint
+	Categoria  string // Vacía = todas
+}
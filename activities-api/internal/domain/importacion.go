@@ -0,0 +1,94 @@
+package domain
+
+// Modos de importación del catálogo de actividades
+const (
+	ImportModoAtomico = "atomico" // Se crean todas las filas o ninguna
+	ImportModoParcial = "parcial" // Se crean las filas válidas y se informan las que fallaron
+)
+
+// ActividadCatalogo son los datos de una actividad que se exportan e importan entre sucursales
+// (sin ID, lugares ni versión: al importarla se crea una actividad nueva)
+type ActividadCatalogo struct {
+	Titulo        string `json:"titulo"`
+	Descripcion   string `json:"descripcion"`
+	Cupo          uint   `json:"cupo"`
+	Dia           string `json:"dia"`
+	HorarioInicio string `json:"horario_inicio"` // "HH:MM"
+	HorarioFinal  string `json:"horario_final"`  // "HH:MM"
+	FotoUrl       string `json:"foto_url"`
+	Instructor    string `json:"instructor"`
+	InstructorID  *uint  `json:"instructor_id,omitempty"`
+	Categoria     string `json:"categoria"`
+	SucursalID    *uint  `json:"sucursal_id,omitempty"`
+	Sala          string `json:"sala,omitempty"`
+}
+
+// ToCatalogo convierte una actividad existente en una fila exportable
+func (a Actividad) ToCatalogo() ActividadCatalogo {
+	return ActividadCatalogo{
+		Titulo:        a.Titulo,
+		Descripcion:   a.Descripcion,
+		Cupo:          a.Cupo,
+		Dia:           a.Dia,
+		HorarioInicio: a.HorarioInicio,
+		HorarioFinal:  a.HorarioFinal,
+		FotoUrl:       a.FotoUrl,
+		Instructor:    a.Instructor,
+		InstructorID:  a.InstructorID,
+		Categoria:     a.Categoria,
+		SucursalID:    a.SucursalID,
+		Sala:          a.Sala,
+	}
+}
+
+// ToCreate convierte una fila importada en el alta de la actividad
+func (c ActividadCatalogo) ToCreate() ActividadCreate {
+	return ActividadCreate{
+		Titulo:        c.Titulo,
+		Descripcion:   c.Descripcion,
+		Cupo:          c.Cupo,
+		Dia:           c.Dia,
+		HorarioInicio: c.HorarioInicio,
+		HorarioFinal:  c.HorarioFinal,
+		FotoUrl:       c.FotoUrl,
+		Instructor:    c.Instructor,
+		InstructorID:  c.InstructorID,
+		Categoria:     c.Categoria,
+		SucursalID:    c.SucursalID,
+		Sala:          c.Sala,
+	}
+}
+
+// ActividadImportFila es una fila leída del CSV o JSON a importar
+type ActividadImportFila struct {
+	Fila      int // Línea del CSV o posición (desde 1) en el JSON
+	Actividad ActividadCatalogo
+	Errores   []string // Errores de formato (ej: cupo no numérico); la fila no se importa
+}
+
+// ActividadImportOptions configura una importación
+type ActividadImportOptions struct {
+	Modo       string // atomico | parcial
+	DryRun     bool   // Solo valida: no crea nada
+	SucursalID *uint  // Si no es nil reemplaza la sucursal de todas las filas (copiar un catálogo a otra sucursal)
+}
+
+// ActividadImportResultado es el resultado de una fila importada
+type ActividadImportResultado struct {
+	Fila        int                `json:"fila"`
+	Titulo      string             `json:"titulo"`
+	ActividadID uint               `json:"actividad_id,omitempty"` // Solo si la actividad se creó
+	Errores     []string           `json:"errores,omitempty"`
+	Conflictos  []ConflictoHorario `json:"conflictos,omitempty"`
+}
+
+// ActividadImportResult resume una importación
+type ActividadImportResult struct {
+	Modo      string                     `json:"modo"`
+	DryRun    bool                       `json:"dry_run"`
+	Total     int                        `json:"total"`
+	Validas   int                        `json:"validas"`
+	Invalidas int                        `json:"invalidas"`
+	Creadas   int                        `json:"creadas"`
+	Filas     []ActividadImportResultado `json:"filas"`
+}
@@ -1,6 +1,18 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Eventos de inscripciones (routing key inscription.<action> en gym_events)
+const (
+	InscripcionEventType    = "inscription"
+	InscripcionEventCreated = "create"
+	InscripcionEventDeleted = "delete"
+)
 
 // Inscripcion representa la entidad de negocio Inscripcion
 type Inscripcion struct {
@@ -9,7 +21,7 @@ type Inscripcion struct {
 	ActividadID      uint      `json:"actividad_id"`
 	FechaInscripcion time.Time `json:"fecha_inscripcion"`
 	IsActiva         bool      `json:"is_activa"`
-	SuscripcionID    *string   `json:"suscripcion_id,omitempty"` // TODO: Agregar cuando se implemente subscriptions-api
+	SuscripcionID    *string   `json:"suscripcion_id,omitempty"` // Suscripción que habilitó la inscripción
 	CreatedAt        time.Time `json:"created_at,omitempty"`
 	UpdatedAt        time.Time `json:"updated_at,omitempty"`
 }
@@ -18,7 +30,6 @@ type Inscripcion struct {
 type InscripcionCreate struct {
 	UsuarioID   uint `json:"usuario_id" binding:"required"`
 	ActividadID uint `json:"actividad_id" binding:"required"`
-	// TODO: Agregar SuscripcionID cuando se implemente subscriptions-api
 }
 
 // InscripcionResponse representa la respuesta con datos adicionales
@@ -45,3 +56,80 @@ func (i Inscripcion) ToResponse() InscripcionResponse {
 		// ActividadTitulo se puede agregar después si se necesita (JOIN)
 	}
 }
+
+// Códigos de error de inscripción (campo "code" de la respuesta HTTP)
+const (
+	InscripcionErrUserNotFound            = "USER_NOT_FOUND"
+	InscripcionErrActivityNotFound        = "ACTIVITY_NOT_FOUND"
+	InscripcionErrNoActiveSubscription    = "NO_ACTIVE_SUBSCRIPTION"
+	InscripcionErrPlanNotCovering         = "PLAN_DOES_NOT_COVER_ACTIVITY"
+	InscripcionErrAlreadyEnrolled         = "ALREADY_ENROLLED"
+	InscripcionErrActivityFull            = "ACTIVITY_FULL"
+	InscripcionErrEntitlementsUnavailable = "ENTITLEMENTS_UNAVAILABLE" // users-api o subscriptions-api no respondieron
+
+	// Lista de espera
+	InscripcionErrAlreadyWaitlisted  = "ALREADY_WAITLISTED"
+	InscripcionErrActivityHasSpots   = "ACTIVITY_HAS_SPOTS" // Hay lugar: inscribirse directamente
+	InscripcionErrWaitlistPending    = "WAITLIST_PENDING"   // Hay socios esperando: los lugares libres son de ellos
+	InscripcionErrNotWaitlisted      = "NOT_WAITLISTED"
+	InscripcionErrNoPendingPromotion = "NO_PENDING_PROMOTION" // No hay lugar para confirmar (o ya venció)
+
+	// Sesiones
+	InscripcionErrSessionNotFound     = "SESSION_NOT_FOUND"
+	InscripcionErrSessionNotAvailable = "SESSION_NOT_AVAILABLE" // Cancelada o ya comenzó
+	InscripcionErrNotEnrolled         = "NOT_ENROLLED"
+
+	// Asistencia
+	InscripcionErrSuspended = "ENROLLMENT_SUSPENDED" // Suspendido por inasistencias reiteradas
+)
+
+// InscripcionError es un rechazo de inscripción con un código legible por máquina
+type InscripcionError struct {
+	Code    string
+	Message string
+	Err     error // Causa (opcional)
+}
+
+func (e *InscripcionError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *InscripcionError) Unwrap() error {
+	return e.Err
+}
+
+// ErrNoActiveSubscription lo devuelve el cliente de subscriptions-api cuando el usuario no tiene suscripción activa
+var ErrNoActiveSubscription = errors.New("no active subscription")
+
+// Plan.TipoAcceso (subscriptions-api)
+const (
+	TipoAccesoCompleto = "completo" // Cubre todas las actividades
+	TipoAccesoLimitado = "limitado" // Solo las actividades de ActividadesPermitidas
+)
+
+// SuscripcionActiva es la suscripción vigente de un usuario con los datos de su plan
+type SuscripcionActiva struct {
+	ID                    string
+	PlanID                string
+	PlanNombre            string
+	TipoAcceso            string
+	ActividadesPermitidas []string // IDs de actividad
+	FechaVencimiento      time.Time
+}
+
+// Cubre indica si el plan habilita la actividad
+func (s SuscripcionActiva) Cubre(actividadID uint) bool {
+	if s.TipoAcceso == TipoAccesoCompleto {
+		return true
+	}
+	id := strconv.FormatUint(uint64(actividadID), 10)
+	for _, permitida := range s.ActividadesPermitidas {
+		if strings.TrimSpace(permitida) == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// Estados de una entrada de la lista de espera
+const (
+	ListaEsperaEsperando  = "esperando"  // En la cola, sin lugar todavía
+	ListaEsperaPromovido  = "promovido"  // Se le asignó una inscripción y tiene que confirmarla antes de ExpiraAt
+	ListaEsperaConfirmado = "confirmado" // Confirmó la inscripción
+	ListaEsperaVencido    = "vencido"    // No confirmó a tiempo; el lugar pasó al siguiente
+	ListaEsperaCancelado  = "cancelado"  // Salió de la lista (o rechazó el lugar)
+)
+
+// Eventos de la lista de espera (routing key waitlist.<action> en gym_events)
+const (
+	ListaEsperaEventType     = "waitlist"
+	ListaEsperaEventPromoted = "promoted"
+	ListaEsperaEventExpired  = "expired"
+	ListaEsperaEventRemoved  = "removed" // Salió de la cola porque ya no puede inscribirse (suscripción, plan o suspensión)
+)
+
+// ListaEspera representa el lugar de un socio en la lista de espera de una actividad
+type ListaEspera struct {
+	ID            uint       `json:"id"`
+	UsuarioID     uint       `json:"usuario_id"`
+	ActividadID   uint       `json:"actividad_id"`
+	Estado        string     `json:"estado"`
+	Posicion      int        `json:"posicion,omitempty"` // 1 = próximo en ser promovido (solo en estado esperando)
+	SuscripcionID *string    `json:"suscripcion_id,omitempty"`
+	InscripcionID *uint      `json:"inscripcion_id,omitempty"` // Inscripción creada al promoverlo
+	PromovidoAt   *time.Time `json:"promovido_at,omitempty"`
+	ExpiraAt      *time.Time `json:"expira_at,omitempty"` // Límite para confirmar el lugar
+	CreatedAt     time.Time  `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// OutboxEvento es un evento guardado en la tabla outbox a la espera de que el relay lo publique
+// en gym_events (sobre {action, type, id, timestamp, data}, routing key <type>.<action>)
+type OutboxEvento struct {
+	ID          uint64                 `json:"id"`
+	EventType   string                 `json:"type"`
+	Action      string                 `json:"action"`
+	AggregateID string                 `json:"aggregate_id"`
+	Data        map[string]interface{} `json:"data"`
+	Intentos    int                    `json:"intentos"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+// ErrEventoInvalido indica que el evento nunca se va a poder publicar (por ejemplo, no se puede serializar);
+// el relay lo descarta en vez de reintentarlo. Cualquier otro error de publicación se considera transitorio
+var ErrEventoInvalido = errors.New("evento inválido")
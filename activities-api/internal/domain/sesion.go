@@ -0,0 +1,110 @@
+package domain
+
+import "time"
+
+// FormatoFecha es el formato de las fechas sin hora (YYYY-MM-DD)
+const FormatoFecha = "2006-01-02"
+
+// Estados de una sesión
+const (
+	SesionProgramada = "programada"
+	SesionCancelada  = "cancelada"
+)
+
+// Eventos de sesiones (routing key session.<action> en gym_events)
+const (
+	SesionEventType        = "session"
+	SesionEventCancelled   = "cancelled"
+	SesionEventRescheduled = "rescheduled"
+)
+
+// ReglaRecurrencia genera sesiones fechadas de una actividad (ej: lunes y miércoles 18:00-19:00)
+type ReglaRecurrencia struct {
+	ID               uint      `json:"id"`
+	ActividadID      uint      `json:"actividad_id"`
+	Dias             []string  `json:"dias"`
+	HorarioInicio    string    `json:"horario_inicio"` // HH:MM
+	HorarioFinal     string    `json:"horario_final"`  // HH:MM
+	FechaDesde       string    `json:"fecha_desde"`    // YYYY-MM-DD
+	FechaHasta       *string   `json:"fecha_hasta,omitempty"`
+	IntervaloSemanas uint      `json:"intervalo_semanas"` // 1 = todas las semanas, 2 = semana por medio
+	Excepciones      []string  `json:"excepciones"`       // Fechas sin clase (feriados), YYYY-MM-DD
+	Cupo             *uint     `json:"cupo,omitempty"`    // nil = cupo de la actividad
+	GeneradaHasta    *string   `json:"generada_hasta,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ReglaRecurrenciaCreate representa los datos para crear una regla
+// Días y horarios vacíos toman los de la actividad; fecha_desde vacía es hoy
+type ReglaRecurrenciaCreate struct {
+	Dias             []string `json:"dias" binding:"omitempty,dive,oneof=Lunes Martes Miercoles Jueves Viernes Sabado Domingo"`
+	HorarioInicio    string   `json:"horario_inicio"`
+	HorarioFinal     string   `json:"horario_final"`
+	FechaDesde       string   `json:"fecha_desde"`
+	FechaHasta       *string  `json:"fecha_hasta"`
+	IntervaloSemanas uint     `json:"intervalo_semanas" binding:"omitempty,min=1,max=4"`
+	Excepciones      []string `json:"excepciones"`
+	Cupo             *uint    `json:"cupo" binding:"omitempty,min=1"`
+}
+
+// Sesion es una clase concreta de una actividad en una fecha
+type Sesion struct {
+	ID                uint      `json:"id"`
+	ActividadID       uint      `json:"actividad_id"`
+	ActividadTitulo   string    `json:"actividad_titulo,omitempty"`
+	SucursalID        *uint     `json:"sucursal_id,omitempty"`
+	ReglaID           *uint     `json:"regla_id,omitempty"` // nil = sesión suelta
+	Fecha             string    `json:"fecha"`              // YYYY-MM-DD (en la zona horaria del gimnasio)
+	Inicio            time.Time `json:"inicio"`
+	Fin               time.Time `json:"fin"`
+	Cupo              uint      `json:"cupo"`
+	Inscriptos        uint      `json:"inscriptos"`
+	Lugares           uint      `json:"lugares"`
+	Estado            string    `json:"estado"`
+	MotivoCancelacion string    `json:"motivo_cancelacion,omitempty"`
+	Reprogramada      bool      `json:"reprogramada"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// SesionCreate representa los datos para crear una sesión suelta (fuera de las reglas)
+type SesionCreate struct {
+	ActividadID   uint   `json:"actividad_id" binding:"required"`
+	Fecha         string `json:"fecha" binding:"required"`
+	HorarioInicio string `json:"horario_inicio" binding:"required"`
+	HorarioFinal  string `json:"horario_final" binding:"required"`
+	Cupo          *uint  `json:"cupo" binding:"omitempty,min=1"` // nil = cupo de la actividad
+}
+
+// SesionUpdate reprograma una sesión y/o cambia su cupo
+type SesionUpdate struct {
+	Fecha         string `json:"fecha" binding:"required"`
+	HorarioInicio string `json:"horario_inicio" binding:"required"`
+	HorarioFinal  string `json:"horario_final" binding:"required"`
+	Cupo          uint   `json:"cupo" binding:"required,min=1"`
+}
+
+// SesionCancel representa el motivo de cancelación de una sesión
+type SesionCancel struct {
+	Motivo string `json:"motivo" binding:"max=255"`
+}
+
+// SesionFiltro filtra el listado de sesiones por rango de fechas (YYYY-MM-DD, ambas inclusive)
+type SesionFiltro struct {
+	FechaDesde  string // Vacío = hoy
+	FechaHasta  string // Vacío = una semana desde FechaDesde
+	ActividadID *uint
+	SucursalID  *uint
+	Estado      string // Vacío = todas
+}
+
+// InscripcionSesion es la inscripción de un socio a una sesión puntual
+type InscripcionSesion struct {
+	ID               uint      `json:"id"`
+	SesionID         uint      `json:"sesion_id"`
+	UsuarioID        uint      `json:"usuario_id"`
+	FechaInscripcion time.Time `json:"fecha_inscripcion"`
+	IsActiva         bool      `json:"is_activa"`
+	SuscripcionID    *string   `json:"suscripcion_id,omitempty"`
+	Sesion           *Sesion   `json:"sesion,omitempty"`
+}
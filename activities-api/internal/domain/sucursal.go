@@ -2,26 +2,54 @@ package domain
 
 import "time"
 
+// Eventos de sucursales (routing key sucursal.<action> en gym_events)
+// search-api los usa para mostrar el nombre de la sucursal en las actividades
+const (
+	SucursalEventType    = "sucursal"
+	SucursalEventCreated = "create"
+	SucursalEventUpdated = "update"
+	SucursalEventDeleted = "delete"
+)
+
 // Sucursal representa la entidad de negocio Sucursal
-// TODO: Para que los compañeros implementen CRUD completo
 type Sucursal struct {
-	ID        uint      `json:"id"`
-	Nombre    string    `json:"nombre"`
-	Direccion string    `json:"direccion"`
-	Telefono  string    `json:"telefono"`
-	CreatedAt time.Time `json:"created_at,omitempty"`
-	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	ID        uint              `json:"id"`
+	Nombre    string            `json:"nombre"`
+	Direccion string            `json:"direccion"`
+	Telefono  string            `json:"telefono"`
+	Horarios  []HorarioSucursal `json:"horarios"`
+	Capacidad uint              `json:"capacidad"`          // Aforo máximo de la sucursal
+	Latitud   *float64          `json:"latitud,omitempty"`  // Coordenadas para mapas
+	Longitud  *float64          `json:"longitud,omitempty"` // (se informan las dos o ninguna)
+	CreatedAt time.Time         `json:"created_at,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at,omitempty"`
+}
+
+// HorarioSucursal representa el horario de apertura de un día de la semana
+type HorarioSucursal struct {
+	Dia      string `json:"dia" binding:"required"`      // Lunes ... Domingo (mismo formato que Actividad)
+	Apertura string `json:"apertura" binding:"required"` // "HH:MM"
+	Cierre   string `json:"cierre" binding:"required"`   // "HH:MM"
 }
 
 // SucursalCreate representa los datos para crear una sucursal
 type SucursalCreate struct {
-	Nombre    string `json:"nombre" binding:"required"`
-	Direccion string `json:"direccion" binding:"required"`
-	Telefono  string `json:"telefono" binding:"required"`
+	Nombre    string            `json:"nombre" binding:"required,max=100"`
+	Direccion string            `json:"direccion" binding:"required,max=255"`
+	Telefono  string            `json:"telefono" binding:"required,max=20"`
+	Horarios  []HorarioSucursal `json:"horarios" binding:"dive"`
+	Capacidad uint              `json:"capacidad" binding:"required,min=1"`
+	Latitud   *float64          `json:"latitud,omitempty" binding:"omitempty,min=-90,max=90"`
+	Longitud  *float64          `json:"longitud,omitempty" binding:"omitempty,min=-180,max=180"`
 }
 
-// TODO: Los compañeros deben implementar:
-// - Repository (internal/repository/sucursales_mysql.go)
-// - Service (internal/services/sucursales.go)
-// - Controller (internal/controllers/sucursales.go)
-// - Endpoints en main.go
+// SucursalUpdate representa los datos para actualizar una sucursal (reemplazo completo)
+type SucursalUpdate struct {
+	Nombre    string            `json:"nombre" binding:"required,max=100"`
+	Direccion string            `json:"direccion" binding:"required,max=255"`
+	Telefono  string            `json:"telefono" binding:"required,max=20"`
+	Horarios  []HorarioSucursal `json:"horarios" binding:"dive"`
+	Capacidad uint              `json:"capacidad" binding:"required,min=1"`
+	Latitud   *float64          `json:"latitud,omitempty" binding:"omitempty,min=-90,max=90"`
+	Longitud  *float64          `json:"longitud,omitempty" binding:"omitempty,min=-180,max=180"`
+}
@@ -10,9 +10,9 @@ import (
 func CORSMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		ctx.Header("Access-Control-Allow-Origin", "*")
-		ctx.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		ctx.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		ctx.Header("Access-Control-Expose-Headers", "Content-Length")
+		ctx.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		ctx.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match")
+		ctx.Header("Access-Control-Expose-Headers", "Content-Length, ETag")
 		ctx.Header("Access-Control-Allow-Credentials", "true")
 
 		// Handle preflight requests
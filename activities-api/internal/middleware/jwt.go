@@ -1,7 +1,8 @@
 package middleware
 
 import (
-	"fmt"
+	"context"
+	"log"
 	"net/http"
 	"strings"
 
@@ -9,10 +10,21 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// SessionChecker consulta si la sesión de un token (claim "sid") fue revocada en users-api
+type SessionChecker interface {
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// KeyResolver resuelve la clave pública que verifica un token según su kid (JWKS de users-api)
+type KeyResolver interface {
+	Keyfunc(token *jwt.Token) (interface{}, error)
+}
+
 // JWTAuthMiddleware valida el token JWT en el header Authorization
 // Nota: Este middleware NO valida si el usuario existe en la BD (eso lo hace users-api)
-// Solo valida que el token sea válido y extrae los claims
-func JWTAuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// Solo valida que el token sea válido, que su sesión no esté revocada y extrae los claims
+// sessions puede ser nil para omitir el chequeo de revocación
+func JWTAuthMiddleware(keys KeyResolver, sessions SessionChecker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// Obtener header Authorization
 		auth := ctx.GetHeader("Authorization")
@@ -32,19 +44,16 @@ func JWTAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		// Parsear y validar token
-		token, err := jwt.Parse(parts[1], func(token *jwt.Token) (interface{}, error) {
-			// Validar el algoritmo
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		})
-
+		// Parsear y validar token (la clave pública y el algoritmo se resuelven por kid)
+		token, err := jwt.Parse(parts[1], keys.Keyfunc)
 		if err != nil || !token.Valid {
+			details := "invalid token"
+			if err != nil {
+				details = err.Error()
+			}
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error":   "Invalid or expired token",
-				"details": err.Error(),
+				"details": details,
 			})
 			return
 		}
@@ -58,6 +67,20 @@ func JWTAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		// Verificar revocación (logout o reuso de refresh token)
+		// Si users-api no responde se acepta el token: la firma y la expiración ya fueron validadas
+		if sid, _ := claims["sid"].(string); sid != "" && sessions != nil {
+			revoked, err := sessions.IsRevoked(ctx.Request.Context(), sid)
+			if err != nil {
+				log.Printf("⚠️  Could not check session %s: %v", sid, err)
+			} else if revoked {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": "Session revoked",
+				})
+				return
+			}
+		}
+
 		// Guardar claims en contexto
 		idUser, _ := claims["id_usuario"].(float64)
 		isAdmin, _ := claims["is_admin"].(bool)
@@ -66,6 +89,9 @@ func JWTAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		ctx.Set("id_usuario", uint(idUser))
 		ctx.Set("is_admin", isAdmin)
 		ctx.Set("username", username)
+		ctx.Set("roles", claimStrings(claims["roles"]))
+		ctx.Set("permissions", claimStrings(claims["permissions"]))
+		ctx.Set("sucursal_ids", claimUints(claims["sucursal_ids"]))
 
 		ctx.Next()
 	}
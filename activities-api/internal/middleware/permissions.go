@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"activities-api/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission verifica que el usuario tenga al menos uno de los permisos indicados
+// Debe usarse DESPUÉS de JWTAuthMiddleware. Los permisos con alcance (":own", ":sucursal")
+// solo habilitan la ruta: el service verifica que la actividad esté dentro del alcance
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		actor := ActorFromContext(ctx)
+		for _, permission := range permissions {
+			if actor.Can(permission) {
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":    "You don't have permission to perform this action",
+			"required": permissions,
+		})
+	}
+}
+
+// ActorFromContext arma el Actor con los claims que guardó JWTAuthMiddleware
+func ActorFromContext(ctx *gin.Context) domain.Actor {
+	sucursalIDs, _ := ctx.Get("sucursal_ids")
+	ids, _ := sucursalIDs.([]uint)
+
+	return domain.Actor{
+		UsuarioID:   ctx.GetUint("id_usuario"),
+		IsAdmin:     ctx.GetBool("is_admin"),
+		Permissions: ctx.GetStringSlice("permissions"),
+		SucursalIDs: ids,
+	}
+}
+
+// claimStrings convierte un claim array del JWT a []string
+func claimStrings(value interface{}) []string {
+	items, _ := value.([]interface{})
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// claimUints convierte un claim array numérico del JWT a []uint
+func claimUints(value interface{}) []uint {
+	items, _ := value.([]interface{})
+	result := make([]uint, 0, len(items))
+	for _, item := range items {
+		if n, ok := item.(float64); ok && n >= 0 {
+			result = append(result, uint(n))
+		}
+	}
+	return result
+}
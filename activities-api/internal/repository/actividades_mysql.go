@@ -12,6 +12,7 @@ import (
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -19,10 +20,16 @@ import (
 type ActividadesRepository interface {
 	List(ctx context.Context) ([]domain.Actividad, error)
 	GetByID(ctx context.Context, id uint) (domain.Actividad, error)
-	GetByParams(ctx context.Context, params map[string]interface{}) ([]domain.Actividad, error)
+	Search(ctx context.Context, busqueda domain.ActividadBusqueda) ([]domain.Actividad, int64, error)
 	Create(ctx context.Context, actividad domain.Actividad, horaInicio, horaFin time.Time) (domain.Actividad, error)
 	Update(ctx context.Context, id uint, actividad domain.Actividad, horaInicio, horaFin time.Time) (domain.Actividad, error)
 	Delete(ctx context.Context, id uint) error
+
+	// Superposición de horarios
+	LockDia(ctx context.Context, dia string) error
+	ListByDia(ctx context.Context, dia string) ([]domain.Actividad, error)
+	SaveConflictosForzados(ctx context.Context, actividadID, forzadoPor uint, conflictos []domain.ConflictoHorario) error
+	ListConflictosForzados(ctx context.Context, actividadID uint) ([]domain.ConflictoForzado, error)
 }
 
 // MySQLActividadesRepository implementa ActividadesRepository usando MySQL/GORM
@@ -66,8 +73,8 @@ func NewMySQLActividadesRepository(cfg config.MySQLConfig) *MySQLActividadesRepo
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	// Auto-migration
-	if err := db.AutoMigrate(&dao.Actividad{}, &dao.Sucursal{}); err != nil {
+	// Auto-migration (inscripciones también, porque la vista actividades_lugares la necesita)
+	if err := db.AutoMigrate(&dao.Actividad{}, &dao.Sucursal{}, &dao.Inscripcion{}, &dao.ConflictoForzado{}, &dao.ActividadDia{}); err != nil {
 		log.Fatalf("Error auto-migrating tables: %v", err)
 		return nil
 	}
@@ -98,7 +105,7 @@ func NewMySQLActividadesRepository(cfg config.MySQLConfig) *MySQLActividadesRepo
 func (r *MySQLActividadesRepository) List(ctx context.Context) ([]domain.Actividad, error) {
 	var actividadesDAO []dao.ActividadVista
 
-	if err := r.db.WithContext(ctx).Find(&actividadesDAO).Error; err != nil {
+	if err := conn(ctx, r.db).Find(&actividadesDAO).Error; err != nil {
 		return nil, fmt.Errorf("error listing actividades: %w", err)
 	}
 
@@ -115,7 +122,7 @@ func (r *MySQLActividadesRepository) List(ctx context.Context) ([]domain.Activid
 func (r *MySQLActividadesRepository) GetByID(ctx context.Context, id uint) (domain.Actividad, error) {
 	var actividadDAO dao.ActividadVista
 
-	err := r.db.WithContext(ctx).Where("id_actividad = ?", id).First(&actividadDAO).Error
+	err := conn(ctx, r.db).Where("id_actividad = ?", id).First(&actividadDAO).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return domain.Actividad{}, errors.New("actividad not found")
@@ -126,28 +133,63 @@ func (r *MySQLActividadesRepository) GetByID(ctx context.Context, id uint) (doma
 	return actividadDAO.ToDomain(), nil
 }
 
-// GetByParams busca actividades por parámetros
+// Search busca actividades con filtros, orden y paginación; devuelve la página y el total de coincidencias
 // Migrado de backend/clients/actividad/actividad_client.go:11
-func (r *MySQLActividadesRepository) GetByParams(ctx context.Context, params map[string]interface{}) ([]domain.Actividad, error) {
-	var actividadesDAO []dao.ActividadVista
-	query := r.db.WithContext(ctx).Model(&dao.ActividadVista{})
+func (r *MySQLActividadesRepository) Search(ctx context.Context, busqueda domain.ActividadBusqueda) ([]domain.Actividad, int64, error) {
+	query := conn(ctx, r.db).Model(&dao.ActividadVista{})
 
 	// Filtros opcionales
-	if id, ok := params["id"]; ok && id != "" {
-		query = query.Where("id_actividad = ?", id)
+	if busqueda.ID != nil {
+		query = query.Where("id_actividad = ?", *busqueda.ID)
+	}
+	if busqueda.Titulo != "" {
+		query = query.Where("titulo LIKE ?", fmt.Sprintf("%%%s%%", busqueda.Titulo))
+	}
+	if len(busqueda.Categorias) > 0 {
+		query = query.Where(anyLike(r.db, "categoria", busqueda.Categorias))
+	}
+	if len(busqueda.Dias) > 0 {
+		query = query.Where("dia IN ?", busqueda.Dias)
+	}
+	if len(busqueda.Instructores) > 0 {
+		query = query.Where(anyLike(r.db, "instructor", busqueda.Instructores))
+	}
+	if len(busqueda.InstructorIDs) > 0 {
+		query = query.Where("instructor_id IN ?", busqueda.InstructorIDs)
+	}
+	if len(busqueda.SucursalIDs) > 0 {
+		query = query.Where("sucursal_id IN ?", busqueda.SucursalIDs)
+	}
+	if busqueda.Horario != "" {
+		query = query.Where("TIME(?) BETWEEN TIME(horario_inicio) AND TIME(horario_final)", busqueda.Horario)
 	}
-	if titulo, ok := params["titulo"]; ok && titulo != "" {
-		query = query.Where("titulo LIKE ?", fmt.Sprintf("%%%s%%", titulo))
+	// Ventana horaria: la actividad se superpone con [desde, hasta)
+	if busqueda.Desde != "" {
+		query = query.Where("TIME(horario_final) > TIME(?)", busqueda.Desde)
 	}
-	if horario, ok := params["horario"]; ok && horario != "" {
-		query = query.Where("TIME(?) BETWEEN TIME(horario_inicio) AND TIME(horario_final)", horario)
+	if busqueda.Hasta != "" {
+		query = query.Where("TIME(horario_inicio) < TIME(?)", busqueda.Hasta)
 	}
-	if categoria, ok := params["categoria"]; ok && categoria != "" {
-		query = query.Where("categoria LIKE ?", fmt.Sprintf("%%%s%%", categoria))
+	if busqueda.MinLugares > 0 {
+		query = query.Where("lugares >= ?", busqueda.MinLugares)
 	}
 
-	if err := query.Find(&actividadesDAO).Error; err != nil {
-		return nil, fmt.Errorf("error searching actividades: %w", err)
+	// Session permite reutilizar los filtros para el conteo y para la página
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("error counting actividades: %w", err)
+	}
+
+	var actividadesDAO []dao.ActividadVista
+	err := query.
+		Order(actividadesOrder(busqueda.Orden, busqueda.Desc)).
+		Limit(busqueda.Limit).
+		Offset(busqueda.Offset).
+		Find(&actividadesDAO).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("error searching actividades: %w", err)
 	}
 
 	// Convertir a Domain
@@ -156,7 +198,38 @@ func (r *MySQLActividadesRepository) GetByParams(ctx context.Context, params map
 		actividades[i] = actDAO.ToDomain()
 	}
 
-	return actividades, nil
+	return actividades, total, nil
+}
+
+// anyLike arma "(columna LIKE %v1% OR columna LIKE %v2% ...)" para los filtros con varios valores
+func anyLike(db *gorm.DB, column string, values []string) *gorm.DB {
+	cond := db.Session(&gorm.Session{NewDB: true})
+	for _, value := range values {
+		cond = cond.Or(column+" LIKE ?", fmt.Sprintf("%%%s%%", value))
+	}
+	return cond
+}
+
+// actividadesOrder traduce el orden de la búsqueda a SQL; id_actividad desempata para que la paginación sea estable
+// (dia es un ENUM, así que ordena de Lunes a Domingo)
+func actividadesOrder(orden string, desc bool) string {
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+
+	switch orden {
+	case domain.ActividadOrdenHorario:
+		return fmt.Sprintf("dia %s, horario_inicio %s, id_actividad", dir, dir)
+	case domain.ActividadOrdenPopularidad:
+		return fmt.Sprintf("(cupo - lugares) %s, id_actividad", dir)
+	case domain.ActividadOrdenLugares:
+		return fmt.Sprintf("lugares %s, id_actividad", dir)
+	case domain.ActividadOrdenTitulo:
+		return fmt.Sprintf("titulo %s, id_actividad", dir)
+	default:
+		return fmt.Sprintf("id_actividad %s", dir)
+	}
 }
 
 // Create inserta una nueva actividad
@@ -164,27 +237,50 @@ func (r *MySQLActividadesRepository) Create(ctx context.Context, actividad domai
 	actividadDAO := dao.ActividadFromDomain(actividad, horaInicio, horaFin)
 	actividadDAO.CreatedAt = time.Now()
 	actividadDAO.UpdatedAt = time.Now()
+	actividadDAO.Version = 1
 
-	if err := r.db.WithContext(ctx).Create(&actividadDAO).Error; err != nil {
+	if err := conn(ctx, r.db).Create(&actividadDAO).Error; err != nil {
 		return domain.Actividad{}, fmt.Errorf("error creating actividad: %w", err)
 	}
 
 	return actividadDAO.ToDomain(), nil
 }
 
-// Update actualiza una actividad existente
+// Update reemplaza los datos editables de una actividad e incrementa su versión
+// Si actividad.Version no es 0 solo actualiza si sigue en esa versión (si no, domain.ErrVersionConflict)
 func (r *MySQLActividadesRepository) Update(ctx context.Context, id uint, actividad domain.Actividad, horaInicio, horaFin time.Time) (domain.Actividad, error) {
 	actividadDAO := dao.ActividadFromDomain(actividad, horaInicio, horaFin)
 	actividadDAO.ID = id
 	actividadDAO.UpdatedAt = time.Now()
 
-	// GORM ejecutará el hook BeforeUpdate que valida cupos
-	result := r.db.WithContext(ctx).Model(&dao.Actividad{ID: id, Cupo: actividadDAO.Cupo}).Updates(&actividadDAO)
-	if result.Error != nil {
-		return domain.Actividad{}, fmt.Errorf("error updating actividad: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return domain.Actividad{}, errors.New("actividad not found")
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Bloquear la fila para comparar y pisar la versión sin que otra actualización se intercale
+		var current dao.Actividad
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id_actividad", "version").
+			First(&current, "id_actividad = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("actividad not found")
+			}
+			return err
+		}
+		if actividad.Version != 0 && current.Version != actividad.Version {
+			return domain.ErrVersionConflict
+		}
+		actividadDAO.Version = current.Version + 1
+
+		// GORM ejecutará el hook BeforeUpdate que valida cupos
+		// Select incluye los campos en cero/null (ej: desasignar la sucursal desde un PATCH)
+		return tx.Model(&dao.Actividad{ID: id, Cupo: actividadDAO.Cupo}).
+			Select("titulo", "descripcion", "cupo", "dia", "horario_inicio", "horario_final", "foto_url",
+				"instructor", "instructor_id", "categoria", "sucursal_id", "sala", "version", "updated_at").
+			Updates(&actividadDAO).Error
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrVersionConflict) || err.Error() == "actividad not found" {
+			return domain.Actividad{}, err
+		}
+		return domain.Actividad{}, fmt.Errorf("error updating actividad: %w", err)
 	}
 
 	// Obtener la actividad actualizada
@@ -193,7 +289,7 @@ func (r *MySQLActividadesRepository) Update(ctx context.Context, id uint, activi
 
 // Delete elimina una actividad
 func (r *MySQLActividadesRepository) Delete(ctx context.Context, id uint) error {
-	result := r.db.WithContext(ctx).Delete(&dao.Actividad{}, id)
+	result := conn(ctx, r.db).Delete(&dao.Actividad{}, id)
 	if result.Error != nil {
 		return fmt.Errorf("error deleting actividad: %w", result.Error)
 	}
@@ -203,3 +299,79 @@ func (r *MySQLActividadesRepository) Delete(ctx context.Context, id uint) error
 
 	return nil
 }
+
+// LockDia bloquea el día hasta el fin de la transacción del contexto, para que dos altas o ediciones
+// concurrentes del mismo día no pasen ambas el control de superposiciones (crea la fila del día si no existe)
+func (r *MySQLActividadesRepository) LockDia(ctx context.Context, dia string) error {
+	err := conn(ctx, r.db).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"dia"})}).
+		Create(&dao.ActividadDia{Dia: dia}).Error
+	if err != nil {
+		return fmt.Errorf("error locking dia: %w", err)
+	}
+
+	return nil
+}
+
+// ListByDia obtiene las actividades de un día de la semana (para detectar superposiciones)
+func (r *MySQLActividadesRepository) ListByDia(ctx context.Context, dia string) ([]domain.Actividad, error) {
+	var actividadesDAO []dao.ActividadVista
+
+	err := conn(ctx, r.db).
+		Where("dia = ?", dia).
+		Order("horario_inicio, id_actividad").
+		Find(&actividadesDAO).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing actividades by dia: %w", err)
+	}
+
+	actividades := make([]domain.Actividad, len(actividadesDAO))
+	for i, actDAO := range actividadesDAO {
+		actividades[i] = actDAO.ToDomain()
+	}
+
+	return actividades, nil
+}
+
+// SaveConflictosForzados registra las superposiciones que el administrador decidió forzar al guardar la actividad
+func (r *MySQLActividadesRepository) SaveConflictosForzados(ctx context.Context, actividadID, forzadoPor uint, conflictos []domain.ConflictoHorario) error {
+	if len(conflictos) == 0 {
+		return nil
+	}
+
+	conflictosDAO := make([]dao.ConflictoForzado, len(conflictos))
+	for i, conflicto := range conflictos {
+		conflictosDAO[i] = dao.ConflictoForzado{
+			ActividadID:    actividadID,
+			ConflictoConID: conflicto.ActividadID,
+			Motivo:         conflicto.Motivo,
+			ForzadoPor:     forzadoPor,
+		}
+	}
+
+	if err := conn(ctx, r.db).Omit(clause.Associations).Create(&conflictosDAO).Error; err != nil {
+		return fmt.Errorf("error saving conflictos forzados: %w", err)
+	}
+
+	return nil
+}
+
+// ListConflictosForzados obtiene el historial de superposiciones forzadas de una actividad
+func (r *MySQLActividadesRepository) ListConflictosForzados(ctx context.Context, actividadID uint) ([]domain.ConflictoForzado, error) {
+	var conflictosDAO []dao.ConflictoForzado
+
+	err := conn(ctx, r.db).
+		Where("actividad_id = ?", actividadID).
+		Order("created_at DESC, id DESC").
+		Find(&conflictosDAO).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing conflictos forzados: %w", err)
+	}
+
+	conflictos := make([]domain.ConflictoForzado, len(conflictosDAO))
+	for i, conflictoDAO := range conflictosDAO {
+		conflictos[i] = conflictoDAO.ToDomain()
+	}
+
+	return conflictos, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"activities-api/internal/domain"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestUpdateConcurrentSameVersion verifica que de dos actualizaciones con el mismo If-Match solo una se aplica
+func TestUpdateConcurrentSameVersion(t *testing.T) {
+	repo, actividad := newTestRepositories(t, 5)
+	actividadesRepo := &MySQLActividadesRepository{db: repo.db}
+
+	ctx := context.Background()
+	horaInicio, _ := time.Parse("15:04", "10:00")
+	horaFin, _ := time.Parse("15:04", "11:00")
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			update := actividad
+			update.Cupo = uint(10 + i)
+			_, errs[i] = actividadesRepo.Update(ctx, actividad.ID, update, horaInicio, horaFin)
+		}(i)
+	}
+	wg.Wait()
+
+	ok, conflicts := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			ok++
+		case errors.Is(err, domain.ErrVersionConflict):
+			conflicts++
+		default:
+			t.Fatalf("error inesperado: %v", err)
+		}
+	}
+	if ok != 1 || conflicts != 1 {
+		t.Fatalf("se aplicaron %d actualizaciones y %d conflictos, se esperaba 1 y 1", ok, conflicts)
+	}
+
+	updated, err := actividadesRepo.GetByID(ctx, actividad.ID)
+	if err != nil {
+		t.Fatalf("error leyendo actividad: %v", err)
+	}
+	if updated.Version != actividad.Version+1 {
+		t.Fatalf("version = %d, se esperaba %d", updated.Version, actividad.Version+1)
+	}
+}
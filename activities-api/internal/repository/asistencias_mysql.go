@@ -0,0 +1,399 @@
+package repository
+
+import (
+	"activities-api/internal/dao"
+	"activities-api/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AsistenciasRepository define la interfaz del repositorio de asistencias a sesiones y suspensiones por inasistencias
+type AsistenciasRepository interface {
+	// Asistencias
+	GetCodigoCheckin(ctx context.Context, sesionID uint, nuevo string) (string, error)
+	CheckIn(ctx context.Context, asistencia domain.Asistencia) (domain.Asistencia, error)
+	CheckOut(ctx context.Context, sesionID, usuarioID uint, at time.Time) (domain.Asistencia, error)
+	ListBySesiones(ctx context.Context, sesionIDs []uint) ([]domain.Asistencia, error)
+	ListByUser(ctx context.Context, usuarioID uint, desde, hasta *time.Time) ([]domain.Asistencia, error)
+	ListEsperados(ctx context.Context, sesiones []domain.Sesion) (map[uint]map[uint]string, error)
+	CountNoShows(ctx context.Context, usuarioID uint, desde, hasta time.Time) (int64, error)
+
+	// Suspensiones
+	GetLastSuspension(ctx context.Context, usuarioID uint) (*domain.Suspension, error)
+	CreateSuspension(ctx context.Context, suspension domain.Suspension) (domain.Suspension, error)
+	LiftSuspension(ctx context.Context, usuarioID, levantadaPor uint, at time.Time) (domain.Suspension, error)
+	ListSuspensionesByUser(ctx context.Context, usuarioID uint) ([]domain.Suspension, error)
+
+	DeleteByUser(ctx context.Context, usuarioID uint) (int64, error)
+}
+
+// MySQLAsistenciasRepository implementa AsistenciasRepository usando MySQL/GORM
+type MySQLAsistenciasRepository struct {
+	db  *gorm.DB
+	loc *time.Location // Zona horaria del gimnasio (para la fecha de cada sesión)
+}
+
+// NewMySQLAsistenciasRepository crea una nueva instancia del repository
+// Comparte la conexión DB con ActividadesRepository (requiere las tablas de sesiones)
+func NewMySQLAsistenciasRepository(db *gorm.DB, loc *time.Location) *MySQLAsistenciasRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.Asistencia{}, &dao.Suspension{}); err != nil {
+		fmt.Printf("Error auto-migrating Asistencia tables: %v\n", err)
+	}
+
+	return &MySQLAsistenciasRepository{
+		db:  db,
+		loc: loc,
+	}
+}
+
+// GetCodigoCheckin devuelve el código de check-in de la sesión; si todavía no tiene, guarda nuevo
+// Con nuevo vacío solo consulta (devuelve "" si la sesión no tiene código)
+func (r *MySQLAsistenciasRepository) GetCodigoCheckin(ctx context.Context, sesionID uint, nuevo string) (string, error) {
+	var codigo string
+
+	if nuevo == "" {
+		var sesionDAO dao.Sesion
+		err := r.db.WithContext(ctx).Select("id", "codigo_checkin").First(&sesionDAO, sesionID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("sesion not found")
+		}
+		if err != nil {
+			return "", fmt.Errorf("error getting codigo de check-in: %w", err)
+		}
+		if sesionDAO.CodigoCheckin != nil {
+			codigo = *sesionDAO.CodigoCheckin
+		}
+		return codigo, nil
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		sesionDAO, err := lockSesion(tx, sesionID)
+		if err != nil {
+			return err
+		}
+		if sesionDAO.CodigoCheckin != nil && *sesionDAO.CodigoCheckin != "" {
+			codigo = *sesionDAO.CodigoCheckin
+			return nil
+		}
+
+		codigo = nuevo
+		return tx.Model(&dao.Sesion{}).Where("id = ?", sesionID).Update("codigo_checkin", nuevo).Error
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return "", err
+		}
+		return "", fmt.Errorf("error getting codigo de check-in: %w", err)
+	}
+
+	return codigo, nil
+}
+
+// CheckIn registra la asistencia de un socio a una sesión
+func (r *MySQLAsistenciasRepository) CheckIn(ctx context.Context, asistencia domain.Asistencia) (domain.Asistencia, error) {
+	asistenciaDAO := dao.AsistenciaFromDomain(asistencia)
+
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Create(&asistenciaDAO).Error; err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return domain.Asistencia{}, errors.New("el usuario ya registró su asistencia a esta sesión")
+		}
+		return domain.Asistencia{}, fmt.Errorf("error creating asistencia: %w", err)
+	}
+
+	return asistenciaDAO.ToDomain(), nil
+}
+
+// CheckOut registra la salida de un socio de una sesión
+func (r *MySQLAsistenciasRepository) CheckOut(ctx context.Context, sesionID, usuarioID uint, at time.Time) (domain.Asistencia, error) {
+	var asistenciaDAO dao.Asistencia
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("sesion_id = ? AND usuario_id = ?", sesionID, usuarioID).
+			First(&asistenciaDAO).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("asistencia not found")
+		}
+		if err != nil {
+			return err
+		}
+		if asistenciaDAO.CheckOutAt != nil {
+			return errors.New("el usuario ya registró la salida de esta sesión")
+		}
+		if at.Before(asistenciaDAO.CheckInAt) {
+			at = asistenciaDAO.CheckInAt
+		}
+
+		asistenciaDAO.CheckOutAt = &at
+		return tx.Model(&dao.Asistencia{}).Where("id = ?", asistenciaDAO.ID).Update("check_out_at", at).Error
+	})
+	if err != nil {
+		errString := err.Error()
+		if strings.Contains(errString, "not found") || strings.HasPrefix(errString, "el usuario") {
+			return domain.Asistencia{}, err
+		}
+		return domain.Asistencia{}, fmt.Errorf("error checking out: %w", err)
+	}
+
+	return asistenciaDAO.ToDomain(), nil
+}
+
+// ListBySesiones obtiene las asistencias registradas en las sesiones indicadas
+func (r *MySQLAsistenciasRepository) ListBySesiones(ctx context.Context, sesionIDs []uint) ([]domain.Asistencia, error) {
+	if len(sesionIDs) == 0 {
+		return []domain.Asistencia{}, nil
+	}
+
+	var asistenciasDAO []dao.Asistencia
+	err := r.db.WithContext(ctx).
+		Where("sesion_id IN ?", sesionIDs).
+		Order("check_in_at").
+		Find(&asistenciasDAO).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing asistencias: %w", err)
+	}
+
+	asistencias := make([]domain.Asistencia, len(asistenciasDAO))
+	for i, asistenciaDAO := range asistenciasDAO {
+		asistencias[i] = asistenciaDAO.ToDomain()
+	}
+
+	return asistencias, nil
+}
+
+// ListByUser obtiene las asistencias de un socio (con la sesión) cuyas sesiones empiezan en [desde, hasta)
+func (r *MySQLAsistenciasRepository) ListByUser(ctx context.Context, usuarioID uint, desde, hasta *time.Time) ([]domain.Asistencia, error) {
+	query := r.db.WithContext(ctx).
+		Joins("JOIN sesiones ON sesiones.id = asistencias.sesion_id").
+		Where("asistencias.usuario_id = ?", usuarioID)
+	if desde != nil {
+		query = query.Where("sesiones.inicio >= ?", *desde)
+	}
+	if hasta != nil {
+		query = query.Where("sesiones.inicio < ?", *hasta)
+	}
+
+	var asistenciasDAO []dao.Asistencia
+	if err := query.Order("sesiones.inicio").Find(&asistenciasDAO).Error; err != nil {
+		return nil, fmt.Errorf("error listing asistencias: %w", err)
+	}
+	if len(asistenciasDAO) == 0 {
+		return []domain.Asistencia{}, nil
+	}
+
+	// Una sola consulta para el detalle de todas las sesiones
+	sesionIDs := make([]uint, len(asistenciasDAO))
+	for i, asistenciaDAO := range asistenciasDAO {
+		sesionIDs[i] = asistenciaDAO.SesionID
+	}
+	var sesionesDAO []dao.SesionDetalle
+	if err := detalle(r.db.WithContext(ctx)).Where("sesiones.id IN ?", sesionIDs).Scan(&sesionesDAO).Error; err != nil {
+		return nil, fmt.Errorf("error listing asistencias: %w", err)
+	}
+	sesiones := make(map[uint]domain.Sesion, len(sesionesDAO))
+	for _, sesionDAO := range sesionesDAO {
+		sesiones[sesionDAO.ID] = sesionDAO.ToDomain(r.loc)
+	}
+
+	asistencias := make([]domain.Asistencia, len(asistenciasDAO))
+	for i, asistenciaDAO := range asistenciasDAO {
+		asistencias[i] = asistenciaDAO.ToDomain()
+		if sesion, ok := sesiones[asistenciaDAO.SesionID]; ok {
+			asistencias[i].Sesion = &sesion
+		}
+	}
+
+	return asistencias, nil
+}
+
+// ListEsperados devuelve, por sesión, los socios que deberían presentarse y por qué inscripción
+// (domain.EsperadoPorSesion o domain.EsperadoPorActividad si se inscribió a la actividad antes de que empiece la sesión)
+func (r *MySQLAsistenciasRepository) ListEsperados(ctx context.Context, sesiones []domain.Sesion) (map[uint]map[uint]string, error) {
+	esperados := make(map[uint]map[uint]string, len(sesiones))
+	if len(sesiones) == 0 {
+		return esperados, nil
+	}
+
+	sesionIDs := make([]uint, len(sesiones))
+	actividadIDs := make([]uint, 0, len(sesiones))
+	vistas := make(map[uint]bool)
+	for i, sesion := range sesiones {
+		sesionIDs[i] = sesion.ID
+		esperados[sesion.ID] = make(map[uint]string)
+		if !vistas[sesion.ActividadID] {
+			vistas[sesion.ActividadID] = true
+			actividadIDs = append(actividadIDs, sesion.ActividadID)
+		}
+	}
+
+	var inscripcionesActividad []dao.Inscripcion
+	err := r.db.WithContext(ctx).
+		Where("actividad_id IN ? AND is_activa = ? AND deleted_at IS NULL", actividadIDs, true).
+		Find(&inscripcionesActividad).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing esperados: %w", err)
+	}
+	for _, sesion := range sesiones {
+		for _, insc := range inscripcionesActividad {
+			if insc.ActividadID == sesion.ActividadID && insc.FechaInscripcion.Before(sesion.Inicio) {
+				esperados[sesion.ID][insc.UsuarioID] = domain.EsperadoPorActividad
+			}
+		}
+	}
+
+	var inscripcionesSesion []dao.InscripcionSesion
+	err = r.db.WithContext(ctx).
+		Where("sesion_id IN ? AND is_activa = ?", sesionIDs, true).
+		Find(&inscripcionesSesion).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing esperados: %w", err)
+	}
+	for _, insc := range inscripcionesSesion {
+		esperados[insc.SesionID][insc.UsuarioID] = domain.EsperadoPorSesion
+	}
+
+	return esperados, nil
+}
+
+// CountNoShows cuenta las sesiones programadas que terminaron en [desde, hasta) en las que el socio
+// estaba inscripto (a la sesión o a la actividad) y no registró asistencia
+func (r *MySQLAsistenciasRepository) CountNoShows(ctx context.Context, usuarioID uint, desde, hasta time.Time) (int64, error) {
+	var noShows int64
+
+	err := r.db.WithContext(ctx).
+		Model(&dao.Sesion{}).
+		Where("sesiones.estado = ? AND sesiones.fin >= ? AND sesiones.fin < ?", domain.SesionProgramada, desde, hasta).
+		Where(`(EXISTS (SELECT 1 FROM inscripciones_sesion i
+				WHERE i.sesion_id = sesiones.id AND i.usuario_id = ? AND i.is_activa = true)
+			OR EXISTS (SELECT 1 FROM inscripciones i
+				WHERE i.actividad_id = sesiones.actividad_id AND i.usuario_id = ? AND i.is_activa = true
+				AND i.deleted_at IS NULL AND i.fecha_inscripcion < sesiones.inicio))`, usuarioID, usuarioID).
+		Where("NOT EXISTS (SELECT 1 FROM asistencias a WHERE a.sesion_id = sesiones.id AND a.usuario_id = ?)", usuarioID).
+		Count(&noShows).Error
+	if err != nil {
+		return 0, fmt.Errorf("error counting no-shows: %w", err)
+	}
+
+	return noShows, nil
+}
+
+// GetLastSuspension obtiene la suspensión más reciente del socio (nil si nunca fue suspendido)
+func (r *MySQLAsistenciasRepository) GetLastSuspension(ctx context.Context, usuarioID uint) (*domain.Suspension, error) {
+	var suspensionDAO dao.Suspension
+
+	err := r.db.WithContext(ctx).
+		Where("usuario_id = ?", usuarioID).
+		Order("desde DESC, id DESC").
+		First(&suspensionDAO).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting suspension: %w", err)
+	}
+
+	suspension := suspensionDAO.ToDomain()
+	return &suspension, nil
+}
+
+// CreateSuspension registra una suspensión
+func (r *MySQLAsistenciasRepository) CreateSuspension(ctx context.Context, suspension domain.Suspension) (domain.Suspension, error) {
+	suspensionDAO := dao.Suspension{
+		UsuarioID: suspension.UsuarioID,
+		Desde:     suspension.Desde,
+		Hasta:     suspension.Hasta,
+		NoShows:   suspension.NoShows,
+	}
+
+	if err := r.db.WithContext(ctx).Create(&suspensionDAO).Error; err != nil {
+		return domain.Suspension{}, fmt.Errorf("error creating suspension: %w", err)
+	}
+
+	return suspensionDAO.ToDomain(), nil
+}
+
+// LiftSuspension levanta la suspensión vigente del socio antes de su vencimiento
+func (r *MySQLAsistenciasRepository) LiftSuspension(ctx context.Context, usuarioID, levantadaPor uint, at time.Time) (domain.Suspension, error) {
+	var suspensionDAO dao.Suspension
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("usuario_id = ? AND levantada_at IS NULL AND hasta > ?", usuarioID, at).
+			Order("desde DESC").
+			First(&suspensionDAO).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("suspension not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		suspensionDAO.LevantadaPor = &levantadaPor
+		suspensionDAO.LevantadaAt = &at
+		return tx.Model(&dao.Suspension{}).Where("id = ?", suspensionDAO.ID).Updates(map[string]interface{}{
+			"levantada_por": levantadaPor,
+			"levantada_at":  at,
+		}).Error
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return domain.Suspension{}, err
+		}
+		return domain.Suspension{}, fmt.Errorf("error lifting suspension: %w", err)
+	}
+
+	return suspensionDAO.ToDomain(), nil
+}
+
+// ListSuspensionesByUser obtiene todas las suspensiones del socio
+func (r *MySQLAsistenciasRepository) ListSuspensionesByUser(ctx context.Context, usuarioID uint) ([]domain.Suspension, error) {
+	var suspensionesDAO []dao.Suspension
+
+	err := r.db.WithContext(ctx).
+		Where("usuario_id = ?", usuarioID).
+		Order("desde").
+		Find(&suspensionesDAO).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing suspensiones: %w", err)
+	}
+
+	suspensiones := make([]domain.Suspension, len(suspensionesDAO))
+	for i, suspensionDAO := range suspensionesDAO {
+		suspensiones[i] = suspensionDAO.ToDomain()
+	}
+
+	return suspensiones, nil
+}
+
+// DeleteByUser borra físicamente las asistencias y suspensiones de un usuario (derecho al olvido)
+func (r *MySQLAsistenciasRepository) DeleteByUser(ctx context.Context, usuarioID uint) (int64, error) {
+	var deleted int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("usuario_id = ?", usuarioID).Delete(&dao.Asistencia{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+
+		result = tx.Where("usuario_id = ?", usuarioID).Delete(&dao.Suspension{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted += result.RowsAffected
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error deleting asistencias: %w", err)
+	}
+
+	return deleted, nil
+}
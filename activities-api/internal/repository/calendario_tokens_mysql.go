@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"activities-api/internal/dao"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CalendarioTokensRepository define la interfaz de los tokens de los feeds ICS de los socios
+type CalendarioTokensRepository interface {
+	Save(ctx context.Context, usuarioID uint, tokenHash string) (time.Time, error)
+	GetUsuarioID(ctx context.Context, tokenHash string) (uint, error)
+	Delete(ctx context.Context, usuarioID uint) (int64, error)
+}
+
+// MySQLCalendarioTokensRepository implementa CalendarioTokensRepository usando MySQL/GORM
+type MySQLCalendarioTokensRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLCalendarioTokensRepository crea una nueva instancia del repository
+// Comparte la conexión DB con ActividadesRepository
+func NewMySQLCalendarioTokensRepository(db *gorm.DB) *MySQLCalendarioTokensRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.CalendarioToken{}); err != nil {
+		fmt.Printf("Error auto-migrating CalendarioToken table: %v\n", err)
+	}
+
+	return &MySQLCalendarioTokensRepository{
+		db: db,
+	}
+}
+
+// Save guarda el hash del token del socio, reemplazando el anterior si tenía uno
+func (r *MySQLCalendarioTokensRepository) Save(ctx context.Context, usuarioID uint, tokenHash string) (time.Time, error) {
+	tokenDAO := dao.CalendarioToken{
+		UsuarioID: usuarioID,
+		TokenHash: tokenHash,
+		CreatedAt: time.Now(),
+	}
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "usuario_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"token_hash", "created_at"}),
+		}).
+		Create(&tokenDAO).Error
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error saving calendario token: %w", err)
+	}
+
+	return tokenDAO.CreatedAt, nil
+}
+
+// GetUsuarioID obtiene el socio dueño del token
+func (r *MySQLCalendarioTokensRepository) GetUsuarioID(ctx context.Context, tokenHash string) (uint, error) {
+	var tokenDAO dao.CalendarioToken
+
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&tokenDAO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("calendario token not found")
+		}
+		return 0, fmt.Errorf("error getting calendario token: %w", err)
+	}
+
+	return tokenDAO.UsuarioID, nil
+}
+
+// Delete revoca el token del socio
+func (r *MySQLCalendarioTokensRepository) Delete(ctx context.Context, usuarioID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Where("usuario_id = ?", usuarioID).Delete(&dao.CalendarioToken{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("error deleting calendario token: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
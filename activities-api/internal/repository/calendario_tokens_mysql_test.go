@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+// TestCalendarioTokenRotation verifica que regenerar el token revoca el anterior
+func TestCalendarioTokenRotation(t *testing.T) {
+	repo, _ := newTestRepositories(t, 1)
+	tokens := NewMySQLCalendarioTokensRepository(repo.db)
+
+	ctx := context.Background()
+	const usuarioID = 3_000_001
+	t.Cleanup(func() {
+		tokens.Delete(ctx, usuarioID)
+	})
+
+	if _, err := tokens.Save(ctx, usuarioID, "hash-viejo"); err != nil {
+		t.Fatalf("error guardando token: %v", err)
+	}
+	if _, err := tokens.Save(ctx, usuarioID, "hash-nuevo"); err != nil {
+		t.Fatalf("error regenerando token: %v", err)
+	}
+
+	if _, err := tokens.GetUsuarioID(ctx, "hash-viejo"); err == nil {
+		t.Fatal("el token anterior debería estar revocado")
+	}
+	got, err := tokens.GetUsuarioID(ctx, "hash-nuevo")
+	if err != nil || got != usuarioID {
+		t.Fatalf("GetUsuarioID = %d, %v; se esperaba %d", got, err, usuarioID)
+	}
+
+	if deleted, err := tokens.Delete(ctx, usuarioID); err != nil || deleted != 1 {
+		t.Fatalf("Delete = %d, %v; se esperaba 1", deleted, err)
+	}
+}
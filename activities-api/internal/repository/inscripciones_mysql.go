@@ -6,9 +6,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // InscripcionesRepository define la interfaz del repositorio de inscripciones
@@ -17,6 +19,7 @@ type InscripcionesRepository interface {
 	GetByUserAndActividad(ctx context.Context, usuarioID, actividadID uint) (domain.Inscripcion, error)
 	Create(ctx context.Context, inscripcion domain.Inscripcion) (domain.Inscripcion, error)
 	Deactivate(ctx context.Context, usuarioID, actividadID uint) error
+	DeleteByUser(ctx context.Context, usuarioID uint) (int64, error)
 }
 
 // MySQLInscripcionesRepository implementa InscripcionesRepository usando MySQL/GORM
@@ -28,6 +31,13 @@ type MySQLInscripcionesRepository struct {
 // NewMySQLInscripcionesRepository crea una nueva instancia del repository
 // Comparte la conexión DB con ActividadesRepository
 func NewMySQLInscripcionesRepository(db *gorm.DB) *MySQLInscripcionesRepository {
+	// Las bases anteriores al índice único pueden tener filas repetidas: sin limpiarlas, AutoMigrate falla al crearlo
+	if db.Migrator().HasTable(&dao.Inscripcion{}) && !db.Migrator().HasIndex(&dao.Inscripcion{}, "idx_inscripciones_usuario_actividad") {
+		if err := dedupeInscripciones(db); err != nil {
+			fmt.Printf("Error deduplicating inscripciones: %v\n", err)
+		}
+	}
+
 	// Auto-migration
 	if err := db.AutoMigrate(&dao.Inscripcion{}); err != nil {
 		fmt.Printf("Error auto-migrating Inscripcion table: %v\n", err)
@@ -38,12 +48,43 @@ func NewMySQLInscripcionesRepository(db *gorm.DB) *MySQLInscripcionesRepository
 	}
 }
 
+// dedupeInscripciones deja una sola fila por (usuario_id, actividad_id) antes de crear el índice único
+// Se conserva la inscripción activa o, si no hay, la más reciente; las entradas de lista de espera
+// que apuntaban a una fila borrada pasan a apuntar a la conservada
+func dedupeInscripciones(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		ranked := `SELECT id, FIRST_VALUE(id) OVER w AS keep_id, ROW_NUMBER() OVER w AS fila
+			FROM inscripciones
+			WINDOW w AS (PARTITION BY usuario_id, actividad_id
+				ORDER BY (is_activa = 1 AND deleted_at IS NULL) DESC, fecha_inscripcion DESC, id DESC)`
+
+		if tx.Migrator().HasTable(&dao.ListaEspera{}) {
+			if err := tx.Exec(`UPDATE lista_espera le
+				JOIN (` + ranked + `) d ON d.id = le.inscripcion_id AND d.fila > 1
+				SET le.inscripcion_id = d.keep_id`).Error; err != nil {
+				return fmt.Errorf("error repointing lista de espera: %w", err)
+			}
+		}
+
+		result := tx.Exec(`DELETE i FROM inscripciones i
+			JOIN (` + ranked + `) d ON d.id = i.id AND d.fila > 1`)
+		if result.Error != nil {
+			return fmt.Errorf("error deleting duplicated inscripciones: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			fmt.Printf("⚠️  Se borraron %d inscripciones duplicadas antes de crear idx_inscripciones_usuario_actividad\n", result.RowsAffected)
+		}
+
+		return nil
+	})
+}
+
 // ListByUser obtiene todas las inscripciones de un usuario
 // Migrado de backend/clients/inscripcion/inscripcion_client.go:12
 func (r *MySQLInscripcionesRepository) ListByUser(ctx context.Context, usuarioID uint) ([]domain.Inscripcion, error) {
 	var inscripcionesDAO []dao.Inscripcion
 
-	err := r.db.WithContext(ctx).
+	err := conn(ctx, r.db).
 		Where("usuario_id = ?", usuarioID).
 		Find(&inscripcionesDAO).Error
 
@@ -64,7 +105,7 @@ func (r *MySQLInscripcionesRepository) ListByUser(ctx context.Context, usuarioID
 func (r *MySQLInscripcionesRepository) GetByUserAndActividad(ctx context.Context, usuarioID, actividadID uint) (domain.Inscripcion, error) {
 	var inscripcionDAO dao.Inscripcion
 
-	err := r.db.WithContext(ctx).
+	err := conn(ctx, r.db).
 		Where("usuario_id = ? AND actividad_id = ?", usuarioID, actividadID).
 		First(&inscripcionDAO).Error
 
@@ -79,60 +120,126 @@ func (r *MySQLInscripcionesRepository) GetByUserAndActividad(ctx context.Context
 }
 
 // Create crea una nueva inscripción o reactiva una existente
+// Corre en una transacción que bloquea la fila de la actividad (SELECT ... FOR UPDATE): las inscripciones
+// concurrentes a una misma actividad se serializan y el hook de cupo ve las inscripciones ya confirmadas
 // Migrado de backend/clients/inscripcion/inscripcion_client.go:27
 func (r *MySQLInscripcionesRepository) Create(ctx context.Context, inscripcion domain.Inscripcion) (domain.Inscripcion, error) {
 	inscripcionDAO := dao.InscripcionFromDomain(inscripcion)
 	inscripcionDAO.FechaInscripcion = time.Now()
 	inscripcionDAO.IsActiva = true
 
-	// Intentar buscar inscripción existente (por usuario y actividad)
-	var existing dao.Inscripcion
-	err := r.db.WithContext(ctx).
-		Where("usuario_id = ? AND actividad_id = ?", inscripcionDAO.UsuarioID, inscripcionDAO.ActividadID).
-		First(&existing).Error
+	var created dao.Inscripcion
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Bloquear la actividad hasta el commit (debe ser la primera lectura de la transacción para que
+		// las lecturas siguientes, incluida la vista actividades_lugares, vean las inscripciones previas)
+		var actividad dao.Actividad
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id_actividad").
+			First(&actividad, "id_actividad = ?", inscripcionDAO.ActividadID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("actividad not found")
+			}
+			return err
+		}
+
+		// Intentar buscar inscripción existente (por usuario y actividad)
+		var existing dao.Inscripcion
+		err := tx.Where("usuario_id = ? AND actividad_id = ?", inscripcionDAO.UsuarioID, inscripcionDAO.ActividadID).
+			First(&existing).Error
 
-	if err == nil {
-		// La inscripción ya existe
-		if existing.IsActiva {
-			return domain.Inscripcion{}, errors.New("el usuario ya está inscripto en esta actividad")
+		if err == nil && existing.IsActiva {
+			return errors.New("el usuario ya está inscripto en esta actividad")
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
 		}
 
-		// Reactivar inscripción (ejecuta hook BeforeUpdate)
-		existing.IsActiva = true
-		if err := r.db.WithContext(ctx).Model(&existing).Update("is_activa", true).Error; err != nil {
-			return domain.Inscripcion{}, fmt.Errorf("error reactivating inscripcion: %w", err)
+		// Con socios en la lista de espera los lugares libres son de ellos (se promueven por orden de llegada)
+		var esperando int64
+		if err := tx.Model(&dao.ListaEspera{}).
+			Where("actividad_id = ? AND estado = ?", inscripcionDAO.ActividadID, domain.ListaEsperaEsperando).
+			Count(&esperando).Error; err != nil {
+			return err
+		}
+		if esperando > 0 {
+			return errors.New("la actividad tiene usuarios en lista de espera")
 		}
 
-		return existing.ToDomain(), nil
-	}
+		if err == nil {
+			// Reactivar inscripción con la suscripción vigente (ejecuta hook BeforeUpdate)
+			existing.IsActiva = true
+			existing.SuscripcionID = inscripcionDAO.SuscripcionID
+			if err := tx.Model(&existing).Updates(map[string]interface{}{
+				"is_activa":      true,
+				"suscripcion_id": inscripcionDAO.SuscripcionID,
+			}).Error; err != nil {
+				return err
+			}
+
+			created = existing
+			return nil
+		}
 
-	// No existe, crear nueva (ejecuta hook BeforeCreate)
-	if err := r.db.WithContext(ctx).Create(&inscripcionDAO).Error; err != nil {
+		// No existe, crear nueva (ejecuta hook BeforeCreate)
+		if err := tx.Create(&inscripcionDAO).Error; err != nil {
+			// El índice único (usuario_id, actividad_id) impide duplicados aunque se saltee el bloqueo
+			if strings.Contains(err.Error(), "Duplicate entry") {
+				return errors.New("el usuario ya está inscripto en esta actividad")
+			}
+			return err
+		}
+
+		created = inscripcionDAO
+		return nil
+	})
+	if err != nil {
 		return domain.Inscripcion{}, fmt.Errorf("error creating inscripcion: %w", err)
 	}
 
-	return inscripcionDAO.ToDomain(), nil
+	return created.ToDomain(), nil
 }
 
 // Deactivate desactiva una inscripción (soft delete lógico)
+// Bloquea la actividad igual que Create para que los lugares que se lean después en la misma transacción
+// (ej: el evento activity.update del outbox) reflejen el orden real de altas y bajas
 // Migrado de backend/clients/inscripcion/inscripcion_client.go:53
 func (r *MySQLInscripcionesRepository) Deactivate(ctx context.Context, usuarioID, actividadID uint) error {
-	result := r.db.WithContext(ctx).
-		Model(&dao.Inscripcion{}).
-		Where("usuario_id = ? AND actividad_id = ?", usuarioID, actividadID).
-		Update("is_activa", false)
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var actividad dao.Actividad
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id_actividad").
+			First(&actividad, "id_actividad = ?", actividadID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("inscripcion not found")
+			}
+			return fmt.Errorf("error deactivating inscripcion: %w", err)
+		}
+
+		result := tx.Model(&dao.Inscripcion{}).
+			Where("usuario_id = ? AND actividad_id = ?", usuarioID, actividadID).
+			Update("is_activa", false)
+
+		if result.Error != nil {
+			return fmt.Errorf("error deactivating inscripcion: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("inscripcion not found")
+		}
+
+		return nil
+	})
+}
+
+// DeleteByUser borra físicamente todas las inscripciones de un usuario (derecho al olvido)
+// Devuelve cuántas se borraron; los cupos se liberan porque la vista solo cuenta filas existentes
+func (r *MySQLInscripcionesRepository) DeleteByUser(ctx context.Context, usuarioID uint) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("usuario_id = ?", usuarioID).
+		Delete(&dao.Inscripcion{})
 
 	if result.Error != nil {
-		return fmt.Errorf("error deactivating inscripcion: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return errors.New("inscripcion not found")
+		return 0, fmt.Errorf("error deleting inscripciones: %w", result.Error)
 	}
 
-	return nil
+	return result.RowsAffected, nil
 }
-
-// TODO: Los compañeros deben agregar:
-// - Validación de usuario existe (HTTP call a users-api)
-// - Validación de suscripción activa (HTTP call a subscriptions-api)
-// - Publicación de eventos a RabbitMQ
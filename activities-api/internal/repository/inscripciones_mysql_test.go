@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"activities-api/internal/config"
+	"activities-api/internal/dao"
+	"activities-api/internal/domain"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// Tests de integración contra MySQL: se saltean si no está MYSQL_TEST_HOST
+//
+//	docker run -d --name mysql-test -e MYSQL_ROOT_PASSWORD=root -e MYSQL_DATABASE=activities_test -p 3307:3306 mysql:8
+//	MYSQL_TEST_HOST=127.0.0.1 MYSQL_TEST_PORT=3307 MYSQL_TEST_PASS=root go test ./internal/repository/ -run Concurrent -v
+
+// newTestRepositories conecta a la base de test y crea una actividad con el cupo indicado
+// La actividad (y sus inscripciones, por la FK en cascada) se borra al terminar el test
+func newTestRepositories(t *testing.T, cupo uint) (*MySQLInscripcionesRepository, domain.Actividad) {
+	t.Helper()
+
+	host := os.Getenv("MYSQL_TEST_HOST")
+	if host == "" {
+		t.Skip("MYSQL_TEST_HOST no configurado: se saltea el test de integración con MySQL")
+	}
+
+	actividadesRepo := NewMySQLActividadesRepository(config.MySQLConfig{
+		User:   getTestEnv("MYSQL_TEST_USER", "root"),
+		Pass:   getTestEnv("MYSQL_TEST_PASS", ""),
+		Host:   host,
+		Port:   getTestEnv("MYSQL_TEST_PORT", "3306"),
+		Schema: getTestEnv("MYSQL_TEST_SCHEMA", "activities_test"),
+	})
+	actividadesRepo.db.Logger = logger.Default.LogMode(logger.Silent)
+	inscripcionesRepo := NewMySQLInscripcionesRepository(actividadesRepo.GetDB())
+	NewMySQLListaEsperaRepository(actividadesRepo.GetDB()) // Create consulta la lista de espera
+
+	ctx := context.Background()
+	horaInicio, _ := time.Parse("15:04", "10:00")
+	horaFin, _ := time.Parse("15:04", "11:00")
+	actividad, err := actividadesRepo.Create(ctx, domain.Actividad{
+		Titulo:     "Test concurrencia",
+		Cupo:       cupo,
+		Dia:        "Lunes",
+		FotoUrl:    "https://example.com/foto.jpg",
+		Instructor: "Test",
+		Categoria:  "test",
+	}, horaInicio, horaFin)
+	if err != nil {
+		t.Fatalf("error creando actividad de test: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := actividadesRepo.Delete(ctx, actividad.ID); err != nil {
+			t.Logf("error borrando actividad de test: %v", err)
+		}
+		if sqlDB, err := actividadesRepo.GetDB().DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return inscripcionesRepo, actividad
+}
+
+func getTestEnv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
+
+// enrollConcurrently lanza todas las inscripciones a la vez y devuelve cuántas se crearon y los errores
+func enrollConcurrently(repo *MySQLInscripcionesRepository, actividadID uint, usuarioIDs []uint) (int, []error) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+		errs    []error
+		start   = make(chan struct{})
+	)
+
+	for _, usuarioID := range usuarioIDs {
+		wg.Add(1)
+		go func(usuarioID uint) {
+			defer wg.Done()
+			<-start
+
+			_, err := repo.Create(context.Background(), domain.Inscripcion{
+				UsuarioID:   usuarioID,
+				ActividadID: actividadID,
+				IsActiva:    true,
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			created++
+		}(usuarioID)
+	}
+
+	close(start)
+	wg.Wait()
+
+	return created, errs
+}
+
+func TestCreateConcurrentEnrollmentsRespectCupo(t *testing.T) {
+	const (
+		cupo     = 10
+		intentos = 300
+	)
+	repo, actividad := newTestRepositories(t, cupo)
+
+	usuarioIDs := make([]uint, intentos)
+	for i := range usuarioIDs {
+		usuarioIDs[i] = uint(1_000_000 + i)
+	}
+
+	created, errs := enrollConcurrently(repo, actividad.ID, usuarioIDs)
+
+	if created != cupo {
+		t.Fatalf("se crearon %d inscripciones, se esperaban exactamente %d", created, cupo)
+	}
+	for _, err := range errs {
+		if !strings.Contains(err.Error(), "cupo de la actividad ha sido alcanzado") {
+			t.Errorf("error inesperado: %v", err)
+		}
+	}
+
+	var activas int64
+	if err := repo.db.Table("inscripciones").
+		Where("actividad_id = ? AND is_activa = ?", actividad.ID, true).
+		Count(&activas).Error; err != nil {
+		t.Fatalf("error contando inscripciones: %v", err)
+	}
+	if activas != cupo {
+		t.Fatalf("hay %d inscripciones activas, se esperaban %d", activas, cupo)
+	}
+}
+
+func TestCreateConcurrentEnrollmentsSameUser(t *testing.T) {
+	const intentos = 100
+	repo, actividad := newTestRepositories(t, intentos)
+
+	usuarioIDs := make([]uint, intentos)
+	for i := range usuarioIDs {
+		usuarioIDs[i] = 2_000_000
+	}
+
+	created, errs := enrollConcurrently(repo, actividad.ID, usuarioIDs)
+
+	if created != 1 {
+		t.Fatalf("se crearon %d inscripciones del mismo usuario, se esperaba 1", created)
+	}
+	for _, err := range errs {
+		if !strings.Contains(err.Error(), "ya está inscripto") {
+			t.Errorf("error inesperado: %v", err)
+		}
+	}
+}
+
+// TestCreateRejectedWhileWaitlistPending verifica que con socios esperando nadie se inscribe directamente
+// y que, una vez promovido el primero, vuelve a aplicar solo el cupo
+func TestCreateRejectedWhileWaitlistPending(t *testing.T) {
+	repo, actividad := newTestRepositories(t, 2)
+	listaEspera := NewMySQLListaEsperaRepository(repo.db)
+	ctx := context.Background()
+
+	if _, err := repo.Create(ctx, domain.Inscripcion{UsuarioID: 3_000_000, ActividadID: actividad.ID, IsActiva: true}); err != nil {
+		t.Fatalf("error inscribiendo: %v", err)
+	}
+	entry, err := listaEspera.Join(ctx, domain.ListaEspera{UsuarioID: 3_000_001, ActividadID: actividad.ID})
+	if err != nil {
+		t.Fatalf("error anotando en la lista de espera: %v", err)
+	}
+
+	_, err = repo.Create(ctx, domain.Inscripcion{UsuarioID: 3_000_002, ActividadID: actividad.ID, IsActiva: true})
+	if err == nil || !strings.Contains(err.Error(), "usuarios en lista de espera") {
+		t.Fatalf("Create() error = %v, se esperaba el rechazo por lista de espera", err)
+	}
+
+	promoted, hayLugar, err := listaEspera.PromoteNext(ctx, entry, time.Now().Add(time.Hour))
+	if err != nil || promoted == nil || !hayLugar {
+		t.Fatalf("PromoteNext() = (%v, %v, %v), se esperaba la promoción", promoted, hayLugar, err)
+	}
+
+	_, err = repo.Create(ctx, domain.Inscripcion{UsuarioID: 3_000_002, ActividadID: actividad.ID, IsActiva: true})
+	if err == nil || !strings.Contains(err.Error(), "cupo de la actividad ha sido alcanzado") {
+		t.Fatalf("Create() error = %v, se esperaba cupo alcanzado", err)
+	}
+}
+
+// TestDedupeInscripciones verifica que una base con filas repetidas (anterior al índice único)
+// conserva la activa o la más reciente y después recibe el índice
+func TestDedupeInscripciones(t *testing.T) {
+	repo, actividad := newTestRepositories(t, 10)
+	db := repo.db
+	if err := db.Migrator().DropIndex(&dao.Inscripcion{}, "idx_inscripciones_usuario_actividad"); err != nil {
+		t.Fatalf("error borrando el índice único: %v", err)
+	}
+
+	insert := func(usuarioID uint, activa bool, fecha time.Time) uint {
+		t.Helper()
+		if err := db.Exec("INSERT INTO inscripciones (usuario_id, actividad_id, fecha_inscripcion, is_activa, created_at, updated_at) VALUES (?, ?, ?, ?, NOW(), NOW())",
+			usuarioID, actividad.ID, fecha, activa).Error; err != nil {
+			t.Fatalf("error insertando inscripción: %v", err)
+		}
+		var id uint
+		db.Raw("SELECT LAST_INSERT_ID()").Scan(&id)
+		return id
+	}
+	now := time.Now().Truncate(time.Second)
+	// Usuario con una fila activa vieja y una inactiva más nueva: gana la activa
+	activa := insert(4_000_000, true, now.Add(-48*time.Hour))
+	insert(4_000_000, false, now.Add(-time.Hour))
+	// Usuario con dos filas inactivas: gana la más reciente
+	insert(4_000_001, false, now.Add(-48*time.Hour))
+	reciente := insert(4_000_001, false, now.Add(-time.Hour))
+
+	NewMySQLInscripcionesRepository(db)
+
+	if !db.Migrator().HasIndex(&dao.Inscripcion{}, "idx_inscripciones_usuario_actividad") {
+		t.Fatal("no se creó el índice único después de deduplicar")
+	}
+	for usuarioID, want := range map[uint]uint{4_000_000: activa, 4_000_001: reciente} {
+		var ids []uint
+		db.Model(&dao.Inscripcion{}).Where("usuario_id = ? AND actividad_id = ?", usuarioID, actividad.ID).Pluck("id", &ids)
+		if len(ids) != 1 || ids[0] != want {
+			t.Errorf("usuario %d conserva %v, se esperaba [%d]", usuarioID, ids, want)
+		}
+	}
+}
@@ -0,0 +1,446 @@
+package repository
+
+import (
+	"activities-api/internal/dao"
+	"activities-api/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListaEsperaRepository define la interfaz del repositorio de la lista de espera
+type ListaEsperaRepository interface {
+	Join(ctx context.Context, entry domain.ListaEspera) (domain.ListaEspera, error)
+	ListByUser(ctx context.Context, usuarioID uint) ([]domain.ListaEspera, error)
+	Leave(ctx context.Context, usuarioID, actividadID uint) (domain.ListaEspera, error)
+	DeclinePromotion(ctx context.Context, usuarioID, actividadID uint) error
+	NextWaiting(ctx context.Context, actividadID uint) (*domain.ListaEspera, error)
+	Remove(ctx context.Context, id uint) (bool, error)
+	PromoteNext(ctx context.Context, entry domain.ListaEspera, expiraAt time.Time) (promoted *domain.ListaEspera, hayLugar bool, err error)
+	Confirm(ctx context.Context, usuarioID, actividadID uint, now time.Time) (domain.ListaEspera, error)
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]domain.ListaEspera, error)
+	Expire(ctx context.Context, id uint) (bool, error)
+	DeleteByUser(ctx context.Context, usuarioID uint) (int64, error)
+}
+
+// MySQLListaEsperaRepository implementa ListaEsperaRepository usando MySQL/GORM
+type MySQLListaEsperaRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLListaEsperaRepository crea una nueva instancia del repository
+// Comparte la conexión DB con ActividadesRepository
+func NewMySQLListaEsperaRepository(db *gorm.DB) *MySQLListaEsperaRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.ListaEspera{}); err != nil {
+		fmt.Printf("Error auto-migrating ListaEspera table: %v\n", err)
+	}
+
+	return &MySQLListaEsperaRepository{
+		db: db,
+	}
+}
+
+// Join agrega al usuario al final de la lista de espera de la actividad
+func (r *MySQLListaEsperaRepository) Join(ctx context.Context, entry domain.ListaEspera) (domain.ListaEspera, error) {
+	entryDAO := dao.ListaEspera{
+		UsuarioID:     entry.UsuarioID,
+		ActividadID:   entry.ActividadID,
+		Estado:        domain.ListaEsperaEsperando,
+		SuscripcionID: entry.SuscripcionID,
+	}
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&dao.ListaEspera{}).
+			Where("usuario_id = ? AND actividad_id = ? AND estado IN ?", entry.UsuarioID, entry.ActividadID,
+				[]string{domain.ListaEsperaEsperando, domain.ListaEsperaPromovido}).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("el usuario ya está en la lista de espera de esta actividad")
+		}
+
+		if err := tx.Model(&dao.Inscripcion{}).
+			Where("usuario_id = ? AND actividad_id = ? AND is_activa = ?", entry.UsuarioID, entry.ActividadID, true).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("el usuario ya está inscripto en esta actividad")
+		}
+
+		return tx.Create(&entryDAO).Error
+	})
+	if err != nil {
+		return domain.ListaEspera{}, fmt.Errorf("error joining lista de espera: %w", err)
+	}
+
+	joined := entryDAO.ToDomain()
+	joined.Posicion, err = r.posicion(ctx, entryDAO)
+	if err != nil {
+		return domain.ListaEspera{}, err
+	}
+
+	return joined, nil
+}
+
+// ListByUser obtiene todas las entradas del usuario (las más recientes primero)
+// Las que están esperando incluyen su posición en la cola
+func (r *MySQLListaEsperaRepository) ListByUser(ctx context.Context, usuarioID uint) ([]domain.ListaEspera, error) {
+	var entriesDAO []dao.ListaEspera
+
+	err := conn(ctx, r.db).
+		Where("usuario_id = ?", usuarioID).
+		Order("id DESC").
+		Find(&entriesDAO).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing lista de espera: %w", err)
+	}
+
+	entries := make([]domain.ListaEspera, len(entriesDAO))
+	for i, entryDAO := range entriesDAO {
+		entries[i] = entryDAO.ToDomain()
+		if entryDAO.Estado == domain.ListaEsperaEsperando {
+			if entries[i].Posicion, err = r.posicion(ctx, entryDAO); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// Leave saca al usuario de la lista de espera
+// Si ya había sido promovido, rechaza el lugar: la inscripción se desactiva
+// Devuelve la entrada con el estado que tenía antes de salir
+func (r *MySQLListaEsperaRepository) Leave(ctx context.Context, usuarioID, actividadID uint) (domain.ListaEspera, error) {
+	var entryDAO dao.ListaEspera
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("usuario_id = ? AND actividad_id = ? AND estado IN ?", usuarioID, actividadID,
+				[]string{domain.ListaEsperaEsperando, domain.ListaEsperaPromovido}).
+			First(&entryDAO).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("lista de espera not found")
+			}
+			return err
+		}
+
+		if entryDAO.Estado == domain.ListaEsperaPromovido && entryDAO.InscripcionID != nil {
+			if err := deactivateInscripcion(tx, *entryDAO.InscripcionID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&dao.ListaEspera{}).
+			Where("id = ?", entryDAO.ID).
+			Update("estado", domain.ListaEsperaCancelado).Error
+	})
+	if err != nil {
+		if err.Error() == "lista de espera not found" {
+			return domain.ListaEspera{}, err
+		}
+		return domain.ListaEspera{}, fmt.Errorf("error leaving lista de espera: %w", err)
+	}
+
+	return entryDAO.ToDomain(), nil
+}
+
+// DeclinePromotion cancela la promoción pendiente del usuario (se desinscribió antes de confirmar)
+func (r *MySQLListaEsperaRepository) DeclinePromotion(ctx context.Context, usuarioID, actividadID uint) error {
+	err := conn(ctx, r.db).
+		Model(&dao.ListaEspera{}).
+		Where("usuario_id = ? AND actividad_id = ? AND estado = ?", usuarioID, actividadID, domain.ListaEsperaPromovido).
+		Update("estado", domain.ListaEsperaCancelado).Error
+	if err != nil {
+		return fmt.Errorf("error declining promotion: %w", err)
+	}
+
+	return nil
+}
+
+// NextWaiting devuelve el primero de la cola de la actividad (nil si no hay nadie esperando)
+func (r *MySQLListaEsperaRepository) NextWaiting(ctx context.Context, actividadID uint) (*domain.ListaEspera, error) {
+	var entryDAO dao.ListaEspera
+	err := conn(ctx, r.db).
+		Where("actividad_id = ? AND estado = ?", actividadID, domain.ListaEsperaEsperando).
+		Order("id").
+		First(&entryDAO).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting lista de espera: %w", err)
+	}
+
+	entry := entryDAO.ToDomain()
+	return &entry, nil
+}
+
+// Remove saca de la cola una entrada que sigue esperando (el socio ya no puede inscribirse)
+// Devuelve false si la entrada ya no estaba esperando
+func (r *MySQLListaEsperaRepository) Remove(ctx context.Context, id uint) (bool, error) {
+	result := conn(ctx, r.db).
+		Model(&dao.ListaEspera{}).
+		Where("id = ? AND estado = ?", id, domain.ListaEsperaEsperando).
+		Update("estado", domain.ListaEsperaCancelado)
+	if result.Error != nil {
+		return false, fmt.Errorf("error removing lista de espera: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// PromoteNext promueve la entrada (ya validada por el servicio) si la actividad tiene lugar y la entrada sigue
+// primera en la cola: le crea (o reactiva) la inscripción con entry.SuscripcionID y le da hasta expiraAt para
+// confirmarla. hayLugar es false si la actividad no tiene lugares; promoted es nil con hayLugar true si la cola
+// cambió mientras tanto (el servicio vuelve a leer el primero)
+func (r *MySQLListaEsperaRepository) PromoteNext(ctx context.Context, entry domain.ListaEspera, expiraAt time.Time) (*domain.ListaEspera, bool, error) {
+	var promoted *domain.ListaEspera
+	hayLugar := true
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Bloquear la actividad para que dos liberaciones simultáneas no promuevan al mismo lugar
+		var actividad dao.Actividad
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id_actividad").
+			First(&actividad, "id_actividad = ?", entry.ActividadID).Error; err != nil {
+			return err
+		}
+
+		var lugares int64
+		if err := tx.Table("actividades_lugares").
+			Select("lugares").
+			Where("id_actividad = ?", entry.ActividadID).
+			Scan(&lugares).Error; err != nil {
+			return err
+		}
+		if lugares <= 0 {
+			hayLugar = false
+			return nil
+		}
+
+		var entryDAO dao.ListaEspera
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("actividad_id = ? AND estado = ?", entry.ActividadID, domain.ListaEsperaEsperando).
+			Order("id").
+			First(&entryDAO).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && entryDAO.ID != entry.ID) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entryDAO.SuscripcionID = entry.SuscripcionID
+		inscripcionID, err := r.inscribir(tx, entryDAO)
+		if err != nil {
+			return err
+		}
+		if inscripcionID == 0 {
+			// Se inscribió por su cuenta mientras esperaba: sale de la lista y sigue el próximo
+			return tx.Model(&dao.ListaEspera{}).
+				Where("id = ?", entryDAO.ID).
+				Update("estado", domain.ListaEsperaCancelado).Error
+		}
+
+		now := time.Now()
+		entryDAO.Estado = domain.ListaEsperaPromovido
+		entryDAO.InscripcionID = &inscripcionID
+		entryDAO.PromovidoAt = &now
+		entryDAO.ExpiraAt = &expiraAt
+		if err := tx.Model(&dao.ListaEspera{}).
+			Where("id = ?", entryDAO.ID).
+			Updates(map[string]interface{}{
+				"estado":         entryDAO.Estado,
+				"suscripcion_id": entryDAO.SuscripcionID,
+				"inscripcion_id": inscripcionID,
+				"promovido_at":   now,
+				"expira_at":      expiraAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		promovido := entryDAO.ToDomain()
+		promoted = &promovido
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("error promoting lista de espera: %w", err)
+	}
+
+	return promoted, hayLugar, nil
+}
+
+// inscribir crea o reactiva la inscripción del usuario promovido (ejecuta los hooks de cupo)
+// Devuelve 0 si el usuario ya tenía una inscripción activa
+func (r *MySQLListaEsperaRepository) inscribir(tx *gorm.DB, entryDAO dao.ListaEspera) (uint, error) {
+	var existing dao.Inscripcion
+	err := tx.Where("usuario_id = ? AND actividad_id = ?", entryDAO.UsuarioID, entryDAO.ActividadID).
+		First(&existing).Error
+
+	if err == nil {
+		if existing.IsActiva {
+			return 0, nil
+		}
+
+		existing.IsActiva = true
+		if err := tx.Model(&existing).Updates(map[string]interface{}{
+			"is_activa":         true,
+			"suscripcion_id":    entryDAO.SuscripcionID,
+			"fecha_inscripcion": time.Now(),
+		}).Error; err != nil {
+			return 0, err
+		}
+		return existing.ID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	inscripcionDAO := dao.Inscripcion{
+		UsuarioID:        entryDAO.UsuarioID,
+		ActividadID:      entryDAO.ActividadID,
+		FechaInscripcion: time.Now(),
+		IsActiva:         true,
+		SuscripcionID:    entryDAO.SuscripcionID,
+	}
+	if err := tx.Create(&inscripcionDAO).Error; err != nil {
+		return 0, err
+	}
+
+	return inscripcionDAO.ID, nil
+}
+
+// Confirm confirma el lugar asignado al usuario si la ventana de confirmación sigue abierta
+func (r *MySQLListaEsperaRepository) Confirm(ctx context.Context, usuarioID, actividadID uint, now time.Time) (domain.ListaEspera, error) {
+	var entryDAO dao.ListaEspera
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("usuario_id = ? AND actividad_id = ? AND estado = ? AND expira_at > ?",
+				usuarioID, actividadID, domain.ListaEsperaPromovido, now).
+			First(&entryDAO).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("promocion not found")
+			}
+			return err
+		}
+
+		entryDAO.Estado = domain.ListaEsperaConfirmado
+		return tx.Model(&dao.ListaEspera{}).
+			Where("id = ?", entryDAO.ID).
+			Update("estado", domain.ListaEsperaConfirmado).Error
+	})
+	if err != nil {
+		if err.Error() == "promocion not found" {
+			return domain.ListaEspera{}, err
+		}
+		return domain.ListaEspera{}, fmt.Errorf("error confirming lista de espera: %w", err)
+	}
+
+	return entryDAO.ToDomain(), nil
+}
+
+// ListExpired obtiene las promociones cuya ventana de confirmación venció
+func (r *MySQLListaEsperaRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]domain.ListaEspera, error) {
+	var entriesDAO []dao.ListaEspera
+
+	err := conn(ctx, r.db).
+		Where("estado = ? AND expira_at <= ?", domain.ListaEsperaPromovido, now).
+		Order("expira_at").
+		Limit(limit).
+		Find(&entriesDAO).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing expired promotions: %w", err)
+	}
+
+	entries := make([]domain.ListaEspera, len(entriesDAO))
+	for i, entryDAO := range entriesDAO {
+		entries[i] = entryDAO.ToDomain()
+	}
+
+	return entries, nil
+}
+
+// Expire marca la promoción como vencida y desactiva la inscripción que se le había asignado
+// Devuelve false si la entrada ya no estaba pendiente (la confirmó o salió de la lista)
+func (r *MySQLListaEsperaRepository) Expire(ctx context.Context, id uint) (bool, error) {
+	expired := false
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var entryDAO dao.ListaEspera
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND estado = ?", id, domain.ListaEsperaPromovido).
+			First(&entryDAO).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if entryDAO.InscripcionID != nil {
+			if err := deactivateInscripcion(tx, *entryDAO.InscripcionID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&dao.ListaEspera{}).
+			Where("id = ?", entryDAO.ID).
+			Update("estado", domain.ListaEsperaVencido).Error; err != nil {
+			return err
+		}
+
+		expired = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("error expiring promotion: %w", err)
+	}
+
+	return expired, nil
+}
+
+// DeleteByUser borra físicamente todas las entradas de un usuario (derecho al olvido)
+func (r *MySQLListaEsperaRepository) DeleteByUser(ctx context.Context, usuarioID uint) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("usuario_id = ?", usuarioID).
+		Delete(&dao.ListaEspera{})
+
+	if result.Error != nil {
+		return 0, fmt.Errorf("error deleting lista de espera: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// posicion calcula la posición en la cola (1 = próximo) de una entrada que está esperando
+func (r *MySQLListaEsperaRepository) posicion(ctx context.Context, entryDAO dao.ListaEspera) (int, error) {
+	var ahead int64
+	err := conn(ctx, r.db).
+		Model(&dao.ListaEspera{}).
+		Where("actividad_id = ? AND estado = ? AND id <= ?", entryDAO.ActividadID, domain.ListaEsperaEsperando, entryDAO.ID).
+		Count(&ahead).Error
+	if err != nil {
+		return 0, fmt.Errorf("error getting posicion: %w", err)
+	}
+
+	return int(ahead), nil
+}
+
+// deactivateInscripcion libera el lugar de una inscripción asignada desde la lista de espera
+func deactivateInscripcion(tx *gorm.DB, inscripcionID uint) error {
+	return tx.Model(&dao.Inscripcion{}).
+		Where("id = ? AND is_activa = ?", inscripcionID, true).
+		Update("is_activa", false).Error
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"activities-api/internal/dao"
+	"activities-api/internal/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository define la interfaz del outbox de eventos
+type OutboxRepository interface {
+	// Enqueue guarda el evento; si el contexto trae una transacción (Transactor) se confirma junto con ella
+	Enqueue(ctx context.Context, eventType, action, id string, data map[string]interface{}) error
+	// PublishEvent encola fuera de toda transacción (implementa services.EventPublisher)
+	PublishEvent(eventType, action, id string, data map[string]interface{}) error
+	RelayPending(ctx context.Context, limit int, publish func(evento domain.OutboxEvento) error) (published, descartados int, err error)
+	PurgePublished(ctx context.Context, before time.Time) (int64, error)
+}
+
+// MySQLOutboxRepository implementa OutboxRepository usando MySQL/GORM
+type MySQLOutboxRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLOutboxRepository crea una nueva instancia del repository
+// Comparte la conexión DB con ActividadesRepository
+func NewMySQLOutboxRepository(db *gorm.DB) *MySQLOutboxRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.OutboxEvento{}); err != nil {
+		fmt.Printf("Error auto-migrating OutboxEvento table: %v\n", err)
+	}
+
+	return &MySQLOutboxRepository{
+		db: db,
+	}
+}
+
+// Enqueue guarda un evento pendiente de publicar
+func (r *MySQLOutboxRepository) Enqueue(ctx context.Context, eventType, action, id string, data map[string]interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error serializando evento: %w", err)
+	}
+
+	eventoDAO := dao.OutboxEvento{
+		EventType:   eventType,
+		Action:      action,
+		AggregateID: id,
+		Payload:     string(payload),
+	}
+	if err := conn(ctx, r.db).Create(&eventoDAO).Error; err != nil {
+		return fmt.Errorf("error enqueuing evento: %w", err)
+	}
+
+	return nil
+}
+
+// PublishEvent encola el evento sin transacción (para los servicios que publican después de confirmar el cambio)
+func (r *MySQLOutboxRepository) PublishEvent(eventType, action, id string, data map[string]interface{}) error {
+	return r.Enqueue(context.Background(), eventType, action, id, data)
+}
+
+// RelayPending publica hasta limit eventos pendientes (por orden de inserción) y los marca como publicados
+// Bloquea las filas con SKIP LOCKED para que dos instancias no publiquen el mismo evento; con varias instancias
+// cada una toma un lote distinto, así que el orden entre lotes no está garantizado (los consumidores no deben depender de él).
+// Un error de publicación se registra en el evento y corta el lote (si RabbitMQ está caído, los siguientes también fallarían)
+// sin descartarlo, por más que se repita. Solo se descartan (descartado_at) los eventos cuyo payload no se puede leer o que
+// el publisher rechaza con domain.ErrEventoInvalido, y el lote sigue, así un evento que nunca se puede publicar no bloquea
+// el outbox y una caída larga de RabbitMQ no pierde eventos. La entrega es at-least-once: si el commit falla
+// después de publicar, el evento se vuelve a publicar en la próxima pasada
+func (r *MySQLOutboxRepository) RelayPending(ctx context.Context, limit int, publish func(evento domain.OutboxEvento) error) (int, int, error) {
+	published, descartados := 0, 0
+	var publishErr error
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var eventosDAO []dao.OutboxEvento
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("publicado_at IS NULL AND descartado_at IS NULL").
+			Order("id").
+			Limit(limit).
+			Find(&eventosDAO).Error; err != nil {
+			return err
+		}
+
+		for _, eventoDAO := range eventosDAO {
+			evento, err := eventoDAO.ToDomain()
+			descartar := err != nil
+			if err == nil {
+				err = publish(evento)
+				descartar = errors.Is(err, domain.ErrEventoInvalido)
+			}
+			if err != nil {
+				updates := map[string]interface{}{
+					"intentos":     gorm.Expr("intentos + 1"),
+					"ultimo_error": err.Error(),
+				}
+				if descartar {
+					updates["descartado_at"] = time.Now()
+				}
+				if err := tx.Model(&dao.OutboxEvento{}).Where("id = ?", eventoDAO.ID).Updates(updates).Error; err != nil {
+					return err
+				}
+
+				if descartar {
+					descartados++
+					continue
+				}
+				publishErr = fmt.Errorf("error publicando evento %d (%s.%s): %w", eventoDAO.ID, eventoDAO.EventType, eventoDAO.Action, err)
+				return nil
+			}
+
+			if err := tx.Model(&dao.OutboxEvento{}).
+				Where("id = ?", eventoDAO.ID).
+				Update("publicado_at", time.Now()).Error; err != nil {
+				return err
+			}
+			published++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("error relaying outbox: %w", err)
+	}
+
+	return published, descartados, publishErr
+}
+
+// PurgePublished borra los eventos publicados antes de before
+func (r *MySQLOutboxRepository) PurgePublished(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("publicado_at IS NOT NULL AND publicado_at < ?", before).
+		Delete(&dao.OutboxEvento{})
+
+	if result.Error != nil {
+		return 0, fmt.Errorf("error purging outbox: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
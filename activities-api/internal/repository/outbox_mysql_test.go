@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"activities-api/internal/dao"
+	"activities-api/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestTransactionOutboxAtomic verifica que el evento del outbox se confirma o se descarta junto con la inscripción
+func TestTransactionOutboxAtomic(t *testing.T) {
+	repo, actividad := newTestRepositories(t, 5)
+	transactor := NewMySQLTransactor(repo.db)
+	outbox := NewMySQLOutboxRepository(repo.db)
+
+	aggregateID := fmt.Sprintf("test-outbox-%d", actividad.ID)
+	t.Cleanup(func() {
+		repo.db.Where("aggregate_id = ?", aggregateID).Delete(&dao.OutboxEvento{})
+	})
+
+	enroll := func(usuarioID uint, fail bool) error {
+		return transactor.Transaction(context.Background(), func(ctx context.Context) error {
+			if _, err := repo.Create(ctx, domain.Inscripcion{UsuarioID: usuarioID, ActividadID: actividad.ID, IsActiva: true}); err != nil {
+				return err
+			}
+			if err := outbox.Enqueue(ctx, domain.InscripcionEventType, domain.InscripcionEventCreated, aggregateID,
+				map[string]interface{}{"usuario_id": usuarioID}); err != nil {
+				return err
+			}
+			if fail {
+				return errors.New("rollback de prueba")
+			}
+			return nil
+		})
+	}
+
+	countEventos := func() int64 {
+		var count int64
+		if err := repo.db.Model(&dao.OutboxEvento{}).Where("aggregate_id = ?", aggregateID).Count(&count).Error; err != nil {
+			t.Fatalf("error contando eventos: %v", err)
+		}
+		return count
+	}
+
+	if err := enroll(3_000_000, true); err == nil {
+		t.Fatal("se esperaba el error del rollback")
+	}
+	if _, err := repo.GetByUserAndActividad(context.Background(), 3_000_000, actividad.ID); err == nil {
+		t.Fatal("la inscripción no debería existir después del rollback")
+	}
+	if n := countEventos(); n != 0 {
+		t.Fatalf("quedaron %d eventos después del rollback, se esperaba 0", n)
+	}
+
+	if err := enroll(3_000_000, false); err != nil {
+		t.Fatalf("error inscribiendo: %v", err)
+	}
+	if n := countEventos(); n != 1 {
+		t.Fatalf("hay %d eventos después del commit, se esperaba 1", n)
+	}
+}
+
+// TestRelayPendingTransientError verifica que un error transitorio de RabbitMQ nunca descarta el evento,
+// por más pasadas que falle, y que se publica cuando RabbitMQ vuelve
+func TestRelayPendingTransientError(t *testing.T) {
+	repo, actividad := newTestRepositories(t, 1)
+	outbox := NewMySQLOutboxRepository(repo.db)
+
+	ctx := context.Background()
+	aggregateID := fmt.Sprintf("test-transient-%d", actividad.ID)
+	t.Cleanup(func() {
+		repo.db.Where("aggregate_id = ?", aggregateID).Delete(&dao.OutboxEvento{})
+	})
+
+	if err := outbox.Enqueue(ctx, domain.ActividadEventType, domain.ActividadEventUpdated, aggregateID, map[string]interface{}{}); err != nil {
+		t.Fatalf("error encolando evento: %v", err)
+	}
+
+	caido := true
+	publicado := false
+	publish := func(evento domain.OutboxEvento) error {
+		if evento.AggregateID != aggregateID {
+			return nil
+		}
+		if caido {
+			return errors.New("connection refused")
+		}
+		publicado = true
+		return nil
+	}
+
+	const pasadas = 50
+	for pasada := 1; pasada <= pasadas; pasada++ {
+		if _, descartados, err := outbox.RelayPending(ctx, 1000, publish); err == nil || descartados != 0 {
+			t.Fatalf("pasada %d: err = %v, descartados = %d; se esperaba el error sin descartar", pasada, err, descartados)
+		}
+	}
+
+	var evento dao.OutboxEvento
+	if err := repo.db.Where("aggregate_id = ?", aggregateID).First(&evento).Error; err != nil {
+		t.Fatalf("error leyendo el evento: %v", err)
+	}
+	if evento.DescartadoAt != nil || evento.Intentos != pasadas {
+		t.Fatalf("evento descartado_at = %v, intentos = %d; se esperaba pendiente con %d intentos", evento.DescartadoAt, evento.Intentos, pasadas)
+	}
+
+	caido = false
+	if _, _, err := outbox.RelayPending(ctx, 1000, publish); err != nil {
+		t.Fatalf("error publicando con RabbitMQ disponible: %v", err)
+	}
+	if !publicado {
+		t.Fatal("el evento debería publicarse cuando RabbitMQ vuelve")
+	}
+}
+
+// TestRelayPendingDeadLetter verifica que un evento inválido se descarta en la primera pasada
+// y deja de bloquear a los que vienen después
+func TestRelayPendingDeadLetter(t *testing.T) {
+	repo, actividad := newTestRepositories(t, 1)
+	outbox := NewMySQLOutboxRepository(repo.db)
+
+	ctx := context.Background()
+	poisonID := fmt.Sprintf("test-poison-%d", actividad.ID)
+	nextID := fmt.Sprintf("test-next-%d", actividad.ID)
+	t.Cleanup(func() {
+		repo.db.Where("aggregate_id IN ?", []string{poisonID, nextID}).Delete(&dao.OutboxEvento{})
+	})
+
+	for _, id := range []string{poisonID, nextID} {
+		if err := outbox.Enqueue(ctx, domain.ActividadEventType, domain.ActividadEventUpdated, id, map[string]interface{}{}); err != nil {
+			t.Fatalf("error encolando evento: %v", err)
+		}
+	}
+
+	publicados := map[string]bool{}
+	publish := func(evento domain.OutboxEvento) error {
+		if evento.AggregateID == poisonID {
+			return fmt.Errorf("%w: payload no serializable", domain.ErrEventoInvalido)
+		}
+		publicados[evento.AggregateID] = true
+		return nil
+	}
+
+	if _, _, err := outbox.RelayPending(ctx, 1000, publish); err != nil {
+		t.Fatalf("un evento inválido no debería cortar el lote: %v", err)
+	}
+	if !publicados[nextID] {
+		t.Fatal("el evento siguiente debería publicarse después de descartar el inválido")
+	}
+
+	var poison dao.OutboxEvento
+	if err := repo.db.Where("aggregate_id = ?", poisonID).First(&poison).Error; err != nil {
+		t.Fatalf("error leyendo el evento descartado: %v", err)
+	}
+	if poison.DescartadoAt == nil || poison.Intentos != 1 {
+		t.Fatalf("evento descartado_at = %v, intentos = %d; se esperaba descartado con 1 intento", poison.DescartadoAt, poison.Intentos)
+	}
+
+	if _, _, err := outbox.RelayPending(ctx, 1000, publish); err != nil {
+		t.Fatalf("el evento descartado no debería volver a intentarse: %v", err)
+	}
+}
@@ -0,0 +1,486 @@
+package repository
+
+import (
+	"activities-api/internal/dao"
+	"activities-api/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SesionesRepository define la interfaz del repositorio de sesiones, sus reglas de recurrencia e inscripciones
+type SesionesRepository interface {
+	// Reglas de recurrencia
+	ListReglasByActividad(ctx context.Context, actividadID uint) ([]domain.ReglaRecurrencia, error)
+	ListReglasVigentes(ctx context.Context, fecha string) ([]domain.ReglaRecurrencia, error)
+	GetRegla(ctx context.Context, id uint) (domain.ReglaRecurrencia, error)
+	CreateRegla(ctx context.Context, regla domain.ReglaRecurrencia) (domain.ReglaRecurrencia, error)
+	DeleteRegla(ctx context.Context, id uint, desde time.Time, motivo string) (int64, error)
+	SaveGeneradas(ctx context.Context, reglaID uint, sesiones []domain.Sesion, generadaHasta string) (int64, error)
+
+	// Sesiones
+	List(ctx context.Context, filtro domain.SesionFiltro, desde, hasta time.Time) ([]domain.Sesion, error)
+	GetByID(ctx context.Context, id uint) (domain.Sesion, error)
+	Create(ctx context.Context, sesion domain.Sesion) (domain.Sesion, error)
+	Update(ctx context.Context, id uint, inicio, fin time.Time, cupo uint) (domain.Sesion, error)
+	Cancel(ctx context.Context, id uint, motivo string) (domain.Sesion, error)
+
+	// Inscripciones a sesiones
+	Enroll(ctx context.Context, inscripcion domain.InscripcionSesion, now time.Time) (domain.InscripcionSesion, error)
+	Unenroll(ctx context.Context, usuarioID, sesionID uint) error
+	ListInscripcionesByUser(ctx context.Context, usuarioID uint, desde *time.Time) ([]domain.InscripcionSesion, error)
+	DeleteInscripcionesByUser(ctx context.Context, usuarioID uint) (int64, error)
+}
+
+// MySQLSesionesRepository implementa SesionesRepository usando MySQL/GORM
+type MySQLSesionesRepository struct {
+	db  *gorm.DB
+	loc *time.Location // Zona horaria del gimnasio (para la fecha de cada sesión)
+}
+
+// NewMySQLSesionesRepository crea una nueva instancia del repository
+// Comparte la conexión DB con ActividadesRepository
+func NewMySQLSesionesRepository(db *gorm.DB, loc *time.Location) *MySQLSesionesRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.ReglaRecurrencia{}, &dao.Sesion{}, &dao.InscripcionSesion{}); err != nil {
+		fmt.Printf("Error auto-migrating Sesion tables: %v\n", err)
+	}
+
+	return &MySQLSesionesRepository{
+		db:  db,
+		loc: loc,
+	}
+}
+
+// ListReglasByActividad obtiene las reglas de recurrencia de una actividad
+func (r *MySQLSesionesRepository) ListReglasByActividad(ctx context.Context, actividadID uint) ([]domain.ReglaRecurrencia, error) {
+	var reglasDAO []dao.ReglaRecurrencia
+
+	err := r.db.WithContext(ctx).
+		Where("actividad_id = ?", actividadID).
+		Order("id").
+		Find(&reglasDAO).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing reglas: %w", err)
+	}
+
+	reglas := make([]domain.ReglaRecurrencia, len(reglasDAO))
+	for i, reglaDAO := range reglasDAO {
+		reglas[i] = reglaDAO.ToDomain()
+	}
+
+	return reglas, nil
+}
+
+// ListReglasVigentes obtiene las reglas que siguen generando sesiones a partir de la fecha (YYYY-MM-DD)
+func (r *MySQLSesionesRepository) ListReglasVigentes(ctx context.Context, fecha string) ([]domain.ReglaRecurrencia, error) {
+	var reglasDAO []dao.ReglaRecurrencia
+
+	err := r.db.WithContext(ctx).
+		Where("fecha_hasta IS NULL OR fecha_hasta >= ?", fecha).
+		Order("id").
+		Find(&reglasDAO).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing reglas vigentes: %w", err)
+	}
+
+	reglas := make([]domain.ReglaRecurrencia, len(reglasDAO))
+	for i, reglaDAO := range reglasDAO {
+		reglas[i] = reglaDAO.ToDomain()
+	}
+
+	return reglas, nil
+}
+
+// GetRegla obtiene una regla de recurrencia por ID
+func (r *MySQLSesionesRepository) GetRegla(ctx context.Context, id uint) (domain.ReglaRecurrencia, error) {
+	var reglaDAO dao.ReglaRecurrencia
+
+	if err := r.db.WithContext(ctx).First(&reglaDAO, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ReglaRecurrencia{}, errors.New("regla not found")
+		}
+		return domain.ReglaRecurrencia{}, fmt.Errorf("error getting regla: %w", err)
+	}
+
+	return reglaDAO.ToDomain(), nil
+}
+
+// CreateRegla crea una regla de recurrencia (las sesiones las genera el service)
+func (r *MySQLSesionesRepository) CreateRegla(ctx context.Context, regla domain.ReglaRecurrencia) (domain.ReglaRecurrencia, error) {
+	reglaDAO := dao.ReglaRecurrenciaFromDomain(regla)
+
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Create(&reglaDAO).Error; err != nil {
+		return domain.ReglaRecurrencia{}, fmt.Errorf("error creating regla: %w", err)
+	}
+
+	return reglaDAO.ToDomain(), nil
+}
+
+// DeleteRegla borra una regla y cancela sus sesiones programadas desde la fecha indicada
+// Las sesiones pasadas quedan como historial (regla_id pasa a NULL por la FK)
+// Devuelve cuántas sesiones se cancelaron
+func (r *MySQLSesionesRepository) DeleteRegla(ctx context.Context, id uint, desde time.Time, motivo string) (int64, error) {
+	var canceladas int64
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&dao.Sesion{}).
+			Where("regla_id = ? AND estado = ? AND inicio >= ?", id, domain.SesionProgramada, desde).
+			Updates(map[string]interface{}{
+				"estado":             domain.SesionCancelada,
+				"motivo_cancelacion": motivo,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		canceladas = result.RowsAffected
+
+		result = tx.Delete(&dao.ReglaRecurrencia{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("regla not found")
+		}
+
+		return nil
+	})
+	if err != nil {
+		if err.Error() == "regla not found" {
+			return 0, err
+		}
+		return 0, fmt.Errorf("error deleting regla: %w", err)
+	}
+
+	return canceladas, nil
+}
+
+// SaveGeneradas guarda las sesiones generadas por una regla y avanza su fecha de generación
+// Las que ya existían (misma regla y fecha original) se ignoran; devuelve cuántas se crearon
+func (r *MySQLSesionesRepository) SaveGeneradas(ctx context.Context, reglaID uint, sesiones []domain.Sesion, generadaHasta string) (int64, error) {
+	var creadas int64
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if len(sesiones) > 0 {
+			sesionesDAO := make([]dao.Sesion, len(sesiones))
+			for i, sesion := range sesiones {
+				sesionesDAO[i] = dao.SesionFromDomain(sesion)
+			}
+
+			result := tx.Omit(clause.Associations).
+				Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&sesionesDAO)
+			if result.Error != nil {
+				return result.Error
+			}
+			creadas = result.RowsAffected
+		}
+
+		return tx.Model(&dao.ReglaRecurrencia{}).
+			Where("id = ?", reglaID).
+			Update("generada_hasta", generadaHasta).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error saving sesiones: %w", err)
+	}
+
+	return creadas, nil
+}
+
+// List obtiene las sesiones que empiezan en [desde, hasta) ordenadas por inicio
+func (r *MySQLSesionesRepository) List(ctx context.Context, filtro domain.SesionFiltro, desde, hasta time.Time) ([]domain.Sesion, error) {
+	query := detalle(r.db.WithContext(ctx)).
+		Where("sesiones.inicio >= ? AND sesiones.inicio < ?", desde, hasta)
+
+	if filtro.ActividadID != nil {
+		query = query.Where("sesiones.actividad_id = ?", *filtro.ActividadID)
+	}
+	if filtro.SucursalID != nil {
+		query = query.Where("actividades.sucursal_id = ?", *filtro.SucursalID)
+	}
+	if filtro.Estado != "" {
+		query = query.Where("sesiones.estado = ?", filtro.Estado)
+	}
+
+	var sesionesDAO []dao.SesionDetalle
+	if err := query.Order("sesiones.inicio, sesiones.id").Scan(&sesionesDAO).Error; err != nil {
+		return nil, fmt.Errorf("error listing sesiones: %w", err)
+	}
+
+	sesiones := make([]domain.Sesion, len(sesionesDAO))
+	for i, sesionDAO := range sesionesDAO {
+		sesiones[i] = sesionDAO.ToDomain(r.loc)
+	}
+
+	return sesiones, nil
+}
+
+// GetByID obtiene una sesión por ID con su cantidad de inscriptos
+func (r *MySQLSesionesRepository) GetByID(ctx context.Context, id uint) (domain.Sesion, error) {
+	return r.getByID(r.db.WithContext(ctx), id)
+}
+
+// Create crea una sesión suelta
+func (r *MySQLSesionesRepository) Create(ctx context.Context, sesion domain.Sesion) (domain.Sesion, error) {
+	sesionDAO := dao.SesionFromDomain(sesion)
+
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Create(&sesionDAO).Error; err != nil {
+		return domain.Sesion{}, fmt.Errorf("error creating sesion: %w", err)
+	}
+
+	return r.GetByID(ctx, sesionDAO.ID)
+}
+
+// Update reprograma una sesión y/o cambia su cupo
+// El cupo no puede quedar por debajo de los inscriptos (se bloquea la sesión igual que al inscribir)
+func (r *MySQLSesionesRepository) Update(ctx context.Context, id uint, inicio, fin time.Time, cupo uint) (domain.Sesion, error) {
+	var updated domain.Sesion
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		sesionDAO, err := lockSesion(tx, id)
+		if err != nil {
+			return err
+		}
+
+		inscriptos, err := countInscriptos(tx, id)
+		if err != nil {
+			return err
+		}
+		if int64(cupo) < inscriptos {
+			return fmt.Errorf("no se puede cambiar el cupo, la sesión tiene %d inscriptos", inscriptos)
+		}
+
+		updates := map[string]interface{}{
+			"inicio": inicio,
+			"fin":    fin,
+			"cupo":   cupo,
+		}
+		if !inicio.Equal(sesionDAO.Inicio) || !fin.Equal(sesionDAO.Fin) {
+			updates["reprogramada"] = true
+		}
+		if err := tx.Model(&dao.Sesion{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		updated, err = r.getByID(tx, id)
+		return err
+	})
+	if err != nil {
+		return domain.Sesion{}, wrapSesionError("error updating sesion", err)
+	}
+
+	return updated, nil
+}
+
+// Cancel cancela una sesión programada (las inscripciones quedan para avisar a los socios)
+func (r *MySQLSesionesRepository) Cancel(ctx context.Context, id uint, motivo string) (domain.Sesion, error) {
+	var cancelled domain.Sesion
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		sesionDAO, err := lockSesion(tx, id)
+		if err != nil {
+			return err
+		}
+		if sesionDAO.Estado == domain.SesionCancelada {
+			return errors.New("la sesión ya está cancelada")
+		}
+
+		if err := tx.Model(&dao.Sesion{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"estado":             domain.SesionCancelada,
+			"motivo_cancelacion": motivo,
+		}).Error; err != nil {
+			return err
+		}
+
+		cancelled, err = r.getByID(tx, id)
+		return err
+	})
+	if err != nil {
+		return domain.Sesion{}, wrapSesionError("error cancelling sesion", err)
+	}
+
+	return cancelled, nil
+}
+
+// Enroll inscribe al usuario en la sesión (o reactiva su inscripción)
+// Bloquea la fila de la sesión para que dos inscripciones simultáneas no superen el cupo
+func (r *MySQLSesionesRepository) Enroll(ctx context.Context, inscripcion domain.InscripcionSesion, now time.Time) (domain.InscripcionSesion, error) {
+	var enrolled dao.InscripcionSesion
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		sesionDAO, err := lockSesion(tx, inscripcion.SesionID)
+		if err != nil {
+			return err
+		}
+		if sesionDAO.Estado == domain.SesionCancelada {
+			return errors.New("la sesión está cancelada")
+		}
+		if !sesionDAO.Inicio.After(now) {
+			return errors.New("la sesión ya comenzó")
+		}
+
+		var existing dao.InscripcionSesion
+		err = tx.Where("sesion_id = ? AND usuario_id = ?", inscripcion.SesionID, inscripcion.UsuarioID).
+			First(&existing).Error
+		if err == nil && existing.IsActiva {
+			return errors.New("el usuario ya está inscripto en esta sesión")
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		inscriptos, err := countInscriptos(tx, inscripcion.SesionID)
+		if err != nil {
+			return err
+		}
+		if inscriptos >= int64(sesionDAO.Cupo) {
+			return errors.New("no se puede inscribir, el cupo de la sesión ha sido alcanzado")
+		}
+
+		if existing.ID != 0 {
+			// Reactivar inscripción con la suscripción vigente
+			existing.IsActiva = true
+			existing.SuscripcionID = inscripcion.SuscripcionID
+			existing.FechaInscripcion = now
+			if err := tx.Model(&dao.InscripcionSesion{}).Where("id = ?", existing.ID).Updates(map[string]interface{}{
+				"is_activa":         true,
+				"suscripcion_id":    inscripcion.SuscripcionID,
+				"fecha_inscripcion": now,
+			}).Error; err != nil {
+				return err
+			}
+			enrolled = existing
+			return nil
+		}
+
+		enrolled = dao.InscripcionSesion{
+			SesionID:         inscripcion.SesionID,
+			UsuarioID:        inscripcion.UsuarioID,
+			FechaInscripcion: now,
+			IsActiva:         true,
+			SuscripcionID:    inscripcion.SuscripcionID,
+		}
+		return tx.Omit(clause.Associations).Create(&enrolled).Error
+	})
+	if err != nil {
+		return domain.InscripcionSesion{}, wrapSesionError("error enrolling in sesion", err)
+	}
+
+	return enrolled.ToDomain(), nil
+}
+
+// Unenroll desactiva la inscripción del usuario a la sesión
+func (r *MySQLSesionesRepository) Unenroll(ctx context.Context, usuarioID, sesionID uint) error {
+	result := r.db.WithContext(ctx).
+		Model(&dao.InscripcionSesion{}).
+		Where("usuario_id = ? AND sesion_id = ? AND is_activa = ?", usuarioID, sesionID, true).
+		Update("is_activa", false)
+
+	if result.Error != nil {
+		return fmt.Errorf("error unenrolling from sesion: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("inscripcion not found")
+	}
+
+	return nil
+}
+
+// ListInscripcionesByUser obtiene las inscripciones del usuario a sesiones (con la sesión), opcionalmente desde una fecha
+func (r *MySQLSesionesRepository) ListInscripcionesByUser(ctx context.Context, usuarioID uint, desde *time.Time) ([]domain.InscripcionSesion, error) {
+	query := r.db.WithContext(ctx).
+		Joins("JOIN sesiones ON sesiones.id = inscripciones_sesion.sesion_id").
+		Where("inscripciones_sesion.usuario_id = ?", usuarioID)
+	if desde != nil {
+		query = query.Where("sesiones.inicio >= ?", *desde)
+	}
+
+	var inscripcionesDAO []dao.InscripcionSesion
+	if err := query.Order("sesiones.inicio").Find(&inscripcionesDAO).Error; err != nil {
+		return nil, fmt.Errorf("error listing inscripciones a sesiones: %w", err)
+	}
+
+	inscripciones := make([]domain.InscripcionSesion, len(inscripcionesDAO))
+	for i, inscDAO := range inscripcionesDAO {
+		inscripciones[i] = inscDAO.ToDomain()
+		if sesion, err := r.GetByID(ctx, inscDAO.SesionID); err == nil {
+			inscripciones[i].Sesion = &sesion
+		}
+	}
+
+	return inscripciones, nil
+}
+
+// DeleteInscripcionesByUser borra físicamente las inscripciones a sesiones de un usuario (derecho al olvido)
+func (r *MySQLSesionesRepository) DeleteInscripcionesByUser(ctx context.Context, usuarioID uint) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("usuario_id = ?", usuarioID).
+		Delete(&dao.InscripcionSesion{})
+
+	if result.Error != nil {
+		return 0, fmt.Errorf("error deleting inscripciones a sesiones: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// getByID obtiene una sesión con detalle usando la conexión (o transacción) indicada
+func (r *MySQLSesionesRepository) getByID(db *gorm.DB, id uint) (domain.Sesion, error) {
+	var sesionDAO dao.SesionDetalle
+
+	err := detalle(db).Where("sesiones.id = ?", id).Take(&sesionDAO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Sesion{}, errors.New("sesion not found")
+		}
+		return domain.Sesion{}, fmt.Errorf("error getting sesion: %w", err)
+	}
+
+	return sesionDAO.ToDomain(r.loc), nil
+}
+
+// detalle arma la consulta de sesiones con el título y la sucursal de la actividad y los inscriptos activos
+func detalle(db *gorm.DB) *gorm.DB {
+	return db.Table("sesiones").
+		Select(`sesiones.*, actividades.titulo AS actividad_titulo, actividades.sucursal_id,
+			(SELECT COUNT(*) FROM inscripciones_sesion i WHERE i.sesion_id = sesiones.id AND i.is_activa = true) AS inscriptos`).
+		Joins("JOIN actividades ON actividades.id_actividad = sesiones.actividad_id")
+}
+
+// lockSesion bloquea la fila de la sesión hasta el fin de la transacción
+func lockSesion(tx *gorm.DB, id uint) (dao.Sesion, error) {
+	var sesionDAO dao.Sesion
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&sesionDAO, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dao.Sesion{}, errors.New("sesion not found")
+	}
+
+	return sesionDAO, err
+}
+
+// countInscriptos cuenta las inscripciones activas de una sesión
+func countInscriptos(tx *gorm.DB, sesionID uint) (int64, error) {
+	var inscriptos int64
+
+	err := tx.Model(&dao.InscripcionSesion{}).
+		Where("sesion_id = ? AND is_activa = ?", sesionID, true).
+		Count(&inscriptos).Error
+
+	return inscriptos, err
+}
+
+// wrapSesionError deja pasar los errores de negocio tal cual y envuelve los de base de datos
+func wrapSesionError(msg string, err error) error {
+	errString := err.Error()
+	if strings.Contains(errString, "not found") || strings.HasPrefix(errString, "la sesión") ||
+		strings.HasPrefix(errString, "el usuario") || strings.HasPrefix(errString, "no se puede") {
+		return err
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"activities-api/internal/dao"
+	"activities-api/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// SucursalesRepository define la interfaz del repositorio de sucursales
+type SucursalesRepository interface {
+	List(ctx context.Context) ([]domain.Sucursal, error)
+	GetByID(ctx context.Context, id uint) (domain.Sucursal, error)
+	Create(ctx context.Context, sucursal domain.Sucursal) (domain.Sucursal, error)
+	Update(ctx context.Context, id uint, sucursal domain.Sucursal) (domain.Sucursal, error)
+	Delete(ctx context.Context, id uint) error
+	MaxCupoActividades(ctx context.Context, id uint) (uint, error)
+}
+
+// MySQLSucursalesRepository implementa SucursalesRepository usando MySQL/GORM
+type MySQLSucursalesRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLSucursalesRepository crea una nueva instancia del repository
+// Comparte la conexión DB con ActividadesRepository (que ya migra la tabla sucursales)
+func NewMySQLSucursalesRepository(db *gorm.DB) *MySQLSucursalesRepository {
+	// FK actividades.sucursal_id -> sucursales.id
+	// AutoMigrate no la crea porque la relación está declarada del lado de Sucursal
+	if !db.Migrator().HasConstraint(&dao.Sucursal{}, "Actividades") {
+		if err := db.Migrator().CreateConstraint(&dao.Sucursal{}, "Actividades"); err != nil {
+			log.Printf("⚠️  Warning: No se pudo crear la FK actividades.sucursal_id (¿actividades con sucursales inexistentes?): %v", err)
+		}
+	}
+
+	return &MySQLSucursalesRepository{
+		db: db,
+	}
+}
+
+// List obtiene todas las sucursales (sin las dadas de baja)
+func (r *MySQLSucursalesRepository) List(ctx context.Context) ([]domain.Sucursal, error) {
+	var sucursalesDAO []dao.Sucursal
+
+	if err := r.db.WithContext(ctx).Order("nombre").Find(&sucursalesDAO).Error; err != nil {
+		return nil, fmt.Errorf("error listing sucursales: %w", err)
+	}
+
+	sucursales := make([]domain.Sucursal, len(sucursalesDAO))
+	for i, sucDAO := range sucursalesDAO {
+		sucursales[i] = sucDAO.ToDomain()
+	}
+
+	return sucursales, nil
+}
+
+// GetByID obtiene una sucursal por ID
+func (r *MySQLSucursalesRepository) GetByID(ctx context.Context, id uint) (domain.Sucursal, error) {
+	var sucursalDAO dao.Sucursal
+
+	err := r.db.WithContext(ctx).First(&sucursalDAO, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Sucursal{}, errors.New("sucursal not found")
+		}
+		return domain.Sucursal{}, fmt.Errorf("error getting sucursal by ID: %w", err)
+	}
+
+	return sucursalDAO.ToDomain(), nil
+}
+
+// Create inserta una nueva sucursal
+func (r *MySQLSucursalesRepository) Create(ctx context.Context, sucursal domain.Sucursal) (domain.Sucursal, error) {
+	sucursalDAO := dao.SucursalFromDomain(sucursal)
+
+	if err := r.db.WithContext(ctx).Create(&sucursalDAO).Error; err != nil {
+		return domain.Sucursal{}, fmt.Errorf("error creating sucursal: %w", err)
+	}
+
+	return sucursalDAO.ToDomain(), nil
+}
+
+// Update reemplaza los datos de una sucursal existente
+func (r *MySQLSucursalesRepository) Update(ctx context.Context, id uint, sucursal domain.Sucursal) (domain.Sucursal, error) {
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return domain.Sucursal{}, err
+	}
+
+	sucursalDAO := dao.SucursalFromDomain(sucursal)
+	sucursalDAO.ID = id
+
+	// Select explícito para poder limpiar las coordenadas (Updates ignora los campos nil)
+	err := r.db.WithContext(ctx).
+		Model(&dao.Sucursal{ID: id}).
+		Select("nombre", "direccion", "telefono", "horarios", "capacidad", "latitud", "longitud", "updated_at").
+		Updates(&sucursalDAO).Error
+	if err != nil {
+		return domain.Sucursal{}, fmt.Errorf("error updating sucursal: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Delete da de baja una sucursal (soft delete)
+// No se permite si todavía tiene actividades asignadas
+func (r *MySQLSucursalesRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var actividades int64
+		if err := tx.Model(&dao.Actividad{}).Where("sucursal_id = ?", id).Count(&actividades).Error; err != nil {
+			return fmt.Errorf("error counting actividades of sucursal: %w", err)
+		}
+		if actividades > 0 {
+			return fmt.Errorf("la sucursal tiene %d actividades asignadas", actividades)
+		}
+
+		result := tx.Delete(&dao.Sucursal{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("error deleting sucursal: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("sucursal not found")
+		}
+
+		return nil
+	})
+}
+
+// MaxCupoActividades devuelve el cupo más alto entre las actividades de la sucursal (0 si no tiene)
+func (r *MySQLSucursalesRepository) MaxCupoActividades(ctx context.Context, id uint) (uint, error) {
+	var maxCupo uint
+	err := r.db.WithContext(ctx).
+		Model(&dao.Actividad{}).
+		Where("sucursal_id = ?", id).
+		Select("COALESCE(MAX(cupo), 0)").
+		Scan(&maxCupo).Error
+	if err != nil {
+		return 0, fmt.Errorf("error getting max cupo of sucursal: %w", err)
+	}
+
+	return maxCupo, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey es la clave del contexto donde viaja la transacción abierta por Transactor
+type txKey struct{}
+
+// Transactor corre operaciones de varios repositorios en una misma transacción
+// fn debe usar el contexto que recibe: los repositorios toman de ahí la transacción (ver conn)
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// MySQLTransactor implementa Transactor con GORM
+type MySQLTransactor struct {
+	db *gorm.DB
+}
+
+// NewMySQLTransactor crea una nueva instancia del transactor
+// Comparte la conexión DB con ActividadesRepository
+func NewMySQLTransactor(db *gorm.DB) *MySQLTransactor {
+	return &MySQLTransactor{
+		db: db,
+	}
+}
+
+// Transaction abre una transacción (o un savepoint si el contexto ya trae una) y la propaga a fn por el contexto
+func (t *MySQLTransactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn devuelve la transacción que trae el contexto o, si no hay, la conexión con el contexto
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
@@ -4,30 +4,60 @@ import (
 	"activities-api/internal/domain"
 	"activities-api/internal/repository"
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// Tamaño de página de la búsqueda de actividades
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
 // ActividadesService define la interfaz del servicio de actividades
 type ActividadesService interface {
 	List(ctx context.Context) ([]domain.ActividadResponse, error)
 	GetByID(ctx context.Context, id uint) (domain.ActividadResponse, error)
-	Search(ctx context.Context, params map[string]interface{}) ([]domain.ActividadResponse, error)
-	Create(ctx context.Context, actividadCreate domain.ActividadCreate) (domain.ActividadResponse, error)
-	Update(ctx context.Context, id uint, actividadUpdate domain.ActividadUpdate) (domain.ActividadResponse, error)
-	Delete(ctx context.Context, id uint) error
+	Search(ctx context.Context, busqueda domain.ActividadBusqueda) (domain.ActividadesPage, error)
+	Create(ctx context.Context, actor domain.Actor, actividadCreate domain.ActividadCreate) (domain.ActividadResponse, error)
+	Update(ctx context.Context, actor domain.Actor, id uint, actividadUpdate domain.ActividadUpdate) (domain.ActividadResponse, error)
+	Patch(ctx context.Context, actor domain.Actor, id uint, patch domain.ActividadPatch) (domain.ActividadResponse, error)
+	Delete(ctx context.Context, actor domain.Actor, id uint) error
+	ListConflictosForzados(ctx context.Context, id uint) ([]domain.ConflictoForzado, error)
+
+	// Importación y exportación del catálogo
+	Import(ctx context.Context, actor domain.Actor, filas []domain.ActividadImportFila, opts domain.ActividadImportOptions) (domain.ActividadImportResult, error)
+	Export(ctx context.Context, sucursalID *uint) ([]domain.ActividadCatalogo, error)
 }
 
 // ActividadesServiceImpl implementa ActividadesService
 // Migrado de backend/services/actividad_service.go con dependency injection
 type ActividadesServiceImpl struct {
 	repository repository.ActividadesRepository
+	sucursales repository.SucursalesRepository
+	waitlist   ListaEsperaPromoter
+	tx         repository.Transactor
+	outbox     repository.OutboxRepository // activity.* se encola en la misma transacción que el cambio
 }
 
 // NewActividadesService crea una nueva instancia del servicio
-func NewActividadesService(repo repository.ActividadesRepository) *ActividadesServiceImpl {
+func NewActividadesService(
+	repo repository.ActividadesRepository,
+	sucursalesRepo repository.SucursalesRepository,
+	waitlist ListaEsperaPromoter,
+	tx repository.Transactor,
+	outbox repository.OutboxRepository,
+) *ActividadesServiceImpl {
 	return &ActividadesServiceImpl{
 		repository: repo,
+		sucursales: sucursalesRepo,
+		waitlist:   waitlist,
+		tx:         tx,
+		outbox:     outbox,
 	}
 }
 
@@ -59,12 +89,22 @@ func (s *ActividadesServiceImpl) GetByID(ctx context.Context, id uint) (domain.A
 	return actividad.ToResponse(), nil
 }
 
-// Search busca actividades por parámetros
+// Search busca actividades por filtros con orden y paginación
 // Migrado de backend/services/actividad_service.go:103
-func (s *ActividadesServiceImpl) Search(ctx context.Context, params map[string]interface{}) ([]domain.ActividadResponse, error) {
-	actividades, err := s.repository.GetByParams(ctx, params)
+func (s *ActividadesServiceImpl) Search(ctx context.Context, busqueda domain.ActividadBusqueda) (domain.ActividadesPage, error) {
+	if busqueda.Limit <= 0 {
+		busqueda.Limit = DefaultSearchLimit
+	}
+	if busqueda.Limit > MaxSearchLimit {
+		busqueda.Limit = MaxSearchLimit
+	}
+	if busqueda.Offset < 0 {
+		busqueda.Offset = 0
+	}
+
+	actividades, total, err := s.repository.Search(ctx, busqueda)
 	if err != nil {
-		return nil, fmt.Errorf("error searching actividades: %w", err)
+		return domain.ActividadesPage{}, fmt.Errorf("error searching actividades: %w", err)
 	}
 
 	// Convertir a Response DTO
@@ -73,16 +113,29 @@ func (s *ActividadesServiceImpl) Search(ctx context.Context, params map[string]i
 		responses[i] = act.ToResponse()
 	}
 
-	return responses, nil
+	return domain.ActividadesPage{
+		Results:    responses,
+		TotalCount: total,
+		Limit:      busqueda.Limit,
+		Offset:     busqueda.Offset,
+	}, nil
 }
 
 // Create crea una nueva actividad
 // Migrado de backend/services/actividad_service.go:123
-func (s *ActividadesServiceImpl) Create(ctx context.Context, actividadCreate domain.ActividadCreate) (domain.ActividadResponse, error) {
+func (s *ActividadesServiceImpl) Create(ctx context.Context, actor domain.Actor, actividadCreate domain.ActividadCreate) (domain.ActividadResponse, error) {
+	// Verificar permisos (gerente de sucursal: solo en sus sucursales)
+	if !actor.Can(domain.PermissionActivitiesManage) && !actor.InSucursal(actividadCreate.SucursalID) {
+		return domain.ActividadResponse{}, forbidden("solo podés crear actividades en tus sucursales")
+	}
+
 	// Validar campos básicos
 	if err := s.validateBasicFields(actividadCreate); err != nil {
 		return domain.ActividadResponse{}, err
 	}
+	if err := checkSucursal(ctx, s.sucursales, actividadCreate.SucursalID, actividadCreate.Cupo); err != nil {
+		return domain.ActividadResponse{}, err
+	}
 
 	// Parsear horarios
 	horaInicio, horaFin, err := s.parseHorarios(actividadCreate.HorarioInicio, actividadCreate.HorarioFinal)
@@ -100,25 +153,111 @@ func (s *ActividadesServiceImpl) Create(ctx context.Context, actividadCreate dom
 		HorarioFinal:  actividadCreate.HorarioFinal,
 		FotoUrl:       actividadCreate.FotoUrl,
 		Instructor:    actividadCreate.Instructor,
+		InstructorID:  actividadCreate.InstructorID,
 		Categoria:     actividadCreate.Categoria,
 		SucursalID:    actividadCreate.SucursalID,
+		Sala:          strings.TrimSpace(actividadCreate.Sala),
+	}
+
+	if err := authorizeForzar(actor, actividadCreate.Forzar); err != nil {
+		return domain.ActividadResponse{}, err
 	}
 
-	createdActividad, err := s.repository.Create(ctx, actividad, horaInicio, horaFin)
+	var createdActividad domain.Actividad
+	var conflictos []domain.ConflictoHorario
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		// Detectar superposiciones de instructor y sala (un administrador puede forzarlas)
+		conflictos, err = s.checkConflictos(ctx, actividad, horaInicio, horaFin, actividadCreate.Forzar)
+		if err != nil {
+			return err
+		}
+
+		created, err := s.repository.Create(ctx, actividad, horaInicio, horaFin)
+		if err != nil {
+			return err
+		}
+		if err := s.repository.SaveConflictosForzados(ctx, created.ID, actor.UsuarioID, conflictos); err != nil {
+			return err
+		}
+
+		createdActividad, err = enqueueActividad(ctx, s.outbox, s.repository, domain.ActividadEventCreated, created.ID)
+		return err
+	})
 	if err != nil {
 		return domain.ActividadResponse{}, fmt.Errorf("error creating actividad: %w", err)
 	}
+	logConflictosForzados(actor, createdActividad.ID, conflictos)
 
 	return createdActividad.ToResponse(), nil
 }
 
 // Update actualiza una actividad existente
 // Migrado de backend/services/actividad_service.go:151
-func (s *ActividadesServiceImpl) Update(ctx context.Context, id uint, actividadUpdate domain.ActividadUpdate) (domain.ActividadResponse, error) {
+func (s *ActividadesServiceImpl) Update(ctx context.Context, actor domain.Actor, id uint, actividadUpdate domain.ActividadUpdate) (domain.ActividadResponse, error) {
+	existing, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return domain.ActividadResponse{}, fmt.Errorf("error updating actividad: %w", err)
+	}
+
+	// PUT conserva los campos opcionales omitidos (para quitarlos se usa PATCH con null o "")
+	if actividadUpdate.Descripcion == "" {
+		actividadUpdate.Descripcion = existing.Descripcion
+	}
+	if actividadUpdate.InstructorID == nil {
+		actividadUpdate.InstructorID = existing.InstructorID
+	}
+	if actividadUpdate.SucursalID == nil {
+		actividadUpdate.SucursalID = existing.SucursalID
+	}
+	if actividadUpdate.Sala == "" {
+		actividadUpdate.Sala = existing.Sala
+	}
+
+	return s.update(ctx, actor, existing, actividadUpdate)
+}
+
+// Patch actualiza solo los campos enviados; el resto conserva el valor actual
+func (s *ActividadesServiceImpl) Patch(ctx context.Context, actor domain.Actor, id uint, patch domain.ActividadPatch) (domain.ActividadResponse, error) {
+	existing, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return domain.ActividadResponse{}, fmt.Errorf("error updating actividad: %w", err)
+	}
+
+	return s.update(ctx, actor, existing, patch.Apply(existing.ToUpdate()))
+}
+
+// update valida y guarda la actividad completa (PUT o PATCH ya combinado con la actual)
+func (s *ActividadesServiceImpl) update(ctx context.Context, actor domain.Actor, existing domain.Actividad, actividadUpdate domain.ActividadUpdate) (domain.ActividadResponse, error) {
+	id := existing.ID
+
+	// Verificar permisos sobre la actividad actual
+	ownOnly, err := s.authorizeUpdate(actor, existing, actividadUpdate)
+	if err != nil {
+		return domain.ActividadResponse{}, err
+	}
+	if ownOnly {
+		// El instructor no puede reasignar la actividad a otro instructor ni a otra sucursal
+		actividadUpdate.InstructorID = existing.InstructorID
+		actividadUpdate.SucursalID = existing.SucursalID
+	}
+
+	// Concurrencia optimista: el If-Match tiene que coincidir con la versión actual
+	// Sin If-Match se usa la versión leída: los campos no enviados salen de existing, así que si otro
+	// la modificó mientras tanto el repositorio (con la fila bloqueada) responde ErrVersionConflict en vez de pisarlo
+	if actividadUpdate.Version == 0 {
+		actividadUpdate.Version = existing.Version
+	}
+	if actividadUpdate.Version != existing.Version {
+		return domain.ActividadResponse{}, domain.ErrVersionConflict
+	}
+
 	// Validar campos básicos
 	if err := s.validateBasicFieldsUpdate(actividadUpdate); err != nil {
 		return domain.ActividadResponse{}, err
 	}
+	if err := checkSucursal(ctx, s.sucursales, actividadUpdate.SucursalID, actividadUpdate.Cupo); err != nil {
+		return domain.ActividadResponse{}, err
+	}
 
 	// Parsear horarios
 	horaInicio, horaFin, err := s.parseHorarios(actividadUpdate.HorarioInicio, actividadUpdate.HorarioFinal)
@@ -136,28 +275,217 @@ func (s *ActividadesServiceImpl) Update(ctx context.Context, id uint, actividadU
 		HorarioFinal:  actividadUpdate.HorarioFinal,
 		FotoUrl:       actividadUpdate.FotoUrl,
 		Instructor:    actividadUpdate.Instructor,
+		InstructorID:  actividadUpdate.InstructorID,
 		Categoria:     actividadUpdate.Categoria,
 		SucursalID:    actividadUpdate.SucursalID,
+		Sala:          strings.TrimSpace(actividadUpdate.Sala),
 	}
+	actividad.ID = id
+	actividad.Version = actividadUpdate.Version
 
-	updatedActividad, err := s.repository.Update(ctx, id, actividad, horaInicio, horaFin)
+	if err := authorizeForzar(actor, actividadUpdate.Forzar); err != nil {
+		return domain.ActividadResponse{}, err
+	}
+
+	var updatedActividad domain.Actividad
+	var conflictos []domain.ConflictoHorario
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		// Detectar superposiciones de instructor y sala (un administrador puede forzarlas)
+		conflictos, err = s.checkConflictos(ctx, actividad, horaInicio, horaFin, actividadUpdate.Forzar)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.repository.Update(ctx, id, actividad, horaInicio, horaFin); err != nil {
+			return err
+		}
+		if err := s.repository.SaveConflictosForzados(ctx, id, actor.UsuarioID, conflictos); err != nil {
+			return err
+		}
+
+		updatedActividad, err = enqueueActividad(ctx, s.outbox, s.repository, domain.ActividadEventUpdated, id)
+		return err
+	})
 	if err != nil {
 		return domain.ActividadResponse{}, fmt.Errorf("error updating actividad: %w", err)
 	}
+	logConflictosForzados(actor, id, conflictos)
+
+	// Si se amplió el cupo, los lugares nuevos pasan a la lista de espera (un error no revierte el cambio)
+	if actividad.Cupo > existing.Cupo {
+		if err := s.waitlist.PromoteWaiting(ctx, id); err != nil {
+			log.Printf("⚠️  Warning: No se pudo promover la lista de espera de la actividad %d: %v", id, err)
+		} else if refreshed, err := s.repository.GetByID(ctx, id); err == nil {
+			updatedActividad = refreshed
+		}
+	}
 
 	return updatedActividad.ToResponse(), nil
 }
 
 // Delete elimina una actividad
 // Migrado de backend/services/actividad_service.go:184
-func (s *ActividadesServiceImpl) Delete(ctx context.Context, id uint) error {
-	if err := s.repository.Delete(ctx, id); err != nil {
+func (s *ActividadesServiceImpl) Delete(ctx context.Context, actor domain.Actor, id uint) error {
+	if !actor.Can(domain.PermissionActivitiesManage) {
+		existing, err := s.repository.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("error deleting actividad: %w", err)
+		}
+		if !actor.Can(domain.PermissionActivitiesManageSucursal) || !actor.InSucursal(existing.SucursalID) {
+			return forbidden("solo podés eliminar actividades de tus sucursales")
+		}
+	}
+
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		return s.outbox.Enqueue(ctx, domain.ActividadEventType, domain.ActividadEventDeleted, strconv.FormatUint(uint64(id), 10), nil)
+	})
+	if err != nil {
 		return fmt.Errorf("error deleting actividad: %w", err)
 	}
 
 	return nil
 }
 
+// ListConflictosForzados obtiene las superposiciones de horario que se forzaron al guardar la actividad
+func (s *ActividadesServiceImpl) ListConflictosForzados(ctx context.Context, id uint) ([]domain.ConflictoForzado, error) {
+	if _, err := s.repository.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	conflictos, err := s.repository.ListConflictosForzados(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error listing conflictos forzados: %w", err)
+	}
+
+	return conflictos, nil
+}
+
+// authorizeForzar verifica que solo un administrador (activities:manage) fuerce una superposición de horarios
+func authorizeForzar(actor domain.Actor, forzar bool) error {
+	if forzar && !actor.Can(domain.PermissionActivitiesManage) {
+		return forbidden("solo un administrador puede forzar una superposición de horarios")
+	}
+	return nil
+}
+
+// checkConflictos busca actividades del mismo día con horario superpuesto y el mismo instructor o la misma sala
+// Se llama dentro de la transacción del guardado: bloquea el día para que dos altas concurrentes no pasen ambas el control
+// Sin forzar devuelve *domain.ConflictoHorarioError; forzando devuelve los conflictos a registrar
+func (s *ActividadesServiceImpl) checkConflictos(ctx context.Context, actividad domain.Actividad, horaInicio, horaFin time.Time, forzar bool) ([]domain.ConflictoHorario, error) {
+	if err := s.repository.LockDia(ctx, actividad.Dia); err != nil {
+		return nil, fmt.Errorf("error checking conflictos: %w", err)
+	}
+
+	otras, err := s.repository.ListByDia(ctx, actividad.Dia)
+	if err != nil {
+		return nil, fmt.Errorf("error checking conflictos: %w", err)
+	}
+
+	// Los horarios guardados vienen como "HH:MM": se comparan normalizados como texto
+	actividad.HorarioInicio = horaInicio.Format("15:04")
+	actividad.HorarioFinal = horaFin.Format("15:04")
+	conflictos := detectConflictos(actividad, otras)
+	if len(conflictos) == 0 {
+		return nil, nil
+	}
+	if !forzar {
+		return nil, &domain.ConflictoHorarioError{Conflictos: conflictos}
+	}
+
+	return conflictos, nil
+}
+
+// logConflictosForzados avisa que se guardó una actividad con superposiciones forzadas (ya registradas en la transacción)
+func logConflictosForzados(actor domain.Actor, actividadID uint, conflictos []domain.ConflictoHorario) {
+	if len(conflictos) == 0 {
+		return
+	}
+
+	log.Printf("⚠️  Actividad %d guardada con %d superposiciones forzadas por el usuario %d", actividadID, len(conflictos), actor.UsuarioID)
+}
+
+// detectConflictos devuelve las actividades (del mismo día) cuyo horario se superpone con el de la actividad
+// y que comparten instructor o sala de la misma sucursal; que una termine cuando empieza la otra no es superposición
+func detectConflictos(actividad domain.Actividad, otras []domain.Actividad) []domain.ConflictoHorario {
+	conflictos := []domain.ConflictoHorario{}
+
+	for _, otra := range otras {
+		if otra.ID == actividad.ID || otra.Dia != actividad.Dia {
+			continue
+		}
+		if !(actividad.HorarioInicio < otra.HorarioFinal && otra.HorarioInicio < actividad.HorarioFinal) {
+			continue
+		}
+
+		conflicto := domain.ConflictoHorario{
+			ActividadID:   otra.ID,
+			Titulo:        otra.Titulo,
+			Dia:           otra.Dia,
+			HorarioInicio: otra.HorarioInicio,
+			HorarioFinal:  otra.HorarioFinal,
+		}
+		if mismoInstructor(actividad, otra) {
+			conflicto.Motivo = domain.ConflictoInstructor
+			conflictos = append(conflictos, conflicto)
+		}
+		if mismaSala(actividad, otra) {
+			conflicto.Motivo = domain.ConflictoSala
+			conflictos = append(conflictos, conflicto)
+		}
+	}
+
+	return conflictos
+}
+
+// mismoInstructor compara por instructor_id si ambas lo tienen y, si no, por nombre
+func mismoInstructor(a, b domain.Actividad) bool {
+	if a.InstructorID != nil && b.InstructorID != nil {
+		return *a.InstructorID == *b.InstructorID
+	}
+	nombre := strings.TrimSpace(a.Instructor)
+	return nombre != "" && strings.EqualFold(nombre, strings.TrimSpace(b.Instructor))
+}
+
+// mismaSala indica si ambas actividades usan la misma sala de la misma sucursal
+func mismaSala(a, b domain.Actividad) bool {
+	if a.SucursalID == nil || b.SucursalID == nil || *a.SucursalID != *b.SucursalID {
+		return false
+	}
+	sala := strings.TrimSpace(a.Sala)
+	return sala != "" && strings.EqualFold(sala, strings.TrimSpace(b.Sala))
+}
+
+// authorizeUpdate verifica que el actor pueda modificar la actividad
+// Devuelve true si solo la puede modificar por ser su instructor (activities:update:own)
+func (s *ActividadesServiceImpl) authorizeUpdate(actor domain.Actor, existing domain.Actividad, actividadUpdate domain.ActividadUpdate) (bool, error) {
+	if actor.Can(domain.PermissionActivitiesManage) {
+		return false, nil
+	}
+
+	// Gerente de sucursal: la actividad debe ser (y seguir siendo) de una de sus sucursales
+	if actor.Can(domain.PermissionActivitiesManageSucursal) && actor.InSucursal(existing.SucursalID) {
+		if !actor.InSucursal(actividadUpdate.SucursalID) {
+			return false, forbidden("no podés mover la actividad a una sucursal que no gestionás")
+		}
+		return false, nil
+	}
+
+	if actor.Can(domain.PermissionActivitiesUpdateOwn) && existing.InstructorID != nil && *existing.InstructorID == actor.UsuarioID {
+		return true, nil
+	}
+
+	return false, forbidden("no tenés permiso para modificar esta actividad")
+}
+
+// forbidden crea un error de autorización (el controller lo traduce a 403)
+func forbidden(msg string) error {
+	return errors.New("forbidden: " + msg)
+}
+
 // validateBasicFields valida los campos básicos para crear
 // Migrado de backend/services/actividad_service.go:32
 func (s *ActividadesServiceImpl) validateBasicFields(actividadCreate domain.ActividadCreate) error {
@@ -193,6 +521,27 @@ func (s *ActividadesServiceImpl) validateBasicFieldsUpdate(actividadUpdate domai
 	return nil
 }
 
+// checkSucursal valida que la sucursal exista y que el cupo no supere su capacidad
+func checkSucursal(ctx context.Context, sucursales repository.SucursalesRepository, sucursalID *uint, cupo uint) error {
+	if sucursalID == nil {
+		return nil
+	}
+
+	sucursal, err := sucursales.GetByID(ctx, *sucursalID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return fmt.Errorf("la sucursal %d no existe", *sucursalID)
+		}
+		return fmt.Errorf("error validating sucursal: %w", err)
+	}
+
+	if sucursal.Capacidad > 0 && cupo > sucursal.Capacidad {
+		return fmt.Errorf("el cupo (%d) supera la capacidad de la sucursal (%d)", cupo, sucursal.Capacidad)
+	}
+
+	return nil
+}
+
 // parseHorarios parsea horarios en formato "HH:MM" a time.Time
 // Migrado de backend/services/actividad_service.go:49
 func (s *ActividadesServiceImpl) parseHorarios(horaInicio, horaFin string) (time.Time, time.Time, error) {
@@ -221,7 +570,3 @@ func (s *ActividadesServiceImpl) parseHorarios(horaInicio, horaFin string) (time
 
 	return inicio, fin, nil
 }
-
-// TODO: Los compañeros deben agregar:
-// - Publicación de eventos a RabbitMQ cuando se crea/actualiza/elimina actividad
-// - Validación de sucursal_id existe (HTTP call a activities-api sucursales endpoint cuando esté listo)
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+// TestMismoInstructor verifica que se compara por instructor_id cuando ambas lo tienen y si no por nombre
+func TestMismoInstructor(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b domain.Actividad
+		want bool
+	}{
+		{"mismo id", domain.Actividad{InstructorID: uintPtr(1), Instructor: "Ana"}, domain.Actividad{InstructorID: uintPtr(1), Instructor: "Otra"}, true},
+		{"distinto id con el mismo nombre", domain.Actividad{InstructorID: uintPtr(1), Instructor: "Ana"}, domain.Actividad{InstructorID: uintPtr(2), Instructor: "Ana"}, false},
+		{"un solo id compara por nombre", domain.Actividad{InstructorID: uintPtr(1), Instructor: "Ana"}, domain.Actividad{Instructor: "ana "}, true},
+		{"mismo nombre sin ids", domain.Actividad{Instructor: " ANA"}, domain.Actividad{Instructor: "ana"}, true},
+		{"distinto nombre", domain.Actividad{Instructor: "Ana"}, domain.Actividad{Instructor: "Juan"}, false},
+		{"sin nombre", domain.Actividad{Instructor: " "}, domain.Actividad{Instructor: ""}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mismoInstructor(tt.a, tt.b); got != tt.want {
+				t.Fatalf("mismoInstructor() = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMismaSala verifica que la sala solo coincide dentro de la misma sucursal
+func TestMismaSala(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b domain.Actividad
+		want bool
+	}{
+		{"misma sala y sucursal", domain.Actividad{SucursalID: uintPtr(1), Sala: "Sala 1"}, domain.Actividad{SucursalID: uintPtr(1), Sala: " sala 1"}, true},
+		{"misma sala en otra sucursal", domain.Actividad{SucursalID: uintPtr(1), Sala: "Sala 1"}, domain.Actividad{SucursalID: uintPtr(2), Sala: "Sala 1"}, false},
+		{"sin sucursal", domain.Actividad{Sala: "Sala 1"}, domain.Actividad{Sala: "Sala 1"}, false},
+		{"una sin sucursal", domain.Actividad{SucursalID: uintPtr(1), Sala: "Sala 1"}, domain.Actividad{Sala: "Sala 1"}, false},
+		{"sin sala asignada", domain.Actividad{SucursalID: uintPtr(1)}, domain.Actividad{SucursalID: uintPtr(1)}, false},
+		{"distinta sala", domain.Actividad{SucursalID: uintPtr(1), Sala: "Sala 1"}, domain.Actividad{SucursalID: uintPtr(1), Sala: "Sala 2"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mismaSala(tt.a, tt.b); got != tt.want {
+				t.Fatalf("mismaSala() = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDetectConflictos verifica la superposición de horarios (los bordes no cuentan) y los motivos
+func TestDetectConflictos(t *testing.T) {
+	actividad := domain.Actividad{
+		ID:            1,
+		Dia:           "Lunes",
+		HorarioInicio: "10:00",
+		HorarioFinal:  "11:00",
+		Instructor:    "Ana",
+		SucursalID:    uintPtr(1),
+		Sala:          "Sala 1",
+	}
+	otra := func(id uint, dia, inicio, fin, instructor, sala string) domain.Actividad {
+		return domain.Actividad{ID: id, Titulo: "Otra", Dia: dia, HorarioInicio: inicio, HorarioFinal: fin, Instructor: instructor, SucursalID: uintPtr(1), Sala: sala}
+	}
+
+	tests := []struct {
+		name  string
+		otras []domain.Actividad
+		want  []string // motivos esperados, en orden
+	}{
+		{"misma actividad", []domain.Actividad{otra(1, "Lunes", "10:00", "11:00", "Ana", "Sala 1")}, []string{}},
+		{"otro día", []domain.Actividad{otra(2, "Martes", "10:00", "11:00", "Ana", "Sala 1")}, []string{}},
+		{"termina cuando empieza", []domain.Actividad{otra(2, "Lunes", "09:00", "10:00", "Ana", "Sala 1")}, []string{}},
+		{"empieza cuando termina", []domain.Actividad{otra(2, "Lunes", "11:00", "12:00", "Ana", "Sala 1")}, []string{}},
+		{"superpuesta sin recursos en común", []domain.Actividad{otra(2, "Lunes", "10:30", "11:30", "Juan", "Sala 2")}, []string{}},
+		{"mismo instructor", []domain.Actividad{otra(2, "Lunes", "10:30", "11:30", "Ana", "Sala 2")}, []string{domain.ConflictoInstructor}},
+		{"misma sala", []domain.Actividad{otra(2, "Lunes", "09:30", "10:30", "Juan", "Sala 1")}, []string{domain.ConflictoSala}},
+		{"contenida con instructor y sala", []domain.Actividad{otra(2, "Lunes", "10:15", "10:45", "Ana", "Sala 1")}, []string{domain.ConflictoInstructor, domain.ConflictoSala}},
+		{
+			"varias actividades",
+			[]domain.Actividad{
+				otra(2, "Lunes", "10:30", "11:30", "Ana", "Sala 2"),
+				otra(3, "Lunes", "11:00", "12:00", "Ana", "Sala 1"),
+				otra(4, "Lunes", "09:00", "12:00", "Juan", "Sala 1"),
+			},
+			[]string{domain.ConflictoInstructor, domain.ConflictoSala},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflictos := detectConflictos(actividad, tt.otras)
+			got := make([]string, len(conflictos))
+			for i, c := range conflictos {
+				got[i] = c.Motivo
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("motivos = %v, se esperaba %v (conflictos %+v)", got, tt.want, conflictos)
+			}
+		})
+	}
+}
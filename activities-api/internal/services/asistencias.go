@@ -0,0 +1,448 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/repository"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// diasHistorialAsistencia es el rango por defecto (en días) de los listados de asistencia
+const diasHistorialAsistencia = 28
+
+// AsistenciasService define la interfaz del servicio de asistencia a sesiones
+type AsistenciasService interface {
+	SuspensionChecker
+	CodigoCheckin(ctx context.Context, actor domain.Actor, sesionID uint) (domain.CodigoCheckin, error)
+	CheckIn(ctx context.Context, usuarioID, sesionID uint, codigo string) (domain.Asistencia, error)
+	CheckOut(ctx context.Context, usuarioID, sesionID uint) (domain.Asistencia, error)
+	RollCall(ctx context.Context, actor domain.Actor, sesionID, usuarioID uint) (domain.Asistencia, error)
+	RollCallCheckOut(ctx context.Context, actor domain.Actor, sesionID, usuarioID uint) (domain.Asistencia, error)
+	ListBySesion(ctx context.Context, actor domain.Actor, sesionID uint) ([]domain.AsistenciaSesion, error)
+	ListByActividad(ctx context.Context, actor domain.Actor, actividadID uint, filtro domain.AsistenciaFiltro) ([]domain.ResumenSesion, error)
+	ListByUser(ctx context.Context, usuarioID uint, filtro domain.AsistenciaFiltro) (domain.AsistenciasUsuario, error)
+	LiftSuspension(ctx context.Context, actor domain.Actor, usuarioID uint) (domain.Suspension, error)
+}
+
+// AsistenciasPolicy define la ventana de check-in y la suspensión por inasistencias
+type AsistenciasPolicy struct {
+	CheckinAntes       time.Duration  // Cuánto antes del inicio se habilita el check-in
+	NoShowLimite       int            // Inasistencias que suspenden las inscripciones (0 = nunca se suspende)
+	NoShowVentana      time.Duration  // Período en el que se cuentan las inasistencias
+	SuspensionDuracion time.Duration  // Duración de la suspensión
+	Location           *time.Location // Zona horaria del gimnasio (rangos de fechas)
+}
+
+// AsistenciasServiceImpl implementa AsistenciasService
+type AsistenciasServiceImpl struct {
+	repository      repository.AsistenciasRepository
+	sesionesRepo    repository.SesionesRepository
+	actividadesRepo repository.ActividadesRepository
+	policy          AsistenciasPolicy
+}
+
+// NewAsistenciasService crea una nueva instancia del servicio
+func NewAsistenciasService(
+	repo repository.AsistenciasRepository,
+	sesionesRepo repository.SesionesRepository,
+	actividadesRepo repository.ActividadesRepository,
+	policy AsistenciasPolicy,
+) *AsistenciasServiceImpl {
+	return &AsistenciasServiceImpl{
+		repository:      repo,
+		sesionesRepo:    sesionesRepo,
+		actividadesRepo: actividadesRepo,
+		policy:          policy,
+	}
+}
+
+// CodigoCheckin devuelve el código que se muestra como QR para que los socios registren su asistencia
+func (s *AsistenciasServiceImpl) CodigoCheckin(ctx context.Context, actor domain.Actor, sesionID uint) (domain.CodigoCheckin, error) {
+	sesion, err := s.authorizeSesion(ctx, actor, sesionID)
+	if err != nil {
+		return domain.CodigoCheckin{}, err
+	}
+	if sesion.Estado == domain.SesionCancelada {
+		return domain.CodigoCheckin{}, fmt.Errorf("la sesión está cancelada")
+	}
+
+	nuevo, err := generarCodigo()
+	if err != nil {
+		return domain.CodigoCheckin{}, fmt.Errorf("error generating codigo de check-in: %w", err)
+	}
+	codigo, err := s.repository.GetCodigoCheckin(ctx, sesionID, nuevo)
+	if err != nil {
+		return domain.CodigoCheckin{}, err
+	}
+
+	return domain.CodigoCheckin{
+		SesionID:    sesionID,
+		Codigo:      codigo,
+		ValidoDesde: sesion.Inicio.Add(-s.policy.CheckinAntes),
+		ValidoHasta: sesion.Fin,
+	}, nil
+}
+
+// CheckIn registra la asistencia del socio escaneando el código de la sesión
+// Solo pueden hacerlo los inscriptos (a la sesión o a la actividad) dentro de la ventana de check-in
+func (s *AsistenciasServiceImpl) CheckIn(ctx context.Context, usuarioID, sesionID uint, codigo string) (domain.Asistencia, error) {
+	sesion, err := s.sesionesRepo.GetByID(ctx, sesionID)
+	if err != nil {
+		return domain.Asistencia{}, err
+	}
+
+	now := time.Now()
+	if err := s.checkVentana(sesion, now, true); err != nil {
+		return domain.Asistencia{}, err
+	}
+
+	esperado, err := s.repository.GetCodigoCheckin(ctx, sesionID, "")
+	if err != nil {
+		return domain.Asistencia{}, err
+	}
+	if esperado == "" || subtle.ConstantTimeCompare([]byte(esperado), []byte(codigo)) != 1 {
+		return domain.Asistencia{}, fmt.Errorf("código de check-in inválido")
+	}
+
+	esperados, err := s.repository.ListEsperados(ctx, []domain.Sesion{sesion})
+	if err != nil {
+		return domain.Asistencia{}, err
+	}
+	if _, ok := esperados[sesionID][usuarioID]; !ok {
+		return domain.Asistencia{}, fmt.Errorf("el usuario no está inscripto a esta sesión")
+	}
+
+	return s.repository.CheckIn(ctx, domain.Asistencia{
+		SesionID:  sesionID,
+		UsuarioID: usuarioID,
+		Metodo:    domain.AsistenciaMetodoQR,
+		CheckInAt: now,
+	})
+}
+
+// CheckOut registra la salida del socio
+func (s *AsistenciasServiceImpl) CheckOut(ctx context.Context, usuarioID, sesionID uint) (domain.Asistencia, error) {
+	return s.repository.CheckOut(ctx, sesionID, usuarioID, time.Now())
+}
+
+// RollCall registra la asistencia de un socio al tomar lista (instructor o gerente)
+// A diferencia del QR, acepta socios sin inscripción (se presentaron igual) y correcciones después de la clase
+func (s *AsistenciasServiceImpl) RollCall(ctx context.Context, actor domain.Actor, sesionID, usuarioID uint) (domain.Asistencia, error) {
+	sesion, err := s.authorizeSesion(ctx, actor, sesionID)
+	if err != nil {
+		return domain.Asistencia{}, err
+	}
+
+	now := time.Now()
+	if err := s.checkVentana(sesion, now, false); err != nil {
+		return domain.Asistencia{}, err
+	}
+
+	registradoPor := actor.UsuarioID
+	return s.repository.CheckIn(ctx, domain.Asistencia{
+		SesionID:      sesionID,
+		UsuarioID:     usuarioID,
+		Metodo:        domain.AsistenciaMetodoManual,
+		RegistradoPor: &registradoPor,
+		CheckInAt:     now,
+	})
+}
+
+// RollCallCheckOut registra la salida de un socio desde la planilla del instructor
+func (s *AsistenciasServiceImpl) RollCallCheckOut(ctx context.Context, actor domain.Actor, sesionID, usuarioID uint) (domain.Asistencia, error) {
+	if _, err := s.authorizeSesion(ctx, actor, sesionID); err != nil {
+		return domain.Asistencia{}, err
+	}
+
+	return s.repository.CheckOut(ctx, sesionID, usuarioID, time.Now())
+}
+
+// ListBySesion arma la planilla de la sesión: inscriptos (presentes o no) y presentes sin inscripción
+func (s *AsistenciasServiceImpl) ListBySesion(ctx context.Context, actor domain.Actor, sesionID uint) ([]domain.AsistenciaSesion, error) {
+	sesion, err := s.authorizeSesion(ctx, actor, sesionID)
+	if err != nil {
+		return nil, err
+	}
+
+	esperados, err := s.repository.ListEsperados(ctx, []domain.Sesion{sesion})
+	if err != nil {
+		return nil, err
+	}
+	asistencias, err := s.repository.ListBySesiones(ctx, []uint{sesionID})
+	if err != nil {
+		return nil, err
+	}
+
+	terminada := sesion.Estado == domain.SesionProgramada && sesion.Fin.Before(time.Now())
+	filas := make(map[uint]*domain.AsistenciaSesion)
+	for usuarioID, origen := range esperados[sesionID] {
+		filas[usuarioID] = &domain.AsistenciaSesion{UsuarioID: usuarioID, Esperado: origen, NoShow: terminada}
+	}
+	for i := range asistencias {
+		fila, ok := filas[asistencias[i].UsuarioID]
+		if !ok {
+			fila = &domain.AsistenciaSesion{UsuarioID: asistencias[i].UsuarioID}
+			filas[asistencias[i].UsuarioID] = fila
+		}
+		fila.Presente = true
+		fila.NoShow = false
+		fila.Asistencia = &asistencias[i]
+	}
+
+	planilla := make([]domain.AsistenciaSesion, 0, len(filas))
+	for _, fila := range filas {
+		planilla = append(planilla, *fila)
+	}
+	sort.Slice(planilla, func(i, j int) bool { return planilla[i].UsuarioID < planilla[j].UsuarioID })
+
+	return planilla, nil
+}
+
+// ListByActividad resume la asistencia de cada sesión programada de la actividad en el rango
+func (s *AsistenciasServiceImpl) ListByActividad(ctx context.Context, actor domain.Actor, actividadID uint, filtro domain.AsistenciaFiltro) ([]domain.ResumenSesion, error) {
+	actividad, err := s.actividadesRepo.GetByID(ctx, actividadID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeSesiones(actor, actividad, true); err != nil {
+		return nil, err
+	}
+
+	desde, hasta, err := s.rango(filtro)
+	if err != nil {
+		return nil, err
+	}
+
+	sesiones, err := s.sesionesRepo.List(ctx, domain.SesionFiltro{ActividadID: &actividadID, Estado: domain.SesionProgramada}, desde, hasta)
+	if err != nil {
+		return nil, fmt.Errorf("error listing sesiones: %w", err)
+	}
+	esperados, err := s.repository.ListEsperados(ctx, sesiones)
+	if err != nil {
+		return nil, err
+	}
+	sesionIDs := make([]uint, len(sesiones))
+	for i, sesion := range sesiones {
+		sesionIDs[i] = sesion.ID
+	}
+	asistencias, err := s.repository.ListBySesiones(ctx, sesionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	presentes := make(map[uint]map[uint]bool, len(sesiones))
+	for _, asistencia := range asistencias {
+		if presentes[asistencia.SesionID] == nil {
+			presentes[asistencia.SesionID] = make(map[uint]bool)
+		}
+		presentes[asistencia.SesionID][asistencia.UsuarioID] = true
+	}
+
+	now := time.Now()
+	resumenes := make([]domain.ResumenSesion, len(sesiones))
+	for i, sesion := range sesiones {
+		resumen := domain.ResumenSesion{
+			Sesion:    sesion,
+			Esperados: len(esperados[sesion.ID]),
+			Presentes: len(presentes[sesion.ID]),
+		}
+		if sesion.Fin.Before(now) {
+			for usuarioID := range esperados[sesion.ID] {
+				if !presentes[sesion.ID][usuarioID] {
+					resumen.NoShows++
+				}
+			}
+		}
+		resumenes[i] = resumen
+	}
+
+	return resumenes, nil
+}
+
+// ListByUser obtiene el historial de asistencia del socio con sus inasistencias y su suspensión vigente
+func (s *AsistenciasServiceImpl) ListByUser(ctx context.Context, usuarioID uint, filtro domain.AsistenciaFiltro) (domain.AsistenciasUsuario, error) {
+	desde, hasta, err := s.rango(filtro)
+	if err != nil {
+		return domain.AsistenciasUsuario{}, err
+	}
+
+	asistencias, err := s.repository.ListByUser(ctx, usuarioID, &desde, &hasta)
+	if err != nil {
+		return domain.AsistenciasUsuario{}, err
+	}
+
+	now := time.Now()
+	hastaNoShows := hasta
+	if hastaNoShows.After(now) {
+		hastaNoShows = now
+	}
+	var noShows int64
+	if desde.Before(hastaNoShows) {
+		noShows, err = s.repository.CountNoShows(ctx, usuarioID, desde, hastaNoShows)
+		if err != nil {
+			return domain.AsistenciasUsuario{}, err
+		}
+	}
+
+	historial := domain.AsistenciasUsuario{
+		UsuarioID:   usuarioID,
+		Asistencias: asistencias,
+		NoShows:     noShows,
+	}
+
+	suspension, err := s.repository.GetLastSuspension(ctx, usuarioID)
+	if err != nil {
+		return domain.AsistenciasUsuario{}, err
+	}
+	if suspension != nil && suspension.Activa(now) {
+		historial.Suspension = suspension
+	}
+
+	return historial, nil
+}
+
+// LiftSuspension levanta la suspensión vigente del socio; las inasistencias anteriores dejan de contar
+func (s *AsistenciasServiceImpl) LiftSuspension(ctx context.Context, actor domain.Actor, usuarioID uint) (domain.Suspension, error) {
+	suspension, err := s.repository.LiftSuspension(ctx, usuarioID, actor.UsuarioID, time.Now())
+	if err != nil {
+		return domain.Suspension{}, err
+	}
+
+	log.Printf("✅ Suspensión del usuario %d levantada por %d", usuarioID, actor.UsuarioID)
+	return suspension, nil
+}
+
+// CheckSuspension rechaza al socio suspendido; si alcanzó el límite de inasistencias lo suspende en este momento
+// Solo cuentan las inasistencias posteriores a su última suspensión (vencida o levantada)
+func (s *AsistenciasServiceImpl) CheckSuspension(ctx context.Context, usuarioID uint) error {
+	if s.policy.NoShowLimite <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	ultima, err := s.repository.GetLastSuspension(ctx, usuarioID)
+	if err != nil {
+		return err
+	}
+	if ultima != nil && ultima.Activa(now) {
+		return s.suspendedError(*ultima)
+	}
+
+	desde := now.Add(-s.policy.NoShowVentana)
+	if ultima != nil {
+		fin := ultima.Hasta
+		if ultima.LevantadaAt != nil {
+			fin = *ultima.LevantadaAt
+		}
+		if fin.After(desde) {
+			desde = fin
+		}
+	}
+
+	noShows, err := s.repository.CountNoShows(ctx, usuarioID, desde, now)
+	if err != nil {
+		return err
+	}
+	if noShows < int64(s.policy.NoShowLimite) {
+		return nil
+	}
+
+	suspension, err := s.repository.CreateSuspension(ctx, domain.Suspension{
+		UsuarioID: usuarioID,
+		Desde:     now,
+		Hasta:     now.Add(s.policy.SuspensionDuracion),
+		NoShows:   noShows,
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("🚫 Usuario %d suspendido hasta %s por %d inasistencias", usuarioID, suspension.Hasta.Format(time.RFC3339), noShows)
+
+	return s.suspendedError(suspension)
+}
+
+// authorizeSesion obtiene la sesión y verifica que el actor gestione su actividad (el instructor incluido)
+func (s *AsistenciasServiceImpl) authorizeSesion(ctx context.Context, actor domain.Actor, sesionID uint) (domain.Sesion, error) {
+	sesion, err := s.sesionesRepo.GetByID(ctx, sesionID)
+	if err != nil {
+		return domain.Sesion{}, err
+	}
+
+	actividad, err := s.actividadesRepo.GetByID(ctx, sesion.ActividadID)
+	if err != nil {
+		return domain.Sesion{}, fmt.Errorf("error getting actividad: %w", err)
+	}
+	if err := authorizeSesiones(actor, actividad, true); err != nil {
+		return domain.Sesion{}, err
+	}
+
+	return sesion, nil
+}
+
+// checkVentana valida que la sesión no esté cancelada y que el check-in ya esté habilitado
+// (y, para el QR, que la sesión no haya terminado)
+func (s *AsistenciasServiceImpl) checkVentana(sesion domain.Sesion, now time.Time, cierraAlTerminar bool) error {
+	if sesion.Estado == domain.SesionCancelada {
+		return fmt.Errorf("la sesión está cancelada")
+	}
+	if now.Before(sesion.Inicio.Add(-s.policy.CheckinAntes)) {
+		return fmt.Errorf("el check-in se habilita %s antes del inicio de la sesión", s.policy.CheckinAntes)
+	}
+	if cierraAlTerminar && now.After(sesion.Fin) {
+		return fmt.Errorf("la sesión ya terminó")
+	}
+	return nil
+}
+
+// rango convierte el filtro de fechas en [desde, hasta) en la zona del gimnasio (por defecto, las últimas 4 semanas)
+func (s *AsistenciasServiceImpl) rango(filtro domain.AsistenciaFiltro) (time.Time, time.Time, error) {
+	now := time.Now().In(s.policy.Location)
+	hasta := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.policy.Location).AddDate(0, 0, 1)
+	if filtro.FechaHasta != "" {
+		fechaHasta, err := time.ParseInLocation(domain.FormatoFecha, filtro.FechaHasta, s.policy.Location)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("formato de hasta inválido (debe ser YYYY-MM-DD): %s", filtro.FechaHasta)
+		}
+		hasta = fechaHasta.AddDate(0, 0, 1) // Inclusive
+	}
+
+	desde := hasta.AddDate(0, 0, -diasHistorialAsistencia)
+	if filtro.FechaDesde != "" {
+		fechaDesde, err := time.ParseInLocation(domain.FormatoFecha, filtro.FechaDesde, s.policy.Location)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("formato de desde inválido (debe ser YYYY-MM-DD): %s", filtro.FechaDesde)
+		}
+		desde = fechaDesde
+	}
+
+	if !hasta.After(desde) {
+		return time.Time{}, time.Time{}, fmt.Errorf("hasta no puede ser anterior a desde")
+	}
+	if hasta.Sub(desde) > maxRangoSesiones*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("el rango no puede superar %d días", maxRangoSesiones)
+	}
+
+	return desde, hasta, nil
+}
+
+// suspendedError arma el rechazo de inscripción de un socio suspendido
+func (s *AsistenciasServiceImpl) suspendedError(suspension domain.Suspension) error {
+	return inscripcionError(domain.InscripcionErrSuspended,
+		fmt.Sprintf("Inscripciones suspendidas hasta el %s por %d inasistencias",
+			suspension.Hasta.In(s.policy.Location).Format("02/01/2006 15:04"), suspension.NoShows), nil)
+}
+
+// generarCodigo genera un código aleatorio de check-in (32 caracteres hexadecimales)
+func generarCodigo() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,509 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/repository"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ventana de sesiones fechadas que se publican en los feeds
+const (
+	calendarioDiasAtras    = 28
+	calendarioDiasAdelante = 180
+)
+
+// CalendarioService define la interfaz del servicio de feeds iCalendar (RFC 5545)
+type CalendarioService interface {
+	CreateToken(ctx context.Context, usuarioID uint) (domain.CalendarioToken, error)
+	RevokeToken(ctx context.Context, usuarioID uint) error
+	MemberFeed(ctx context.Context, token string) ([]byte, error)
+	PublicFeed(ctx context.Context, filtro domain.CalendarioFiltro) ([]byte, error)
+}
+
+// CalendarioPolicy configura los feeds
+type CalendarioPolicy struct {
+	Location *time.Location // Zona horaria del gimnasio (la misma de parseHorarios y las sesiones)
+}
+
+// CalendarioServiceImpl implementa CalendarioService
+type CalendarioServiceImpl struct {
+	tokens        repository.CalendarioTokensRepository
+	inscripciones repository.InscripcionesRepository
+	actividades   repository.ActividadesRepository
+	sesiones      repository.SesionesRepository
+	sucursales    repository.SucursalesRepository
+	policy        CalendarioPolicy
+}
+
+// NewCalendarioService crea una nueva instancia del servicio
+func NewCalendarioService(
+	tokens repository.CalendarioTokensRepository,
+	inscripciones repository.InscripcionesRepository,
+	actividades repository.ActividadesRepository,
+	sesiones repository.SesionesRepository,
+	sucursales repository.SucursalesRepository,
+	policy CalendarioPolicy,
+) *CalendarioServiceImpl {
+	if policy.Location == nil {
+		policy.Location = time.Local
+	}
+
+	return &CalendarioServiceImpl{
+		tokens:        tokens,
+		inscripciones: inscripciones,
+		actividades:   actividades,
+		sesiones:      sesiones,
+		sucursales:    sucursales,
+		policy:        policy,
+	}
+}
+
+// CreateToken genera el token del feed personal del socio; si ya tenía uno, el anterior deja de funcionar
+func (s *CalendarioServiceImpl) CreateToken(ctx context.Context, usuarioID uint) (domain.CalendarioToken, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return domain.CalendarioToken{}, fmt.Errorf("error generating calendario token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	createdAt, err := s.tokens.Save(ctx, usuarioID, hashCalendarioToken(token))
+	if err != nil {
+		return domain.CalendarioToken{}, err
+	}
+	log.Printf("📅 Token de calendario generado para el usuario %d", usuarioID)
+
+	return domain.CalendarioToken{
+		UsuarioID: usuarioID,
+		Token:     token,
+		URL:       "/calendario/socios/" + token + ".ics",
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// RevokeToken revoca el token del feed personal del socio
+func (s *CalendarioServiceImpl) RevokeToken(ctx context.Context, usuarioID uint) error {
+	deleted, err := s.tokens.Delete(ctx, usuarioID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return fmt.Errorf("calendario token not found")
+	}
+
+	log.Printf("📅 Token de calendario revocado para el usuario %d", usuarioID)
+	return nil
+}
+
+// MemberFeed arma el calendario de las clases del socio: sus inscripciones activas a actividades
+// y sus inscripciones a sesiones puntuales
+func (s *CalendarioServiceImpl) MemberFeed(ctx context.Context, token string) ([]byte, error) {
+	usuarioID, err := s.tokens.GetUsuarioID(ctx, hashCalendarioToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	inscripciones, err := s.inscripciones.ListByUser(ctx, usuarioID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing inscripciones: %w", err)
+	}
+
+	desde, hasta := s.ventana()
+	feed := s.newFeed(ctx)
+	for _, inscripcion := range inscripciones {
+		if !inscripcion.IsActiva {
+			continue
+		}
+		actividad, ok := feed.actividad(inscripcion.ActividadID)
+		if !ok {
+			continue
+		}
+
+		sesiones, err := s.sesiones.List(ctx, domain.SesionFiltro{ActividadID: &actividad.ID}, desde, hasta)
+		if err != nil {
+			return nil, fmt.Errorf("error listing sesiones: %w", err)
+		}
+		feed.addActividad(actividad, sesiones, inscripcion.FechaInscripcion)
+	}
+
+	inscripcionesSesion, err := s.sesiones.ListInscripcionesByUser(ctx, usuarioID, &desde)
+	if err != nil {
+		return nil, fmt.Errorf("error listing inscripciones a sesiones: %w", err)
+	}
+	for _, inscripcion := range inscripcionesSesion {
+		if !inscripcion.IsActiva || inscripcion.Sesion == nil {
+			continue
+		}
+		if actividad, ok := feed.actividad(inscripcion.Sesion.ActividadID); ok {
+			feed.addSesion(actividad, *inscripcion.Sesion)
+		}
+	}
+
+	return feed.render("Mis clases"), nil
+}
+
+// PublicFeed arma el calendario de horarios del gimnasio (opcionalmente de una sucursal y/o categoría)
+func (s *CalendarioServiceImpl) PublicFeed(ctx context.Context, filtro domain.CalendarioFiltro) ([]byte, error) {
+	actividades, err := s.actividades.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing actividades: %w", err)
+	}
+	sort.Slice(actividades, func(i, j int) bool { return actividades[i].ID < actividades[j].ID })
+
+	desde, hasta := s.ventana()
+	sesiones, err := s.sesiones.List(ctx, domain.SesionFiltro{SucursalID: filtro.SucursalID}, desde, hasta)
+	if err != nil {
+		return nil, fmt.Errorf("error listing sesiones: %w", err)
+	}
+	sesionesPorActividad := make(map[uint][]domain.Sesion)
+	for _, sesion := range sesiones {
+		sesionesPorActividad[sesion.ActividadID] = append(sesionesPorActividad[sesion.ActividadID], sesion)
+	}
+
+	feed := s.newFeed(ctx)
+	nombre := "Horarios"
+	for _, actividad := range actividades {
+		if filtro.SucursalID != nil && (actividad.SucursalID == nil || *actividad.SucursalID != *filtro.SucursalID) {
+			continue
+		}
+		if filtro.Categoria != "" && !strings.EqualFold(strings.TrimSpace(actividad.Categoria), filtro.Categoria) {
+			continue
+		}
+		feed.addActividad(actividad, sesionesPorActividad[actividad.ID], actividad.CreatedAt)
+	}
+
+	if filtro.SucursalID != nil {
+		if sucursal, ok := feed.sucursal(*filtro.SucursalID); ok {
+			nombre += " - " + sucursal.Nombre
+		}
+	}
+	if filtro.Categoria != "" {
+		nombre += " - " + filtro.Categoria
+	}
+
+	return feed.render(nombre), nil
+}
+
+// ventana devuelve el rango de sesiones fechadas que se publican
+func (s *CalendarioServiceImpl) ventana() (time.Time, time.Time) {
+	now := time.Now().In(s.policy.Location)
+	return now.AddDate(0, 0, -calendarioDiasAtras), now.AddDate(0, 0, calendarioDiasAdelante)
+}
+
+// hashCalendarioToken es lo que se guarda del token (el token en claro solo lo conoce el socio)
+func hashCalendarioToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// calendarioFeed junta los eventos de un feed, con las actividades y sucursales ya consultadas
+type calendarioFeed struct {
+	s           *CalendarioServiceImpl
+	ctx         context.Context
+	eventos     []icsEvento
+	sesiones    map[uint]bool
+	actividades map[uint]*domain.Actividad
+	sucursales  map[uint]*domain.Sucursal
+}
+
+func (s *CalendarioServiceImpl) newFeed(ctx context.Context) *calendarioFeed {
+	return &calendarioFeed{
+		s:           s,
+		ctx:         ctx,
+		sesiones:    make(map[uint]bool),
+		actividades: make(map[uint]*domain.Actividad),
+		sucursales:  make(map[uint]*domain.Sucursal),
+	}
+}
+
+// actividad obtiene la actividad (una sola consulta por feed); false si ya no existe
+func (f *calendarioFeed) actividad(id uint) (domain.Actividad, bool) {
+	if actividad, ok := f.actividades[id]; ok {
+		return *actividad, actividad.ID != 0
+	}
+
+	actividad, err := f.s.actividades.GetByID(f.ctx, id)
+	if err != nil {
+		log.Printf("⚠️  Warning: Actividad %d fuera del calendario: %v", id, err)
+		actividad = domain.Actividad{}
+	}
+	f.actividades[id] = &actividad
+	return actividad, actividad.ID != 0
+}
+
+// sucursal obtiene la sucursal (una sola consulta por feed) para la ubicación de los eventos
+func (f *calendarioFeed) sucursal(id uint) (domain.Sucursal, bool) {
+	if sucursal, ok := f.sucursales[id]; ok {
+		return *sucursal, sucursal.ID != 0
+	}
+
+	sucursal, err := f.s.sucursales.GetByID(f.ctx, id)
+	if err != nil {
+		sucursal = domain.Sucursal{}
+	}
+	f.sucursales[id] = &sucursal
+	return sucursal, sucursal.ID != 0
+}
+
+// addActividad agrega la actividad: si tiene sesiones fechadas en la ventana se publican esas
+// (con sus cancelaciones y reprogramaciones); si no, un evento semanal con RRULE desde la primera clase a partir de desde
+func (f *calendarioFeed) addActividad(actividad domain.Actividad, sesiones []domain.Sesion, desde time.Time) {
+	if len(sesiones) > 0 {
+		for _, sesion := range sesiones {
+			// Las sesiones anteriores a la inscripción no son del socio
+			if sesion.Fin.Before(desde) {
+				continue
+			}
+			f.addSesion(actividad, sesion)
+		}
+		return
+	}
+
+	diaIndex := -1
+	for i, dia := range diasSemana {
+		if dia == actividad.Dia {
+			diaIndex = i
+		}
+	}
+	loc := f.s.policy.Location
+	inicioHora, errInicio := time.ParseInLocation("15:04", actividad.HorarioInicio, loc)
+	finHora, errFin := time.ParseInLocation("15:04", actividad.HorarioFinal, loc)
+	if diaIndex < 0 || errInicio != nil || errFin != nil {
+		log.Printf("⚠️  Warning: Actividad %d sin día u horario válido, no se publica en el calendario", actividad.ID)
+		return
+	}
+
+	// Primera clase: el día de la semana de la actividad a partir de desde (creación o inscripción)
+	if desde.IsZero() {
+		desde = time.Now()
+	}
+	desde = desde.In(loc)
+	fecha := time.Date(desde.Year(), desde.Month(), desde.Day(), 0, 0, 0, 0, loc)
+	fecha = fecha.AddDate(0, 0, (diaIndex-diaSemanaIndex(fecha)+7)%7)
+
+	evento := f.evento(actividad)
+	evento.UID = fmt.Sprintf("actividad-%d@activities-api", actividad.ID)
+	evento.Inicio = time.Date(fecha.Year(), fecha.Month(), fecha.Day(), inicioHora.Hour(), inicioHora.Minute(), 0, 0, loc)
+	evento.Fin = time.Date(fecha.Year(), fecha.Month(), fecha.Day(), finHora.Hour(), finHora.Minute(), 0, 0, loc)
+	evento.RRule = "FREQ=WEEKLY;BYDAY=" + icsDias[diaIndex]
+	evento.Sequence = actividad.Version
+	f.eventos = append(f.eventos, evento)
+}
+
+// addSesion agrega una sesión fechada (una sola vez aunque el socio esté inscripto a la actividad y a la sesión)
+func (f *calendarioFeed) addSesion(actividad domain.Actividad, sesion domain.Sesion) {
+	if f.sesiones[sesion.ID] {
+		return
+	}
+	f.sesiones[sesion.ID] = true
+
+	evento := f.evento(actividad)
+	evento.UID = fmt.Sprintf("sesion-%d@activities-api", sesion.ID)
+	evento.Inicio = sesion.Inicio.In(f.s.policy.Location)
+	evento.Fin = sesion.Fin.In(f.s.policy.Location)
+	evento.Cancelada = sesion.Estado == domain.SesionCancelada
+	if evento.Cancelada && sesion.MotivoCancelacion != "" {
+		evento.Descripcion = strings.TrimSpace("Cancelada: " + sesion.MotivoCancelacion + "\n\n" + evento.Descripcion)
+	}
+	if !sesion.UpdatedAt.IsZero() {
+		evento.LastModified = sesion.UpdatedAt
+	}
+	f.eventos = append(f.eventos, evento)
+}
+
+// evento arma los datos comunes (título, descripción, ubicación) de los eventos de una actividad
+func (f *calendarioFeed) evento(actividad domain.Actividad) icsEvento {
+	descripcion := actividad.Descripcion
+	if actividad.Instructor != "" {
+		descripcion = strings.TrimSpace(descripcion + "\n\nInstructor: " + actividad.Instructor)
+	}
+
+	var ubicacion []string
+	if actividad.Sala != "" {
+		ubicacion = append(ubicacion, actividad.Sala)
+	}
+	if actividad.SucursalID != nil {
+		if sucursal, ok := f.sucursal(*actividad.SucursalID); ok {
+			ubicacion = append(ubicacion, sucursal.Nombre, sucursal.Direccion)
+		}
+	}
+
+	return icsEvento{
+		Titulo:       actividad.Titulo,
+		Descripcion:  descripcion,
+		Ubicacion:    strings.Join(ubicacion, ", "),
+		Categoria:    actividad.Categoria,
+		LastModified: actividad.UpdatedAt,
+	}
+}
+
+// render escribe el VCALENDAR con la zona horaria del gimnasio y los eventos
+func (f *calendarioFeed) render(nombre string) []byte {
+	loc := f.s.policy.Location
+	ics := &icsWriter{}
+
+	ics.line("BEGIN", "VCALENDAR")
+	ics.line("VERSION", "2.0")
+	ics.line("PRODID", "-//Gym Management//activities-api//ES")
+	ics.line("CALSCALE", "GREGORIAN")
+	ics.line("METHOD", "PUBLISH")
+	ics.text("X-WR-CALNAME", nombre)
+	ics.line("X-WR-TIMEZONE", loc.String())
+
+	// El VTIMEZONE cubre desde el primer evento hasta el final de la ventana del feed
+	desde, hasta := f.s.ventana()
+	for _, evento := range f.eventos {
+		if evento.Inicio.Before(desde) {
+			desde = evento.Inicio
+		}
+	}
+	ics.timezone(loc, desde, hasta)
+
+	dtstamp := time.Now().UTC().Format(icsFormatoUTC)
+	for _, evento := range f.eventos {
+		ics.line("BEGIN", "VEVENT")
+		ics.line("UID", evento.UID)
+		ics.line("DTSTAMP", dtstamp)
+		ics.line("DTSTART;TZID="+loc.String(), evento.Inicio.Format(icsFormatoLocal))
+		ics.line("DTEND;TZID="+loc.String(), evento.Fin.Format(icsFormatoLocal))
+		if evento.RRule != "" {
+			ics.line("RRULE", evento.RRule)
+		}
+		ics.text("SUMMARY", evento.Titulo)
+		if evento.Descripcion != "" {
+			ics.text("DESCRIPTION", evento.Descripcion)
+		}
+		if evento.Ubicacion != "" {
+			ics.text("LOCATION", evento.Ubicacion)
+		}
+		if evento.Categoria != "" {
+			ics.text("CATEGORIES", evento.Categoria)
+		}
+		if evento.Cancelada {
+			ics.line("STATUS", "CANCELLED")
+		} else {
+			ics.line("STATUS", "CONFIRMED")
+		}
+		ics.line("SEQUENCE", fmt.Sprintf("%d", evento.Sequence))
+		if !evento.LastModified.IsZero() {
+			ics.line("LAST-MODIFIED", evento.LastModified.UTC().Format(icsFormatoUTC))
+		}
+		ics.line("END", "VEVENT")
+	}
+
+	ics.line("END", "VCALENDAR")
+	return []byte(ics.String())
+}
+
+// Formatos de fecha de iCalendar
+const (
+	icsFormatoLocal = "20060102T150405"  // Con TZID
+	icsFormatoUTC   = "20060102T150405Z" // DTSTAMP / LAST-MODIFIED
+)
+
+// icsDias son los BYDAY de RRULE en el orden de diasSemana
+var icsDias = []string{"MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+
+// icsEvento es un VEVENT del feed
+type icsEvento struct {
+	UID          string
+	Inicio       time.Time // En la zona horaria del gimnasio
+	Fin          time.Time
+	RRule        string // Vacía = evento único (sesión fechada)
+	Titulo       string
+	Descripcion  string
+	Ubicacion    string
+	Categoria    string
+	Cancelada    bool
+	Sequence     uint
+	LastModified time.Time
+}
+
+// icsWriter escribe líneas de contenido iCalendar (CRLF y plegado a 75 octetos)
+type icsWriter struct {
+	strings.Builder
+}
+
+// line escribe "NOMBRE:valor" tal cual
+func (w *icsWriter) line(name, value string) {
+	line := name + ":" + value
+	for len(line) > 75 {
+		// Cortar sin partir un carácter UTF-8
+		cut := 75
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n")
+		line = " " + line[cut:]
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// text escribe un valor de texto escapando \ ; , y saltos de línea
+func (w *icsWriter) text(name, value string) {
+	value = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+	w.line(name, value)
+}
+
+// timezone escribe el VTIMEZONE de la zona del gimnasio con las transiciones reales entre desde y hasta
+// (una zona sin horario de verano queda con un único STANDARD). Para repeticiones de un RRULE posteriores
+// a hasta los clientes usan el TZID, que es el nombre IANA de la zona
+func (w *icsWriter) timezone(loc *time.Location, desde, hasta time.Time) {
+	w.line("BEGIN", "VTIMEZONE")
+	w.line("TZID", loc.String())
+
+	t := desde.In(loc)
+	for {
+		w.observance(t)
+		_, end := t.ZoneBounds()
+		if end.IsZero() || end.After(hasta) {
+			break
+		}
+		t = end
+	}
+
+	w.line("END", "VTIMEZONE")
+}
+
+// observance escribe el STANDARD o DAYLIGHT del período de la zona que incluye t
+// DTSTART es el comienzo del período en la hora local anterior a la transición (TZOFFSETFROM)
+func (w *icsWriter) observance(t time.Time) {
+	abbr, offset := t.Zone()
+	start, _ := t.ZoneBounds()
+
+	dtstart := "19700101T000000"
+	offsetFrom := offset
+	if !start.IsZero() {
+		_, offsetFrom = start.Add(-time.Second).Zone()
+		dtstart = start.In(time.FixedZone("", offsetFrom)).Format(icsFormatoLocal)
+	}
+
+	component := "STANDARD"
+	if t.IsDST() {
+		component = "DAYLIGHT"
+	}
+	w.line("BEGIN", component)
+	w.line("DTSTART", dtstart)
+	w.line("TZOFFSETFROM", icsOffset(offsetFrom))
+	w.line("TZOFFSETTO", icsOffset(offset))
+	w.text("TZNAME", abbr)
+	w.line("END", component)
+}
+
+// icsOffset formatea un offset en segundos como ±HHMM
+func icsOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+}
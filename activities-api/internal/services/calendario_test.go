@@ -0,0 +1,200 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// TestICSWriterLine verifica el plegado a 75 octetos sin partir caracteres multibyte
+func TestICSWriterLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"corta", "Yoga"},
+		{"justo 75 octetos", strings.Repeat("a", 75-len("SUMMARY:"))},
+		{"76 octetos", strings.Repeat("a", 76-len("SUMMARY:"))},
+		{"larga ASCII", strings.Repeat("abcdefghij", 30)},
+		{"acentos en el corte", strings.Repeat("ñ", 100)},
+		{"emojis", strings.Repeat("🏋️ ", 40)},
+		{"mezcla", "x" + strings.Repeat("áé🧘", 50)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &icsWriter{}
+			w.line("SUMMARY", tt.value)
+			out := w.String()
+
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("la línea no termina en CRLF: %q", out)
+			}
+			lineas := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, linea := range lineas {
+				if len(linea) > 75 {
+					t.Errorf("línea %d con %d octetos: %q", i, len(linea), linea)
+				}
+				if !utf8.ValidString(linea) {
+					t.Errorf("línea %d parte un carácter UTF-8: %q", i, linea)
+				}
+				if i > 0 && !strings.HasPrefix(linea, " ") {
+					t.Errorf("la continuación %d no empieza con espacio: %q", i, linea)
+				}
+			}
+
+			// Desplegar (RFC 5545 3.1) devuelve la línea original
+			if got := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); got != "SUMMARY:"+tt.value {
+				t.Fatalf("desplegado = %q, se esperaba %q", got, "SUMMARY:"+tt.value)
+			}
+		})
+	}
+}
+
+// TestICSWriterText verifica el escape de los valores de texto
+func TestICSWriterText(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"sin caracteres especiales", "Yoga matutino", "Yoga matutino"},
+		{"coma y punto y coma", "Sala 1, planta baja; entrada B", `Sala 1\, planta baja\; entrada B`},
+		{"barra invertida", `C:\clases`, `C:\\clases`},
+		{"saltos de línea", "Traer mat\n\nInstructor: Ana\r\nFin", `Traer mat\n\nInstructor: Ana\nFin`},
+		{"barra antes de coma", `a\,b`, `a\\\,b`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &icsWriter{}
+			w.text("DESCRIPTION", tt.value)
+			if got := w.String(); got != "DESCRIPTION:"+tt.want+"\r\n" {
+				t.Fatalf("text() = %q, se esperaba %q", got, "DESCRIPTION:"+tt.want+"\r\n")
+			}
+		})
+	}
+}
+
+// TestAddActividadRRuleStart verifica que el evento semanal empieza en la primera clase a partir de desde,
+// contando los días en la zona del gimnasio
+func TestAddActividadRRuleStart(t *testing.T) {
+	loc := time.FixedZone("ART", -3*60*60)
+	actividad := domain.Actividad{ID: 1, Titulo: "Yoga", Dia: "Miercoles", HorarioInicio: "10:00", HorarioFinal: "11:00", Version: 2}
+
+	tests := []struct {
+		name      string
+		actividad domain.Actividad
+		desde     time.Time
+		want      string // DTSTART local esperado; vacío = no se publica
+	}{
+		{"desde un lunes", actividad, time.Date(2026, 3, 2, 15, 0, 0, 0, loc), "20260304T100000"},
+		{"desde el mismo día", actividad, time.Date(2026, 3, 4, 8, 0, 0, 0, loc), "20260304T100000"},
+		{"desde el jueves pasa a la semana siguiente", actividad, time.Date(2026, 3, 5, 9, 0, 0, 0, loc), "20260311T100000"},
+		{"desde en UTC que ya es jueves", actividad, time.Date(2026, 3, 5, 1, 0, 0, 0, time.UTC), "20260304T100000"},
+		{"cambio de mes", actividad, time.Date(2026, 3, 26, 12, 0, 0, 0, loc), "20260401T100000"},
+		{"domingo", domain.Actividad{ID: 2, Dia: "Domingo", HorarioInicio: "09:00", HorarioFinal: "10:00"}, time.Date(2026, 3, 2, 0, 0, 0, 0, loc), "20260308T090000"},
+		{"día inválido", domain.Actividad{ID: 3, Dia: "Feriado", HorarioInicio: "09:00", HorarioFinal: "10:00"}, time.Date(2026, 3, 2, 0, 0, 0, 0, loc), ""},
+		{"horario inválido", domain.Actividad{ID: 4, Dia: "Lunes", HorarioInicio: "9", HorarioFinal: "10:00"}, time.Date(2026, 3, 2, 0, 0, 0, 0, loc), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewCalendarioService(nil, nil, nil, nil, nil, CalendarioPolicy{Location: loc})
+			feed := s.newFeed(context.Background())
+			feed.addActividad(tt.actividad, nil, tt.desde)
+
+			if tt.want == "" {
+				if len(feed.eventos) != 0 {
+					t.Fatalf("se publicaron %d eventos, se esperaba ninguno", len(feed.eventos))
+				}
+				return
+			}
+			if len(feed.eventos) != 1 {
+				t.Fatalf("se publicaron %d eventos, se esperaba 1", len(feed.eventos))
+			}
+			evento := feed.eventos[0]
+			if got := evento.Inicio.Format(icsFormatoLocal); got != tt.want {
+				t.Fatalf("DTSTART = %s, se esperaba %s", got, tt.want)
+			}
+			if evento.Fin.Sub(evento.Inicio) != time.Hour {
+				t.Fatalf("duración = %s, se esperaba 1h", evento.Fin.Sub(evento.Inicio))
+			}
+			if wantRRule := "FREQ=WEEKLY;BYDAY=" + icsDias[diaSemanaIndex(evento.Inicio)]; evento.RRule != wantRRule {
+				t.Fatalf("RRULE = %s, se esperaba %s", evento.RRule, wantRRule)
+			}
+		})
+	}
+}
+
+// TestICSWriterTimezone verifica que el VTIMEZONE sale de las transiciones reales de la zona
+func TestICSWriterTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("zona horaria no disponible: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		loc   *time.Location
+		desde time.Time
+		hasta time.Time
+		want  []string // STANDARD/DAYLIGHT esperados como "COMPONENTE DTSTART FROM TO"
+	}{
+		{
+			name:  "offset fijo",
+			loc:   time.FixedZone("ART", -3*60*60),
+			desde: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			hasta: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+			want:  []string{"STANDARD 19700101T000000 -0300 -0300"},
+		},
+		{
+			name:  "con horario de verano",
+			loc:   newYork,
+			desde: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC),
+			hasta: time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC),
+			want: []string{
+				"STANDARD 20251102T020000 -0400 -0500",
+				"DAYLIGHT 20260308T020000 -0500 -0400",
+				"STANDARD 20261101T020000 -0400 -0500",
+			},
+		},
+		{
+			name:  "ventana sin transiciones",
+			loc:   newYork,
+			desde: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+			hasta: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+			want:  []string{"DAYLIGHT 20260308T020000 -0500 -0400"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &icsWriter{}
+			w.timezone(tt.loc, tt.desde, tt.hasta)
+			lineas := strings.Split(strings.TrimSuffix(w.String(), "\r\n"), "\r\n")
+
+			if lineas[0] != "BEGIN:VTIMEZONE" || lineas[1] != "TZID:"+tt.loc.String() || lineas[len(lineas)-1] != "END:VTIMEZONE" {
+				t.Fatalf("VTIMEZONE mal formado: %q", lineas)
+			}
+			var got []string
+			var actual []string
+			for _, linea := range lineas[2 : len(lineas)-1] {
+				name, value, _ := strings.Cut(linea, ":")
+				switch name {
+				case "BEGIN", "DTSTART", "TZOFFSETFROM":
+					actual = append(actual, value)
+				case "TZOFFSETTO":
+					got = append(got, strings.Join(append(actual, value), " "))
+					actual = nil
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("componentes =\n%s\nse esperaba\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
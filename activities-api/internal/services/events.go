@@ -0,0 +1,80 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/repository"
+	"context"
+	"strconv"
+)
+
+// EventPublisher publica eventos en el exchange compartido (abstrae RabbitMQ)
+// El sobre es {action, type, id, timestamp, data} con routing key <type>.<action>
+type EventPublisher interface {
+	PublishEvent(eventType, action, id string, data map[string]interface{}) error
+}
+
+// enqueueActividad relee la actividad (con los lugares recalculados) y encola activity.<action>
+// Se llama dentro de la transacción del cambio: el evento se confirma (o se descarta) junto con él
+func enqueueActividad(ctx context.Context, outbox repository.OutboxRepository, actividadesRepo repository.ActividadesRepository, action string, actividadID uint) (domain.Actividad, error) {
+	actividad, err := actividadesRepo.GetByID(ctx, actividadID)
+	if err != nil {
+		return domain.Actividad{}, err
+	}
+
+	id := strconv.FormatUint(uint64(actividad.ID), 10)
+	if err := outbox.Enqueue(ctx, domain.ActividadEventType, action, id, actividadEventData(actividad)); err != nil {
+		return domain.Actividad{}, err
+	}
+
+	return actividad, nil
+}
+
+// actividadEventData arma el data de activity.* con los campos del documento que indexa search-api
+// (sucursal_id va como texto y los lugares libres como cupo_disponible)
+func actividadEventData(actividad domain.Actividad) map[string]interface{} {
+	data := map[string]interface{}{
+		"titulo":          actividad.Titulo,
+		"descripcion":     actividad.Descripcion,
+		"categoria":       actividad.Categoria,
+		"instructor":      actividad.Instructor,
+		"dia":             actividad.Dia,
+		"horario_inicio":  actividad.HorarioInicio,
+		"horario_final":   actividad.HorarioFinal,
+		"foto_url":        actividad.FotoUrl,
+		"sala":            actividad.Sala,
+		"cupo":            actividad.Cupo,
+		"cupo_disponible": actividad.Lugares,
+	}
+	if actividad.InstructorID != nil {
+		data["instructor_id"] = strconv.FormatUint(uint64(*actividad.InstructorID), 10)
+	}
+	if actividad.SucursalID != nil {
+		data["sucursal_id"] = strconv.FormatUint(uint64(*actividad.SucursalID), 10)
+	}
+
+	return data
+}
+
+// enqueueInscripcion encola inscription.<action> y activity.update con los lugares libres que quedaron
+// Se llama dentro de la transacción que crea o desactiva la inscripción
+func enqueueInscripcion(ctx context.Context, outbox repository.OutboxRepository, actividadesRepo repository.ActividadesRepository, action string, inscripcion domain.Inscripcion) error {
+	actividad, err := actividadesRepo.GetByID(ctx, inscripcion.ActividadID)
+	if err != nil {
+		return err
+	}
+
+	id := strconv.FormatUint(uint64(inscripcion.ID), 10)
+	data := map[string]interface{}{
+		"usuario_id":     strconv.FormatUint(uint64(inscripcion.UsuarioID), 10),
+		"actividad_id":   strconv.FormatUint(uint64(inscripcion.ActividadID), 10),
+		"suscripcion_id": inscripcion.SuscripcionID,
+		"is_activa":      inscripcion.IsActiva,
+		"lugares":        actividad.Lugares,
+	}
+	if err := outbox.Enqueue(ctx, domain.InscripcionEventType, action, id, data); err != nil {
+		return err
+	}
+
+	return outbox.Enqueue(ctx, domain.ActividadEventType, domain.ActividadEventUpdated,
+		strconv.FormatUint(uint64(actividad.ID), 10), actividadEventData(actividad))
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxImportFilas es la cantidad máxima de actividades por importación
+const MaxImportFilas = 1000
+
+// ActividadesCSVColumns son las columnas del CSV de importación/exportación (el orden en la importación es libre)
+var ActividadesCSVColumns = []string{
+	"titulo", "descripcion", "cupo", "dia", "horario_inicio", "horario_final", "foto_url",
+	"instructor", "instructor_id", "categoria", "sucursal_id", "sala",
+}
+
+// Columnas obligatorias en el encabezado del CSV (las mismas que exige POST /actividades)
+var actividadesCSVRequired = []string{
+	"titulo", "cupo", "dia", "horario_inicio", "horario_final", "foto_url", "instructor", "categoria",
+}
+
+// errImportRollback descarta la transacción de una importación en dry-run o atómica con errores
+var errImportRollback = errors.New("importación descartada")
+
+// Import da de alta un catálogo de actividades (POST /actividades/importar)
+// Cada fila se valida y se crea como en Create (permisos, sucursal, superposiciones y evento activity.create)
+// En modo atómico se crean todas o ninguna; en modo parcial solo las válidas
+// El dry-run corre la importación completa en una transacción que se descarta, así también detecta superposiciones entre filas
+func (s *ActividadesServiceImpl) Import(ctx context.Context, actor domain.Actor, filas []domain.ActividadImportFila, opts domain.ActividadImportOptions) (domain.ActividadImportResult, error) {
+	result := domain.ActividadImportResult{
+		Modo:   opts.Modo,
+		DryRun: opts.DryRun,
+		Total:  len(filas),
+		Filas:  make([]domain.ActividadImportResultado, len(filas)),
+	}
+
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		for i, fila := range filas {
+			result.Filas[i] = s.importFila(ctx, actor, fila, opts.SucursalID)
+			if len(result.Filas[i].Errores) > 0 {
+				result.Invalidas++
+			} else {
+				result.Validas++
+			}
+		}
+
+		if opts.DryRun || (opts.Modo == domain.ImportModoAtomico && result.Invalidas > 0) {
+			return errImportRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRollback) {
+		return domain.ActividadImportResult{}, fmt.Errorf("error importing actividades: %w", err)
+	}
+
+	if errors.Is(err, errImportRollback) {
+		// Los IDs asignados dentro de la transacción descartada no existen
+		for i := range result.Filas {
+			result.Filas[i].ActividadID = 0
+		}
+		return result, nil
+	}
+
+	result.Creadas = result.Validas
+	log.Printf("📥 Importación de actividades (%s) por el usuario %d: %d creadas, %d con errores", opts.Modo, actor.UsuarioID, result.Creadas, result.Invalidas)
+
+	return result, nil
+}
+
+// importFila valida y crea una fila dentro de la transacción de la importación
+// Cada fila usa su propio savepoint: si falla, deshace solo lo suyo
+func (s *ActividadesServiceImpl) importFila(ctx context.Context, actor domain.Actor, fila domain.ActividadImportFila, sucursalID *uint) domain.ActividadImportResultado {
+	resultado := domain.ActividadImportResultado{
+		Fila:    fila.Fila,
+		Titulo:  fila.Actividad.Titulo,
+		Errores: fila.Errores,
+	}
+	if len(resultado.Errores) > 0 {
+		return resultado
+	}
+
+	actividadCreate := fila.Actividad.ToCreate()
+	if sucursalID != nil {
+		actividadCreate.SucursalID = sucursalID
+	}
+
+	if errs := s.validateImportFila(actividadCreate); len(errs) > 0 {
+		resultado.Errores = errs
+		return resultado
+	}
+
+	created, err := s.Create(ctx, actor, actividadCreate)
+	if err != nil {
+		var conflictoErr *domain.ConflictoHorarioError
+		if errors.As(err, &conflictoErr) {
+			resultado.Conflictos = conflictoErr.Conflictos
+		}
+		resultado.Errores = []string{err.Error()}
+		return resultado
+	}
+
+	resultado.ActividadID = created.ID
+	return resultado
+}
+
+// validateImportFila junta todos los errores de datos de una fila (los que en POST /actividades valida el binding)
+func (s *ActividadesServiceImpl) validateImportFila(actividadCreate domain.ActividadCreate) []string {
+	var errs []string
+
+	if err := s.validateBasicFields(actividadCreate); err != nil {
+		errs = append(errs, err.Error())
+	}
+	for campo, valor := range map[string]string{
+		"foto_url":   actividadCreate.FotoUrl,
+		"instructor": actividadCreate.Instructor,
+		"categoria":  actividadCreate.Categoria,
+	} {
+		if strings.TrimSpace(valor) == "" {
+			errs = append(errs, fmt.Sprintf("%s no puede estar vacío", campo))
+		}
+	}
+	if len(actividadCreate.Sala) > 50 {
+		errs = append(errs, "la sala no puede superar los 50 caracteres")
+	}
+	if _, _, err := s.parseHorarios(actividadCreate.HorarioInicio, actividadCreate.HorarioFinal); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	sort.Strings(errs)
+	return errs
+}
+
+// Export devuelve el catálogo de actividades (de una sucursal si sucursalID no es nil) en formato importable
+func (s *ActividadesServiceImpl) Export(ctx context.Context, sucursalID *uint) ([]domain.ActividadCatalogo, error) {
+	actividades, err := s.repository.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error exporting actividades: %w", err)
+	}
+	sort.Slice(actividades, func(i, j int) bool { return actividades[i].ID < actividades[j].ID })
+
+	catalogo := []domain.ActividadCatalogo{}
+	for _, actividad := range actividades {
+		if sucursalID != nil && (actividad.SucursalID == nil || *actividad.SucursalID != *sucursalID) {
+			continue
+		}
+		catalogo = append(catalogo, actividad.ToCatalogo())
+	}
+
+	return catalogo, nil
+}
+
+// ParseActividadesJSON lee un array JSON de actividades; un elemento mal formado queda como error de su fila
+func ParseActividadesJSON(r io.Reader) ([]domain.ActividadImportFila, error) {
+	var elementos []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&elementos); err != nil {
+		return nil, fmt.Errorf("el JSON debe ser un array de actividades: %v", err)
+	}
+
+	filas := make([]domain.ActividadImportFila, len(elementos))
+	for i, elemento := range elementos {
+		filas[i].Fila = i + 1
+		if err := json.Unmarshal(elemento, &filas[i].Actividad); err != nil {
+			filas[i].Errores = []string{fmt.Sprintf("formato inválido: %v", err)}
+		}
+	}
+
+	return filas, nil
+}
+
+// ParseActividadesCSV lee un CSV con encabezado (columnas de ActividadesCSVColumns)
+// Un encabezado inválido rechaza el archivo; un valor inválido solo su fila
+func ParseActividadesCSV(r io.Reader) ([]domain.ActividadImportFila, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("el CSV está vacío")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("CSV inválido: %v", err)
+	}
+
+	columnas := make(map[string]int, len(header))
+	for i, nombre := range header {
+		nombre = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(nombre, "\ufeff"))) // BOM de Excel
+		if !containsString(ActividadesCSVColumns, nombre) {
+			return nil, fmt.Errorf("columna desconocida en el CSV: %q", nombre)
+		}
+		if _, ok := columnas[nombre]; ok {
+			return nil, fmt.Errorf("columna repetida en el CSV: %q", nombre)
+		}
+		columnas[nombre] = i
+	}
+	for _, nombre := range actividadesCSVRequired {
+		if _, ok := columnas[nombre]; !ok {
+			return nil, fmt.Errorf("falta la columna %q en el CSV", nombre)
+		}
+	}
+
+	filas := []domain.ActividadImportFila{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSV inválido: %v", err)
+		}
+		linea, _ := reader.FieldPos(0)
+
+		fila := domain.ActividadImportFila{Fila: linea}
+		if len(record) != len(header) {
+			fila.Errores = []string{fmt.Sprintf("la fila tiene %d columnas y el encabezado %d", len(record), len(header))}
+			filas = append(filas, fila)
+			continue
+		}
+
+		valor := func(nombre string) string {
+			if i, ok := columnas[nombre]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		fila.Actividad = domain.ActividadCatalogo{
+			Titulo:        valor("titulo"),
+			Descripcion:   valor("descripcion"),
+			Dia:           valor("dia"),
+			HorarioInicio: valor("horario_inicio"),
+			HorarioFinal:  valor("horario_final"),
+			FotoUrl:       valor("foto_url"),
+			Instructor:    valor("instructor"),
+			Categoria:     valor("categoria"),
+			Sala:          valor("sala"),
+		}
+		if cupo, err := strconv.ParseUint(valor("cupo"), 10, 32); err != nil {
+			fila.Errores = append(fila.Errores, fmt.Sprintf("cupo inválido: %q", valor("cupo")))
+		} else {
+			fila.Actividad.Cupo = uint(cupo)
+		}
+		for nombre, dst := range map[string]**uint{
+			"instructor_id": &fila.Actividad.InstructorID,
+			"sucursal_id":   &fila.Actividad.SucursalID,
+		} {
+			id, err := parseOptionalID(valor(nombre))
+			if err != nil {
+				fila.Errores = append(fila.Errores, fmt.Sprintf("%s inválido: %q", nombre, valor(nombre)))
+				continue
+			}
+			*dst = id
+		}
+		sort.Strings(fila.Errores)
+
+		filas = append(filas, fila)
+	}
+
+	return filas, nil
+}
+
+// WriteActividadesCSV escribe el catálogo con el encabezado de ActividadesCSVColumns
+func WriteActividadesCSV(w io.Writer, catalogo []domain.ActividadCatalogo) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(ActividadesCSVColumns); err != nil {
+		return err
+	}
+
+	for _, actividad := range catalogo {
+		record := []string{
+			actividad.Titulo,
+			actividad.Descripcion,
+			strconv.FormatUint(uint64(actividad.Cupo), 10),
+			actividad.Dia,
+			actividad.HorarioInicio,
+			actividad.HorarioFinal,
+			actividad.FotoUrl,
+			actividad.Instructor,
+			formatOptionalID(actividad.InstructorID),
+			actividad.Categoria,
+			formatOptionalID(actividad.SucursalID),
+			actividad.Sala,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// parseOptionalID parsea un ID opcional del CSV (vacío = nil)
+func parseOptionalID(value string) (*uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		return nil, fmt.Errorf("id inválido: %q", value)
+	}
+	v := uint(id)
+	return &v, nil
+}
+
+// formatOptionalID escribe un ID opcional en el CSV (nil = vacío)
+func formatOptionalID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
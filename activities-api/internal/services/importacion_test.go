@@ -0,0 +1,168 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const csvHeader = "titulo,cupo,dia,horario_inicio,horario_final,foto_url,instructor,categoria"
+
+// TestParseActividadesCSV verifica el encabezado (BOM, mayúsculas, columnas desconocidas o faltantes)
+// y que un valor inválido solo marca su fila
+func TestParseActividadesCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		csv         string
+		wantErr     string
+		wantFilas   int
+		wantLineas  []int
+		wantErrores [][]string
+	}{
+		{
+			name:        "con BOM de Excel",
+			csv:         "\ufeff" + csvHeader + "\nYoga,20,Lunes,10:00,11:00,http://f/1.jpg,Ana,yoga\n",
+			wantFilas:   1,
+			wantLineas:  []int{2},
+			wantErrores: [][]string{nil},
+		},
+		{
+			name:        "encabezado en mayúsculas y en otro orden",
+			csv:         "CUPO, Titulo,dia,horario_inicio,horario_final,foto_url,instructor,categoria,sala\n20,Yoga,Lunes,10:00,11:00,http://f/1.jpg,Ana,yoga,Sala 1\n",
+			wantFilas:   1,
+			wantLineas:  []int{2},
+			wantErrores: [][]string{nil},
+		},
+		{
+			name:    "columna desconocida",
+			csv:     csvHeader + ",precio\nYoga,20,Lunes,10:00,11:00,http://f/1.jpg,Ana,yoga,100\n",
+			wantErr: `columna desconocida en el CSV: "precio"`,
+		},
+		{
+			name:    "columna repetida",
+			csv:     csvHeader + ",titulo\n",
+			wantErr: `columna repetida en el CSV: "titulo"`,
+		},
+		{
+			name:    "falta una columna obligatoria",
+			csv:     "titulo,cupo\nYoga,20\n",
+			wantErr: `falta la columna "dia" en el CSV`,
+		},
+		{
+			name:    "vacío",
+			csv:     "",
+			wantErr: "el CSV está vacío",
+		},
+		{
+			name: "errores por fila",
+			csv: csvHeader + ",instructor_id,sucursal_id\n" +
+				"Yoga,20,Lunes,10:00,11:00,http://f/1.jpg,Ana,yoga,,\n" +
+				"Pilates,veinte,Martes,10:00,11:00,http://f/2.jpg,Juan,pilates,0,x\n" +
+				"Spinning,10,Martes\n",
+			wantFilas:  3,
+			wantLineas: []int{2, 3, 4},
+			wantErrores: [][]string{
+				nil,
+				{`cupo inválido: "veinte"`, `instructor_id inválido: "0"`, `sucursal_id inválido: "x"`},
+				{"la fila tiene 3 columnas y el encabezado 10"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filas, err := ParseActividadesCSV(strings.NewReader(tt.csv))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseActividadesCSV() error = %v, se esperaba %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseActividadesCSV() error = %v", err)
+			}
+			if len(filas) != tt.wantFilas {
+				t.Fatalf("se leyeron %d filas, se esperaban %d", len(filas), tt.wantFilas)
+			}
+			for i, fila := range filas {
+				if fila.Fila != tt.wantLineas[i] {
+					t.Errorf("fila %d en la línea %d, se esperaba %d", i, fila.Fila, tt.wantLineas[i])
+				}
+				if !reflect.DeepEqual(fila.Errores, tt.wantErrores[i]) {
+					t.Errorf("errores de la fila %d = %q, se esperaba %q", i, fila.Errores, tt.wantErrores[i])
+				}
+			}
+		})
+	}
+}
+
+// TestActividadesCSVRoundTrip verifica que lo exportado se vuelve a importar igual
+func TestActividadesCSVRoundTrip(t *testing.T) {
+	catalogo := []domain.ActividadCatalogo{
+		{Titulo: "Yoga, nivel 1", Descripcion: "Con \"mat\" propio", Cupo: 20, Dia: "Lunes", HorarioInicio: "10:00", HorarioFinal: "11:00", FotoUrl: "http://f/1.jpg", Instructor: "Ana", InstructorID: uintPtr(3), Categoria: "yoga", SucursalID: uintPtr(1), Sala: "Sala 1"},
+		{Titulo: "Spinning", Cupo: 10, Dia: "Martes", HorarioInicio: "18:00", HorarioFinal: "19:00", FotoUrl: "http://f/2.jpg", Instructor: "Juan", Categoria: "spinning"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteActividadesCSV(&buf, catalogo); err != nil {
+		t.Fatalf("WriteActividadesCSV() error = %v", err)
+	}
+	filas, err := ParseActividadesCSV(&buf)
+	if err != nil {
+		t.Fatalf("ParseActividadesCSV() error = %v", err)
+	}
+
+	if len(filas) != len(catalogo) {
+		t.Fatalf("se leyeron %d filas, se esperaban %d", len(filas), len(catalogo))
+	}
+	for i, fila := range filas {
+		if len(fila.Errores) > 0 || !reflect.DeepEqual(fila.Actividad, catalogo[i]) {
+			t.Errorf("fila %d = %+v (errores %q), se esperaba %+v", i, fila.Actividad, fila.Errores, catalogo[i])
+		}
+	}
+}
+
+// TestParseActividadesJSON verifica que solo se exige un array y que un elemento mal formado marca su fila
+func TestParseActividadesJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		json         string
+		wantErr      bool
+		wantFilas    int
+		wantConError []int // filas (desde 1) con error de formato
+	}{
+		{"array válido", `[{"titulo":"Yoga","cupo":20},{"titulo":"Spinning","cupo":10}]`, false, 2, nil},
+		{"campos desconocidos se ignoran", `[{"titulo":"Yoga","cupo":20,"precio":100}]`, false, 1, nil},
+		{"elemento mal formado", `[{"titulo":"Yoga","cupo":20},{"titulo":"Spinning","cupo":"diez"},"texto"]`, false, 3, []int{2, 3}},
+		{"array vacío", `[]`, false, 0, nil},
+		{"objeto en vez de array", `{"titulo":"Yoga"}`, true, 0, nil},
+		{"JSON inválido", `[{"titulo":`, true, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filas, err := ParseActividadesJSON(strings.NewReader(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseActividadesJSON() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if len(filas) != tt.wantFilas {
+				t.Fatalf("se leyeron %d filas, se esperaban %d", len(filas), tt.wantFilas)
+			}
+
+			var conError []int
+			for i, fila := range filas {
+				if fila.Fila != i+1 {
+					t.Errorf("fila %d numerada %d", i+1, fila.Fila)
+				}
+				if len(fila.Errores) > 0 {
+					conError = append(conError, fila.Fila)
+				}
+			}
+			if !reflect.DeepEqual(conError, tt.wantConError) {
+				t.Fatalf("filas con error = %v, se esperaba %v", conError, tt.wantConError)
+			}
+		})
+	}
+}
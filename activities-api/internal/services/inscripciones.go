@@ -4,7 +4,10 @@ import (
 	"activities-api/internal/domain"
 	"activities-api/internal/repository"
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 )
 
 // InscripcionesService define la interfaz del servicio de inscripciones
@@ -19,13 +22,51 @@ type InscripcionesService interface {
 type InscripcionesServiceImpl struct {
 	inscripcionesRepo repository.InscripcionesRepository
 	actividadesRepo   repository.ActividadesRepository
+	users             UserValidator
+	subscriptions     SubscriptionsClient
+	suspensions       SuspensionChecker // Opcional: nil si no se suspende por inasistencias
+	waitlist          ListaEsperaPromoter
+	tx                repository.Transactor
+	outbox            repository.OutboxRepository // inscription.* y activity.update se encolan con la inscripción
+}
+
+// UserValidator valida usuarios contra users-api
+type UserValidator interface {
+	UserExists(ctx context.Context, usuarioID uint) (bool, error)
+}
+
+// SubscriptionsClient consulta la suscripción activa de un usuario (con su plan) en subscriptions-api
+// Devuelve domain.ErrNoActiveSubscription si el usuario no tiene una
+type SubscriptionsClient interface {
+	GetActiveSubscription(ctx context.Context, usuarioID uint) (domain.SuscripcionActiva, error)
+}
+
+// SuspensionChecker rechaza la inscripción de un socio suspendido por inasistencias reiteradas
+// Devuelve un *domain.InscripcionError con código ENROLLMENT_SUSPENDED
+type SuspensionChecker interface {
+	CheckSuspension(ctx context.Context, usuarioID uint) error
 }
 
 // NewInscripcionesService crea una nueva instancia del servicio
-func NewInscripcionesService(inscripcionesRepo repository.InscripcionesRepository, actividadesRepo repository.ActividadesRepository) *InscripcionesServiceImpl {
+func NewInscripcionesService(
+	inscripcionesRepo repository.InscripcionesRepository,
+	actividadesRepo repository.ActividadesRepository,
+	users UserValidator,
+	subscriptions SubscriptionsClient,
+	suspensions SuspensionChecker,
+	waitlist ListaEsperaPromoter,
+	tx repository.Transactor,
+	outbox repository.OutboxRepository,
+) *InscripcionesServiceImpl {
 	return &InscripcionesServiceImpl{
 		inscripcionesRepo: inscripcionesRepo,
 		actividadesRepo:   actividadesRepo,
+		users:             users,
+		subscriptions:     subscriptions,
+		suspensions:       suspensions,
+		waitlist:          waitlist,
+		tx:                tx,
+		outbox:            outbox,
 	}
 }
 
@@ -47,107 +88,136 @@ func (s *InscripcionesServiceImpl) ListByUser(ctx context.Context, usuarioID uin
 }
 
 // Create inscribe a un usuario en una actividad
+// Valida que el usuario exista, que tenga una suscripción activa y que su plan cubra la actividad
+// Si hay socios en la lista de espera se rechaza: los lugares que se liberen son para ellos
 // Migrado de backend/services/inscripcion_service.go:44
 func (s *InscripcionesServiceImpl) Create(ctx context.Context, usuarioID, actividadID uint) (domain.InscripcionResponse, error) {
-	// TODO: Validación 1 - Validar que el usuario existe (HTTP call a users-api)
-	// if err := s.validateUserExists(ctx, usuarioID); err != nil {
-	//     return domain.InscripcionResponse{}, fmt.Errorf("usuario inválido: %w", err)
-	// }
-
-	// TODO: Validación 2 - Validar que tiene suscripción activa (HTTP call a subscriptions-api)
-	// activeSub, err := s.getActiveSubscription(ctx, usuarioID)
-	// if err != nil {
-	//     return domain.InscripcionResponse{}, fmt.Errorf("no tiene suscripción activa: %w", err)
-	// }
-
-	// TODO: Validación 3 - Validar que el plan cubra la actividad
-	// actividad, err := s.actividadesRepo.GetByID(ctx, actividadID)
-	// if err != nil {
-	//     return domain.InscripcionResponse{}, fmt.Errorf("actividad no encontrada: %w", err)
-	// }
-	// if actividad.RequierePlanPremium && activeSub.Plan.TipoAcceso != "completo" {
-	//     return domain.InscripcionResponse{}, fmt.Errorf("esta actividad requiere plan premium")
-	// }
-
-	// Validar que la actividad existe
-	_, err := s.actividadesRepo.GetByID(ctx, actividadID)
+	_, suscripcion, err := checkEntitlements(ctx, s.actividadesRepo, s.users, s.subscriptions, s.suspensions, usuarioID, actividadID)
 	if err != nil {
-		return domain.InscripcionResponse{}, fmt.Errorf("actividad no encontrada: %w", err)
+		return domain.InscripcionResponse{}, err
 	}
 
 	// Crear inscripción
 	inscripcion := domain.Inscripcion{
-		UsuarioID:   usuarioID,
-		ActividadID: actividadID,
-		IsActiva:    true,
-		// TODO: SuscripcionID: activeSub.ID (cuando subscriptions-api esté listo)
+		UsuarioID:     usuarioID,
+		ActividadID:   actividadID,
+		IsActiva:      true,
+		SuscripcionID: &suscripcion.ID,
 	}
 
-	createdInscripcion, err := s.inscripcionesRepo.Create(ctx, inscripcion)
+	var createdInscripcion domain.Inscripcion
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		created, err := s.inscripcionesRepo.Create(ctx, inscripcion)
+		if err != nil {
+			return err
+		}
+
+		createdInscripcion = created
+		return enqueueInscripcion(ctx, s.outbox, s.actividadesRepo, domain.InscripcionEventCreated, created)
+	})
 	if err != nil {
+		errString := err.Error()
+		if strings.Contains(errString, "ya está inscripto") {
+			return domain.InscripcionResponse{}, inscripcionError(domain.InscripcionErrAlreadyEnrolled, "El usuario ya está inscripto a esta actividad", nil)
+		}
+		if strings.Contains(errString, "usuarios en lista de espera") {
+			// Si quedaron lugares libres con gente esperando (ej: falló una promoción), se asignan ahora
+			if err := s.waitlist.PromoteWaiting(ctx, actividadID); err != nil {
+				log.Printf("⚠️  Warning: No se pudo promover la lista de espera de la actividad %d: %v", actividadID, err)
+			}
+			return domain.InscripcionResponse{}, inscripcionError(domain.InscripcionErrWaitlistPending,
+				"La actividad tiene socios en lista de espera, puede anotarse en la lista", nil)
+		}
+		if strings.Contains(errString, "cupo de la actividad ha sido alcanzado") {
+			return domain.InscripcionResponse{}, inscripcionError(domain.InscripcionErrActivityFull,
+				"No se puede inscribir, el cupo de la actividad ha sido alcanzado (puede anotarse en la lista de espera)", nil)
+		}
 		return domain.InscripcionResponse{}, fmt.Errorf("error creating inscripcion: %w", err)
 	}
 
-	// TODO: Publicar evento a RabbitMQ
-	// if err := s.publisher.Publish(ctx, "inscription.created", createdInscripcion.ID); err != nil {
-	//     log.Printf("Error publishing event: %v", err)
-	// }
-
 	return createdInscripcion.ToResponse(), nil
 }
 
 // Deactivate desinscribe a un usuario de una actividad
 // Migrado de backend/services/inscripcion_service.go:48
 func (s *InscripcionesServiceImpl) Deactivate(ctx context.Context, usuarioID, actividadID uint) error {
-	if err := s.inscripcionesRepo.Deactivate(ctx, usuarioID, actividadID); err != nil {
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.inscripcionesRepo.Deactivate(ctx, usuarioID, actividadID); err != nil {
+			return err
+		}
+
+		inscripcion, err := s.inscripcionesRepo.GetByUserAndActividad(ctx, usuarioID, actividadID)
+		if err != nil {
+			return err
+		}
+		return enqueueInscripcion(ctx, s.outbox, s.actividadesRepo, domain.InscripcionEventDeleted, inscripcion)
+	})
+	if err != nil {
 		return fmt.Errorf("error deactivating inscripcion: %w", err)
 	}
 
-	// TODO: Publicar evento a RabbitMQ
-	// if err := s.publisher.Publish(ctx, "inscription.deleted", inscripcionID); err != nil {
-	//     log.Printf("Error publishing event: %v", err)
-	// }
+	// El lugar liberado pasa al primero de la lista de espera (un error no revierte la baja)
+	if err := s.waitlist.ReleaseSpot(ctx, usuarioID, actividadID); err != nil {
+		log.Printf("⚠️  Warning: No se pudo promover la lista de espera de la actividad %d: %v", actividadID, err)
+	}
 
 	return nil
 }
 
-// TODO: Los compañeros deben implementar estas funciones:
-//
-// func (s *InscripcionesServiceImpl) validateUserExists(ctx context.Context, userID uint) error {
-//     resp, err := http.Get(fmt.Sprintf("http://users-api:8080/users/%d", userID))
-//     if err != nil {
-//         return fmt.Errorf("error validating user: %w", err)
-//     }
-//     defer resp.Body.Close()
-//
-//     if resp.StatusCode == 404 {
-//         return errors.New("user not found")
-//     }
-//     if resp.StatusCode != 200 {
-//         return errors.New("error validating user")
-//     }
-//
-//     return nil
-// }
-//
-// func (s *InscripcionesServiceImpl) getActiveSubscription(ctx context.Context, userID uint) (Subscription, error) {
-//     resp, err := http.Get(fmt.Sprintf("http://subscriptions-api:8081/subscriptions/active/%d", userID))
-//     if err != nil {
-//         return Subscription{}, fmt.Errorf("error getting active subscription: %w", err)
-//     }
-//     defer resp.Body.Close()
-//
-//     if resp.StatusCode == 404 {
-//         return Subscription{}, errors.New("no active subscription found")
-//     }
-//     if resp.StatusCode != 200 {
-//         return Subscription{}, errors.New("error getting active subscription")
-//     }
-//
-//     var subscription Subscription
-//     if err := json.NewDecoder(resp.Body).Decode(&subscription); err != nil {
-//         return Subscription{}, fmt.Errorf("error decoding subscription: %w", err)
-//     }
-//
-//     return subscription, nil
-// }
+// checkEntitlements valida que la actividad exista, que el usuario exista y no esté suspendido, que tenga una
+// suscripción activa y que su plan cubra la actividad (lo comparten la inscripción, la lista de espera y las sesiones)
+func checkEntitlements(
+	ctx context.Context,
+	actividadesRepo repository.ActividadesRepository,
+	users UserValidator,
+	subscriptions SubscriptionsClient,
+	suspensions SuspensionChecker,
+	usuarioID, actividadID uint,
+) (domain.Actividad, domain.SuscripcionActiva, error) {
+	// Validar que la actividad existe
+	actividad, err := actividadesRepo.GetByID(ctx, actividadID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return domain.Actividad{}, domain.SuscripcionActiva{}, inscripcionError(domain.InscripcionErrActivityNotFound, "La actividad no existe", nil)
+		}
+		return domain.Actividad{}, domain.SuscripcionActiva{}, fmt.Errorf("error getting actividad: %w", err)
+	}
+
+	// Validar que el usuario existe (users-api)
+	exists, err := users.UserExists(ctx, usuarioID)
+	if err != nil {
+		return domain.Actividad{}, domain.SuscripcionActiva{}, inscripcionError(domain.InscripcionErrEntitlementsUnavailable, "No se pudo validar el usuario", err)
+	}
+	if !exists {
+		return domain.Actividad{}, domain.SuscripcionActiva{}, inscripcionError(domain.InscripcionErrUserNotFound, "El usuario no existe", nil)
+	}
+
+	// Validar que no esté suspendido por inasistencias
+	if suspensions != nil {
+		if err := suspensions.CheckSuspension(ctx, usuarioID); err != nil {
+			return domain.Actividad{}, domain.SuscripcionActiva{}, err
+		}
+	}
+
+	// Validar que tiene suscripción activa (subscriptions-api)
+	suscripcion, err := subscriptions.GetActiveSubscription(ctx, usuarioID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoActiveSubscription) {
+			return domain.Actividad{}, domain.SuscripcionActiva{}, inscripcionError(domain.InscripcionErrNoActiveSubscription, "Debe tener una suscripción activa para inscribirse", nil)
+		}
+		return domain.Actividad{}, domain.SuscripcionActiva{}, inscripcionError(domain.InscripcionErrEntitlementsUnavailable, "No se pudo validar la suscripción", err)
+	}
+
+	// Validar que el plan cubra la actividad
+	if !suscripcion.Cubre(actividadID) {
+		return domain.Actividad{}, domain.SuscripcionActiva{}, inscripcionError(domain.InscripcionErrPlanNotCovering,
+			fmt.Sprintf("El plan %s no incluye esta actividad", suscripcion.PlanNombre), nil)
+	}
+
+	return actividad, suscripcion, nil
+}
+
+// inscripcionError crea un rechazo de inscripción con código (el controller lo traduce a HTTP)
+func inscripcionError(code, msg string, cause error) error {
+	return &domain.InscripcionError{Code: code, Message: msg, Err: cause}
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// expiredBatchSize es cuántas promociones vencidas se procesan por pasada
+const expiredBatchSize = 100
+
+// ListaEsperaPromoter promueve la lista de espera cuando se libera o se agrega un lugar
+type ListaEsperaPromoter interface {
+	PromoteWaiting(ctx context.Context, actividadID uint) error
+	ReleaseSpot(ctx context.Context, usuarioID, actividadID uint) error
+}
+
+// ListaEsperaService define la interfaz del servicio de lista de espera
+type ListaEsperaService interface {
+	ListaEsperaPromoter
+	ListByUser(ctx context.Context, usuarioID uint) ([]domain.ListaEspera, error)
+	Join(ctx context.Context, usuarioID, actividadID uint) (domain.ListaEspera, error)
+	Leave(ctx context.Context, usuarioID, actividadID uint) error
+	Confirm(ctx context.Context, usuarioID, actividadID uint) (domain.ListaEspera, error)
+}
+
+// ListaEsperaPolicy define los tiempos de la lista de espera
+type ListaEsperaPolicy struct {
+	ConfirmationWindow time.Duration // Tiempo que tiene el promovido para confirmar el lugar
+	SweepInterval      time.Duration // Cada cuánto se buscan promociones vencidas
+}
+
+// ListaEsperaServiceImpl implementa ListaEsperaService
+type ListaEsperaServiceImpl struct {
+	repository      repository.ListaEsperaRepository
+	actividadesRepo repository.ActividadesRepository
+	users           UserValidator
+	subscriptions   SubscriptionsClient
+	suspensions     SuspensionChecker // Opcional: nil si no se suspende por inasistencias
+	tx              repository.Transactor
+	outbox          repository.OutboxRepository // waitlist.* y activity.update se encolan con cada cambio
+	policy          ListaEsperaPolicy
+}
+
+// NewListaEsperaService crea una nueva instancia del servicio
+func NewListaEsperaService(
+	repo repository.ListaEsperaRepository,
+	actividadesRepo repository.ActividadesRepository,
+	users UserValidator,
+	subscriptions SubscriptionsClient,
+	suspensions SuspensionChecker,
+	tx repository.Transactor,
+	outbox repository.OutboxRepository,
+	policy ListaEsperaPolicy,
+) *ListaEsperaServiceImpl {
+	return &ListaEsperaServiceImpl{
+		repository:      repo,
+		actividadesRepo: actividadesRepo,
+		users:           users,
+		subscriptions:   subscriptions,
+		suspensions:     suspensions,
+		tx:              tx,
+		outbox:          outbox,
+		policy:          policy,
+	}
+}
+
+// ListByUser obtiene las entradas vigentes del usuario (esperando con su posición, o promovidas con su vencimiento)
+func (s *ListaEsperaServiceImpl) ListByUser(ctx context.Context, usuarioID uint) ([]domain.ListaEspera, error) {
+	entries, err := s.repository.ListByUser(ctx, usuarioID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing lista de espera: %w", err)
+	}
+
+	vigentes := make([]domain.ListaEspera, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Estado == domain.ListaEsperaEsperando || entry.Estado == domain.ListaEsperaPromovido {
+			vigentes = append(vigentes, entry)
+		}
+	}
+
+	return vigentes, nil
+}
+
+// Join anota al usuario en la lista de espera de una actividad sin lugares
+// Aplican las mismas validaciones que al inscribirse (usuario, suscripción activa y plan)
+func (s *ListaEsperaServiceImpl) Join(ctx context.Context, usuarioID, actividadID uint) (domain.ListaEspera, error) {
+	actividad, suscripcion, err := checkEntitlements(ctx, s.actividadesRepo, s.users, s.subscriptions, s.suspensions, usuarioID, actividadID)
+	if err != nil {
+		return domain.ListaEspera{}, err
+	}
+
+	if actividad.Lugares > 0 {
+		return domain.ListaEspera{}, inscripcionError(domain.InscripcionErrActivityHasSpots,
+			"La actividad tiene lugares disponibles, puede inscribirse directamente", nil)
+	}
+
+	entry, err := s.repository.Join(ctx, domain.ListaEspera{
+		UsuarioID:     usuarioID,
+		ActividadID:   actividadID,
+		SuscripcionID: &suscripcion.ID,
+	})
+	if err != nil {
+		errString := err.Error()
+		if strings.Contains(errString, "ya está en la lista de espera") {
+			return domain.ListaEspera{}, inscripcionError(domain.InscripcionErrAlreadyWaitlisted, "El usuario ya está en la lista de espera de esta actividad", nil)
+		}
+		if strings.Contains(errString, "ya está inscripto") {
+			return domain.ListaEspera{}, inscripcionError(domain.InscripcionErrAlreadyEnrolled, "El usuario ya está inscripto a esta actividad", nil)
+		}
+		return domain.ListaEspera{}, fmt.Errorf("error joining lista de espera: %w", err)
+	}
+
+	return entry, nil
+}
+
+// Leave saca al usuario de la lista de espera
+// Si ya tenía un lugar asignado sin confirmar, el lugar pasa al siguiente
+func (s *ListaEsperaServiceImpl) Leave(ctx context.Context, usuarioID, actividadID uint) error {
+	var entry domain.ListaEspera
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		left, err := s.repository.Leave(ctx, usuarioID, actividadID)
+		if err != nil {
+			return err
+		}
+
+		// Si tenía un lugar asignado, al rechazarlo se libera
+		entry = left
+		if entry.Estado == domain.ListaEsperaPromovido {
+			_, err = enqueueActividad(ctx, s.outbox, s.actividadesRepo, domain.ActividadEventUpdated, actividadID)
+		}
+		return err
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return inscripcionError(domain.InscripcionErrNotWaitlisted, "El usuario no está en la lista de espera de esta actividad", nil)
+		}
+		return fmt.Errorf("error leaving lista de espera: %w", err)
+	}
+
+	if entry.Estado == domain.ListaEsperaPromovido {
+		if err := s.PromoteWaiting(ctx, actividadID); err != nil {
+			log.Printf("⚠️  Warning: No se pudo promover la lista de espera de la actividad %d: %v", actividadID, err)
+		}
+	}
+
+	return nil
+}
+
+// Confirm confirma el lugar asignado al usuario dentro de la ventana de confirmación
+func (s *ListaEsperaServiceImpl) Confirm(ctx context.Context, usuarioID, actividadID uint) (domain.ListaEspera, error) {
+	entry, err := s.repository.Confirm(ctx, usuarioID, actividadID, time.Now())
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return domain.ListaEspera{}, inscripcionError(domain.InscripcionErrNoPendingPromotion,
+				"No tiene un lugar pendiente de confirmar en esta actividad (o la ventana de confirmación venció)", nil)
+		}
+		return domain.ListaEspera{}, fmt.Errorf("error confirming lista de espera: %w", err)
+	}
+
+	return entry, nil
+}
+
+// PromoteWaiting asigna los lugares libres de la actividad a los primeros de la lista de espera
+// Antes de promover a cada socio se revalidan sus permisos (usuario, suspensión, suscripción y plan), igual que al
+// inscribirse: si ya no puede inscribirse sale de la cola y el lugar pasa al siguiente
+func (s *ListaEsperaServiceImpl) PromoteWaiting(ctx context.Context, actividadID uint) error {
+	for {
+		next, err := s.repository.NextWaiting(ctx, actividadID)
+		if err != nil || next == nil {
+			return err
+		}
+
+		// Sin lugares no hace falta validar a nadie (PromoteNext lo vuelve a verificar con la actividad bloqueada)
+		actividad, err := s.actividadesRepo.GetByID(ctx, actividadID)
+		if err != nil {
+			return fmt.Errorf("error getting actividad: %w", err)
+		}
+		if actividad.Lugares == 0 {
+			return nil
+		}
+
+		// Las validaciones consultan users-api y subscriptions-api: se hacen fuera de la transacción
+		_, suscripcion, err := checkEntitlements(ctx, s.actividadesRepo, s.users, s.subscriptions, s.suspensions, next.UsuarioID, actividadID)
+		if err != nil {
+			var inscripcionErr *domain.InscripcionError
+			if !errors.As(err, &inscripcionErr) || inscripcionErr.Code == domain.InscripcionErrEntitlementsUnavailable ||
+				inscripcionErr.Code == domain.InscripcionErrActivityNotFound {
+				return err
+			}
+			if err := s.remove(ctx, *next, inscripcionErr.Code); err != nil {
+				return err
+			}
+			continue
+		}
+		next.SuscripcionID = &suscripcion.ID
+
+		var promoted *domain.ListaEspera
+		hayLugar := true
+		err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+			var err error
+			promoted, hayLugar, err = s.repository.PromoteNext(ctx, *next, time.Now().Add(s.policy.ConfirmationWindow))
+			if err != nil || promoted == nil {
+				return err
+			}
+
+			if err := s.publish(ctx, domain.ListaEsperaEventPromoted, *promoted); err != nil {
+				return err
+			}
+			_, err = enqueueActividad(ctx, s.outbox, s.actividadesRepo, domain.ActividadEventUpdated, actividadID)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if !hayLugar {
+			return nil
+		}
+		if promoted == nil {
+			continue // La cola cambió mientras se validaba: se vuelve a leer el primero
+		}
+
+		log.Printf("⏳ Lista de espera: usuario %d promovido en actividad %d (confirma hasta %s)",
+			promoted.UsuarioID, promoted.ActividadID, promoted.ExpiraAt.Format(time.RFC3339))
+	}
+}
+
+// remove saca de la cola a un socio que ya no puede inscribirse y publica waitlist.removed con el motivo
+func (s *ListaEsperaServiceImpl) remove(ctx context.Context, entry domain.ListaEspera, motivo string) error {
+	removed := false
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		removed, err = s.repository.Remove(ctx, entry.ID)
+		if err != nil || !removed {
+			return err
+		}
+
+		entry.Estado = domain.ListaEsperaCancelado
+		data := listaEsperaEventData(entry)
+		data["motivo"] = motivo
+		return s.outbox.Enqueue(ctx, domain.ListaEsperaEventType, domain.ListaEsperaEventRemoved, strconv.FormatUint(uint64(entry.ID), 10), data)
+	})
+	if err != nil {
+		return err
+	}
+
+	if removed {
+		log.Printf("🚫 Lista de espera: usuario %d sale de la cola de la actividad %d (%s)", entry.UsuarioID, entry.ActividadID, motivo)
+	}
+	return nil
+}
+
+// ReleaseSpot se invoca cuando un usuario se desinscribe: cancela su promoción pendiente (si la tenía)
+// y asigna el lugar liberado al siguiente de la lista
+func (s *ListaEsperaServiceImpl) ReleaseSpot(ctx context.Context, usuarioID, actividadID uint) error {
+	if err := s.repository.DeclinePromotion(ctx, usuarioID, actividadID); err != nil {
+		return err
+	}
+
+	return s.PromoteWaiting(ctx, actividadID)
+}
+
+// ExpirePromotions vence las promociones no confirmadas a tiempo y pasa cada lugar al siguiente
+// Devuelve cuántas promociones venció
+func (s *ListaEsperaServiceImpl) ExpirePromotions(ctx context.Context) (int, error) {
+	entries, err := s.repository.ListExpired(ctx, time.Now(), expiredBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, entry := range entries {
+		entry.Estado = domain.ListaEsperaVencido
+		ok := false
+		err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+			var err error
+			ok, err = s.repository.Expire(ctx, entry.ID)
+			if err != nil || !ok {
+				return err
+			}
+
+			if err := s.publish(ctx, domain.ListaEsperaEventExpired, entry); err != nil {
+				return err
+			}
+			_, err = enqueueActividad(ctx, s.outbox, s.actividadesRepo, domain.ActividadEventUpdated, entry.ActividadID)
+			return err
+		})
+		if err != nil {
+			log.Printf("❌ Error venciendo promoción %d: %v", entry.ID, err)
+			continue
+		}
+		if !ok {
+			continue // La confirmó o salió de la lista mientras tanto
+		}
+		expired++
+
+		log.Printf("⌛ Lista de espera: venció la promoción del usuario %d en actividad %d", entry.UsuarioID, entry.ActividadID)
+
+		if err := s.PromoteWaiting(ctx, entry.ActividadID); err != nil {
+			log.Printf("⚠️  Warning: No se pudo promover la lista de espera de la actividad %d: %v", entry.ActividadID, err)
+		}
+	}
+
+	return expired, nil
+}
+
+// Start lanza en background el barrido periódico de promociones vencidas hasta que se cancele el contexto
+func (s *ListaEsperaServiceImpl) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.policy.SweepInterval)
+		defer ticker.Stop()
+
+		for {
+			// Vencer de a lotes antes de esperar al próximo tick
+			for {
+				expired, err := s.ExpirePromotions(ctx)
+				if err != nil {
+					log.Printf("❌ Error buscando promociones vencidas: %v", err)
+				}
+				if err != nil || expired < expiredBatchSize {
+					break
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	log.Printf("⏳ Lista de espera: barrido de promociones vencidas cada %s (ventana de confirmación %s)",
+		s.policy.SweepInterval, s.policy.ConfirmationWindow)
+}
+
+// publish encola waitlist.<action> (para que se notifique al socio) en la transacción del contexto
+func (s *ListaEsperaServiceImpl) publish(ctx context.Context, action string, entry domain.ListaEspera) error {
+	return s.outbox.Enqueue(ctx, domain.ListaEsperaEventType, action, strconv.FormatUint(uint64(entry.ID), 10), listaEsperaEventData(entry))
+}
+
+// listaEsperaEventData arma el data de los eventos waitlist.*
+func listaEsperaEventData(entry domain.ListaEspera) map[string]interface{} {
+	return map[string]interface{}{
+		"usuario_id":   strconv.FormatUint(uint64(entry.UsuarioID), 10),
+		"actividad_id": strconv.FormatUint(uint64(entry.ActividadID), 10),
+		"estado":       entry.Estado,
+		"expira_at":    entry.ExpiraAt,
+	}
+}
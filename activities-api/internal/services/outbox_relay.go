@@ -0,0 +1,94 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/repository"
+	"context"
+	"log"
+	"time"
+)
+
+// OutboxRelayPolicy define el ritmo del relay del outbox
+type OutboxRelayPolicy struct {
+	Interval  time.Duration // Cada cuánto se publican los eventos pendientes
+	BatchSize int           // Eventos por pasada
+	Retention time.Duration // Cuánto se conservan los eventos ya publicados
+}
+
+// OutboxRelay publica en RabbitMQ los eventos que los servicios encolaron en el outbox
+// Los eventos se publican en el orden en que se encolaron; si RabbitMQ falla quedan pendientes y se reintentan
+// hasta que vuelva. Solo los eventos inválidos quedan descartados en la tabla para revisarlos a mano
+type OutboxRelay struct {
+	repository repository.OutboxRepository
+	publisher  EventPublisher
+	policy     OutboxRelayPolicy
+}
+
+// NewOutboxRelay crea una nueva instancia del relay
+func NewOutboxRelay(repo repository.OutboxRepository, publisher EventPublisher, policy OutboxRelayPolicy) *OutboxRelay {
+	return &OutboxRelay{
+		repository: repo,
+		publisher:  publisher,
+		policy:     policy,
+	}
+}
+
+// RelayPending publica los eventos pendientes por lotes hasta vaciar el outbox o hasta el primer error
+// Devuelve cuántos publicó y cuántos descartó por inválidos
+func (r *OutboxRelay) RelayPending(ctx context.Context) (int, int, error) {
+	total, totalDescartados := 0, 0
+	for {
+		published, descartados, err := r.repository.RelayPending(ctx, r.policy.BatchSize, func(evento domain.OutboxEvento) error {
+			return r.publisher.PublishEvent(evento.EventType, evento.Action, evento.AggregateID, evento.Data)
+		})
+		total += published
+		totalDescartados += descartados
+		if err != nil || published+descartados < r.policy.BatchSize {
+			return total, totalDescartados, err
+		}
+	}
+}
+
+// Start lanza en background el relay periódico (y la limpieza de eventos publicados) hasta que se cancele el contexto
+func (r *OutboxRelay) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.policy.Interval)
+		defer ticker.Stop()
+
+		failing := false
+		lastPurge := time.Time{}
+		for {
+			// Mientras RabbitMQ no responda se loguea solo el primer error
+			published, descartados, err := r.RelayPending(ctx)
+			if err != nil && !failing {
+				log.Printf("❌ Error publicando eventos del outbox (se reintenta cada %s): %v", r.policy.Interval, err)
+			} else if err == nil && failing {
+				log.Printf("✅ Outbox: se reanudó la publicación de eventos")
+			}
+			failing = err != nil
+			if published > 0 {
+				log.Printf("📤 Outbox: %d eventos publicados", published)
+			}
+			if descartados > 0 {
+				log.Printf("⚠️  Outbox: %d eventos inválidos descartados (quedan en outbox_eventos con descartado_at)", descartados)
+			}
+
+			if time.Since(lastPurge) >= time.Hour {
+				lastPurge = time.Now()
+				if purged, err := r.repository.PurgePublished(ctx, time.Now().Add(-r.policy.Retention)); err != nil {
+					log.Printf("❌ Error limpiando el outbox: %v", err)
+				} else if purged > 0 {
+					log.Printf("🧹 Outbox: %d eventos publicados borrados", purged)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	log.Printf("📤 Relay del outbox cada %s (lotes de %d, retención %s)", r.policy.Interval, r.policy.BatchSize, r.policy.Retention)
+}
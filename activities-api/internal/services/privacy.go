@@ -0,0 +1,150 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/repository"
+	"context"
+	"fmt"
+)
+
+// PrivacyService define la interfaz del servicio que exporta y borra los datos de un socio
+// Lo invoca el consumidor de pedidos de privacidad que coordina users-api
+type PrivacyService interface {
+	ExportUserData(ctx context.Context, usuarioID uint) (map[string]interface{}, int, error)
+	EraseUserData(ctx context.Context, usuarioID uint) (int, error)
+}
+
+// PrivacyServiceImpl implementa PrivacyService
+type PrivacyServiceImpl struct {
+	inscripcionesRepo repository.InscripcionesRepository
+	actividadesRepo   repository.ActividadesRepository
+	listaEsperaRepo   repository.ListaEsperaRepository
+	sesionesRepo      repository.SesionesRepository
+	asistenciasRepo   repository.AsistenciasRepository
+	calendarioTokens  repository.CalendarioTokensRepository
+	tx                repository.Transactor
+	outbox            repository.OutboxRepository // activity.update con los lugares que libera el borrado
+}
+
+// NewPrivacyService crea una nueva instancia del servicio
+func NewPrivacyService(
+	inscripcionesRepo repository.InscripcionesRepository,
+	actividadesRepo repository.ActividadesRepository,
+	listaEsperaRepo repository.ListaEsperaRepository,
+	sesionesRepo repository.SesionesRepository,
+	asistenciasRepo repository.AsistenciasRepository,
+	calendarioTokens repository.CalendarioTokensRepository,
+	tx repository.Transactor,
+	outbox repository.OutboxRepository,
+) *PrivacyServiceImpl {
+	return &PrivacyServiceImpl{
+		inscripcionesRepo: inscripcionesRepo,
+		actividadesRepo:   actividadesRepo,
+		listaEsperaRepo:   listaEsperaRepo,
+		sesionesRepo:      sesionesRepo,
+		asistenciasRepo:   asistenciasRepo,
+		calendarioTokens:  calendarioTokens,
+		tx:                tx,
+		outbox:            outbox,
+	}
+}
+
+// ExportUserData devuelve las inscripciones del socio (activas e históricas) con el título de cada actividad
+// y su historial en listas de espera, sesiones, asistencias y suspensiones
+func (s *PrivacyServiceImpl) ExportUserData(ctx context.Context, usuarioID uint) (map[string]interface{}, int, error) {
+	inscripciones, err := s.inscripcionesRepo.ListByUser(ctx, usuarioID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error exporting inscripciones: %w", err)
+	}
+
+	responses := make([]domain.InscripcionResponse, len(inscripciones))
+	for i, insc := range inscripciones {
+		responses[i] = insc.ToResponse()
+		if actividad, err := s.actividadesRepo.GetByID(ctx, insc.ActividadID); err == nil {
+			titulo := actividad.Titulo
+			responses[i].ActividadTitulo = &titulo
+		}
+	}
+
+	listaEspera, err := s.listaEsperaRepo.ListByUser(ctx, usuarioID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error exporting lista de espera: %w", err)
+	}
+
+	inscripcionesSesion, err := s.sesionesRepo.ListInscripcionesByUser(ctx, usuarioID, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error exporting inscripciones a sesiones: %w", err)
+	}
+
+	asistencias, err := s.asistenciasRepo.ListByUser(ctx, usuarioID, nil, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error exporting asistencias: %w", err)
+	}
+	suspensiones, err := s.asistenciasRepo.ListSuspensionesByUser(ctx, usuarioID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error exporting suspensiones: %w", err)
+	}
+
+	return map[string]interface{}{
+		"inscripciones":        responses,
+		"lista_espera":         listaEspera,
+		"inscripciones_sesion": inscripcionesSesion,
+		"asistencias":          asistencias,
+		"suspensiones":         suspensiones,
+	}, len(responses) + len(listaEspera) + len(inscripcionesSesion) + len(asistencias) + len(suspensiones), nil
+}
+
+// EraseUserData borra las inscripciones (a actividades y a sesiones), las entradas de lista de espera,
+// las asistencias, las suspensiones y el token del calendario del socio
+// (no contienen datos que haya que conservar)
+func (s *PrivacyServiceImpl) EraseUserData(ctx context.Context, usuarioID uint) (int, error) {
+	inscripciones, err := s.inscripcionesRepo.ListByUser(ctx, usuarioID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Las inscripciones activas liberan su lugar: search-api recibe los lugares recalculados
+	var deleted int64
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		deleted, err = s.inscripcionesRepo.DeleteByUser(ctx, usuarioID)
+		if err != nil {
+			return err
+		}
+
+		for _, inscripcion := range inscripciones {
+			if !inscripcion.IsActiva {
+				continue
+			}
+			if _, err := enqueueActividad(ctx, s.outbox, s.actividadesRepo, domain.ActividadEventUpdated, inscripcion.ActividadID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	deletedEspera, err := s.listaEsperaRepo.DeleteByUser(ctx, usuarioID)
+	if err != nil {
+		return 0, err
+	}
+
+	deletedSesiones, err := s.sesionesRepo.DeleteInscripcionesByUser(ctx, usuarioID)
+	if err != nil {
+		return 0, err
+	}
+
+	deletedAsistencias, err := s.asistenciasRepo.DeleteByUser(ctx, usuarioID)
+	if err != nil {
+		return 0, err
+	}
+
+	deletedToken, err := s.calendarioTokens.Delete(ctx, usuarioID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted + deletedEspera + deletedSesiones + deletedAsistencias + deletedToken), nil
+}
@@ -0,0 +1,684 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/repository"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRangoSesiones es el rango máximo (en días) que se puede listar de una vez
+const maxRangoSesiones = 92
+
+// SesionesService define la interfaz del servicio de sesiones fechadas
+type SesionesService interface {
+	ListReglas(ctx context.Context, actividadID uint) ([]domain.ReglaRecurrencia, error)
+	CreateRegla(ctx context.Context, actor domain.Actor, actividadID uint, reglaCreate domain.ReglaRecurrenciaCreate) (domain.ReglaRecurrencia, error)
+	DeleteRegla(ctx context.Context, actor domain.Actor, id uint) error
+	List(ctx context.Context, filtro domain.SesionFiltro) ([]domain.Sesion, error)
+	GetByID(ctx context.Context, id uint) (domain.Sesion, error)
+	Create(ctx context.Context, actor domain.Actor, sesionCreate domain.SesionCreate) (domain.Sesion, error)
+	Update(ctx context.Context, actor domain.Actor, id uint, sesionUpdate domain.SesionUpdate) (domain.Sesion, error)
+	Cancel(ctx context.Context, actor domain.Actor, id uint, motivo string) (domain.Sesion, error)
+	Enroll(ctx context.Context, usuarioID, sesionID uint) (domain.InscripcionSesion, error)
+	Unenroll(ctx context.Context, usuarioID, sesionID uint) error
+	ListInscripcionesByUser(ctx context.Context, usuarioID uint) ([]domain.InscripcionSesion, error)
+}
+
+// SesionesPolicy define la generación de sesiones
+type SesionesPolicy struct {
+	HorizonteDias      int            // Hasta cuántos días hacia adelante se generan sesiones
+	GenerationInterval time.Duration  // Cada cuánto se extiende el horizonte
+	Location           *time.Location // Zona horaria del gimnasio (días y horarios de las reglas)
+}
+
+// SesionesServiceImpl implementa SesionesService
+type SesionesServiceImpl struct {
+	repository      repository.SesionesRepository
+	actividadesRepo repository.ActividadesRepository
+	sucursales      repository.SucursalesRepository
+	users           UserValidator
+	subscriptions   SubscriptionsClient
+	suspensions     SuspensionChecker // Opcional: nil si no se suspende por inasistencias
+	tx              repository.Transactor
+	outbox          repository.OutboxRepository // session.* se encola con cada reprogramación o cancelación
+	policy          SesionesPolicy
+}
+
+// NewSesionesService crea una nueva instancia del servicio
+func NewSesionesService(
+	repo repository.SesionesRepository,
+	actividadesRepo repository.ActividadesRepository,
+	sucursalesRepo repository.SucursalesRepository,
+	users UserValidator,
+	subscriptions SubscriptionsClient,
+	suspensions SuspensionChecker,
+	tx repository.Transactor,
+	outbox repository.OutboxRepository,
+	policy SesionesPolicy,
+) *SesionesServiceImpl {
+	return &SesionesServiceImpl{
+		repository:      repo,
+		actividadesRepo: actividadesRepo,
+		sucursales:      sucursalesRepo,
+		users:           users,
+		subscriptions:   subscriptions,
+		suspensions:     suspensions,
+		tx:              tx,
+		outbox:          outbox,
+		policy:          policy,
+	}
+}
+
+// ListReglas obtiene las reglas de recurrencia de una actividad
+func (s *SesionesServiceImpl) ListReglas(ctx context.Context, actividadID uint) ([]domain.ReglaRecurrencia, error) {
+	reglas, err := s.repository.ListReglasByActividad(ctx, actividadID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing reglas: %w", err)
+	}
+
+	return reglas, nil
+}
+
+// CreateRegla crea una regla de recurrencia y genera sus sesiones hasta el horizonte
+// Días y horarios vacíos toman los de la actividad
+func (s *SesionesServiceImpl) CreateRegla(ctx context.Context, actor domain.Actor, actividadID uint, reglaCreate domain.ReglaRecurrenciaCreate) (domain.ReglaRecurrencia, error) {
+	actividad, err := s.actividadesRepo.GetByID(ctx, actividadID)
+	if err != nil {
+		return domain.ReglaRecurrencia{}, fmt.Errorf("error creating regla: %w", err)
+	}
+	if err := authorizeSesiones(actor, actividad, false); err != nil {
+		return domain.ReglaRecurrencia{}, err
+	}
+
+	regla := domain.ReglaRecurrencia{
+		ActividadID:      actividadID,
+		Dias:             reglaCreate.Dias,
+		HorarioInicio:    strings.TrimSpace(reglaCreate.HorarioInicio),
+		HorarioFinal:     strings.TrimSpace(reglaCreate.HorarioFinal),
+		FechaDesde:       strings.TrimSpace(reglaCreate.FechaDesde),
+		FechaHasta:       reglaCreate.FechaHasta,
+		IntervaloSemanas: reglaCreate.IntervaloSemanas,
+		Excepciones:      reglaCreate.Excepciones,
+		Cupo:             reglaCreate.Cupo,
+	}
+	if len(regla.Dias) == 0 {
+		regla.Dias = []string{actividad.Dia}
+	}
+	if regla.HorarioInicio == "" && regla.HorarioFinal == "" {
+		regla.HorarioInicio = actividad.HorarioInicio
+		regla.HorarioFinal = actividad.HorarioFinal
+	}
+	if regla.FechaDesde == "" {
+		regla.FechaDesde = s.hoy().Format(domain.FormatoFecha)
+	}
+	if regla.IntervaloSemanas == 0 {
+		regla.IntervaloSemanas = 1
+	}
+
+	if err := validateRegla(regla); err != nil {
+		return domain.ReglaRecurrencia{}, err
+	}
+	if regla.Cupo != nil {
+		if err := checkSucursal(ctx, s.sucursales, actividad.SucursalID, *regla.Cupo); err != nil {
+			return domain.ReglaRecurrencia{}, err
+		}
+	}
+
+	createdRegla, err := s.repository.CreateRegla(ctx, regla)
+	if err != nil {
+		return domain.ReglaRecurrencia{}, fmt.Errorf("error creating regla: %w", err)
+	}
+
+	generadas, err := s.generateRegla(ctx, createdRegla, actividad.Cupo)
+	if err != nil {
+		// La regla queda creada: el generador periódico vuelve a intentar
+		log.Printf("⚠️  Warning: No se pudieron generar las sesiones de la regla %d: %v", createdRegla.ID, err)
+		return createdRegla, nil
+	}
+	log.Printf("📅 Regla %d de la actividad %d: %d sesiones generadas", createdRegla.ID, actividadID, generadas)
+
+	return s.repository.GetRegla(ctx, createdRegla.ID)
+}
+
+// DeleteRegla borra una regla y cancela sus sesiones futuras (las pasadas quedan como historial)
+func (s *SesionesServiceImpl) DeleteRegla(ctx context.Context, actor domain.Actor, id uint) error {
+	regla, err := s.repository.GetRegla(ctx, id)
+	if err != nil {
+		return err
+	}
+	actividad, err := s.actividadesRepo.GetByID(ctx, regla.ActividadID)
+	if err != nil {
+		return fmt.Errorf("error deleting regla: %w", err)
+	}
+	if err := authorizeSesiones(actor, actividad, false); err != nil {
+		return err
+	}
+
+	canceladas, err := s.repository.DeleteRegla(ctx, id, time.Now(), "Se eliminó la recurrencia de la actividad")
+	if err != nil {
+		return err
+	}
+	log.Printf("📅 Regla %d eliminada: %d sesiones futuras canceladas", id, canceladas)
+
+	return nil
+}
+
+// List obtiene las sesiones de un rango de fechas (por defecto, la semana que empieza hoy)
+func (s *SesionesServiceImpl) List(ctx context.Context, filtro domain.SesionFiltro) ([]domain.Sesion, error) {
+	if filtro.FechaDesde == "" {
+		filtro.FechaDesde = s.hoy().Format(domain.FormatoFecha)
+	}
+	desde, err := time.ParseInLocation(domain.FormatoFecha, filtro.FechaDesde, s.policy.Location)
+	if err != nil {
+		return nil, fmt.Errorf("formato de desde inválido (debe ser YYYY-MM-DD): %s", filtro.FechaDesde)
+	}
+
+	hasta := desde.AddDate(0, 0, 7)
+	if filtro.FechaHasta != "" {
+		fechaHasta, err := time.ParseInLocation(domain.FormatoFecha, filtro.FechaHasta, s.policy.Location)
+		if err != nil {
+			return nil, fmt.Errorf("formato de hasta inválido (debe ser YYYY-MM-DD): %s", filtro.FechaHasta)
+		}
+		hasta = fechaHasta.AddDate(0, 0, 1) // Inclusive
+	}
+	if !hasta.After(desde) {
+		return nil, fmt.Errorf("hasta no puede ser anterior a desde")
+	}
+	if hasta.Sub(desde) > maxRangoSesiones*24*time.Hour {
+		return nil, fmt.Errorf("el rango no puede superar %d días", maxRangoSesiones)
+	}
+
+	sesiones, err := s.repository.List(ctx, filtro, desde, hasta)
+	if err != nil {
+		return nil, fmt.Errorf("error listing sesiones: %w", err)
+	}
+
+	return sesiones, nil
+}
+
+// GetByID obtiene una sesión por ID
+func (s *SesionesServiceImpl) GetByID(ctx context.Context, id uint) (domain.Sesion, error) {
+	sesion, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return domain.Sesion{}, fmt.Errorf("sesion con ID %d no encontrada: %w", id, err)
+	}
+
+	return sesion, nil
+}
+
+// Create crea una sesión suelta (fuera de las reglas de recurrencia)
+func (s *SesionesServiceImpl) Create(ctx context.Context, actor domain.Actor, sesionCreate domain.SesionCreate) (domain.Sesion, error) {
+	actividad, err := s.actividadesRepo.GetByID(ctx, sesionCreate.ActividadID)
+	if err != nil {
+		return domain.Sesion{}, fmt.Errorf("error creating sesion: %w", err)
+	}
+	if err := authorizeSesiones(actor, actividad, false); err != nil {
+		return domain.Sesion{}, err
+	}
+
+	inicio, fin, err := s.parseSesion(sesionCreate.Fecha, sesionCreate.HorarioInicio, sesionCreate.HorarioFinal)
+	if err != nil {
+		return domain.Sesion{}, err
+	}
+
+	cupo := actividad.Cupo
+	if sesionCreate.Cupo != nil {
+		cupo = *sesionCreate.Cupo
+	}
+	if err := checkSucursal(ctx, s.sucursales, actividad.SucursalID, cupo); err != nil {
+		return domain.Sesion{}, err
+	}
+
+	createdSesion, err := s.repository.Create(ctx, domain.Sesion{
+		ActividadID: actividad.ID,
+		Fecha:       sesionCreate.Fecha,
+		Inicio:      inicio,
+		Fin:         fin,
+		Cupo:        cupo,
+	})
+	if err != nil {
+		return domain.Sesion{}, fmt.Errorf("error creating sesion: %w", err)
+	}
+
+	return createdSesion, nil
+}
+
+// Update reprograma una sesión y/o cambia su cupo (el instructor puede hacerlo con las suyas)
+func (s *SesionesServiceImpl) Update(ctx context.Context, actor domain.Actor, id uint, sesionUpdate domain.SesionUpdate) (domain.Sesion, error) {
+	existing, actividad, err := s.getSesionActividad(ctx, id)
+	if err != nil {
+		return domain.Sesion{}, err
+	}
+	if err := authorizeSesiones(actor, actividad, true); err != nil {
+		return domain.Sesion{}, err
+	}
+	if existing.Estado == domain.SesionCancelada {
+		return domain.Sesion{}, fmt.Errorf("no se puede modificar una sesión cancelada")
+	}
+
+	inicio, fin, err := s.parseSesion(sesionUpdate.Fecha, sesionUpdate.HorarioInicio, sesionUpdate.HorarioFinal)
+	if err != nil {
+		return domain.Sesion{}, err
+	}
+	if err := checkSucursal(ctx, s.sucursales, actividad.SucursalID, sesionUpdate.Cupo); err != nil {
+		return domain.Sesion{}, err
+	}
+
+	var updatedSesion domain.Sesion
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		updatedSesion, err = s.repository.Update(ctx, id, inicio, fin, sesionUpdate.Cupo)
+		if err != nil {
+			return err
+		}
+
+		if !updatedSesion.Inicio.Equal(existing.Inicio) || !updatedSesion.Fin.Equal(existing.Fin) {
+			return s.publish(ctx, domain.SesionEventRescheduled, updatedSesion)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Sesion{}, err
+	}
+
+	return updatedSesion, nil
+}
+
+// Cancel cancela una sesión puntual (el instructor puede cancelar las suyas)
+func (s *SesionesServiceImpl) Cancel(ctx context.Context, actor domain.Actor, id uint, motivo string) (domain.Sesion, error) {
+	_, actividad, err := s.getSesionActividad(ctx, id)
+	if err != nil {
+		return domain.Sesion{}, err
+	}
+	if err := authorizeSesiones(actor, actividad, true); err != nil {
+		return domain.Sesion{}, err
+	}
+
+	var cancelledSesion domain.Sesion
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		cancelledSesion, err = s.repository.Cancel(ctx, id, strings.TrimSpace(motivo))
+		if err != nil {
+			return err
+		}
+
+		return s.publish(ctx, domain.SesionEventCancelled, cancelledSesion)
+	})
+	if err != nil {
+		return domain.Sesion{}, err
+	}
+
+	return cancelledSesion, nil
+}
+
+// Enroll inscribe a un usuario en una sesión puntual
+// Aplican las mismas validaciones que al inscribirse a la actividad (usuario, suscripción activa y plan)
+func (s *SesionesServiceImpl) Enroll(ctx context.Context, usuarioID, sesionID uint) (domain.InscripcionSesion, error) {
+	sesion, err := s.repository.GetByID(ctx, sesionID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return domain.InscripcionSesion{}, inscripcionError(domain.InscripcionErrSessionNotFound, "La sesión no existe", nil)
+		}
+		return domain.InscripcionSesion{}, fmt.Errorf("error getting sesion: %w", err)
+	}
+	if sesion.Estado == domain.SesionCancelada || !sesion.Inicio.After(time.Now()) {
+		return domain.InscripcionSesion{}, inscripcionError(domain.InscripcionErrSessionNotAvailable, "La sesión está cancelada o ya comenzó", nil)
+	}
+
+	_, suscripcion, err := checkEntitlements(ctx, s.actividadesRepo, s.users, s.subscriptions, s.suspensions, usuarioID, sesion.ActividadID)
+	if err != nil {
+		return domain.InscripcionSesion{}, err
+	}
+
+	inscripcion, err := s.repository.Enroll(ctx, domain.InscripcionSesion{
+		SesionID:      sesionID,
+		UsuarioID:     usuarioID,
+		SuscripcionID: &suscripcion.ID,
+	}, time.Now())
+	if err != nil {
+		errString := err.Error()
+		switch {
+		case strings.Contains(errString, "ya está inscripto"):
+			return domain.InscripcionSesion{}, inscripcionError(domain.InscripcionErrAlreadyEnrolled, "El usuario ya está inscripto a esta sesión", nil)
+		case strings.Contains(errString, "cupo de la sesión ha sido alcanzado"):
+			return domain.InscripcionSesion{}, inscripcionError(domain.InscripcionErrActivityFull, "No se puede inscribir, el cupo de la sesión ha sido alcanzado", nil)
+		case strings.Contains(errString, "cancelada"), strings.Contains(errString, "ya comenzó"):
+			return domain.InscripcionSesion{}, inscripcionError(domain.InscripcionErrSessionNotAvailable, "La sesión está cancelada o ya comenzó", nil)
+		case strings.Contains(errString, "not found"):
+			return domain.InscripcionSesion{}, inscripcionError(domain.InscripcionErrSessionNotFound, "La sesión no existe", nil)
+		}
+		return domain.InscripcionSesion{}, fmt.Errorf("error enrolling in sesion: %w", err)
+	}
+
+	inscripcion.Sesion = &sesion
+	return inscripcion, nil
+}
+
+// Unenroll desinscribe a un usuario de una sesión puntual
+func (s *SesionesServiceImpl) Unenroll(ctx context.Context, usuarioID, sesionID uint) error {
+	if err := s.repository.Unenroll(ctx, usuarioID, sesionID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return inscripcionError(domain.InscripcionErrNotEnrolled, "El usuario no está inscripto a esta sesión", nil)
+		}
+		return fmt.Errorf("error unenrolling from sesion: %w", err)
+	}
+
+	return nil
+}
+
+// ListInscripcionesByUser obtiene las inscripciones del usuario a sesiones desde hoy
+func (s *SesionesServiceImpl) ListInscripcionesByUser(ctx context.Context, usuarioID uint) ([]domain.InscripcionSesion, error) {
+	desde := s.hoy()
+	inscripciones, err := s.repository.ListInscripcionesByUser(ctx, usuarioID, &desde)
+	if err != nil {
+		return nil, fmt.Errorf("error listing inscripciones a sesiones: %w", err)
+	}
+
+	return inscripciones, nil
+}
+
+// GenerateUpcoming extiende todas las reglas vigentes hasta el horizonte; devuelve cuántas sesiones creó
+func (s *SesionesServiceImpl) GenerateUpcoming(ctx context.Context) (int64, error) {
+	reglas, err := s.repository.ListReglasVigentes(ctx, s.hoy().Format(domain.FormatoFecha))
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, regla := range reglas {
+		actividad, err := s.actividadesRepo.GetByID(ctx, regla.ActividadID)
+		if err != nil {
+			log.Printf("❌ Error generando sesiones de la regla %d: %v", regla.ID, err)
+			continue
+		}
+
+		generadas, err := s.generateRegla(ctx, regla, actividad.Cupo)
+		if err != nil {
+			log.Printf("❌ Error generando sesiones de la regla %d: %v", regla.ID, err)
+			continue
+		}
+		total += generadas
+	}
+
+	return total, nil
+}
+
+// Start lanza en background la generación periódica de sesiones hasta que se cancele el contexto
+func (s *SesionesServiceImpl) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.policy.GenerationInterval)
+		defer ticker.Stop()
+
+		for {
+			generadas, err := s.GenerateUpcoming(ctx)
+			if err != nil {
+				log.Printf("❌ Error generando sesiones: %v", err)
+			} else if generadas > 0 {
+				log.Printf("📅 %d sesiones nuevas generadas", generadas)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	log.Printf("📅 Generación de sesiones cada %s (horizonte %d días)", s.policy.GenerationInterval, s.policy.HorizonteDias)
+}
+
+// generateRegla genera las sesiones de la regla que faltan hasta el horizonte (nunca en el pasado)
+func (s *SesionesServiceImpl) generateRegla(ctx context.Context, regla domain.ReglaRecurrencia, cupoActividad uint) (int64, error) {
+	hoy := s.hoy().Format(domain.FormatoFecha)
+
+	desde := maxFecha(regla.FechaDesde, hoy)
+	if regla.GeneradaHasta != nil {
+		siguiente, err := sumarDias(*regla.GeneradaHasta, 1)
+		if err != nil {
+			return 0, err
+		}
+		desde = maxFecha(desde, siguiente)
+	}
+
+	hasta, err := sumarDias(hoy, s.policy.HorizonteDias)
+	if err != nil {
+		return 0, err
+	}
+	if regla.FechaHasta != nil && *regla.FechaHasta < hasta {
+		hasta = *regla.FechaHasta
+	}
+	if desde > hasta {
+		return 0, nil
+	}
+
+	cupo := cupoActividad
+	if regla.Cupo != nil {
+		cupo = *regla.Cupo
+	}
+
+	fechas, err := ocurrencias(regla, desde, hasta)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	sesiones := make([]domain.Sesion, 0, len(fechas))
+	for _, fecha := range fechas {
+		inicio, fin, err := s.parseSesion(fecha, regla.HorarioInicio, regla.HorarioFinal)
+		if err != nil {
+			return 0, err
+		}
+		if !inicio.After(now) {
+			continue
+		}
+
+		reglaID := regla.ID
+		sesiones = append(sesiones, domain.Sesion{
+			ActividadID: regla.ActividadID,
+			ReglaID:     &reglaID,
+			Fecha:       fecha,
+			Inicio:      inicio,
+			Fin:         fin,
+			Cupo:        cupo,
+		})
+	}
+
+	return s.repository.SaveGeneradas(ctx, regla.ID, sesiones, hasta)
+}
+
+// getSesionActividad obtiene la sesión y su actividad (para autorizar)
+func (s *SesionesServiceImpl) getSesionActividad(ctx context.Context, id uint) (domain.Sesion, domain.Actividad, error) {
+	sesion, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return domain.Sesion{}, domain.Actividad{}, err
+	}
+
+	actividad, err := s.actividadesRepo.GetByID(ctx, sesion.ActividadID)
+	if err != nil {
+		return domain.Sesion{}, domain.Actividad{}, fmt.Errorf("error getting actividad: %w", err)
+	}
+
+	return sesion, actividad, nil
+}
+
+// parseSesion arma el inicio y el fin de una sesión a partir de la fecha y los horarios (zona del gimnasio)
+func (s *SesionesServiceImpl) parseSesion(fecha, horaInicio, horaFin string) (time.Time, time.Time, error) {
+	if _, err := time.Parse(domain.FormatoFecha, fecha); err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("formato de fecha inválido (debe ser YYYY-MM-DD): %s", fecha)
+	}
+
+	inicio, err := time.ParseInLocation(domain.FormatoFecha+" 15:04", fecha+" "+horaInicio, s.policy.Location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("formato de hora inicio inválido (debe ser HH:MM): %s", horaInicio)
+	}
+	fin, err := time.ParseInLocation(domain.FormatoFecha+" 15:04", fecha+" "+horaFin, s.policy.Location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("formato de hora fin inválido (debe ser HH:MM): %s", horaFin)
+	}
+
+	if !fin.After(inicio) {
+		return time.Time{}, time.Time{}, fmt.Errorf("la hora de fin debe ser posterior a la hora de inicio")
+	}
+
+	return inicio, fin, nil
+}
+
+// hoy devuelve el comienzo del día actual en la zona del gimnasio
+func (s *SesionesServiceImpl) hoy() time.Time {
+	now := time.Now().In(s.policy.Location)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.policy.Location)
+}
+
+// publish encola session.<action> (para avisar a los inscriptos) en la transacción del contexto
+func (s *SesionesServiceImpl) publish(ctx context.Context, action string, sesion domain.Sesion) error {
+	id := strconv.FormatUint(uint64(sesion.ID), 10)
+	data := map[string]interface{}{
+		"sesion_id":          id,
+		"actividad_id":       strconv.FormatUint(uint64(sesion.ActividadID), 10),
+		"actividad_titulo":   sesion.ActividadTitulo,
+		"inicio":             sesion.Inicio,
+		"fin":                sesion.Fin,
+		"estado":             sesion.Estado,
+		"motivo_cancelacion": sesion.MotivoCancelacion,
+	}
+
+	return s.outbox.Enqueue(ctx, domain.SesionEventType, action, id, data)
+}
+
+// authorizeSesiones verifica que el actor pueda gestionar las sesiones de la actividad
+// allowInstructor habilita al instructor de la actividad (activities:update:own) para sesiones puntuales
+func authorizeSesiones(actor domain.Actor, actividad domain.Actividad, allowInstructor bool) error {
+	if actor.Can(domain.PermissionActivitiesManage) {
+		return nil
+	}
+	if actor.Can(domain.PermissionActivitiesManageSucursal) && actor.InSucursal(actividad.SucursalID) {
+		return nil
+	}
+	if allowInstructor && actor.Can(domain.PermissionActivitiesUpdateOwn) &&
+		actividad.InstructorID != nil && *actividad.InstructorID == actor.UsuarioID {
+		return nil
+	}
+
+	return forbidden("no tenés permiso para gestionar las sesiones de esta actividad")
+}
+
+// validateRegla valida los datos de una regla que no cubren los tags de binding
+func validateRegla(regla domain.ReglaRecurrencia) error {
+	dias := make(map[string]bool)
+	for _, dia := range regla.Dias {
+		if !isDiaSemana(dia) {
+			return fmt.Errorf("día inválido: %s", dia)
+		}
+		if dias[dia] {
+			return fmt.Errorf("el día %s está repetido", dia)
+		}
+		dias[dia] = true
+	}
+
+	inicio, err := time.Parse("15:04", regla.HorarioInicio)
+	if err != nil {
+		return fmt.Errorf("formato de hora inicio inválido (debe ser HH:MM): %s", regla.HorarioInicio)
+	}
+	fin, err := time.Parse("15:04", regla.HorarioFinal)
+	if err != nil {
+		return fmt.Errorf("formato de hora fin inválido (debe ser HH:MM): %s", regla.HorarioFinal)
+	}
+	if !fin.After(inicio) {
+		return fmt.Errorf("la hora de fin debe ser posterior a la hora de inicio")
+	}
+
+	if _, err := time.Parse(domain.FormatoFecha, regla.FechaDesde); err != nil {
+		return fmt.Errorf("formato de fecha_desde inválido (debe ser YYYY-MM-DD): %s", regla.FechaDesde)
+	}
+	if regla.FechaHasta != nil {
+		if _, err := time.Parse(domain.FormatoFecha, *regla.FechaHasta); err != nil {
+			return fmt.Errorf("formato de fecha_hasta inválido (debe ser YYYY-MM-DD): %s", *regla.FechaHasta)
+		}
+		if *regla.FechaHasta < regla.FechaDesde {
+			return fmt.Errorf("fecha_hasta no puede ser anterior a fecha_desde")
+		}
+	}
+
+	for _, excepcion := range regla.Excepciones {
+		if _, err := time.Parse(domain.FormatoFecha, excepcion); err != nil {
+			return fmt.Errorf("formato de excepción inválido (debe ser YYYY-MM-DD): %s", excepcion)
+		}
+	}
+
+	return nil
+}
+
+// ocurrencias devuelve las fechas (YYYY-MM-DD) entre desde y hasta inclusive en las que la regla tiene clase
+// Las semanas se cuentan de lunes a domingo a partir de la semana de fecha_desde
+func ocurrencias(regla domain.ReglaRecurrencia, desde, hasta string) ([]string, error) {
+	inicioRegla, err := time.Parse(domain.FormatoFecha, regla.FechaDesde)
+	if err != nil {
+		return nil, err
+	}
+	desdeFecha, err := time.Parse(domain.FormatoFecha, desde)
+	if err != nil {
+		return nil, err
+	}
+	hastaFecha, err := time.Parse(domain.FormatoFecha, hasta)
+	if err != nil {
+		return nil, err
+	}
+
+	dias := make(map[string]bool, len(regla.Dias))
+	for _, dia := range regla.Dias {
+		dias[dia] = true
+	}
+	excepciones := make(map[string]bool, len(regla.Excepciones))
+	for _, excepcion := range regla.Excepciones {
+		excepciones[excepcion] = true
+	}
+
+	intervalo := int(regla.IntervaloSemanas)
+	if intervalo == 0 {
+		intervalo = 1
+	}
+	primerLunes := inicioRegla.AddDate(0, 0, -diaSemanaIndex(inicioRegla))
+
+	var fechas []string
+	for d := desdeFecha; !d.After(hastaFecha); d = d.AddDate(0, 0, 1) {
+		fecha := d.Format(domain.FormatoFecha)
+		if !dias[diasSemana[diaSemanaIndex(d)]] || excepciones[fecha] {
+			continue
+		}
+
+		semana := int(d.Sub(primerLunes).Hours()/24) / 7
+		if semana%intervalo != 0 {
+			continue
+		}
+
+		fechas = append(fechas, fecha)
+	}
+
+	return fechas, nil
+}
+
+// diaSemanaIndex devuelve el índice del día en diasSemana (0 = Lunes)
+func diaSemanaIndex(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+// sumarDias suma días a una fecha YYYY-MM-DD
+func sumarDias(fecha string, dias int) (string, error) {
+	t, err := time.Parse(domain.FormatoFecha, fecha)
+	if err != nil {
+		return "", err
+	}
+	return t.AddDate(0, 0, dias).Format(domain.FormatoFecha), nil
+}
+
+// maxFecha devuelve la mayor de dos fechas YYYY-MM-DD (se comparan como texto)
+func maxFecha(a, b string) string {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/repository"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeSesiones guarda lo que recibe SaveGeneradas (el resto del repository no se usa)
+type fakeSesiones struct {
+	repository.SesionesRepository
+	sesiones      []domain.Sesion
+	generadaHasta string
+}
+
+func (f *fakeSesiones) SaveGeneradas(ctx context.Context, reglaID uint, sesiones []domain.Sesion, generadaHasta string) (int64, error) {
+	f.sesiones = sesiones
+	f.generadaHasta = generadaHasta
+	return int64(len(sesiones)), nil
+}
+
+func strPtr(v string) *string {
+	return &v
+}
+
+// TestOcurrencias verifica la expansión de la regla: días de la semana, semanas por medio contadas
+// desde la semana de fecha_desde (de lunes a domingo) y excepciones
+func TestOcurrencias(t *testing.T) {
+	tests := []struct {
+		name  string
+		regla domain.ReglaRecurrencia
+		desde string
+		hasta string
+		want  []string
+	}{
+		{
+			name:  "lunes y miércoles todas las semanas",
+			regla: domain.ReglaRecurrencia{Dias: []string{"Lunes", "Miercoles"}, FechaDesde: "2026-03-02", IntervaloSemanas: 1},
+			desde: "2026-03-02",
+			hasta: "2026-03-15",
+			want:  []string{"2026-03-02", "2026-03-04", "2026-03-09", "2026-03-11"},
+		},
+		{
+			name:  "intervalo cero equivale a todas las semanas",
+			regla: domain.ReglaRecurrencia{Dias: []string{"Viernes"}, FechaDesde: "2026-03-02"},
+			desde: "2026-03-02",
+			hasta: "2026-03-15",
+			want:  []string{"2026-03-06", "2026-03-13"},
+		},
+		{
+			name:  "semana por medio desde la semana de fecha_desde",
+			regla: domain.ReglaRecurrencia{Dias: []string{"Martes"}, FechaDesde: "2026-03-05", IntervaloSemanas: 2},
+			desde: "2026-03-05",
+			hasta: "2026-03-31",
+			want:  []string{"2026-03-17", "2026-03-31"},
+		},
+		{
+			name:  "semana por medio con el rango empezando a mitad de la regla",
+			regla: domain.ReglaRecurrencia{Dias: []string{"Domingo"}, FechaDesde: "2026-03-02", IntervaloSemanas: 2},
+			desde: "2026-03-10",
+			hasta: "2026-04-06",
+			want:  []string{"2026-03-22", "2026-04-05"},
+		},
+		{
+			name:  "excepciones (feriados)",
+			regla: domain.ReglaRecurrencia{Dias: []string{"Lunes"}, FechaDesde: "2026-03-02", IntervaloSemanas: 1, Excepciones: []string{"2026-03-23"}},
+			desde: "2026-03-16",
+			hasta: "2026-03-30",
+			want:  []string{"2026-03-16", "2026-03-30"},
+		},
+		{
+			name:  "cambio de año",
+			regla: domain.ReglaRecurrencia{Dias: []string{"Jueves"}, FechaDesde: "2026-12-01", IntervaloSemanas: 3},
+			desde: "2026-12-01",
+			hasta: "2027-01-31",
+			want:  []string{"2026-12-03", "2026-12-24", "2027-01-14"},
+		},
+		{
+			name:  "rango sin días de la regla",
+			regla: domain.ReglaRecurrencia{Dias: []string{"Sabado"}, FechaDesde: "2026-03-02", IntervaloSemanas: 1},
+			desde: "2026-03-02",
+			hasta: "2026-03-06",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ocurrencias(tt.regla, tt.desde, tt.hasta)
+			if err != nil {
+				t.Fatalf("ocurrencias() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ocurrencias() = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOcurrenciasFechaInvalida verifica que una fecha mal formada se rechaza
+func TestOcurrenciasFechaInvalida(t *testing.T) {
+	regla := domain.ReglaRecurrencia{Dias: []string{"Lunes"}, FechaDesde: "2026-03-02"}
+	if _, err := ocurrencias(regla, "2026-03-02", "02/04/2026"); err == nil {
+		t.Fatal("se esperaba error para una fecha inválida")
+	}
+}
+
+// TestGenerateRegla verifica que la generación respeta el horizonte, fecha_hasta, lo ya generado y el cupo de la regla
+func TestGenerateRegla(t *testing.T) {
+	loc := time.FixedZone("ART", -3*60*60)
+	now := time.Now().In(loc)
+	hoy := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	fecha := func(dias int) string {
+		return hoy.AddDate(0, 0, dias).Format(domain.FormatoFecha)
+	}
+	todos := []string{"Lunes", "Martes", "Miercoles", "Jueves", "Viernes", "Sabado", "Domingo"}
+	cupoRegla := uint(5)
+
+	tests := []struct {
+		name       string
+		regla      domain.ReglaRecurrencia
+		wantFechas []string
+		wantHasta  string
+		wantCupo   uint
+	}{
+		{
+			name:       "hasta el horizonte",
+			regla:      domain.ReglaRecurrencia{Dias: todos, HorarioInicio: "23:58", HorarioFinal: "23:59", FechaDesde: fecha(-10), IntervaloSemanas: 1},
+			wantFechas: []string{fecha(1), fecha(2), fecha(3)},
+			wantHasta:  fecha(3),
+			wantCupo:   20,
+		},
+		{
+			name:       "corta en fecha_hasta",
+			regla:      domain.ReglaRecurrencia{Dias: todos, HorarioInicio: "10:00", HorarioFinal: "11:00", FechaDesde: fecha(1), FechaHasta: strPtr(fecha(2)), IntervaloSemanas: 1},
+			wantFechas: []string{fecha(1), fecha(2)},
+			wantHasta:  fecha(2),
+			wantCupo:   20,
+		},
+		{
+			name:       "sigue desde lo ya generado con el cupo de la regla",
+			regla:      domain.ReglaRecurrencia{Dias: todos, HorarioInicio: "10:00", HorarioFinal: "11:00", FechaDesde: fecha(-10), GeneradaHasta: strPtr(fecha(2)), IntervaloSemanas: 1, Cupo: &cupoRegla},
+			wantFechas: []string{fecha(3)},
+			wantHasta:  fecha(3),
+			wantCupo:   cupoRegla,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSesiones{}
+			s := NewSesionesService(repo, nil, nil, nil, nil, nil, nil, nil, SesionesPolicy{HorizonteDias: 3, Location: loc})
+
+			if _, err := s.generateRegla(context.Background(), tt.regla, 20); err != nil {
+				t.Fatalf("generateRegla() error = %v", err)
+			}
+
+			fechas := make([]string, len(repo.sesiones))
+			for i, sesion := range repo.sesiones {
+				fechas[i] = sesion.Fecha
+				if sesion.Cupo != tt.wantCupo {
+					t.Errorf("sesión %s con cupo %d, se esperaba %d", sesion.Fecha, sesion.Cupo, tt.wantCupo)
+				}
+				if sesion.Inicio.Location() != loc || sesion.Inicio.Format(domain.FormatoFecha+" 15:04") != sesion.Fecha+" "+tt.regla.HorarioInicio {
+					t.Errorf("sesión %s empieza %s, se esperaba a las %s en la zona del gimnasio", sesion.Fecha, sesion.Inicio, tt.regla.HorarioInicio)
+				}
+			}
+			// Hoy puede quedar fuera si el horario ya pasó: se compara desde mañana
+			if len(fechas) > 0 && fechas[0] == fecha(0) {
+				fechas = fechas[1:]
+			}
+			if !reflect.DeepEqual(fechas, tt.wantFechas) {
+				t.Fatalf("fechas generadas = %v, se esperaba %v", fechas, tt.wantFechas)
+			}
+			if repo.generadaHasta != tt.wantHasta {
+				t.Fatalf("generada_hasta = %s, se esperaba %s", repo.generadaHasta, tt.wantHasta)
+			}
+		})
+	}
+}
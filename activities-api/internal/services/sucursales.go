@@ -0,0 +1,222 @@
+package services
+
+import (
+	"activities-api/internal/domain"
+	"activities-api/internal/repository"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// diasSemana son los valores válidos de día (mismo enum que actividades.dia)
+var diasSemana = []string{"Lunes", "Martes", "Miercoles", "Jueves", "Viernes", "Sabado", "Domingo"}
+
+// SucursalesService define la interfaz del servicio de sucursales
+type SucursalesService interface {
+	List(ctx context.Context) ([]domain.Sucursal, error)
+	GetByID(ctx context.Context, id uint) (domain.Sucursal, error)
+	Create(ctx context.Context, actor domain.Actor, sucursalCreate domain.SucursalCreate) (domain.Sucursal, error)
+	Update(ctx context.Context, actor domain.Actor, id uint, sucursalUpdate domain.SucursalUpdate) (domain.Sucursal, error)
+	Delete(ctx context.Context, actor domain.Actor, id uint) error
+}
+
+// SucursalesServiceImpl implementa SucursalesService
+type SucursalesServiceImpl struct {
+	repository repository.SucursalesRepository
+	events     EventPublisher // Opcional: nil si no se publican eventos (en main es el outbox)
+}
+
+// NewSucursalesService crea una nueva instancia del servicio
+func NewSucursalesService(repo repository.SucursalesRepository, events EventPublisher) *SucursalesServiceImpl {
+	return &SucursalesServiceImpl{
+		repository: repo,
+		events:     events,
+	}
+}
+
+// List obtiene todas las sucursales
+func (s *SucursalesServiceImpl) List(ctx context.Context) ([]domain.Sucursal, error) {
+	sucursales, err := s.repository.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing sucursales: %w", err)
+	}
+
+	return sucursales, nil
+}
+
+// GetByID obtiene una sucursal por ID
+func (s *SucursalesServiceImpl) GetByID(ctx context.Context, id uint) (domain.Sucursal, error) {
+	sucursal, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return domain.Sucursal{}, fmt.Errorf("sucursal con ID %d no encontrada: %w", id, err)
+	}
+
+	return sucursal, nil
+}
+
+// Create crea una nueva sucursal (solo activities:manage)
+func (s *SucursalesServiceImpl) Create(ctx context.Context, actor domain.Actor, sucursalCreate domain.SucursalCreate) (domain.Sucursal, error) {
+	if !actor.Can(domain.PermissionActivitiesManage) {
+		return domain.Sucursal{}, forbidden("solo un administrador puede crear sucursales")
+	}
+
+	sucursal := domain.Sucursal{
+		Nombre:    strings.TrimSpace(sucursalCreate.Nombre),
+		Direccion: strings.TrimSpace(sucursalCreate.Direccion),
+		Telefono:  strings.TrimSpace(sucursalCreate.Telefono),
+		Horarios:  sucursalCreate.Horarios,
+		Capacidad: sucursalCreate.Capacidad,
+		Latitud:   sucursalCreate.Latitud,
+		Longitud:  sucursalCreate.Longitud,
+	}
+	if err := validateSucursal(sucursal); err != nil {
+		return domain.Sucursal{}, err
+	}
+
+	createdSucursal, err := s.repository.Create(ctx, sucursal)
+	if err != nil {
+		return domain.Sucursal{}, fmt.Errorf("error creating sucursal: %w", err)
+	}
+
+	s.publish(domain.SucursalEventCreated, createdSucursal)
+
+	return createdSucursal, nil
+}
+
+// Update reemplaza los datos de una sucursal
+// El gerente de sucursal (activities:manage:sucursal) solo puede modificar las suyas
+func (s *SucursalesServiceImpl) Update(ctx context.Context, actor domain.Actor, id uint, sucursalUpdate domain.SucursalUpdate) (domain.Sucursal, error) {
+	if !actor.Can(domain.PermissionActivitiesManage) &&
+		(!actor.Can(domain.PermissionActivitiesManageSucursal) || !actor.InSucursal(&id)) {
+		return domain.Sucursal{}, forbidden("solo podés modificar tus sucursales")
+	}
+
+	sucursal := domain.Sucursal{
+		Nombre:    strings.TrimSpace(sucursalUpdate.Nombre),
+		Direccion: strings.TrimSpace(sucursalUpdate.Direccion),
+		Telefono:  strings.TrimSpace(sucursalUpdate.Telefono),
+		Horarios:  sucursalUpdate.Horarios,
+		Capacidad: sucursalUpdate.Capacidad,
+		Latitud:   sucursalUpdate.Latitud,
+		Longitud:  sucursalUpdate.Longitud,
+	}
+	if err := validateSucursal(sucursal); err != nil {
+		return domain.Sucursal{}, err
+	}
+
+	// La capacidad no puede quedar por debajo del cupo de sus actividades
+	maxCupo, err := s.repository.MaxCupoActividades(ctx, id)
+	if err != nil {
+		return domain.Sucursal{}, fmt.Errorf("error updating sucursal: %w", err)
+	}
+	if sucursal.Capacidad < maxCupo {
+		return domain.Sucursal{}, fmt.Errorf("la capacidad de la sucursal no puede ser menor al cupo de sus actividades (%d)", maxCupo)
+	}
+
+	updatedSucursal, err := s.repository.Update(ctx, id, sucursal)
+	if err != nil {
+		return domain.Sucursal{}, fmt.Errorf("error updating sucursal: %w", err)
+	}
+
+	s.publish(domain.SucursalEventUpdated, updatedSucursal)
+
+	return updatedSucursal, nil
+}
+
+// Delete da de baja una sucursal sin actividades (solo activities:manage)
+func (s *SucursalesServiceImpl) Delete(ctx context.Context, actor domain.Actor, id uint) error {
+	if !actor.Can(domain.PermissionActivitiesManage) {
+		return forbidden("solo un administrador puede eliminar sucursales")
+	}
+
+	if err := s.repository.Delete(ctx, id); err != nil {
+		return fmt.Errorf("error deleting sucursal: %w", err)
+	}
+
+	s.publish(domain.SucursalEventDeleted, domain.Sucursal{ID: id})
+
+	return nil
+}
+
+// publish publica sucursal.<action>; un error no revierte la operación, solo se loguea
+func (s *SucursalesServiceImpl) publish(action string, sucursal domain.Sucursal) {
+	if s.events == nil {
+		return
+	}
+
+	id := strconv.FormatUint(uint64(sucursal.ID), 10)
+	var data map[string]interface{}
+	if action != domain.SucursalEventDeleted {
+		data = map[string]interface{}{
+			"sucursal_id": id,
+			"nombre":      sucursal.Nombre,
+			"direccion":   sucursal.Direccion,
+			"telefono":    sucursal.Telefono,
+			"horarios":    sucursal.Horarios,
+			"capacidad":   sucursal.Capacidad,
+			"latitud":     sucursal.Latitud,
+			"longitud":    sucursal.Longitud,
+		}
+	}
+
+	if err := s.events.PublishEvent(domain.SucursalEventType, action, id, data); err != nil {
+		log.Printf("⚠️  Warning: No se pudo publicar %s.%s (ID: %s): %v", domain.SucursalEventType, action, id, err)
+	}
+}
+
+// validateSucursal valida los datos que no cubren los tags de binding
+func validateSucursal(sucursal domain.Sucursal) error {
+	if sucursal.Nombre == "" {
+		return fmt.Errorf("el nombre no puede estar vacío")
+	}
+
+	if sucursal.Direccion == "" {
+		return fmt.Errorf("la dirección no puede estar vacía")
+	}
+
+	if sucursal.Capacidad == 0 {
+		return fmt.Errorf("la capacidad debe ser mayor a 0")
+	}
+
+	if (sucursal.Latitud == nil) != (sucursal.Longitud == nil) {
+		return fmt.Errorf("latitud y longitud se deben informar juntas")
+	}
+
+	dias := make(map[string]bool)
+	for _, horario := range sucursal.Horarios {
+		if !isDiaSemana(horario.Dia) {
+			return fmt.Errorf("día inválido en horarios: %s", horario.Dia)
+		}
+		if dias[horario.Dia] {
+			return fmt.Errorf("el día %s está repetido en horarios", horario.Dia)
+		}
+		dias[horario.Dia] = true
+
+		apertura, err := time.Parse("15:04", horario.Apertura)
+		if err != nil {
+			return fmt.Errorf("formato de hora de apertura inválido (debe ser HH:MM): %s", horario.Apertura)
+		}
+		cierre, err := time.Parse("15:04", horario.Cierre)
+		if err != nil {
+			return fmt.Errorf("formato de hora de cierre inválido (debe ser HH:MM): %s", horario.Cierre)
+		}
+		if !cierre.After(apertura) {
+			return fmt.Errorf("la hora de cierre del %s debe ser posterior a la de apertura", horario.Dia)
+		}
+	}
+
+	return nil
+}
+
+// isDiaSemana indica si el valor es un día válido
+func isDiaSemana(dia string) bool {
+	for _, d := range diasSemana {
+		if d == dia {
+			return true
+		}
+	}
+	return false
+}
@@ -10,6 +10,7 @@ require (
 	github.com/sirupsen/logrus v1.9.3
 	gorm.io/driver/mysql v1.5.7
 	gorm.io/gorm v1.26.1
+	users-api/pkg/usersclient v0.0.0-00010101000000-000000000000
 )
 
 require (
@@ -43,3 +44,6 @@ require (
 	google.golang.org/protobuf v1.36.6 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+// Cliente de users-api (JWKS, mismo repositorio)
+replace users-api/pkg/usersclient => ../users-api/pkg/usersclient
@@ -12,6 +12,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v4"
 	log "github.com/sirupsen/logrus"
+	"users-api/pkg/usersclient"
 )
 
 type usuarioService struct{}
@@ -24,15 +25,30 @@ type IUsuarioService interface {
 
 var (
 	IncorrectCredentialsError = errors.New("Credenciales incorrectas")
+	InvalidTokenError         = errors.New("Token inválido")
 
 	UsuarioService IUsuarioService
 	jwtSecret      string
+
+	// Claves públicas de users-api (/.well-known/jwks.json); nil si no hay USERS_API_URL
+	// El backend solo conoce claves públicas: puede verificar tokens de users-api pero no emitirlos
+	usersAPIKeys *usersclient.JWKSKeyResolver
+)
+
+// Issuer y kid de los tokens HS256 del login propio: solo esos se verifican con JWT_SECRET,
+// así un token con el kid de users-api (o sin kid) nunca se acepta por HMAC
+const (
+	backendIssuer = "proyecto2025-morini-heredia"
+	backendKID    = "backend-hs256"
 )
 
 func init() {
 	UsuarioService = &usuarioService{}
 
 	jwtSecret = os.Getenv("JWT_SECRET")
+	if usersAPIURL := os.Getenv("USERS_API_URL"); usersAPIURL != "" {
+		usersAPIKeys = usersclient.NewJWKSKeyResolver(usersAPIURL, 10*time.Minute)
+	}
 }
 
 func calculateSHA256(input string) string {
@@ -49,23 +65,34 @@ func (us *usuarioService) GenerateToken(username string, password string) (strin
 	}
 
 	claims := jwt.MapClaims{
-		"iss":        "proyecto2025-morini-heredia",
+		"iss":        backendIssuer,
 		"exp":        time.Now().Add(30 * time.Minute).Unix(),
 		"username":   userdata.Username,
 		"id_usuario": userdata.Id,
 		"is_admin":   userdata.IsAdmin,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = backendKID
 
 	return token.SignedString([]byte(jwtSecret))
 }
 
+// GetClaimsFromToken valida tanto los tokens de users-api (RS256/EdDSA, verificados con su JWKS)
+// como los tokens HS256 emitidos por el login propio de este backend (kid e iss propios)
 func (us *usuarioService) GetClaimsFromToken(tokenString string) (jwt.MapClaims, error) {
+	ownToken := false
 	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
+			if kid, _ := t.Header["kid"].(string); kid != backendKID || t.Method != jwt.SigningMethodHS256 || jwtSecret == "" {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			ownToken = true
+			return []byte(jwtSecret), nil
+		}
+		if usersAPIKeys != nil {
+			return usersAPIKeys.Keyfunc(t)
 		}
-		return []byte(jwtSecret), nil
+		return nil, jwt.ErrSignatureInvalid
 	})
 	if err != nil || !token.Valid {
 		return nil, err
@@ -75,6 +102,9 @@ func (us *usuarioService) GetClaimsFromToken(tokenString string) (jwt.MapClaims,
 	if !ok {
 		return nil, errors.New("Error al obtener los claims")
 	}
+	if ownToken && !claims.VerifyIssuer(backendIssuer, true) {
+		return nil, InvalidTokenError
+	}
 
 	return claims, nil
 }
@@ -4,12 +4,14 @@ import (
 	"log"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yourusername/payments-api/internal/clients"
 	"github.com/yourusername/payments-api/internal/config"
 	"github.com/yourusername/payments-api/internal/controllers"
 	"github.com/yourusername/payments-api/internal/dao"
 	"github.com/yourusername/payments-api/internal/database"
 	"github.com/yourusername/payments-api/internal/middleware"
 	"github.com/yourusername/payments-api/internal/services"
+	"users-api/pkg/usersclient"
 )
 
 func main() {
@@ -28,18 +30,41 @@ func main() {
 
 	// 4. Inicializar Services (Lógica de Negocio) con DI
 	paymentService := services.NewPaymentServiceNew(paymentRepo)
+	privacyService := services.NewPrivacyService(paymentRepo, paymentService)
+
+	// Consumidor de pedidos de exportación/borrado de datos de socios (coordinados por users-api)
+	privacyConsumer, err := clients.NewRabbitMQPrivacyConsumer(
+		cfg.RabbitMQURL,
+		cfg.RabbitMQExchange,
+		cfg.RabbitMQPrivacyQueue,
+		privacyService,
+	)
+	if err != nil {
+		log.Printf("⚠️  Warning: No se pudo conectar a RabbitMQ: %v", err)
+		// Continuar sin RabbitMQ para desarrollo
+	} else {
+		defer privacyConsumer.Close()
+		if err := privacyConsumer.Start(); err != nil {
+			log.Printf("⚠️  Warning: Error iniciando consumidor: %v", err)
+		}
+	}
 
 	// 5. Inicializar Controllers (Capa HTTP) con DI
 	paymentController := controllers.NewPaymentController(paymentService)
 
-	// 6. Configurar Gin Router
+	// 6. Autenticación: claves públicas de users-api (JWKS) y chequeo de revocación de sesiones
+	jwtKeys := usersclient.NewJWKSKeyResolver(cfg.UsersAPIURL, cfg.JWKSCacheTTL)
+	sessionChecker := usersclient.NewSessionChecker(cfg.UsersAPIURL, cfg.SessionCacheTTL)
+	authMiddleware := middleware.JWTAuthMiddleware(jwtKeys, sessionChecker)
+
+	// 7. Configurar Gin Router
 	router := gin.Default()
 	router.Use(middleware.CORS())
 
-	// 7. Registrar Rutas
-	registerRoutes(router, paymentController)
+	// 8. Registrar Rutas
+	registerRoutes(router, authMiddleware, paymentController)
 
-	// 8. Iniciar servidor
+	// 9. Iniciar servidor
 	log.Printf("🚀 Payments API corriendo en puerto %s", cfg.Port)
 	log.Println("📦 Arquitectura: Controllers → Services → Repositories")
 	log.Println("💉 Dependency Injection: Activada")
@@ -50,19 +75,22 @@ func main() {
 }
 
 // registerRoutes - Registra todas las rutas HTTP
-func registerRoutes(router *gin.Engine, paymentController *controllers.PaymentController) {
+func registerRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc, paymentController *controllers.PaymentController) {
 	// Health check
 	router.GET("/healthz", paymentController.HealthCheck)
 
-	// Rutas de pagos
+	// Rutas de pagos (requieren JWT; el controller verifica que el pago sea del usuario
+	// o que tenga payments:read / payments:manage)
 	paymentRoutes := router.Group("/payments")
+	paymentRoutes.Use(authMiddleware)
+	readAll := middleware.RequirePermission(middleware.PermissionPaymentsRead, middleware.PermissionPaymentsManage)
 	{
 		paymentRoutes.POST("", paymentController.CreatePayment)
 		paymentRoutes.GET("/:id", paymentController.GetPayment)
 		paymentRoutes.GET("/user/:user_id", paymentController.GetPaymentsByUser)
-		paymentRoutes.GET("/entity", paymentController.GetPaymentsByEntity)   // Query: ?entity_type=subscription&entity_id=123
-		paymentRoutes.GET("/status", paymentController.GetPaymentsByStatus)   // Query: ?status=pending
-		paymentRoutes.PATCH("/:id/status", paymentController.UpdatePaymentStatus)
+		paymentRoutes.GET("/entity", readAll, paymentController.GetPaymentsByEntity) // Query: ?entity_type=subscription&entity_id=123
+		paymentRoutes.GET("/status", readAll, paymentController.GetPaymentsByStatus) // Query: ?status=pending
+		paymentRoutes.PATCH("/:id/status", middleware.RequirePermission(middleware.PermissionPaymentsManage), paymentController.UpdatePaymentStatus)
 		paymentRoutes.POST("/:id/process", paymentController.ProcessPayment)
 	}
 }
@@ -0,0 +1,239 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/yourusername/payments-api/internal/services"
+)
+
+// privacyServiceName es el nombre con el que este servicio confirma los pedidos a users-api
+const privacyServiceName = "payments-api"
+
+// RabbitMQPrivacyConsumer consume los pedidos de exportación/borrado de datos de un socio
+// (privacy.export_requested / privacy.erasure_requested) y responde con el acuse
+// (privacy.export_completed / privacy.erasure_completed) que registra users-api
+type RabbitMQPrivacyConsumer struct {
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	exchange string
+	queue    string
+	service  *services.PrivacyService
+}
+
+// privacyEvent - Sobre común de los eventos del exchange
+type privacyEvent struct {
+	Action    string                 `json:"action"`
+	Type      string                 `json:"type"`
+	ID        string                 `json:"id"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// NewRabbitMQPrivacyConsumer - Constructor con DI
+func NewRabbitMQPrivacyConsumer(url, exchange, queueName string, privacyService *services.PrivacyService) (*RabbitMQPrivacyConsumer, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// Declarar exchange
+	err = channel.ExchangeDeclare(
+		exchange, // name
+		"topic",  // type
+		true,     // durable
+		false,    // auto-deleted
+		false,    // internal
+		false,    // no-wait
+		nil,      // arguments
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, err
+	}
+
+	// Declarar cola
+	queue, err := channel.QueueDeclare(
+		queueName, // name
+		true,      // durable
+		false,     // delete when unused
+		false,     // exclusive
+		false,     // no-wait
+		nil,       // arguments
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, err
+	}
+
+	// Bind a los pedidos de privacidad
+	bindings := []string{
+		"privacy.export_requested",
+		"privacy.erasure_requested",
+	}
+
+	for _, binding := range bindings {
+		err = channel.QueueBind(
+			queue.Name, // queue name
+			binding,    // routing key
+			exchange,   // exchange
+			false,
+			nil,
+		)
+		if err != nil {
+			channel.Close()
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	log.Printf("✅ Conectado a RabbitMQ como consumidor (Queue: %s)\n", queueName)
+
+	return &RabbitMQPrivacyConsumer{
+		conn:     conn,
+		channel:  channel,
+		exchange: exchange,
+		queue:    queue.Name,
+		service:  privacyService,
+	}, nil
+}
+
+// Start inicia el consumo de mensajes
+func (r *RabbitMQPrivacyConsumer) Start() error {
+	msgs, err := r.channel.Consume(
+		r.queue, // queue
+		"",      // consumer
+		false,   // auto-ack
+		false,   // exclusive
+		false,   // no-local
+		false,   // no-wait
+		nil,     // args
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Println("🎧 Escuchando pedidos de privacidad de RabbitMQ...")
+
+	go func() {
+		for msg := range msgs {
+			r.handleMessage(msg)
+		}
+	}()
+
+	return nil
+}
+
+func (r *RabbitMQPrivacyConsumer) handleMessage(msg amqp.Delivery) {
+	var event privacyEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Printf("❌ Error decodificando evento: %v\n", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	tipo := strings.TrimSuffix(event.Action, "_requested")
+	usuarioID, _ := event.Data["usuario_id"].(string)
+	if usuarioID == "" || (tipo != "export" && tipo != "erasure") {
+		log.Printf("❌ Pedido de privacidad inválido: %s (ID: %s)\n", msg.RoutingKey, event.ID)
+		msg.Nack(false, false)
+		return
+	}
+
+	log.Printf("📥 Pedido de privacidad recibido: %s (ID: %s, usuario: %s)\n", msg.RoutingKey, event.ID, usuarioID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ack := map[string]interface{}{
+		"service":    privacyServiceName,
+		"usuario_id": usuarioID,
+		"status":     "completed",
+	}
+	var (
+		records int
+		data    map[string]interface{}
+		err     error
+	)
+	if tipo == "export" {
+		data, records, err = r.service.ExportUserData(ctx, usuarioID)
+	} else {
+		records, err = r.service.EraseUserData(ctx, usuarioID)
+	}
+	if err != nil {
+		log.Printf("❌ Error procesando pedido de privacidad %s: %v\n", event.ID, err)
+		ack["status"] = "failed"
+		ack["error"] = err.Error()
+	} else {
+		ack["records"] = records
+		if data != nil {
+			ack["data"] = data
+		}
+	}
+
+	// Si no se puede confirmar, el pedido vuelve a la cola (exportar y borrar son idempotentes)
+	if err := r.publishAck(tipo+"_completed", event.ID, ack); err != nil {
+		log.Printf("❌ Error publicando acuse: %v\n", err)
+		msg.Nack(false, true)
+		return
+	}
+
+	msg.Ack(false)
+}
+
+// publishAck publica el acuse para users-api (routing key privacy.<tipo>_completed)
+func (r *RabbitMQPrivacyConsumer) publishAck(action, requestID string, data map[string]interface{}) error {
+	body, err := json.Marshal(privacyEvent{
+		Action:    action,
+		Type:      "privacy",
+		ID:        requestID,
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("error serializando acuse: %w", err)
+	}
+
+	routingKey := "privacy." + action
+	err = r.channel.Publish(
+		r.exchange, // exchange
+		routingKey, // routing key
+		false,      // mandatory
+		false,      // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("📤 Acuse publicado: %s (ID: %s)\n", routingKey, requestID)
+	return nil
+}
+
+// Close cierra las conexiones
+func (r *RabbitMQPrivacyConsumer) Close() error {
+	if r.channel != nil {
+		r.channel.Close()
+	}
+	if r.conn != nil {
+		return r.conn.Close()
+	}
+	return nil
+}
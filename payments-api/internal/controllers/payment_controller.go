@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/yourusername/payments-api/internal/domain/dtos"
+	"github.com/yourusername/payments-api/internal/middleware"
 	"github.com/yourusername/payments-api/internal/services"
 )
 
@@ -29,6 +30,12 @@ func (c *PaymentController) CreatePayment(ctx *gin.Context) {
 		return
 	}
 
+	// Un socio solo puede registrar sus propios pagos; caja puede hacerlo por cualquiera
+	if !middleware.IsSelfOrHasPermission(ctx, req.UserID, middleware.PermissionPaymentsManage) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No podés registrar pagos de otro usuario"})
+		return
+	}
+
 	payment, err := c.service.CreatePayment(ctx.Request.Context(), req)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -48,6 +55,11 @@ func (c *PaymentController) GetPayment(ctx *gin.Context) {
 		return
 	}
 
+	if !middleware.IsSelfOrHasPermission(ctx, payment.UserID, middleware.PermissionPaymentsRead, middleware.PermissionPaymentsManage) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tenés permiso para ver este pago"})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, payment)
 }
 
@@ -55,6 +67,11 @@ func (c *PaymentController) GetPayment(ctx *gin.Context) {
 func (c *PaymentController) GetPaymentsByUser(ctx *gin.Context) {
 	userID := ctx.Param("user_id")
 
+	if !middleware.IsSelfOrHasPermission(ctx, userID, middleware.PermissionPaymentsRead, middleware.PermissionPaymentsManage) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tenés permiso para ver pagos de otro usuario"})
+		return
+	}
+
 	payments, err := c.service.GetPaymentsByUser(ctx.Request.Context(), userID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -124,7 +141,18 @@ func (c *PaymentController) UpdatePaymentStatus(ctx *gin.Context) {
 func (c *PaymentController) ProcessPayment(ctx *gin.Context) {
 	paymentID := ctx.Param("id")
 
-	err := c.service.ProcessPayment(ctx.Request.Context(), paymentID)
+	payment, err := c.service.GetPaymentByID(ctx.Request.Context(), paymentID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !middleware.IsSelfOrHasPermission(ctx, payment.UserID, middleware.PermissionPaymentsManage) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tenés permiso para procesar este pago"})
+		return
+	}
+
+	err = c.service.ProcessPayment(ctx.Request.Context(), paymentID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
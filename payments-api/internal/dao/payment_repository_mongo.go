@@ -12,6 +12,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// AnonymizedUserID reemplaza al user_id de los pagos de un usuario borrado
+const AnonymizedUserID = "anonimizado"
+
 // PaymentRepositoryMongo - Implementación de PaymentRepository con MongoDB
 type PaymentRepositoryMongo struct {
 	collection *mongo.Collection
@@ -137,3 +140,22 @@ func (r *PaymentRepositoryMongo) Count(ctx context.Context, filters map[string]i
 
 	return count, nil
 }
+
+// AnonymizeByUser conserva montos, estados y transacciones (obligación contable)
+// pero elimina la referencia al usuario y la metadata, que puede contener datos personales
+func (r *PaymentRepositoryMongo) AnonymizeByUser(ctx context.Context, userID string) (int64, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"user_id":    AnonymizedUserID,
+			"metadata":   bson.M{"anonymized": true},
+			"updated_at": time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateMany(ctx, bson.M{"user_id": userID}, update)
+	if err != nil {
+		return 0, fmt.Errorf("error al anonimizar pagos: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
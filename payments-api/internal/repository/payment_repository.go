@@ -30,4 +30,7 @@ type PaymentRepository interface {
 
 	// Count cuenta los pagos que cumplen con los filtros
 	Count(ctx context.Context, filters map[string]interface{}) (int64, error)
+
+	// AnonymizeByUser desvincula los pagos de un usuario (derecho al olvido) y devuelve cuántos modificó
+	AnonymizeByUser(ctx context.Context, userID string) (int64, error)
 }
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/payments-api/internal/repository"
+)
+
+// PrivacyService - Exporta y anonimiza los pagos de un usuario
+// Lo invoca el consumidor de pedidos de privacidad que coordina users-api
+type PrivacyService struct {
+	paymentRepo    repository.PaymentRepository
+	paymentService *PaymentServiceNew
+}
+
+// NewPrivacyService - Constructor con DI
+func NewPrivacyService(paymentRepo repository.PaymentRepository, paymentService *PaymentServiceNew) *PrivacyService {
+	return &PrivacyService{
+		paymentRepo:    paymentRepo,
+		paymentService: paymentService,
+	}
+}
+
+// ExportUserData devuelve todos los pagos del usuario
+func (s *PrivacyService) ExportUserData(ctx context.Context, userID string) (map[string]interface{}, int, error) {
+	payments, err := s.paymentService.GetPaymentsByUser(ctx, userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al exportar pagos: %w", err)
+	}
+
+	return map[string]interface{}{
+		"pagos": payments,
+	}, len(payments), nil
+}
+
+// EraseUserData anonimiza los pagos del usuario (los montos se conservan para la contabilidad)
+func (s *PrivacyService) EraseUserData(ctx context.Context, userID string) (int, error) {
+	anonymized, err := s.paymentRepo.AnonymizeByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(anonymized), nil
+}
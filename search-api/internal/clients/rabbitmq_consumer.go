@@ -2,7 +2,10 @@ package clients
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/streadway/amqp"
 	"github.com/yourusername/gym-management/search-api/internal/domain/dtos"
@@ -13,6 +16,7 @@ import (
 type RabbitMQConsumer struct {
 	conn          *amqp.Connection
 	channel       *amqp.Channel
+	exchange      string
 	searchService *services.SearchService
 	cacheService  *services.CacheService
 }
@@ -64,9 +68,12 @@ func NewRabbitMQConsumer(url, exchange, queueName string, searchService *service
 	// Bind a todos los eventos relevantes
 	bindings := []string{
 		"activity.*",
+		"sucursal.*", // Nombre de las sucursales para los documentos de actividades
 		"plan.*",
 		"subscription.*",
 		"inscription.*",
+		"privacy.export_requested",  // Exportación de datos de un socio (coordinada por users-api)
+		"privacy.erasure_requested", // Borrado de datos de un socio (coordinado por users-api)
 	}
 
 	for _, binding := range bindings {
@@ -89,6 +96,7 @@ func NewRabbitMQConsumer(url, exchange, queueName string, searchService *service
 	return &RabbitMQConsumer{
 		conn:          conn,
 		channel:       channel,
+		exchange:      exchange,
 		searchService: searchService,
 		cacheService:  cacheService,
 	}, nil
@@ -132,6 +140,19 @@ func (r *RabbitMQConsumer) handleMessage(msg amqp.Delivery) {
 
 	log.Printf("📥 Evento recibido: %s.%s (ID: %s)\n", event.Type, event.Action, event.ID)
 
+	// Los pedidos de privacidad no indexan nada: se responden con un acuse a users-api
+	if event.Type == "privacy" {
+		r.handlePrivacyRequest(msg, event)
+		return
+	}
+
+	// Las inscripciones no se indexan: los lugares libres llegan en el activity.update que publica activities-api
+	if event.Type == "inscription" {
+		r.cacheService.InvalidatePattern("activity")
+		msg.Ack(false)
+		return
+	}
+
 	// Procesar según la acción
 	switch event.Action {
 	case "create", "update":
@@ -160,6 +181,76 @@ func (r *RabbitMQConsumer) handleMessage(msg amqp.Delivery) {
 	msg.Ack(false)
 }
 
+// handlePrivacyRequest exporta o borra los documentos de un socio y publica el acuse
+// (privacy.export_completed / privacy.erasure_completed) que registra users-api
+func (r *RabbitMQConsumer) handlePrivacyRequest(msg amqp.Delivery, event dtos.RabbitMQEvent) {
+	tipo := strings.TrimSuffix(event.Action, "_requested")
+	usuarioID, _ := event.Data["usuario_id"].(string)
+	if usuarioID == "" || (tipo != "export" && tipo != "erasure") {
+		log.Printf("❌ Pedido de privacidad inválido: %s.%s (ID: %s)\n", event.Type, event.Action, event.ID)
+		msg.Nack(false, false)
+		return
+	}
+
+	ack := map[string]interface{}{
+		"service":    "search-api",
+		"usuario_id": usuarioID,
+		"status":     "completed",
+	}
+	if tipo == "export" {
+		docs := r.searchService.FindByUser(usuarioID)
+		ack["records"] = len(docs)
+		ack["data"] = map[string]interface{}{"documentos": docs}
+	} else {
+		deleted := r.searchService.DeleteByUser(usuarioID)
+		ack["records"] = deleted
+		r.cacheService.InvalidatePattern("subscription")
+		log.Printf("🗑️  Documentos del usuario %s eliminados: %d\n", usuarioID, deleted)
+	}
+
+	// Si no se puede confirmar, el pedido vuelve a la cola (exportar y borrar son idempotentes)
+	if err := r.publishPrivacyAck(tipo+"_completed", event.ID, ack); err != nil {
+		log.Printf("❌ Error publicando acuse: %v\n", err)
+		msg.Nack(false, true)
+		return
+	}
+
+	msg.Ack(false)
+}
+
+// publishPrivacyAck publica el acuse de un pedido de privacidad (routing key privacy.<tipo>_completed)
+func (r *RabbitMQConsumer) publishPrivacyAck(action, requestID string, data map[string]interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"action":    action,
+		"type":      "privacy",
+		"id":        requestID,
+		"timestamp": time.Now(),
+		"data":      data,
+	})
+	if err != nil {
+		return fmt.Errorf("error serializando acuse: %w", err)
+	}
+
+	routingKey := "privacy." + action
+	err = r.channel.Publish(
+		r.exchange, // exchange
+		routingKey, // routing key
+		false,      // mandatory
+		false,      // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("📤 Acuse publicado: %s (ID: %s)\n", routingKey, requestID)
+	return nil
+}
+
 // Close cierra las conexiones
 func (r *RabbitMQConsumer) Close() error {
 	if r.channel != nil {
@@ -4,7 +4,7 @@ package dtos
 // Puede ser una actividad, plan o suscripción
 type SearchDocument struct {
 	ID   string `json:"id"`
-	Type string `json:"type"` // activity, plan, subscription
+	Type string `json:"type"` // activity, sucursal, plan, subscription
 
 	// Campos de Actividad
 	Titulo           string `json:"titulo,omitempty"`
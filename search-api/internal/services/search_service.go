@@ -99,9 +99,9 @@ func (s *SearchService) matchesSearch(doc dtos.SearchDocument, req dtos.SearchRe
 	// Búsqueda por query (búsqueda de texto)
 	if req.Query != "" {
 		query := strings.ToLower(req.Query)
-		text := strings.ToLower(fmt.Sprintf("%s %s %s %s %s",
+		text := strings.ToLower(fmt.Sprintf("%s %s %s %s %s %s",
 			doc.Titulo, doc.Descripcion, doc.Categoria,
-			doc.Instructor, doc.PlanNombre))
+			doc.Instructor, doc.PlanNombre, doc.SucursalNombre))
 
 		if !strings.Contains(text, query) {
 			return false
@@ -155,6 +155,37 @@ func (s *SearchService) GetDocumentByID(id string) (*dtos.SearchDocument, error)
 	return &doc, nil
 }
 
+// FindByUser devuelve los documentos asociados a un socio (exportación de sus datos)
+func (s *SearchService) FindByUser(usuarioID string) []dtos.SearchDocument {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := []dtos.SearchDocument{}
+	for _, doc := range s.documents {
+		if doc.UsuarioID == usuarioID {
+			results = append(results, doc)
+		}
+	}
+
+	return results
+}
+
+// DeleteByUser elimina del índice los documentos asociados a un socio y devuelve cuántos borró
+func (s *SearchService) DeleteByUser(usuarioID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for id, doc := range s.documents {
+		if doc.UsuarioID == usuarioID {
+			delete(s.documents, id)
+			deleted++
+		}
+	}
+
+	return deleted
+}
+
 // IndexFromEvent indexa un documento desde un evento de RabbitMQ
 func (s *SearchService) IndexFromEvent(event dtos.RabbitMQEvent) error {
 	// Convertir data a SearchDocument
@@ -171,9 +202,46 @@ func (s *SearchService) IndexFromEvent(event dtos.RabbitMQEvent) error {
 	doc.ID = event.Type + "_" + event.ID
 	doc.Type = event.Type
 
+	switch doc.Type {
+	case "sucursal":
+		// El nombre de la sucursal llega como "nombre" (el documento lo guarda en SucursalNombre)
+		nombre, _ := event.Data["nombre"].(string)
+		direccion, _ := event.Data["direccion"].(string)
+		doc.Titulo = nombre
+		doc.Descripcion = direccion
+		doc.SucursalID = event.ID
+		doc.SucursalNombre = nombre
+		s.renameSucursal(event.ID, nombre)
+	case "activity":
+		if doc.SucursalID != "" && doc.SucursalNombre == "" {
+			doc.SucursalNombre = s.sucursalNombre(doc.SucursalID)
+		}
+	}
+
 	return s.IndexDocument(doc)
 }
 
+// sucursalNombre devuelve el nombre de una sucursal indexada ("" si no se recibió su evento)
+func (s *SearchService) sucursalNombre(sucursalID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.documents["sucursal_"+sucursalID].SucursalNombre
+}
+
+// renameSucursal actualiza el nombre de la sucursal en las actividades ya indexadas
+func (s *SearchService) renameSucursal(sucursalID, nombre string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, doc := range s.documents {
+		if doc.Type == "activity" && doc.SucursalID == sucursalID {
+			doc.SucursalNombre = nombre
+			s.documents[id] = doc
+		}
+	}
+}
+
 // GetStats retorna estadísticas del índice
 func (s *SearchService) GetStats() map[string]interface{} {
 	s.mu.RLock()
@@ -11,6 +11,7 @@ import (
 	"github.com/yourusername/gym-management/subscriptions-api/internal/database"
 	"github.com/yourusername/gym-management/subscriptions-api/internal/middleware"
 	"github.com/yourusername/gym-management/subscriptions-api/internal/services"
+	"users-api/pkg/usersclient"
 )
 
 func main() {
@@ -29,7 +30,12 @@ func main() {
 	subscriptionRepo := dao.NewSubscriptionRepositoryMongo(mongoDB.Database)
 
 	// 4. Inicializar Clients (Servicios Externos) con DI
-	usersValidator := clients.NewUsersAPIValidator(cfg.UsersAPIURL)
+	usersClient := usersclient.New(usersclient.Config{
+		BaseURL:       cfg.UsersAPIURL,
+		ServiceName:   cfg.UsersAPIService,
+		ServiceSecret: cfg.UsersAPISecret,
+	})
+	usersValidator := clients.NewUsersAPIValidator(usersClient)
 
 	eventPublisher, err := clients.NewRabbitMQEventPublisher(cfg.RabbitMQURL, cfg.RabbitMQExchange)
 	if err != nil {
@@ -49,19 +55,46 @@ func main() {
 		usersValidator,
 		eventPublisher,
 	)
+	privacyService := services.NewPrivacyService(subscriptionRepo, planRepo, subscriptionService)
+
+	// Consumidor de pedidos de exportación/borrado de datos de socios (coordinados por users-api)
+	privacyConsumer, err := clients.NewRabbitMQPrivacyConsumer(
+		cfg.RabbitMQURL,
+		cfg.RabbitMQExchange,
+		cfg.RabbitMQPrivacyQueue,
+		privacyService,
+	)
+	if err != nil {
+		log.Printf("⚠️  Warning: No se pudo conectar a RabbitMQ como consumidor: %v", err)
+	} else {
+		defer privacyConsumer.Close()
+		if err := privacyConsumer.Start(); err != nil {
+			log.Printf("⚠️  Warning: Error iniciando consumidor: %v", err)
+		}
+	}
 
 	// 6. Inicializar Controllers (Capa HTTP) con DI
 	planController := controllers.NewPlanController(planService)
 	subscriptionController := controllers.NewSubscriptionController(subscriptionService)
+	internalController := controllers.NewInternalController(subscriptionService, planService)
+
+	// 7. Autenticación: claves públicas de users-api (JWKS) y chequeo de revocación de sesiones
+	jwtKeys := usersclient.NewJWKSKeyResolver(cfg.UsersAPIURL, cfg.JWKSCacheTTL)
+	sessionChecker := usersclient.NewSessionChecker(cfg.UsersAPIURL, cfg.SessionCacheTTL)
+	authMiddleware := middleware.JWTAuthMiddleware(jwtKeys, sessionChecker)
 
-	// 7. Configurar Gin Router
+	// 8. Configurar Gin Router
 	router := gin.Default()
 	router.Use(middleware.CORS())
 
-	// 8. Registrar Rutas
-	registerRoutes(router, planController, subscriptionController)
+	// 9. Registrar Rutas
+	if len(cfg.ServiceCredentials) == 0 {
+		log.Println("⚠️  SERVICE_CREDENTIALS no configurado: los endpoints internos rechazarán todos los pedidos")
+	}
+	serviceAuth := middleware.ServiceAuthMiddleware(cfg.ServiceCredentials)
+	registerRoutes(router, authMiddleware, serviceAuth, planController, subscriptionController, internalController)
 
-	// 9. Iniciar servidor
+	// 10. Iniciar servidor
 	log.Printf("🚀 Subscriptions API corriendo en puerto %s", cfg.Port)
 	log.Println("📦 Arquitectura: Controllers → Services → Repositories")
 	log.Println("💉 Dependency Injection: Activada")
@@ -74,8 +107,11 @@ func main() {
 // registerRoutes - Registra todas las rutas HTTP
 func registerRoutes(
 	router *gin.Engine,
+	authMiddleware gin.HandlerFunc,
+	serviceAuth gin.HandlerFunc,
 	planController *controllers.PlanController,
 	subscriptionController *controllers.SubscriptionController,
+	internalController *controllers.InternalController,
 ) {
 	// Health check
 	router.GET("/healthz", subscriptionController.HealthCheck)
@@ -83,18 +119,27 @@ func registerRoutes(
 	// Rutas de planes
 	planRoutes := router.Group("/plans")
 	{
-		planRoutes.POST("", planController.CreatePlan)
+		planRoutes.POST("", authMiddleware, middleware.RequirePermission(middleware.PermissionPlansManage), planController.CreatePlan)
 		planRoutes.GET("", planController.ListPlans)
 		planRoutes.GET("/:id", planController.GetPlan)
 	}
 
-	// Rutas de suscripciones
+	// Rutas de suscripciones (requieren JWT; el controller verifica que la suscripción
+	// sea del usuario o que tenga subscriptions:read / subscriptions:manage)
 	subscriptionRoutes := router.Group("/subscriptions")
+	subscriptionRoutes.Use(authMiddleware)
 	{
 		subscriptionRoutes.POST("", subscriptionController.CreateSubscription)
 		subscriptionRoutes.GET("/:id", subscriptionController.GetSubscription)
 		subscriptionRoutes.GET("/active/:user_id", subscriptionController.GetActiveSubscriptionByUser)
-		subscriptionRoutes.PATCH("/:id/status", subscriptionController.UpdateSubscriptionStatus)
+		subscriptionRoutes.PATCH("/:id/status", middleware.RequirePermission(middleware.PermissionSubscriptionsManage), subscriptionController.UpdateSubscriptionStatus)
 		subscriptionRoutes.DELETE("/:id", subscriptionController.CancelSubscription)
 	}
+
+	// API interna para otros microservicios (credenciales de servicio de SERVICE_CREDENTIALS)
+	internalRoutes := router.Group("/internal")
+	internalRoutes.Use(serviceAuth)
+	{
+		internalRoutes.GET("/subscriptions/active/:user_id", internalController.GetActiveSubscription)
+	}
 }
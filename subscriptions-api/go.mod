@@ -4,9 +4,11 @@ go 1.23
 
 require (
 	github.com/gin-gonic/gin v1.10.0
+	github.com/golang-jwt/jwt/v4 v4.5.2
 	github.com/joho/godotenv v1.5.1
 	github.com/streadway/amqp v1.1.0
 	go.mongodb.org/mongo-driver v1.17.1
+	users-api/pkg/usersclient v0.0.0-00010101000000-000000000000
 )
 
 require (
@@ -45,3 +47,6 @@ require (
 	google.golang.org/protobuf v1.34.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+// Cliente de users-api (mismo repositorio)
+replace users-api/pkg/usersclient => ../users-api/pkg/usersclient
@@ -2,60 +2,38 @@ package clients
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
-	"time"
+	"strconv"
+
+	"users-api/pkg/usersclient"
 )
 
-// UsersAPIValidator - Implementación de UserValidator que consulta users-api
+// UsersAPIValidator - Implementación de UserValidator que consulta la API interna de users-api
 type UsersAPIValidator struct {
-	baseURL string
-	client  *http.Client
+	client *usersclient.Client
 }
 
 // NewUsersAPIValidator - Constructor con DI
-func NewUsersAPIValidator(baseURL string) *UsersAPIValidator {
+func NewUsersAPIValidator(client *usersclient.Client) *UsersAPIValidator {
 	return &UsersAPIValidator{
-		baseURL: baseURL,
-		client: &http.Client{
-			Timeout: 5 * time.Second,
-		},
+		client: client,
 	}
 }
 
-type userResponse struct {
-	ID       int    `json:"id_usuario"`
-	Username string `json:"username"`
-}
-
 // ValidateUser - Implementa la interface UserValidator
 func (u *UsersAPIValidator) ValidateUser(ctx context.Context, userID string) (bool, error) {
-	url := fmt.Sprintf("%s/users/%s", u.baseURL, userID)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return false, fmt.Errorf("error creando request: %w", err)
+	id, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil || id == 0 {
+		return false, fmt.Errorf("ID de usuario inválido")
 	}
 
-	resp, err := u.client.Do(req)
-	if err != nil {
+	if _, err := u.client.GetUser(ctx, uint(id)); err != nil {
+		if errors.Is(err, usersclient.ErrNotFound) {
+			return false, fmt.Errorf("usuario no encontrado")
+		}
 		return false, fmt.Errorf("error consultando users-api: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound {
-		return false, fmt.Errorf("usuario no encontrado")
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("error en users-api: status %d", resp.StatusCode)
-	}
-
-	var user userResponse
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
-		return false, fmt.Errorf("error decodificando respuesta: %w", err)
-	}
 
-	return user.ID > 0, nil
+	return true, nil
 }
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/gym-management/subscriptions-api/internal/services"
+)
+
+// InternalController - API interna para otros microservicios (credenciales de servicio, no JWT de usuario)
+type InternalController struct {
+	subscriptionService *services.SubscriptionService // DI
+	planService         *services.PlanService         // DI
+}
+
+// NewInternalController - Constructor con DI
+func NewInternalController(subscriptionService *services.SubscriptionService, planService *services.PlanService) *InternalController {
+	return &InternalController{
+		subscriptionService: subscriptionService,
+		planService:         planService,
+	}
+}
+
+// GetActiveSubscription - GET /internal/subscriptions/active/:user_id
+// Devuelve la suscripción activa del usuario junto con su plan (tipo_acceso, actividades_permitidas)
+// Lo usa activities-api para validar que el plan cubra la actividad al inscribir
+func (c *InternalController) GetActiveSubscription(ctx *gin.Context) {
+	userID := ctx.Param("user_id")
+
+	subscription, err := c.subscriptionService.GetActiveSubscriptionByUserID(ctx.Request.Context(), userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "no hay suscripción activa") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := c.planService.GetPlanByID(ctx.Request.Context(), subscription.PlanID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener el plan de la suscripción", "details": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"subscription": subscription,
+		"plan":         plan,
+	})
+}
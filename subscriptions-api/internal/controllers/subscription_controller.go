@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/yourusername/gym-management/subscriptions-api/internal/domain/dtos"
+	"github.com/yourusername/gym-management/subscriptions-api/internal/middleware"
 	"github.com/yourusername/gym-management/subscriptions-api/internal/services"
 )
 
@@ -29,6 +30,12 @@ func (c *SubscriptionController) CreateSubscription(ctx *gin.Context) {
 		return
 	}
 
+	// Un socio solo puede suscribirse a sí mismo; recepción puede hacerlo por cualquiera
+	if !middleware.IsSelfOrHasPermission(ctx, req.UsuarioID, middleware.PermissionSubscriptionsManage) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No podés crear suscripciones para otro usuario"})
+		return
+	}
+
 	subscription, err := c.subscriptionService.CreateSubscription(ctx.Request.Context(), req)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -48,6 +55,11 @@ func (c *SubscriptionController) GetSubscription(ctx *gin.Context) {
 		return
 	}
 
+	if !middleware.IsSelfOrHasPermission(ctx, subscription.UsuarioID, middleware.PermissionSubscriptionsRead, middleware.PermissionSubscriptionsManage) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tenés permiso para ver esta suscripción"})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, subscription)
 }
 
@@ -55,6 +67,11 @@ func (c *SubscriptionController) GetSubscription(ctx *gin.Context) {
 func (c *SubscriptionController) GetActiveSubscriptionByUser(ctx *gin.Context) {
 	userID := ctx.Param("user_id")
 
+	if !middleware.IsSelfOrHasPermission(ctx, userID, middleware.PermissionSubscriptionsRead, middleware.PermissionSubscriptionsManage) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tenés permiso para ver suscripciones de otro usuario"})
+		return
+	}
+
 	subscription, err := c.subscriptionService.GetActiveSubscriptionByUserID(ctx.Request.Context(), userID)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -87,7 +104,18 @@ func (c *SubscriptionController) UpdateSubscriptionStatus(ctx *gin.Context) {
 func (c *SubscriptionController) CancelSubscription(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	err := c.subscriptionService.CancelSubscription(ctx.Request.Context(), id)
+	subscription, err := c.subscriptionService.GetSubscriptionByID(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !middleware.IsSelfOrHasPermission(ctx, subscription.UsuarioID, middleware.PermissionSubscriptionsManage) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tenés permiso para cancelar esta suscripción"})
+		return
+	}
+
+	err = c.subscriptionService.CancelSubscription(ctx.Request.Context(), id)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
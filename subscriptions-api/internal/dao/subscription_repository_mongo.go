@@ -12,6 +12,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// AnonymizedUserID reemplaza al usuario_id de las suscripciones de un socio borrado
+const AnonymizedUserID = "anonimizado"
+
 // SubscriptionRepositoryMongo - Implementación con MongoDB
 type SubscriptionRepositoryMongo struct {
 	collection *mongo.Collection
@@ -142,3 +145,31 @@ func (r *SubscriptionRepositoryMongo) Count(ctx context.Context, filters map[str
 
 	return count, nil
 }
+
+// AnonymizeByUserID desvincula las suscripciones de un socio (derecho al olvido)
+// Se conservan fechas, plan y renovaciones por motivos contables; las vigentes se cancelan
+func (r *SubscriptionRepositoryMongo) AnonymizeByUserID(ctx context.Context, userID string) (int64, error) {
+	now := time.Now()
+
+	// Cancelar las que siguen vigentes antes de perder la referencia al usuario
+	_, err := r.collection.UpdateMany(ctx, bson.M{
+		"usuario_id": userID,
+		"estado":     bson.M{"$in": []string{"activa", "pendiente_pago"}},
+	}, bson.M{"$set": bson.M{"estado": "cancelada", "updated_at": now}})
+	if err != nil {
+		return 0, fmt.Errorf("error al cancelar suscripciones: %w", err)
+	}
+
+	result, err := r.collection.UpdateMany(ctx, bson.M{"usuario_id": userID}, bson.M{
+		"$set": bson.M{
+			"usuario_id":     AnonymizedUserID,
+			"metadata.notas": "",
+			"updated_at":     now,
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error al anonimizar suscripciones: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// SessionChecker consulta si la sesión de un token (claim "sid") fue revocada en users-api
+type SessionChecker interface {
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// KeyResolver resuelve la clave pública que verifica un token según su kid (JWKS de users-api)
+type KeyResolver interface {
+	Keyfunc(token *jwt.Token) (interface{}, error)
+}
+
+// JWTAuthMiddleware valida el token JWT en el header Authorization
+// Nota: Este middleware NO valida si el usuario existe en la BD (eso lo hace users-api)
+// Solo valida que el token sea válido, que su sesión no esté revocada y extrae los claims
+// sessions puede ser nil para omitir el chequeo de revocación
+func JWTAuthMiddleware(keys KeyResolver, sessions SessionChecker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Obtener header Authorization
+		auth := ctx.GetHeader("Authorization")
+		if auth == "" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Authorization header required",
+			})
+			return
+		}
+
+		// Validar formato "Bearer <token>"
+		parts := strings.SplitN(auth, " ", 2)
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid authorization header format. Expected 'Bearer <token>'",
+			})
+			return
+		}
+
+		// Parsear y validar token (la clave pública y el algoritmo se resuelven por kid)
+		token, err := jwt.Parse(parts[1], keys.Keyfunc)
+		if err != nil || !token.Valid {
+			details := "invalid token"
+			if err != nil {
+				details = err.Error()
+			}
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "Invalid or expired token",
+				"details": details,
+			})
+			return
+		}
+
+		// Extraer claims
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid token claims",
+			})
+			return
+		}
+
+		// Verificar revocación (logout o reuso de refresh token)
+		// Si users-api no responde se acepta el token: la firma y la expiración ya fueron validadas
+		if sid, _ := claims["sid"].(string); sid != "" && sessions != nil {
+			revoked, err := sessions.IsRevoked(ctx.Request.Context(), sid)
+			if err != nil {
+				log.Printf("⚠️  Could not check session %s: %v", sid, err)
+			} else if revoked {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": "Session revoked",
+				})
+				return
+			}
+		}
+
+		// Guardar claims en contexto
+		idUser, _ := claims["id_usuario"].(float64)
+		isAdmin, _ := claims["is_admin"].(bool)
+		username, _ := claims["username"].(string)
+
+		ctx.Set("id_usuario", uint(idUser))
+		ctx.Set("is_admin", isAdmin)
+		ctx.Set("username", username)
+		ctx.Set("roles", claimStrings(claims["roles"]))
+		ctx.Set("permissions", claimStrings(claims["permissions"]))
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Permisos (emitidos por users-api en el claim "permissions") que usa este servicio
+const (
+	PermissionAll                 = "*"
+	PermissionPlansManage         = "plans:manage"
+	PermissionSubscriptionsRead   = "subscriptions:read"
+	PermissionSubscriptionsManage = "subscriptions:manage"
+)
+
+// RequirePermission verifica que el usuario tenga al menos uno de los permisos indicados
+// Debe usarse DESPUÉS de JWTAuthMiddleware
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if HasPermission(ctx, permissions...) {
+			ctx.Next()
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":    "You don't have permission to perform this action",
+			"required": permissions,
+		})
+	}
+}
+
+// HasPermission indica si los claims del token otorgan alguno de los permisos
+// is_admin y el permiso "*" otorgan todos los permisos
+func HasPermission(ctx *gin.Context, permissions ...string) bool {
+	if ctx.GetBool("is_admin") {
+		return true
+	}
+	for _, p := range ctx.GetStringSlice("permissions") {
+		if p == PermissionAll {
+			return true
+		}
+		for _, permission := range permissions {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsSelfOrHasPermission indica si el recurso pertenece al usuario del token
+// o si el usuario tiene alguno de los permisos (ej: recepcionista operando para un socio)
+func IsSelfOrHasPermission(ctx *gin.Context, userID string, permissions ...string) bool {
+	if userID != "" && userID == CurrentUserID(ctx) {
+		return true
+	}
+	return HasPermission(ctx, permissions...)
+}
+
+// CurrentUserID devuelve el id del usuario autenticado como string (formato de los DTOs)
+func CurrentUserID(ctx *gin.Context) string {
+	return strconv.FormatUint(uint64(ctx.GetUint("id_usuario")), 10)
+}
+
+// claimStrings convierte un claim array del JWT a []string
+func claimStrings(value interface{}) []string {
+	items, _ := value.([]interface{})
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
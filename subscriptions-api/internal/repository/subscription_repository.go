@@ -17,4 +17,5 @@ type SubscriptionRepository interface {
 	UpdateStatus(ctx context.Context, id primitive.ObjectID, status, pagoID string) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	Count(ctx context.Context, filters map[string]interface{}) (int64, error)
+	AnonymizeByUserID(ctx context.Context, userID string) (int64, error)
 }
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/gym-management/subscriptions-api/internal/domain/dtos"
+	"github.com/yourusername/gym-management/subscriptions-api/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// PrivacyService - Exporta y anonimiza las suscripciones de un socio
+// Lo invoca el consumidor de pedidos de privacidad que coordina users-api
+type PrivacyService struct {
+	subscriptionRepo    repository.SubscriptionRepository // DI
+	planRepo            repository.PlanRepository         // DI
+	subscriptionService *SubscriptionService              // DI (reutiliza el mapeo a DTO)
+}
+
+// NewPrivacyService - Constructor con DI
+func NewPrivacyService(
+	subscriptionRepo repository.SubscriptionRepository,
+	planRepo repository.PlanRepository,
+	subscriptionService *SubscriptionService,
+) *PrivacyService {
+	return &PrivacyService{
+		subscriptionRepo:    subscriptionRepo,
+		planRepo:            planRepo,
+		subscriptionService: subscriptionService,
+	}
+}
+
+// ExportUserData - Devuelve todas las suscripciones del socio (con su historial de renovaciones)
+func (s *PrivacyService) ExportUserData(ctx context.Context, userID string) (map[string]interface{}, int, error) {
+	subscriptions, err := s.subscriptionRepo.FindAll(ctx, bson.M{"usuario_id": userID})
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al exportar suscripciones: %w", err)
+	}
+
+	responses := make([]*dtos.SubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		planNombre := ""
+		if plan, _ := s.planRepo.FindByID(ctx, subscription.PlanID); plan != nil {
+			planNombre = plan.Nombre
+		}
+		responses = append(responses, s.subscriptionService.mapSubscriptionToResponse(subscription, planNombre))
+	}
+
+	return map[string]interface{}{
+		"suscripciones": responses,
+	}, len(responses), nil
+}
+
+// EraseUserData - Anonimiza las suscripciones del socio
+// Los montos y fechas se conservan para la contabilidad, pero dejan de estar asociados al usuario
+func (s *PrivacyService) EraseUserData(ctx context.Context, userID string) (int, error) {
+	anonymized, err := s.subscriptionRepo.AnonymizeByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(anonymized), nil
+}
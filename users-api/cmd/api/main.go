@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"time"
+	"users-api/internal/clients"
 	"users-api/internal/config"
 	"users-api/internal/controllers"
+	"users-api/internal/domain"
+	"users-api/internal/mail"
 	"users-api/internal/middleware"
+	"users-api/internal/oidc"
 	"users-api/internal/repository"
+	"users-api/internal/security"
 	"users-api/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -25,16 +31,143 @@ func main() {
 
 	// 1️⃣ Capa de datos: Repository (maneja operaciones con MySQL)
 	usersRepo := repository.NewMySQLUsersRepository(cfg.MySQL)
+	sessionsRepo := repository.NewMySQLSessionsRepository(usersRepo.GetDB())
+	userTokensRepo := repository.NewMySQLUserTokensRepository(usersRepo.GetDB())
+	rolesRepo := repository.NewMySQLRolesRepository(usersRepo.GetDB())
+	lockoutEventsRepo := repository.NewMySQLLockoutEventsRepository(usersRepo.GetDB())
+	twoFactorRepo := repository.NewMySQLTwoFactorRepository(usersRepo.GetDB())
+	loginChallengesRepo := repository.NewMySQLLoginChallengesRepository(usersRepo.GetDB())
+	identitiesRepo := repository.NewMySQLIdentitiesRepository(usersRepo.GetDB())
+	oidcStatesRepo := repository.NewMySQLOIDCStatesRepository(usersRepo.GetDB())
+	privacyRequestsRepo := repository.NewMySQLPrivacyRequestsRepository(usersRepo.GetDB())
+	outboxRepo := repository.NewMySQLOutboxRepository(usersRepo.GetDB())
+
+	// Store de intentos fallidos de login (MySQL compartido entre instancias o memoria local)
+	var loginAttempts repository.LoginAttemptsStore
+	switch cfg.Login.Store {
+	case "mysql":
+		loginAttempts = repository.NewMySQLLoginAttemptsStore(usersRepo.GetDB())
+	case "memory":
+		loginAttempts = repository.NewMemoryLoginAttemptsStore()
+	default:
+		log.Fatalf("❌ Invalid LOGIN_ATTEMPT_STORE %q (expected mysql or memory)", cfg.Login.Store)
+	}
+
+	// Hasher de contraseñas (argon2id por defecto, bcrypt opcional, verifica SHA-256 legacy)
+	passwordHasher, err := security.NewPasswordHasher(cfg.Password.Algorithm, cfg.Password.BcryptCost)
+	if err != nil {
+		log.Fatalf("❌ Invalid password hasher configuration: %v", err)
+	}
+
+	// Claves asimétricas de firma JWT (RS256/EdDSA, rotación por kid)
+	signingKeys, err := security.LoadKeySet(cfg.JWT.KeysDir, cfg.JWT.ActiveKID, cfg.JWT.Algorithm)
+	if err != nil {
+		log.Fatalf("❌ Error loading JWT signing keys: %v", err)
+	}
+
+	// Cifrado de los secretos TOTP en reposo (AES-256-GCM)
+	totpSecrets, err := security.NewSecretBox(cfg.TwoFactor.EncryptionKey)
+	if err != nil {
+		log.Fatalf("❌ Invalid TWO_FACTOR_ENCRYPTION_KEY: %v", err)
+	}
+	if !totpSecrets.Enabled() {
+		log.Println("⚠️  TWO_FACTOR_ENCRYPTION_KEY not set: TOTP secrets will be stored unencrypted")
+	}
+
+	// Proveedores de login social (OpenID Connect)
+	oidcProviders, err := oidc.NewRegistry(cfg.OIDC)
+	if err != nil {
+		log.Fatalf("❌ Invalid OIDC configuration: %v", err)
+	}
+
+	// Eventos en el exchange compartido (RabbitMQ) mediante transactional outbox:
+	// los eventos se guardan en MySQL con el cambio que los origina y el relay los publica.
+	// Sin conexión quedan pendientes y el publisher reintenta conectar en cada ronda
+	rabbitPublisher, err := clients.NewRabbitMQEventPublisher(cfg.RabbitMQ.URL, cfg.RabbitMQ.Exchange)
+	if err != nil {
+		log.Printf("⚠️  Warning: No se pudo conectar a RabbitMQ: %v", err)
+		log.Println("⚠️  Los eventos quedan en la outbox hasta que RabbitMQ esté disponible")
+	}
+	defer rabbitPublisher.Close()
+	outboxRelay := services.NewOutboxRelay(outboxRepo, rabbitPublisher, services.OutboxRelayPolicy{
+		PollInterval: cfg.Outbox.PollInterval,
+		BatchSize:    cfg.Outbox.BatchSize,
+		Retention:    cfg.Outbox.Retention,
+	})
+	outboxRelay.Start(context.Background())
+	eventPublisher := services.NewOutboxEventPublisher(outboxRepo)
+
+	// Envío de emails (smtp, file o log según MAIL_DRIVER)
+	mailer, err := mail.NewMailer(cfg.Mail)
+	if err != nil {
+		log.Fatalf("❌ Invalid mail configuration: %v", err)
+	}
 
 	// 2️⃣ Capa de lógica de negocio: Service (validaciones, transformaciones, JWT)
-	usersService := services.NewUsersService(usersRepo, cfg.JWT.Secret)
+	sessionsService := services.NewSessionsService(sessionsRepo, usersRepo, rolesRepo, signingKeys, cfg.JWT.AccessTokenTTL, cfg.JWT.RefreshTokenTTL)
+	accountService := services.NewAccountService(usersRepo, userTokensRepo, passwordHasher, sessionsService, mailer,
+		cfg.Account.AppURL, cfg.Account.PasswordResetTTL, cfg.Account.EmailVerificationTTL)
+	loginProtection := services.NewLoginProtectionService(loginAttempts, lockoutEventsRepo, usersRepo, services.LoginProtectionPolicy{
+		MaxAccountFailures: cfg.Login.MaxAccountFailures,
+		MaxIPFailures:      cfg.Login.MaxIPFailures,
+		FailureWindow:      cfg.Login.FailureWindow,
+		LockoutDuration:    cfg.Login.LockoutDuration,
+		BackoffBase:        cfg.Login.BackoffBase,
+		BackoffMax:         cfg.Login.BackoffMax,
+	})
+	twoFactorService := services.NewTwoFactorService(usersRepo, twoFactorRepo, loginChallengesRepo, passwordHasher, sessionsService, loginProtection, totpSecrets, services.TwoFactorPolicy{
+		RequiredForAdmins:    cfg.TwoFactor.RequiredForAdmins,
+		Issuer:               cfg.TwoFactor.Issuer,
+		ChallengeTTL:         cfg.TwoFactor.ChallengeTTL,
+		MaxChallengeAttempts: cfg.TwoFactor.MaxChallengeAttempts,
+	})
+	usersService := services.NewUsersService(usersRepo, passwordHasher, sessionsService, accountService, loginProtection, twoFactorService)
+	oidcService := services.NewOIDCService(usersRepo, identitiesRepo, oidcStatesRepo, userTokensRepo, oidcProviders, sessionsService, twoFactorService, services.OIDCPolicy{
+		FrontendCallbackURL: cfg.OIDC.FrontendCallbackURL,
+		StateTTL:            cfg.OIDC.StateTTL,
+		LoginCodeTTL:        cfg.OIDC.LoginCodeTTL,
+		AutoProvision:       cfg.OIDC.AutoProvision,
+	})
+	rolesService := services.NewRolesService(rolesRepo, usersRepo, sessionsService)
+	privacyService := services.NewPrivacyService(privacyRequestsRepo, usersRepo, rolesRepo, identitiesRepo, twoFactorRepo, userTokensRepo, lockoutEventsRepo,
+		sessionsService, eventPublisher, cfg.Privacy.Services)
+
+	// Acuses de exportación/borrado de los demás servicios
+	privacyConsumer, err := clients.NewRabbitMQPrivacyConsumer(cfg.RabbitMQ.URL, cfg.RabbitMQ.Exchange, cfg.RabbitMQ.PrivacyQueue, privacyService)
+	if err != nil {
+		log.Printf("⚠️  Warning: No se pudo conectar a RabbitMQ como consumidor: %v", err)
+	} else {
+		defer privacyConsumer.Close()
+		if err := privacyConsumer.Start(); err != nil {
+			log.Printf("⚠️  Warning: Error iniciando consumidor: %v", err)
+		}
+	}
+
+	// Roles predefinidos (admin, branch_manager, receptionist, instructor)
+	if err := rolesService.SeedDefaultRoles(context.Background()); err != nil {
+		log.Fatalf("❌ Error seeding default roles: %v", err)
+	}
 
 	// 3️⃣ Capa de controladores: Controller (maneja HTTP requests/responses)
 	usersController := controllers.NewUsersController(usersService)
+	sessionsController := controllers.NewSessionsController(sessionsService)
+	accountController := controllers.NewAccountController(accountService)
+	rolesController := controllers.NewRolesController(rolesService)
+	lockoutsController := controllers.NewLockoutsController(loginProtection)
+	twoFactorController := controllers.NewTwoFactorController(twoFactorService)
+	internalUsersController := controllers.NewInternalUsersController(usersService)
+	oidcController := controllers.NewOIDCController(oidcService)
+	privacyController := controllers.NewPrivacyController(privacyService)
 
 	// 🌐 Configurar router HTTP con Gin
 	router := gin.Default()
 
+	// IP del cliente para limitar intentos de login: solo se confía en X-Forwarded-For
+	// de los proxies configurados (si no, cualquiera podría falsear su IP)
+	if err := router.SetTrustedProxies(cfg.Login.TrustedProxies); err != nil {
+		log.Fatalf("❌ Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Middleware CORS (debe ir primero)
 	router.Use(middleware.CORSMiddleware())
 
@@ -50,19 +183,101 @@ func main() {
 	// 📚 Rutas públicas (sin autenticación)
 	router.POST("/register", usersController.Register)
 	router.POST("/login", usersController.Login)
+	router.POST("/login/2fa", twoFactorController.LoginVerify)
+	router.POST("/login/2fa/setup", twoFactorController.LoginSetup)
+	router.POST("/login/2fa/setup/confirm", twoFactorController.LoginSetupConfirm)
+	router.POST("/token/refresh", sessionsController.Refresh)
+	router.POST("/password/forgot", accountController.ForgotPassword)
+	router.POST("/password/reset", accountController.ResetPassword)
+	router.POST("/email/verify", accountController.VerifyEmail)
+
+	// Login social (OpenID Connect, authorization code + PKCE)
+	router.GET("/auth/oidc/providers", oidcController.Providers)
+	router.GET("/auth/oidc/:provider/authorize", oidcController.Authorize)
+	router.GET("/auth/oidc/:provider/callback", oidcController.Callback)
+	router.POST("/auth/oidc/exchange", oidcController.Exchange)
+
+	// Claves públicas para que otros microservicios verifiquen los JWT
+	router.GET("/.well-known/jwks.json", sessionsController.JWKS)
+
+	// Endpoint para que otros microservicios verifiquen si una sesión fue revocada
+	router.GET("/sessions/:sid/status", sessionsController.GetStatus)
+
+	// 🔐 API interna para otros microservicios (credenciales de servicio, no JWT de usuario)
+	if len(cfg.Internal.ServiceCredentials) == 0 {
+		log.Println("⚠️  SERVICE_CREDENTIALS not set: internal endpoints will reject every request")
+	}
+	internal := router.Group("/internal")
+	internal.Use(middleware.ServiceAuthMiddleware(cfg.Internal.ServiceCredentials))
+	{
+		internal.GET("/users/:id", internalUsersController.GetByID)
+		internal.GET("/users/:id/exists", internalUsersController.Exists)
+		internal.POST("/users/batch", internalUsersController.Batch)
+	}
 
 	// 📚 Rutas protegidas (requieren JWT)
 	protected := router.Group("/")
-	protected.Use(middleware.JWTAuthMiddleware(usersService))
+	protected.Use(middleware.JWTAuthMiddleware(sessionsService))
 	{
+		protected.POST("/logout", sessionsController.Logout)
+		protected.POST("/email/verify/resend", accountController.ResendVerification)
+
+		// Perfil del usuario autenticado
+		protected.GET("/users/me", usersController.GetMe)
+		protected.PATCH("/users/me", usersController.UpdateMe)
+		protected.PUT("/users/me/password", usersController.ChangePassword)
+		protected.DELETE("/users/me", usersController.DeleteMe)
+
+		// Autenticación en dos pasos (TOTP) del usuario autenticado
+		protected.GET("/users/me/2fa", twoFactorController.Status)
+		protected.POST("/users/me/2fa/setup", twoFactorController.Setup)
+		protected.POST("/users/me/2fa/enable", twoFactorController.Enable)
+		protected.POST("/users/me/2fa/disable", twoFactorController.Disable)
+		protected.POST("/users/me/2fa/recovery-codes", twoFactorController.RegenerateRecoveryCodes)
+
+		// Identidades externas (login social) del usuario autenticado
+		protected.GET("/users/me/identities", oidcController.ListIdentities)
+		protected.POST("/users/me/identities/:provider", oidcController.Link)
+		protected.DELETE("/users/me/identities/:id", oidcController.Unlink)
+
 		// Endpoint para que otros microservicios validen usuario existe
 		protected.GET("/users/:id", usersController.GetByID)
 
-		// Solo admins pueden listar todos los usuarios
-		adminOnly := protected.Group("/")
-		adminOnly.Use(middleware.AdminOnlyMiddleware())
+		// Gestión de usuarios (permisos users:read / users:manage; admin tiene todos)
+		protected.GET("/users", middleware.RequirePermission(domain.PermissionUsersRead), usersController.List)
+		protected.PATCH("/users/:id", middleware.RequirePermission(domain.PermissionUsersManage), usersController.AdminUpdate)
+		protected.DELETE("/users/:id", middleware.RequirePermission(domain.PermissionUsersManage), usersController.Delete)
+		protected.POST("/users/:id/restore", middleware.RequirePermission(domain.PermissionUsersManage), usersController.Restore)
+
+		// Bloqueos de login por fuerza bruta (desbloqueo manual y auditoría)
+		protected.POST("/users/:id/unlock", middleware.RequirePermission(domain.PermissionUsersManage), lockoutsController.UnlockUser)
+		protected.DELETE("/lockouts/ip/:ip", middleware.RequirePermission(domain.PermissionUsersManage), lockoutsController.UnlockIP)
+		protected.GET("/lockouts", middleware.RequirePermission(domain.PermissionUsersRead), lockoutsController.List)
+		protected.DELETE("/users/:id/2fa", middleware.RequirePermission(domain.PermissionUsersManage), twoFactorController.Reset)
+
+		// Roles y permisos (roles:manage)
+		rolesAdmin := protected.Group("/")
+		rolesAdmin.Use(middleware.RequirePermission(domain.PermissionRolesManage))
+		{
+			rolesAdmin.GET("/permissions", rolesController.ListPermissions)
+			rolesAdmin.GET("/roles", rolesController.List)
+			rolesAdmin.POST("/roles", rolesController.Create)
+			rolesAdmin.PUT("/roles/:id", rolesController.Update)
+			rolesAdmin.DELETE("/roles/:id", rolesController.Delete)
+			rolesAdmin.GET("/users/:id/roles", rolesController.ListUserRoles)
+			rolesAdmin.POST("/users/:id/roles", rolesController.AssignRole)
+			rolesAdmin.DELETE("/users/:id/roles/:assignment_id", rolesController.RemoveUserRole)
+		}
+
+		// Exportación y borrado de datos de socios en todos los servicios (privacy:manage)
+		privacyAdmin := protected.Group("/privacy-requests")
+		privacyAdmin.Use(middleware.RequirePermission(domain.PermissionPrivacyManage))
 		{
-			adminOnly.GET("/users", usersController.List)
+			privacyAdmin.POST("", privacyController.Create)
+			privacyAdmin.GET("", privacyController.List)
+			privacyAdmin.GET("/:id", privacyController.GetByID)
+			privacyAdmin.POST("/:id/retry", privacyController.Retry)
+			privacyAdmin.GET("/:id/export", privacyController.Export)
 		}
 	}
 
@@ -79,8 +294,58 @@ func main() {
 	log.Printf("📚 Endpoints:")
 	log.Printf("   POST   /register - Register new user")
 	log.Printf("   POST   /login - Login user")
+	log.Printf("   POST   /login/2fa - Complete login with TOTP or recovery code")
+	log.Printf("   POST   /login/2fa/setup - Start mandatory 2FA enrollment during login")
+	log.Printf("   POST   /login/2fa/setup/confirm - Confirm mandatory 2FA enrollment and login")
+	log.Printf("   POST   /token/refresh - Rotate refresh token")
+	log.Printf("   POST   /password/forgot - Request password reset email")
+	log.Printf("   POST   /password/reset - Reset password with emailed token")
+	log.Printf("   POST   /email/verify - Verify email with emailed token")
+	log.Printf("   GET    /auth/oidc/providers - Social login providers")
+	log.Printf("   GET    /auth/oidc/:provider/authorize - Start social login")
+	log.Printf("   GET    /auth/oidc/:provider/callback - Social login callback")
+	log.Printf("   POST   /auth/oidc/exchange - Exchange social login code for tokens")
+	log.Printf("   GET    /.well-known/jwks.json - Public signing keys")
+	log.Printf("   GET    /sessions/:sid/status - Session revocation status")
+	log.Printf("   GET    /internal/users/:id - Get user by ID (service credentials)")
+	log.Printf("   GET    /internal/users/:id/exists - Check user exists (service credentials)")
+	log.Printf("   POST   /internal/users/batch - Get users by IDs (service credentials)")
+	log.Printf("   POST   /logout - Revoke current session (protected)")
+	log.Printf("   POST   /email/verify/resend - Resend verification email (protected)")
+	log.Printf("   GET    /users/me - Get own profile (protected)")
+	log.Printf("   PATCH  /users/me - Update own profile (protected)")
+	log.Printf("   PUT    /users/me/password - Change own password (protected)")
+	log.Printf("   DELETE /users/me - Delete own account (protected)")
+	log.Printf("   GET    /users/me/2fa - Two factor status (protected)")
+	log.Printf("   POST   /users/me/2fa/setup - Start TOTP enrollment (protected)")
+	log.Printf("   POST   /users/me/2fa/enable - Confirm TOTP enrollment (protected)")
+	log.Printf("   POST   /users/me/2fa/disable - Disable two factor (protected)")
+	log.Printf("   POST   /users/me/2fa/recovery-codes - Regenerate recovery codes (protected)")
+	log.Printf("   GET    /users/me/identities - List linked identities (protected)")
+	log.Printf("   POST   /users/me/identities/:provider - Link identity (protected)")
+	log.Printf("   DELETE /users/me/identities/:id - Unlink identity (protected)")
 	log.Printf("   GET    /users/:id - Get user by ID (protected)")
-	log.Printf("   GET    /users - List all users (admin only)")
+	log.Printf("   GET    /users - List users (users:read)")
+	log.Printf("   PATCH  /users/:id - Update user (users:manage)")
+	log.Printf("   DELETE /users/:id - Soft delete user (users:manage)")
+	log.Printf("   POST   /users/:id/restore - Restore deleted user (users:manage)")
+	log.Printf("   POST   /users/:id/unlock - Unlock login after lockout (users:manage)")
+	log.Printf("   DELETE /lockouts/ip/:ip - Unlock login from IP (users:manage)")
+	log.Printf("   GET    /lockouts - Lockout audit log (users:read)")
+	log.Printf("   DELETE /users/:id/2fa - Reset user two factor (users:manage)")
+	log.Printf("   GET    /permissions - Permission catalog (roles:manage)")
+	log.Printf("   GET    /roles - List roles (roles:manage)")
+	log.Printf("   POST   /roles - Create role (roles:manage)")
+	log.Printf("   PUT    /roles/:id - Update role (roles:manage)")
+	log.Printf("   DELETE /roles/:id - Delete custom role (roles:manage)")
+	log.Printf("   GET    /users/:id/roles - List user roles (roles:manage)")
+	log.Printf("   POST   /users/:id/roles - Assign role (roles:manage)")
+	log.Printf("   DELETE /users/:id/roles/:assignment_id - Remove role (roles:manage)")
+	log.Printf("   POST   /privacy-requests - Request data export or erasure (privacy:manage)")
+	log.Printf("   GET    /privacy-requests - List privacy requests (privacy:manage)")
+	log.Printf("   GET    /privacy-requests/:id - Privacy request status (privacy:manage)")
+	log.Printf("   POST   /privacy-requests/:id/retry - Republish privacy request (privacy:manage)")
+	log.Printf("   GET    /privacy-requests/:id/export - Download exported data (privacy:manage)")
 
 	// Iniciar servidor (bloquea hasta que se pare el servidor)
 	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+	"users-api/internal/oidc/mockprovider"
+)
+
+// Proveedor OpenID Connect mock para desarrollo y tests del login social
+// users-api: OIDC_PROVIDERS=mock, OIDC_MOCK_ISSUER=http://localhost:9999,
+// OIDC_MOCK_CLIENT_ID=users-api, OIDC_MOCK_CLIENT_SECRET=mock-secret
+func main() {
+	port := getEnv("MOCK_OIDC_PORT", "9999")
+	cfg := mockprovider.Config{
+		Issuer:       getEnv("MOCK_OIDC_ISSUER", "http://localhost:"+port),
+		ClientID:     getEnv("MOCK_OIDC_CLIENT_ID", "users-api"),
+		ClientSecret: getEnv("MOCK_OIDC_CLIENT_SECRET", "mock-secret"),
+	}
+
+	provider, err := mockprovider.New(cfg)
+	if err != nil {
+		log.Fatalf("❌ Error creating mock OIDC provider: %v", err)
+	}
+
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           provider,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("🧪 Mock OIDC provider listening on port %s (issuer %s, client_id %s)", port, cfg.Issuer, cfg.ClientID)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("❌ Server error: %v", err)
+	}
+}
+
+func getEnv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
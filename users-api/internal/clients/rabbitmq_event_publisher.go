@@ -0,0 +1,173 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// publishConfirmTimeout es cuánto se espera la confirmación del broker
+const publishConfirmTimeout = 5 * time.Second
+
+// RabbitMQEventPublisher - Implementación de EventPublisher con RabbitMQ
+// Si la conexión se cae (o no se pudo abrir al iniciar) se reconecta en la próxima publicación
+type RabbitMQEventPublisher struct {
+	url      string
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	confirms chan amqp.Confirmation // Publisher confirms: el broker confirma cada mensaje
+	exchange string
+	mu       sync.Mutex // Un canal AMQP no se puede usar desde varias goroutines a la vez
+}
+
+// NewRabbitMQEventPublisher - Constructor con DI
+// Siempre devuelve el publisher; el error indica que la primera conexión falló
+func NewRabbitMQEventPublisher(url, exchange string) (*RabbitMQEventPublisher, error) {
+	publisher := &RabbitMQEventPublisher{
+		url:      url,
+		exchange: exchange,
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	return publisher, publisher.connect()
+}
+
+// connect abre la conexión y el canal y declara el exchange (se llama con mu tomado)
+func (r *RabbitMQEventPublisher) connect() error {
+	conn, err := amqp.Dial(r.url)
+	if err != nil {
+		return fmt.Errorf("error conectando a RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error creando canal: %w", err)
+	}
+
+	// Declarar exchange
+	err = channel.ExchangeDeclare(
+		r.exchange, // name
+		"topic",    // type
+		true,       // durable
+		false,      // auto-deleted
+		false,      // internal
+		false,      // no-wait
+		nil,        // arguments
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return fmt.Errorf("error declarando exchange: %w", err)
+	}
+
+	// Con publisher confirms un evento solo se da por publicado cuando el broker lo aceptó
+	if err := channel.Confirm(false); err != nil {
+		channel.Close()
+		conn.Close()
+		return fmt.Errorf("error activando confirmaciones: %w", err)
+	}
+
+	log.Printf("✅ Conectado a RabbitMQ (Exchange: %s)\n", r.exchange)
+
+	r.conn = conn
+	r.channel = channel
+	r.confirms = channel.NotifyPublish(make(chan amqp.Confirmation, 1))
+	return nil
+}
+
+// disconnect descarta la conexión actual para reconectar en la próxima publicación (se llama con mu tomado)
+func (r *RabbitMQEventPublisher) disconnect() {
+	if r.channel != nil {
+		r.channel.Close()
+		r.channel = nil
+	}
+	if r.conn != nil {
+		r.conn.Close()
+		r.conn = nil
+	}
+}
+
+// rabbitMQEvent es el sobre común de los eventos del exchange (mismo formato que subscriptions-api)
+type rabbitMQEvent struct {
+	Action    string                 `json:"action"`
+	Type      string                 `json:"type"`
+	ID        string                 `json:"id"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// PublishEvent - Implementa la interface EventPublisher
+func (r *RabbitMQEventPublisher) PublishEvent(eventType, action, id string, data map[string]interface{}) error {
+	event := rabbitMQEvent{
+		Action:    action,
+		Type:      eventType,
+		ID:        id,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error serializando evento: %w", err)
+	}
+
+	// Routing key: {type}.{action}
+	routingKey := fmt.Sprintf("%s.%s", eventType, action)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.channel == nil || r.conn.IsClosed() {
+		r.disconnect()
+		if err := r.connect(); err != nil {
+			return err
+		}
+	}
+
+	err = r.channel.Publish(
+		r.exchange, // exchange
+		routingKey, // routing key
+		false,      // mandatory
+		false,      // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+		},
+	)
+
+	if err != nil {
+		log.Printf("❌ Error publicando evento: %v\n", err)
+		r.disconnect()
+		return fmt.Errorf("error publicando evento: %w", err)
+	}
+
+	select {
+	case confirm, ok := <-r.confirms:
+		if !ok || !confirm.Ack {
+			r.disconnect()
+			return fmt.Errorf("error publicando evento: el broker no confirmó %s", routingKey)
+		}
+	case <-time.After(publishConfirmTimeout):
+		r.disconnect()
+		return fmt.Errorf("error publicando evento: sin confirmación del broker para %s", routingKey)
+	}
+
+	log.Printf("📤 Evento publicado: %s (ID: %s)\n", routingKey, id)
+	return nil
+}
+
+// Close - Cierra la conexión
+func (r *RabbitMQEventPublisher) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.disconnect()
+	return nil
+}
@@ -0,0 +1,197 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"users-api/internal/domain"
+	"users-api/internal/services"
+
+	"github.com/streadway/amqp"
+)
+
+// RabbitMQPrivacyConsumer consume los acuses (privacy.*_completed) que publican los servicios
+// al terminar una exportación o un borrado de datos
+type RabbitMQPrivacyConsumer struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	queue   string
+	service services.PrivacyService
+}
+
+// privacyAckEvent es el evento de acuse; Data.Data trae los datos exportados (solo en exportaciones)
+type privacyAckEvent struct {
+	Action    string `json:"action"`
+	Type      string `json:"type"`
+	ID        string `json:"id"` // ID del pedido
+	Timestamp string `json:"timestamp"`
+	Data      struct {
+		Service string          `json:"service"`
+		Status  string          `json:"status"` // completed | failed
+		Error   string          `json:"error"`
+		Records int             `json:"records"`
+		Data    json.RawMessage `json:"data"`
+	} `json:"data"`
+}
+
+// NewRabbitMQPrivacyConsumer - Constructor con DI
+func NewRabbitMQPrivacyConsumer(url, exchange, queueName string, privacyService services.PrivacyService) (*RabbitMQPrivacyConsumer, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// Declarar exchange
+	err = channel.ExchangeDeclare(
+		exchange, // name
+		"topic",  // type
+		true,     // durable
+		false,    // auto-deleted
+		false,    // internal
+		false,    // no-wait
+		nil,      // arguments
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, err
+	}
+
+	// Declarar cola
+	queue, err := channel.QueueDeclare(
+		queueName, // name
+		true,      // durable
+		false,     // delete when unused
+		false,     // exclusive
+		false,     // no-wait
+		nil,       // arguments
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, err
+	}
+
+	// Bind a los acuses de exportación y borrado
+	bindings := []string{
+		services.PrivacyEventType + "." + domain.PrivacyRequestExport + "_completed",
+		services.PrivacyEventType + "." + domain.PrivacyRequestErasure + "_completed",
+	}
+
+	for _, binding := range bindings {
+		err = channel.QueueBind(
+			queue.Name, // queue name
+			binding,    // routing key
+			exchange,   // exchange
+			false,
+			nil,
+		)
+		if err != nil {
+			channel.Close()
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	log.Printf("✅ Conectado a RabbitMQ como consumidor (Queue: %s)\n", queueName)
+
+	return &RabbitMQPrivacyConsumer{
+		conn:    conn,
+		channel: channel,
+		queue:   queue.Name,
+		service: privacyService,
+	}, nil
+}
+
+// Start inicia el consumo de mensajes
+func (r *RabbitMQPrivacyConsumer) Start() error {
+	msgs, err := r.channel.Consume(
+		r.queue, // queue
+		"",      // consumer
+		false,   // auto-ack
+		false,   // exclusive
+		false,   // no-local
+		false,   // no-wait
+		nil,     // args
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Println("🎧 Escuchando acuses de privacidad de RabbitMQ...")
+
+	go func() {
+		for msg := range msgs {
+			r.handleMessage(msg)
+		}
+	}()
+
+	return nil
+}
+
+func (r *RabbitMQPrivacyConsumer) handleMessage(msg amqp.Delivery) {
+	var event privacyAckEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Printf("❌ Error decodificando acuse: %v\n", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	requestID, err := strconv.ParseUint(event.ID, 10, 32)
+	if err != nil || event.Data.Service == "" {
+		log.Printf("❌ Acuse inválido: %s (ID: %s)\n", msg.RoutingKey, event.ID)
+		msg.Nack(false, false)
+		return
+	}
+
+	ack := domain.PrivacyAck{
+		Service:    event.Data.Service,
+		Estado:     event.Data.Status,
+		Error:      event.Data.Error,
+		Records:    event.Data.Records,
+		Data:       event.Data.Data,
+		ReceivedAt: time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := r.service.HandleAck(ctx, uint(requestID), ack); err != nil {
+		if isPermanentAckError(err) {
+			log.Printf("⚠️  Acuse descartado (%s): %v\n", msg.RoutingKey, err)
+			msg.Nack(false, false)
+			return
+		}
+		log.Printf("❌ Error registrando acuse: %v\n", err)
+		msg.Nack(false, true) // Requeue
+		return
+	}
+
+	msg.Ack(false)
+}
+
+// isPermanentAckError indica si reintentar el acuse no tiene sentido (pedido inexistente o servicio ajeno)
+func isPermanentAckError(err error) bool {
+	msg := err.Error()
+	return msg == "privacy request not found" || strings.Contains(msg, "is not part of")
+}
+
+// Close cierra las conexiones
+func (r *RabbitMQPrivacyConsumer) Close() error {
+	if r.channel != nil {
+		r.channel.Close()
+	}
+	if r.conn != nil {
+		return r.conn.Close()
+	}
+	return nil
+}
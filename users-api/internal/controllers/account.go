@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"net/http"
+	"users-api/internal/domain"
+	"users-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountController maneja las peticiones HTTP de recuperación de contraseña y verificación de email
+type AccountController struct {
+	service services.AccountService
+}
+
+// NewAccountController crea una nueva instancia del controller
+// Dependency Injection: recibe el service como parámetro
+func NewAccountController(accountService services.AccountService) *AccountController {
+	return &AccountController{
+		service: accountService,
+	}
+}
+
+// ForgotPassword maneja POST /password/forgot - Envía un link de reset de contraseña
+// Responde 202 exista o no el email (no revela qué cuentas existen)
+// @Summary Solicita el reset de contraseña
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param body body domain.ForgotPasswordRequest true "Email de la cuenta"
+// @Success 202 {object} map[string]interface{} "message"
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Router /password/forgot [post]
+func (c *AccountController) ForgotPassword(ctx *gin.Context) {
+	var req domain.ForgotPasswordRequest
+
+	// Parsear JSON del body
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := c.service.RequestPasswordReset(ctx.Request.Context(), req.Email); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to request password reset",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": "If the email belongs to an account, a reset link has been sent",
+	})
+}
+
+// ResetPassword maneja POST /password/reset - Establece una nueva contraseña con el token recibido por email
+// @Summary Confirma el reset de contraseña
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param body body domain.ResetPasswordRequest true "Token y nueva contraseña"
+// @Success 204
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Router /password/reset [post]
+func (c *AccountController) ResetPassword(ctx *gin.Context) {
+	var req domain.ResetPasswordRequest
+
+	// Parsear JSON del body
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := c.service.ResetPassword(ctx.Request.Context(), req); err != nil {
+		ctx.JSON(userErrorStatus(err), gin.H{
+			"error":   "Failed to reset password",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// VerifyEmail maneja POST /email/verify - Confirma el email con el token recibido
+// @Summary Verifica el email
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param body body domain.VerifyEmailRequest true "Token de verificación"
+// @Success 204
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Router /email/verify [post]
+func (c *AccountController) VerifyEmail(ctx *gin.Context) {
+	var req domain.VerifyEmailRequest
+
+	// Parsear JSON del body
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := c.service.VerifyEmail(ctx.Request.Context(), req.Token); err != nil {
+		ctx.JSON(userErrorStatus(err), gin.H{
+			"error":   "Failed to verify email",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ResendVerification maneja POST /email/verify/resend - Reenvía el link de verificación al usuario autenticado
+// @Summary Reenvía el email de verificación
+// @Tags account
+// @Produce json
+// @Success 202 {object} map[string]interface{} "message"
+// @Failure 409 {object} map[string]interface{} "error, details"
+// @Router /email/verify/resend [post]
+func (c *AccountController) ResendVerification(ctx *gin.Context) {
+	if err := c.service.SendEmailVerification(ctx.Request.Context(), ctx.GetUint("id_usuario")); err != nil {
+		statusCode := userErrorStatus(err)
+		if err.Error() == "email already verified" {
+			statusCode = http.StatusConflict
+		}
+
+		ctx.JSON(statusCode, gin.H{
+			"error":   "Failed to send verification email",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": "Verification email sent",
+	})
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"net/http"
+	"users-api/internal/domain"
+	"users-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InternalUsersController expone la consulta de usuarios para otros microservicios
+// Se autentica con credenciales de servicio (no con el JWT de un usuario)
+type InternalUsersController struct {
+	service services.UsersService
+}
+
+// NewInternalUsersController crea una nueva instancia del controller
+// Dependency Injection: recibe el service como parámetro
+func NewInternalUsersController(usersService services.UsersService) *InternalUsersController {
+	return &InternalUsersController{
+		service: usersService,
+	}
+}
+
+// GetByID maneja GET /internal/users/:id - Obtiene un usuario por ID
+// @Summary Obtiene un usuario (uso interno entre servicios)
+// @Tags internal
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} domain.UserResponse
+// @Failure 401 {object} map[string]interface{} "error"
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /internal/users/{id} [get]
+func (c *InternalUsersController) GetByID(ctx *gin.Context) {
+	id, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := c.service.GetByID(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(userErrorStatus(err), gin.H{
+			"error":   "Failed to get user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// Batch maneja POST /internal/users/batch - Obtiene varios usuarios por ID
+// @Summary Obtiene varios usuarios en una sola llamada (máx 500 IDs)
+// @Tags internal
+// @Accept json
+// @Produce json
+// @Param body body domain.UsersBatchRequest true "IDs de usuario"
+// @Success 200 {object} domain.UsersBatchResponse
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Failure 401 {object} map[string]interface{} "error"
+// @Router /internal/users/batch [post]
+func (c *InternalUsersController) Batch(ctx *gin.Context) {
+	var req domain.UsersBatchRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.service.GetByIDs(ctx.Request.Context(), req.IDs)
+	if err != nil {
+		ctx.JSON(userErrorStatus(err), gin.H{
+			"error":   "Failed to get users",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Exists maneja GET /internal/users/:id/exists - Verifica si existe un usuario
+// @Summary Verifica si existe un usuario activo
+// @Tags internal
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} domain.UserExistsResponse
+// @Failure 401 {object} map[string]interface{} "error"
+// @Router /internal/users/{id}/exists [get]
+func (c *InternalUsersController) Exists(ctx *gin.Context) {
+	id, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	exists, err := c.service.Exists(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to check user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.UserExistsResponse{
+		ID:     id,
+		Exists: exists,
+	})
+}
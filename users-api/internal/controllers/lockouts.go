@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"users-api/internal/domain"
+	"users-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LockoutsController maneja el desbloqueo manual y la auditoría de bloqueos de login
+type LockoutsController struct {
+	service services.LoginProtectionService
+}
+
+// NewLockoutsController crea una nueva instancia del controller
+// Dependency Injection: recibe el service como parámetro
+func NewLockoutsController(protectionService services.LoginProtectionService) *LockoutsController {
+	return &LockoutsController{
+		service: protectionService,
+	}
+}
+
+// UnlockUser maneja POST /users/:id/unlock - Desbloquea el login de un usuario
+// @Summary Desbloquea una cuenta bloqueada por intentos fallidos
+// @Tags lockouts
+// @Param id path int true "User ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /users/{id}/unlock [post]
+func (c *LockoutsController) UnlockUser(ctx *gin.Context) {
+	id, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.UnlockUser(ctx.Request.Context(), ctx.GetUint("id_usuario"), id); err != nil {
+		ctx.JSON(userErrorStatus(err), gin.H{
+			"error":   "Failed to unlock user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// UnlockIP maneja DELETE /lockouts/ip/:ip - Desbloquea una IP
+// @Summary Desbloquea una IP bloqueada por intentos fallidos
+// @Tags lockouts
+// @Param ip path string true "IP"
+// @Success 204
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Router /lockouts/ip/{ip} [delete]
+func (c *LockoutsController) UnlockIP(ctx *gin.Context) {
+	if err := c.service.UnlockIP(ctx.Request.Context(), ctx.GetUint("id_usuario"), ctx.Param("ip")); err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "invalid") {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, gin.H{
+			"error":   "Failed to unlock IP",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// List maneja GET /lockouts - Auditoría de bloqueos y desbloqueos
+// @Summary Lista los eventos de bloqueo de login
+// @Tags lockouts
+// @Produce json
+// @Param usuario_id query int false "Filtrar por usuario"
+// @Param ip query string false "Filtrar por IP"
+// @Param limit query int false "Máximo de eventos (default 100, máx 500)"
+// @Success 200 {object} map[string]interface{} "events, count"
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Router /lockouts [get]
+func (c *LockoutsController) List(ctx *gin.Context) {
+	var query domain.ListLockoutEventsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	events, err := c.service.ListEvents(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to list lockout events",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"events": events,
+		"count":  len(events),
+	})
+}
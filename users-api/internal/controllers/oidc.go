@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"users-api/internal/domain"
+	"users-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCController maneja el login social (OpenID Connect) y las identidades vinculadas
+type OIDCController struct {
+	service services.OIDCService
+}
+
+// NewOIDCController crea una nueva instancia del controller
+// Dependency Injection: recibe el service como parámetro
+func NewOIDCController(oidcService services.OIDCService) *OIDCController {
+	return &OIDCController{
+		service: oidcService,
+	}
+}
+
+// Providers maneja GET /auth/oidc/providers - Lista los proveedores habilitados
+// @Summary Lista los proveedores de login social
+// @Tags oidc
+// @Produce json
+// @Success 200 {object} map[string]interface{} "providers"
+// @Router /auth/oidc/providers [get]
+func (c *OIDCController) Providers(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"providers": c.service.Providers(),
+	})
+}
+
+// Authorize maneja GET /auth/oidc/:provider/authorize - Redirige al proveedor
+// @Summary Inicia el login social (authorization code + PKCE)
+// @Tags oidc
+// @Param provider path string true "Proveedor (ej: google)"
+// @Param redirect query string false "Ruta del frontend a la que volver después del login"
+// @Success 302
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /auth/oidc/{provider}/authorize [get]
+func (c *OIDCController) Authorize(ctx *gin.Context) {
+	authURL, err := c.service.StartLogin(ctx.Request.Context(), ctx.Param("provider"), ctx.Query("redirect"))
+	if err != nil {
+		ctx.JSON(oidcErrorStatus(err), gin.H{
+			"error":   "Failed to start social login",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// Callback maneja GET /auth/oidc/:provider/callback - Vuelta desde el proveedor
+// Siempre redirige al frontend: con ?code= para canjear en /auth/oidc/exchange o con ?error=
+// @Summary Callback del proveedor OIDC
+// @Tags oidc
+// @Param provider path string true "Proveedor"
+// @Param code query string false "Authorization code"
+// @Param state query string false "State"
+// @Success 302
+// @Router /auth/oidc/{provider}/callback [get]
+func (c *OIDCController) Callback(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+
+	var (
+		result domain.OIDCCallbackResult
+		err    error
+	)
+	if providerErr := ctx.Query("error"); providerErr != "" {
+		err = errors.New("access denied")
+	} else {
+		result, err = c.service.HandleCallback(ctx.Request.Context(), provider, ctx.Query("code"), ctx.Query("state"))
+	}
+	if err != nil {
+		log.Printf("⚠️  Social login with %s failed: %v", provider, err)
+		result.Provider = provider
+	}
+
+	ctx.Redirect(http.StatusFound, c.service.CallbackRedirectURL(result, err))
+}
+
+// Exchange maneja POST /auth/oidc/exchange - Canjea el código de login por tokens
+// @Summary Completa el login social
+// @Tags oidc
+// @Accept json
+// @Produce json
+// @Param body body domain.OIDCExchangeRequest true "Código recibido en el callback"
+// @Success 200 {object} map[string]interface{} "user, token, refresh_token, expires_in (o challenge de 2FA)"
+// @Failure 401 {object} map[string]interface{} "error, details"
+// @Router /auth/oidc/exchange [post]
+func (c *OIDCController) Exchange(ctx *gin.Context) {
+	var req domain.OIDCExchangeRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.service.Exchange(ctx.Request.Context(), req.Code)
+	if err != nil {
+		ctx.JSON(oidcErrorStatus(err), gin.H{
+			"error":   "Social login failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Usuario con 2FA: completar con POST /login/2fa
+	if result.Challenge != nil {
+		ctx.JSON(http.StatusOK, result.Challenge)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"user":          result.User,
+		"token":         result.Tokens.AccessToken,
+		"refresh_token": result.Tokens.RefreshToken,
+		"expires_in":    result.Tokens.ExpiresIn,
+	})
+}
+
+// ListIdentities maneja GET /users/me/identities - Identidades externas del usuario autenticado
+// @Summary Lista las identidades vinculadas
+// @Tags oidc
+// @Produce json
+// @Success 200 {object} map[string]interface{} "identities"
+// @Router /users/me/identities [get]
+func (c *OIDCController) ListIdentities(ctx *gin.Context) {
+	identities, err := c.service.ListIdentities(ctx.Request.Context(), ctx.GetUint("id_usuario"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to list identities",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"identities": identities,
+	})
+}
+
+// Link maneja POST /users/me/identities/:provider - Inicia la vinculación de una identidad
+// Devuelve la URL del proveedor; al volver, el callback redirige al frontend con ?linked=<provider>
+// @Summary Vincula una identidad externa a la cuenta
+// @Tags oidc
+// @Produce json
+// @Param provider path string true "Proveedor"
+// @Success 200 {object} map[string]interface{} "authorization_url"
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /users/me/identities/{provider} [post]
+func (c *OIDCController) Link(ctx *gin.Context) {
+	authURL, err := c.service.StartLink(ctx.Request.Context(), ctx.GetUint("id_usuario"), ctx.Param("provider"))
+	if err != nil {
+		ctx.JSON(oidcErrorStatus(err), gin.H{
+			"error":   "Failed to start identity linking",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"authorization_url": authURL,
+	})
+}
+
+// Unlink maneja DELETE /users/me/identities/:id - Desvincula una identidad
+// @Summary Desvincula una identidad externa
+// @Tags oidc
+// @Param id path int true "Identity ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Failure 409 {object} map[string]interface{} "error, details"
+// @Router /users/me/identities/{id} [delete]
+func (c *OIDCController) Unlink(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid identity ID",
+			"details": "ID must be a positive integer",
+		})
+		return
+	}
+
+	if err := c.service.Unlink(ctx.Request.Context(), ctx.GetUint("id_usuario"), uint(id)); err != nil {
+		ctx.JSON(oidcErrorStatus(err), gin.H{
+			"error":   "Failed to unlink identity",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// oidcErrorStatus determina el código HTTP según el error del service
+func oidcErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "invalid or expired token":
+		return http.StatusUnauthorized
+	case contains(msg, "not found"):
+		return http.StatusNotFound
+	case contains(msg, "cannot unlink"):
+		return http.StatusConflict
+	case contains(msg, "must"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"users-api/internal/domain"
+	"users-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrivacyController maneja los pedidos de exportación y borrado de datos de socios
+type PrivacyController struct {
+	service services.PrivacyService
+}
+
+// NewPrivacyController crea una nueva instancia del controller
+// Dependency Injection: recibe el service como parámetro
+func NewPrivacyController(privacyService services.PrivacyService) *PrivacyController {
+	return &PrivacyController{
+		service: privacyService,
+	}
+}
+
+// Create maneja POST /privacy-requests - Inicia una exportación o un borrado
+// @Summary Crea un pedido de exportación o borrado de datos de un socio
+// @Tags privacy
+// @Accept json
+// @Produce json
+// @Param body body domain.PrivacyRequestCreate true "usuario_id y tipo (export | erasure)"
+// @Success 202 {object} domain.PrivacyRequest
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Failure 403 {object} map[string]interface{} "error, details"
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /privacy-requests [post]
+func (c *PrivacyController) Create(ctx *gin.Context) {
+	var req domain.PrivacyRequestCreate
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	request, err := c.service.CreateRequest(ctx.Request.Context(), ctx.GetUint("id_usuario"), req)
+	if err != nil {
+		ctx.JSON(privacyErrorStatus(err), gin.H{
+			"error":   "Failed to create privacy request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, request)
+}
+
+// List maneja GET /privacy-requests - Lista los pedidos
+// @Summary Lista los pedidos de exportación y borrado
+// @Tags privacy
+// @Produce json
+// @Param usuario_id query int false "Filtrar por socio"
+// @Param estado query string false "pending | completed | failed"
+// @Param limit query int false "Máximo de pedidos (default 100, máx 500)"
+// @Success 200 {object} map[string]interface{} "requests, count"
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Router /privacy-requests [get]
+func (c *PrivacyController) List(ctx *gin.Context) {
+	var query domain.ListPrivacyRequestsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	requests, err := c.service.ListRequests(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.JSON(privacyErrorStatus(err), gin.H{
+			"error":   "Failed to list privacy requests",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"requests": requests,
+		"count":    len(requests),
+	})
+}
+
+// GetByID maneja GET /privacy-requests/:id - Estado de un pedido y de cada servicio
+// @Summary Obtiene un pedido con los acuses de cada servicio
+// @Tags privacy
+// @Produce json
+// @Param id path int true "Request ID"
+// @Success 200 {object} domain.PrivacyRequest
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /privacy-requests/{id} [get]
+func (c *PrivacyController) GetByID(ctx *gin.Context) {
+	id, ok := parsePrivacyRequestID(ctx)
+	if !ok {
+		return
+	}
+
+	request, err := c.service.GetRequest(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(privacyErrorStatus(err), gin.H{
+			"error":   "Failed to get privacy request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, request)
+}
+
+// Retry maneja POST /privacy-requests/:id/retry - Reenvía un pedido pendiente o fallido
+// @Summary Reenvía el pedido a los servicios que no confirmaron o fallaron
+// @Tags privacy
+// @Produce json
+// @Param id path int true "Request ID"
+// @Success 202 {object} domain.PrivacyRequest
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Failure 409 {object} map[string]interface{} "error, details"
+// @Router /privacy-requests/{id}/retry [post]
+func (c *PrivacyController) Retry(ctx *gin.Context) {
+	id, ok := parsePrivacyRequestID(ctx)
+	if !ok {
+		return
+	}
+
+	request, err := c.service.RetryRequest(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(privacyErrorStatus(err), gin.H{
+			"error":   "Failed to retry privacy request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, request)
+}
+
+// Export maneja GET /privacy-requests/:id/export - Descarga los datos exportados
+// @Summary Descarga el paquete de datos de una exportación terminada
+// @Tags privacy
+// @Produce application/zip
+// @Produce json
+// @Param id path int true "Request ID"
+// @Param format query string false "zip (default) | json"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Failure 409 {object} map[string]interface{} "error, details"
+// @Router /privacy-requests/{id}/export [get]
+func (c *PrivacyController) Export(ctx *gin.Context) {
+	id, ok := parsePrivacyRequestID(ctx)
+	if !ok {
+		return
+	}
+
+	format := ctx.DefaultQuery("format", "zip")
+	if format != "zip" && format != "json" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid format",
+			"details": "format must be zip or json",
+		})
+		return
+	}
+
+	bundle, err := c.service.ExportBundle(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(privacyErrorStatus(err), gin.H{
+			"error":   "Failed to export user data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("usuario-%d-datos-%d", bundle.UsuarioID, bundle.RequestID)
+	if format == "json" {
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		ctx.JSON(http.StatusOK, bundle)
+		return
+	}
+
+	archive, err := zipExportBundle(bundle)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to export user data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	ctx.Data(http.StatusOK, "application/zip", archive)
+}
+
+// zipExportBundle arma un ZIP con manifest.json y un <servicio>.json por cada servicio
+func zipExportBundle(bundle domain.PrivacyExportBundle) ([]byte, error) {
+	serviceNames := make([]string, 0, len(bundle.Services))
+	for name := range bundle.Services {
+		serviceNames = append(serviceNames, name)
+	}
+	sort.Strings(serviceNames)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	manifest, err := json.MarshalIndent(gin.H{
+		"request_id":   bundle.RequestID,
+		"usuario_id":   bundle.UsuarioID,
+		"generated_at": bundle.GeneratedAt,
+		"services":     serviceNames,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeZipFile(archive, "manifest.json", manifest); err != nil {
+		return nil, err
+	}
+
+	for _, name := range serviceNames {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, bundle.Services[name], "", "  "); err != nil {
+			return nil, fmt.Errorf("invalid data from %s: %w", name, err)
+		}
+		if err := writeZipFile(archive, name+".json", indented.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZipFile(archive *zip.Writer, name string, content []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// parsePrivacyRequestID obtiene el :id de la ruta (responde 400 si no es válido)
+func parsePrivacyRequestID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid privacy request ID",
+			"details": "ID must be a positive integer",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// privacyErrorStatus determina el código HTTP según el error del service
+func privacyErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case contains(msg, "not found"):
+		return http.StatusNotFound
+	case msg == "cannot erase your own account":
+		return http.StatusForbidden
+	case msg == "privacy request already completed" || msg == "export is not ready yet":
+		return http.StatusConflict
+	case contains(msg, "must") || contains(msg, "is not an export"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
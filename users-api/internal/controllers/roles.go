@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"users-api/internal/domain"
+	"users-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RolesController maneja las peticiones HTTP de roles y asignaciones
+type RolesController struct {
+	service services.RolesService
+}
+
+// NewRolesController crea una nueva instancia del controller
+// Dependency Injection: recibe el service como parámetro
+func NewRolesController(rolesService services.RolesService) *RolesController {
+	return &RolesController{
+		service: rolesService,
+	}
+}
+
+// ListPermissions maneja GET /permissions - Catálogo de permisos asignables
+// @Summary Lista los permisos disponibles
+// @Tags roles
+// @Produce json
+// @Success 200 {object} map[string]interface{} "permissions"
+// @Router /permissions [get]
+func (c *RolesController) ListPermissions(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"permissions": domain.AllPermissions,
+	})
+}
+
+// List maneja GET /roles - Lista los roles con sus permisos
+// @Summary Lista roles
+// @Tags roles
+// @Produce json
+// @Success 200 {array} domain.Role
+// @Failure 500 {object} map[string]interface{} "error, details"
+// @Router /roles [get]
+func (c *RolesController) List(ctx *gin.Context) {
+	roles, err := c.service.ListRoles(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to list roles",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"roles": roles,
+		"count": len(roles),
+	})
+}
+
+// Create maneja POST /roles - Crea un rol personalizado
+// @Summary Crea un rol
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param role body domain.RoleCreate true "Nombre y permisos"
+// @Success 201 {object} domain.Role
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Failure 409 {object} map[string]interface{} "error, details"
+// @Router /roles [post]
+func (c *RolesController) Create(ctx *gin.Context) {
+	var roleCreate domain.RoleCreate
+
+	// Parsear JSON del body
+	if err := ctx.ShouldBindJSON(&roleCreate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	role, err := c.service.CreateRole(ctx.Request.Context(), roleCreate)
+	if err != nil {
+		ctx.JSON(roleErrorStatus(err), gin.H{
+			"error":   "Failed to create role",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, role)
+}
+
+// Update maneja PUT /roles/:id - Modifica descripción y/o permisos de un rol
+// @Summary Modifica un rol
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Param role body domain.RoleUpdate true "Campos a modificar"
+// @Success 200 {object} domain.Role
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /roles/{id} [put]
+func (c *RolesController) Update(ctx *gin.Context) {
+	id, ok := parseRoleID(ctx)
+	if !ok {
+		return
+	}
+
+	var roleUpdate domain.RoleUpdate
+
+	// Parsear JSON del body
+	if err := ctx.ShouldBindJSON(&roleUpdate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	role, err := c.service.UpdateRole(ctx.Request.Context(), id, roleUpdate)
+	if err != nil {
+		ctx.JSON(roleErrorStatus(err), gin.H{
+			"error":   "Failed to update role",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+// Delete maneja DELETE /roles/:id - Elimina un rol personalizado
+// @Summary Elimina un rol
+// @Tags roles
+// @Param id path int true "Role ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Failure 409 {object} map[string]interface{} "error, details"
+// @Router /roles/{id} [delete]
+func (c *RolesController) Delete(ctx *gin.Context) {
+	id, ok := parseRoleID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.DeleteRole(ctx.Request.Context(), id); err != nil {
+		ctx.JSON(roleErrorStatus(err), gin.H{
+			"error":   "Failed to delete role",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListUserRoles maneja GET /users/:id/roles - Roles asignados a un usuario
+// @Summary Lista los roles de un usuario
+// @Tags roles
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} domain.UserRole
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /users/{id}/roles [get]
+func (c *RolesController) ListUserRoles(ctx *gin.Context) {
+	userID, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	assignments, err := c.service.ListUserRoles(ctx.Request.Context(), userID)
+	if err != nil {
+		ctx.JSON(roleErrorStatus(err), gin.H{
+			"error":   "Failed to list user roles",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"roles": assignments,
+		"count": len(assignments),
+	})
+}
+
+// AssignRole maneja POST /users/:id/roles - Asigna un rol a un usuario
+// El usuario debe volver a loguearse: sus sesiones se revocan para que el JWT refleje el cambio
+// @Summary Asigna un rol
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param body body domain.UserRoleAssign true "Rol y sucursal"
+// @Success 201 {object} domain.UserRole
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Failure 409 {object} map[string]interface{} "error, details"
+// @Router /users/{id}/roles [post]
+func (c *RolesController) AssignRole(ctx *gin.Context) {
+	userID, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var assign domain.UserRoleAssign
+
+	// Parsear JSON del body
+	if err := ctx.ShouldBindJSON(&assign); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	assignment, err := c.service.AssignRole(ctx.Request.Context(), userID, assign)
+	if err != nil {
+		ctx.JSON(roleErrorStatus(err), gin.H{
+			"error":   "Failed to assign role",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, assignment)
+}
+
+// RemoveUserRole maneja DELETE /users/:id/roles/:assignment_id - Quita un rol a un usuario
+// @Summary Quita un rol
+// @Tags roles
+// @Param id path int true "User ID"
+// @Param assignment_id path int true "ID de la asignación"
+// @Success 204
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /users/{id}/roles/{assignment_id} [delete]
+func (c *RolesController) RemoveUserRole(ctx *gin.Context) {
+	userID, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	assignmentID, err := strconv.ParseUint(ctx.Param("assignment_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid assignment ID",
+			"details": "ID must be a positive integer",
+		})
+		return
+	}
+
+	if err := c.service.RemoveUserRole(ctx.Request.Context(), userID, uint(assignmentID)); err != nil {
+		ctx.JSON(roleErrorStatus(err), gin.H{
+			"error":   "Failed to remove role",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// parseRoleID extrae el ID del rol del path param; responde 400 si es inválido
+func parseRoleID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid role ID",
+			"details": "ID must be a positive integer",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// roleErrorStatus determina el código HTTP según el error del service
+func roleErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case contains(msg, "not found"):
+		return http.StatusNotFound
+	case msg == "role already exists" || msg == "role already assigned" || msg == "cannot delete built-in role":
+		return http.StatusConflict
+	case msg == "cannot modify admin role permissions":
+		return http.StatusForbidden
+	case contains(msg, "required") || contains(msg, "must") || contains(msg, "invalid"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"net/http"
+	"users-api/internal/domain"
+	"users-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionsController maneja las peticiones HTTP de sesiones (refresh y logout)
+type SessionsController struct {
+	service services.SessionsService
+}
+
+// NewSessionsController crea una nueva instancia del controller
+// Dependency Injection: recibe el service como parámetro
+func NewSessionsController(sessionsService services.SessionsService) *SessionsController {
+	return &SessionsController{
+		service: sessionsService,
+	}
+}
+
+// Refresh maneja POST /token/refresh - Rota el refresh token y emite un nuevo access token
+// @Summary Renueva el access token
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Param body body domain.RefreshRequest true "Refresh token"
+// @Success 200 {object} map[string]interface{} "user, token, refresh_token, expires_in"
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Failure 401 {object} map[string]interface{} "error, details"
+// @Router /token/refresh [post]
+func (c *SessionsController) Refresh(ctx *gin.Context) {
+	var req domain.RefreshRequest
+
+	// Parsear JSON del body
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Llamar al service
+	user, tokens, err := c.service.Refresh(ctx.Request.Context(), req.RefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Invalid refresh token",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"user":          user,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// Logout maneja POST /logout - Revoca la sesión del token actual
+// @Summary Cierra la sesión (revoca access y refresh tokens)
+// @Tags sessions
+// @Produce json
+// @Success 204
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Failure 500 {object} map[string]interface{} "error, details"
+// @Router /logout [post]
+func (c *SessionsController) Logout(ctx *gin.Context) {
+	// sid lo setea JWTAuthMiddleware a partir de los claims
+	sessionID := ctx.GetString("sid")
+
+	if err := c.service.Logout(ctx.Request.Context(), sessionID); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "token has no session" {
+			statusCode = http.StatusBadRequest
+		}
+
+		ctx.JSON(statusCode, gin.H{
+			"error":   "Failed to logout",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetStatus maneja GET /sessions/:sid/status - Indica si una sesión fue revocada
+// Usado por el JWTAuthMiddleware de otros microservicios
+// @Summary Estado de revocación de una sesión
+// @Tags sessions
+// @Produce json
+// @Param sid path string true "Session ID (claim sid)"
+// @Success 200 {object} domain.SessionStatus
+// @Failure 500 {object} map[string]interface{} "error, details"
+// @Router /sessions/{sid}/status [get]
+func (c *SessionsController) GetStatus(ctx *gin.Context) {
+	status, err := c.service.GetSessionStatus(ctx.Request.Context(), ctx.Param("sid"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get session status",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+// JWKS maneja GET /.well-known/jwks.json - Publica las claves públicas de firma
+// Otros microservicios verifican los JWT con estas claves (nunca con la privada)
+// @Summary JSON Web Key Set
+// @Tags sessions
+// @Produce json
+// @Success 200 {object} security.JWKS
+// @Router /.well-known/jwks.json [get]
+func (c *SessionsController) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, c.service.JWKS())
+}
@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"net/http"
+	"users-api/internal/domain"
+	"users-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorController maneja el enrolamiento TOTP y el segundo paso del login
+type TwoFactorController struct {
+	service services.TwoFactorService
+}
+
+// NewTwoFactorController crea una nueva instancia del controller
+// Dependency Injection: recibe el service como parámetro
+func NewTwoFactorController(twoFactorService services.TwoFactorService) *TwoFactorController {
+	return &TwoFactorController{
+		service: twoFactorService,
+	}
+}
+
+// Status maneja GET /users/me/2fa - Estado del 2FA del usuario autenticado
+// @Summary Estado de 2FA
+// @Tags two-factor
+// @Produce json
+// @Success 200 {object} domain.TwoFactorStatus
+// @Router /users/me/2fa [get]
+func (c *TwoFactorController) Status(ctx *gin.Context) {
+	status, err := c.service.Status(ctx.Request.Context(), ctx.GetUint("id_usuario"))
+	if err != nil {
+		ctx.JSON(twoFactorErrorStatus(err), gin.H{
+			"error":   "Failed to get two factor status",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+// Setup maneja POST /users/me/2fa/setup - Genera el secreto TOTP a confirmar
+// @Summary Inicia el enrolamiento de 2FA
+// @Tags two-factor
+// @Produce json
+// @Success 200 {object} domain.TwoFactorSetup
+// @Failure 409 {object} map[string]interface{} "error, details"
+// @Router /users/me/2fa/setup [post]
+func (c *TwoFactorController) Setup(ctx *gin.Context) {
+	setup, err := c.service.BeginSetup(ctx.Request.Context(), ctx.GetUint("id_usuario"))
+	if err != nil {
+		ctx.JSON(twoFactorErrorStatus(err), gin.H{
+			"error":   "Failed to start two factor setup",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, setup)
+}
+
+// Enable maneja POST /users/me/2fa/enable - Confirma el enrolamiento con un código de la app
+// @Summary Activa 2FA
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Param body body domain.TwoFactorCodeRequest true "Código TOTP"
+// @Success 200 {object} map[string]interface{} "recovery_codes"
+// @Failure 401 {object} map[string]interface{} "error, details"
+// @Router /users/me/2fa/enable [post]
+func (c *TwoFactorController) Enable(ctx *gin.Context) {
+	var req domain.TwoFactorCodeRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	codes, err := c.service.Enable(ctx.Request.Context(), ctx.GetUint("id_usuario"), req.Code)
+	if err != nil {
+		ctx.JSON(twoFactorErrorStatus(err), gin.H{
+			"error":   "Failed to enable two factor",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+// Disable maneja POST /users/me/2fa/disable - Desactiva 2FA (contraseña + código)
+// @Summary Desactiva 2FA
+// @Tags two-factor
+// @Accept json
+// @Param body body domain.TwoFactorDisableRequest true "Contraseña y código"
+// @Success 204
+// @Failure 403 {object} map[string]interface{} "error, details"
+// @Router /users/me/2fa/disable [post]
+func (c *TwoFactorController) Disable(ctx *gin.Context) {
+	var req domain.TwoFactorDisableRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	if err := c.service.Disable(ctx.Request.Context(), ctx.GetUint("id_usuario"), req); err != nil {
+		ctx.JSON(twoFactorErrorStatus(err), gin.H{
+			"error":   "Failed to disable two factor",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes maneja POST /users/me/2fa/recovery-codes - Reemplaza los códigos de recuperación
+// @Summary Regenera los códigos de recuperación
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Param body body domain.TwoFactorCodeRequest true "Código TOTP"
+// @Success 200 {object} map[string]interface{} "recovery_codes"
+// @Router /users/me/2fa/recovery-codes [post]
+func (c *TwoFactorController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var req domain.TwoFactorCodeRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	codes, err := c.service.RegenerateRecoveryCodes(ctx.Request.Context(), ctx.GetUint("id_usuario"), req.Code)
+	if err != nil {
+		ctx.JSON(twoFactorErrorStatus(err), gin.H{
+			"error":   "Failed to regenerate recovery codes",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+// Reset maneja DELETE /users/:id/2fa - Elimina el 2FA de un usuario (admin)
+// @Summary Resetea el 2FA de un usuario
+// @Tags two-factor
+// @Param id path int true "User ID"
+// @Success 204
+// @Router /users/{id}/2fa [delete]
+func (c *TwoFactorController) Reset(ctx *gin.Context) {
+	id, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.Reset(ctx.Request.Context(), ctx.GetUint("id_usuario"), id); err != nil {
+		ctx.JSON(twoFactorErrorStatus(err), gin.H{
+			"error":   "Failed to reset two factor",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// LoginVerify maneja POST /login/2fa - Segundo paso del login
+// @Summary Completa el login con código TOTP o de recuperación
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Param body body domain.TwoFactorLoginRequest true "Challenge y código"
+// @Success 200 {object} map[string]interface{} "user, token, refresh_token, expires_in"
+// @Failure 401 {object} map[string]interface{} "error, details"
+// @Failure 429 {object} map[string]interface{} "error, details, retry_after"
+// @Router /login/2fa [post]
+func (c *TwoFactorController) LoginVerify(ctx *gin.Context) {
+	var req domain.TwoFactorLoginRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	user, tokens, err := c.service.CompleteLogin(ctx.Request.Context(), req, ctx.ClientIP())
+	if err != nil {
+		if abortIfThrottled(ctx, err) {
+			return
+		}
+		ctx.JSON(twoFactorErrorStatus(err), gin.H{
+			"error":   "Two factor verification failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"user":          user,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// LoginSetup maneja POST /login/2fa/setup - Enrolamiento obligatorio durante el login
+// @Summary Genera el secreto TOTP para un usuario al que la política le exige 2FA
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Param body body domain.TwoFactorChallengeRequest true "Challenge de setup"
+// @Success 200 {object} domain.TwoFactorSetup
+// @Failure 401 {object} map[string]interface{} "error, details"
+// @Router /login/2fa/setup [post]
+func (c *TwoFactorController) LoginSetup(ctx *gin.Context) {
+	var req domain.TwoFactorChallengeRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	setup, err := c.service.BeginChallengeSetup(ctx.Request.Context(), req.Challenge)
+	if err != nil {
+		ctx.JSON(twoFactorErrorStatus(err), gin.H{
+			"error":   "Failed to start two factor setup",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, setup)
+}
+
+// LoginSetupConfirm maneja POST /login/2fa/setup/confirm - Activa 2FA y completa el login
+// @Summary Confirma el enrolamiento obligatorio y emite los tokens
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Param body body domain.TwoFactorLoginRequest true "Challenge de setup y código TOTP"
+// @Success 200 {object} map[string]interface{} "user, token, refresh_token, expires_in, recovery_codes"
+// @Failure 401 {object} map[string]interface{} "error, details"
+// @Router /login/2fa/setup/confirm [post]
+func (c *TwoFactorController) LoginSetupConfirm(ctx *gin.Context) {
+	var req domain.TwoFactorLoginRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	user, tokens, codes, err := c.service.ConfirmChallengeSetup(ctx.Request.Context(), req, ctx.ClientIP())
+	if err != nil {
+		if abortIfThrottled(ctx, err) {
+			return
+		}
+		ctx.JSON(twoFactorErrorStatus(err), gin.H{
+			"error":   "Two factor setup failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"user":           user,
+		"token":          tokens.AccessToken,
+		"refresh_token":  tokens.RefreshToken,
+		"expires_in":     tokens.ExpiresIn,
+		"recovery_codes": codes,
+	})
+}
+
+// bindJSON parsea el body y responde 400 si es inválido
+func bindJSON(ctx *gin.Context, dst interface{}) bool {
+	if err := ctx.ShouldBindJSON(dst); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// twoFactorErrorStatus determina el código HTTP según el error del service
+func twoFactorErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "invalid two factor code" || msg == "invalid or expired challenge" || msg == "current password is incorrect":
+		return http.StatusUnauthorized
+	case msg == "two factor is required for admin accounts":
+		return http.StatusForbidden
+	case msg == "two factor already enabled" || msg == "two factor not enabled" || msg == "two factor setup not started":
+		return http.StatusConflict
+	case contains(msg, "not found"):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
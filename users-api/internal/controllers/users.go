@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
 	"users-api/internal/domain"
+	"users-api/internal/middleware"
 	"users-api/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -28,7 +31,7 @@ func NewUsersController(usersService services.UsersService) *UsersController {
 // @Accept json
 // @Produce json
 // @Param user body domain.UserRegister true "Datos del usuario"
-// @Success 201 {object} map[string]interface{} "user, token"
+// @Success 201 {object} map[string]interface{} "user, token, refresh_token, expires_in"
 // @Failure 400 {object} map[string]interface{} "error, details"
 // @Failure 500 {object} map[string]interface{} "error, details"
 // @Router /register [post]
@@ -45,7 +48,7 @@ func (c *UsersController) Register(ctx *gin.Context) {
 	}
 
 	// Llamar al service
-	user, token, err := c.service.Register(ctx.Request.Context(), userReg)
+	user, tokens, err := c.service.Register(ctx.Request.Context(), userReg)
 	if err != nil {
 		// Determinar código de estado según el error
 		statusCode := http.StatusInternalServerError
@@ -68,8 +71,10 @@ func (c *UsersController) Register(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusCreated, gin.H{
-		"user":  user,
-		"token": token,
+		"user":          user,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
@@ -79,9 +84,10 @@ func (c *UsersController) Register(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param credentials body domain.UserLogin true "Credenciales"
-// @Success 200 {object} map[string]interface{} "user, token"
+// @Success 200 {object} map[string]interface{} "user, token, refresh_token, expires_in (o challenge de 2FA)"
 // @Failure 400 {object} map[string]interface{} "error, details"
 // @Failure 401 {object} map[string]interface{} "error, details"
+// @Failure 429 {object} map[string]interface{} "error, details, retry_after"
 // @Router /login [post]
 func (c *UsersController) Login(ctx *gin.Context) {
 	var credentials domain.UserLogin
@@ -96,8 +102,13 @@ func (c *UsersController) Login(ctx *gin.Context) {
 	}
 
 	// Llamar al service
-	user, token, err := c.service.Login(ctx.Request.Context(), credentials)
+	result, err := c.service.Login(ctx.Request.Context(), credentials, ctx.ClientIP())
 	if err != nil {
+		// Backoff o bloqueo temporal por intentos fallidos
+		if abortIfThrottled(ctx, err) {
+			return
+		}
+
 		ctx.JSON(http.StatusUnauthorized, gin.H{
 			"error":   "Invalid credentials",
 			"details": err.Error(),
@@ -105,12 +116,37 @@ func (c *UsersController) Login(ctx *gin.Context) {
 		return
 	}
 
+	// 2FA: el cliente debe completar el login con POST /login/2fa (o enrolarse con /login/2fa/setup)
+	if result.Challenge != nil {
+		ctx.JSON(http.StatusOK, result.Challenge)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
-		"user":  user,
-		"token": token,
+		"user":          result.User,
+		"token":         result.Tokens.AccessToken,
+		"refresh_token": result.Tokens.RefreshToken,
+		"expires_in":    result.Tokens.ExpiresIn,
 	})
 }
 
+// abortIfThrottled responde 429 con Retry-After si el error es un rechazo por intentos fallidos
+func abortIfThrottled(ctx *gin.Context, err error) bool {
+	var throttled *domain.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(throttled.RetryAfter.Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+	ctx.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many failed login attempts",
+		"details":     err.Error(),
+		"retry_after": retryAfter,
+	})
+	return true
+}
+
 // GetByID maneja GET /users/:id - Obtiene un usuario por ID
 // @Summary Obtiene un usuario por ID
 // @Tags users
@@ -150,28 +186,288 @@ func (c *UsersController) GetByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, user)
 }
 
-// List maneja GET /users - Lista todos los usuarios
-// @Summary Lista todos los usuarios
+// List maneja GET /users - Lista usuarios paginados con filtros y orden
+// @Summary Lista usuarios (paginado)
 // @Tags users
 // @Produce json
-// @Success 200 {array} domain.UserResponse
+// @Param is_admin query bool false "Filtrar por rol"
+// @Param sucursal_origen_id query int false "Filtrar por sucursal de origen"
+// @Param registered_from query string false "Registrados desde (YYYY-MM-DD)"
+// @Param registered_to query string false "Registrados hasta (YYYY-MM-DD, inclusive)"
+// @Param q query string false "Texto libre (nombre, apellido, username, email)"
+// @Param page query int false "Página (default 1)"
+// @Param page_size query int false "Tamaño de página (default 20, máx 100)"
+// @Param sort_by query string false "id, nombre, apellido, username, email, fecha_registro"
+// @Param sort_desc query bool false "Orden descendente"
+// @Success 200 {object} domain.PaginatedUsersResponse
+// @Failure 400 {object} map[string]interface{} "error, details"
 // @Failure 500 {object} map[string]interface{} "error, details"
 // @Router /users [get]
 func (c *UsersController) List(ctx *gin.Context) {
+	var query domain.ListUsersQuery
+
+	// Parsear query params
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	// Llamar al service
-	users, err := c.service.List(ctx.Request.Context())
+	users, err := c.service.List(ctx.Request.Context(), query)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
+		ctx.JSON(userErrorStatus(err), gin.H{
 			"error":   "Failed to list users",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"users": users,
-		"count": len(users),
-	})
+	ctx.JSON(http.StatusOK, users)
+}
+
+// GetMe maneja GET /users/me - Obtiene el perfil del usuario autenticado
+// @Summary Perfil propio
+// @Tags users
+// @Produce json
+// @Success 200 {object} domain.UserResponse
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /users/me [get]
+func (c *UsersController) GetMe(ctx *gin.Context) {
+	user, err := c.service.GetByID(ctx.Request.Context(), ctx.GetUint("id_usuario"))
+	if err != nil {
+		ctx.JSON(userErrorStatus(err), gin.H{
+			"error":   "Failed to get user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// UpdateMe maneja PATCH /users/me - Actualiza el perfil del usuario autenticado
+// @Summary Actualiza el perfil propio
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user body domain.UserUpdate true "Campos a modificar"
+// @Success 200 {object} domain.UserResponse
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Failure 409 {object} map[string]interface{} "error, details"
+// @Router /users/me [patch]
+func (c *UsersController) UpdateMe(ctx *gin.Context) {
+	var update domain.UserUpdate
+
+	// Parsear JSON del body
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := c.service.UpdateProfile(ctx.Request.Context(), ctx.GetUint("id_usuario"), update)
+	if err != nil {
+		ctx.JSON(userErrorStatus(err), gin.H{
+			"error":   "Failed to update user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// ChangePassword maneja PUT /users/me/password - Cambia la contraseña del usuario autenticado
+// Revoca todas las sesiones y devuelve tokens de una sesión nueva
+// @Summary Cambia la contraseña propia
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param body body domain.PasswordChange true "Contraseña actual y nueva"
+// @Success 200 {object} domain.AuthTokens
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Failure 401 {object} map[string]interface{} "error, details"
+// @Router /users/me/password [put]
+func (c *UsersController) ChangePassword(ctx *gin.Context) {
+	var change domain.PasswordChange
+
+	// Parsear JSON del body
+	if err := ctx.ShouldBindJSON(&change); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	tokens, err := c.service.ChangePassword(ctx.Request.Context(), ctx.GetUint("id_usuario"), change)
+	if err != nil {
+		statusCode := userErrorStatus(err)
+		if err.Error() == "current password is incorrect" {
+			statusCode = http.StatusUnauthorized
+		}
+
+		ctx.JSON(statusCode, gin.H{
+			"error":   "Failed to change password",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// DeleteMe maneja DELETE /users/me - Da de baja la cuenta del usuario autenticado
+// @Summary Baja de la cuenta propia (soft delete)
+// @Tags users
+// @Success 204
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /users/me [delete]
+func (c *UsersController) DeleteMe(ctx *gin.Context) {
+	if err := c.service.Delete(ctx.Request.Context(), ctx.GetUint("id_usuario")); err != nil {
+		ctx.JSON(userErrorStatus(err), gin.H{
+			"error":   "Failed to delete user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// AdminUpdate maneja PATCH /users/:id - Actualiza un usuario (users:manage; is_admin requiere roles:manage)
+// @Summary Actualiza un usuario
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param user body domain.AdminUserUpdate true "Campos a modificar"
+// @Success 200 {object} domain.UserResponse
+// @Failure 400 {object} map[string]interface{} "error, details"
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Failure 409 {object} map[string]interface{} "error, details"
+// @Router /users/{id} [patch]
+func (c *UsersController) AdminUpdate(ctx *gin.Context) {
+	id, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var update domain.AdminUserUpdate
+
+	// Parsear JSON del body
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Otorgar o quitar is_admin equivale a gestionar roles
+	if update.IsAdmin != nil && !middleware.HasPermission(ctx, domain.PermissionRolesManage) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error":   "Failed to update user",
+			"details": "changing is_admin requires permission " + domain.PermissionRolesManage,
+		})
+		return
+	}
+
+	user, err := c.service.AdminUpdate(ctx.Request.Context(), ctx.GetUint("id_usuario"), id, update)
+	if err != nil {
+		ctx.JSON(userErrorStatus(err), gin.H{
+			"error":   "Failed to update user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// Delete maneja DELETE /users/:id - Da de baja un usuario (users:manage)
+// @Summary Baja de un usuario (soft delete)
+// @Tags users
+// @Param id path int true "User ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /users/{id} [delete]
+func (c *UsersController) Delete(ctx *gin.Context) {
+	id, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.Delete(ctx.Request.Context(), id); err != nil {
+		ctx.JSON(userErrorStatus(err), gin.H{
+			"error":   "Failed to delete user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Restore maneja POST /users/:id/restore - Reactiva un usuario dado de baja (users:manage)
+// @Summary Restaura un usuario eliminado
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} domain.UserResponse
+// @Failure 404 {object} map[string]interface{} "error, details"
+// @Router /users/{id}/restore [post]
+func (c *UsersController) Restore(ctx *gin.Context) {
+	id, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := c.service.Restore(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(userErrorStatus(err), gin.H{
+			"error":   "Failed to restore user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// parseUserID extrae el ID del path param; responde 400 si es inválido
+func parseUserID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"details": "ID must be a positive integer",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// userErrorStatus determina el código HTTP según el error del service
+func userErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case contains(msg, "not found"):
+		return http.StatusNotFound
+	case msg == "username or email already exists":
+		return http.StatusConflict
+	case msg == "cannot remove your own admin role":
+		return http.StatusForbidden
+	case contains(msg, "required") || contains(msg, "must") || contains(msg, "invalid") || contains(msg, "can only"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
 // Helper function
@@ -0,0 +1,96 @@
+package dao
+
+import (
+	"time"
+	"users-api/internal/domain"
+)
+
+// Identity representa una identidad externa (OIDC) vinculada a un usuario en MySQL
+type Identity struct {
+	ID          uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	UsuarioID   uint       `gorm:"column:usuario_id;not null;index"`
+	Provider    string     `gorm:"column:provider;type:varchar(32);not null;uniqueIndex:idx_identidades_provider_subject"`
+	Subject     string     `gorm:"column:subject;type:varchar(255);collation:ascii_bin;not null;uniqueIndex:idx_identidades_provider_subject"`
+	Email       string     `gorm:"column:email;type:varchar(100)"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (Identity) TableName() string {
+	return "identidades"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (i Identity) ToDomain() domain.Identity {
+	return domain.Identity{
+		ID:          i.ID,
+		UsuarioID:   i.UsuarioID,
+		Provider:    i.Provider,
+		Subject:     i.Subject,
+		Email:       i.Email,
+		CreatedAt:   i.CreatedAt,
+		LastLoginAt: i.LastLoginAt,
+	}
+}
+
+// IdentityFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func IdentityFromDomain(i domain.Identity) Identity {
+	return Identity{
+		ID:          i.ID,
+		UsuarioID:   i.UsuarioID,
+		Provider:    i.Provider,
+		Subject:     i.Subject,
+		Email:       i.Email,
+		CreatedAt:   i.CreatedAt,
+		LastLoginAt: i.LastLoginAt,
+	}
+}
+
+// OIDCLoginState representa un login social en curso en MySQL
+type OIDCLoginState struct {
+	StateHash    string     `gorm:"column:state_hash;type:char(64);collation:ascii_bin;primaryKey"`
+	Provider     string     `gorm:"column:provider;type:varchar(32);not null"`
+	CodeVerifier string     `gorm:"column:code_verifier;type:varchar(128);not null"`
+	Nonce        string     `gorm:"column:nonce;type:varchar(64);not null"`
+	LinkUserID   *uint      `gorm:"column:link_usuario_id"`
+	RedirectPath string     `gorm:"column:redirect_path;type:varchar(255);not null;default:''"`
+	ExpiresAt    time.Time  `gorm:"column:expires_at;not null;index"`
+	UsedAt       *time.Time `gorm:"column:used_at"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (s OIDCLoginState) ToDomain() domain.OIDCLoginState {
+	return domain.OIDCLoginState{
+		StateHash:    s.StateHash,
+		Provider:     s.Provider,
+		CodeVerifier: s.CodeVerifier,
+		Nonce:        s.Nonce,
+		LinkUserID:   s.LinkUserID,
+		RedirectPath: s.RedirectPath,
+		ExpiresAt:    s.ExpiresAt,
+		UsedAt:       s.UsedAt,
+		CreatedAt:    s.CreatedAt,
+	}
+}
+
+// OIDCLoginStateFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func OIDCLoginStateFromDomain(s domain.OIDCLoginState) OIDCLoginState {
+	return OIDCLoginState{
+		StateHash:    s.StateHash,
+		Provider:     s.Provider,
+		CodeVerifier: s.CodeVerifier,
+		Nonce:        s.Nonce,
+		LinkUserID:   s.LinkUserID,
+		RedirectPath: s.RedirectPath,
+		ExpiresAt:    s.ExpiresAt,
+		UsedAt:       s.UsedAt,
+		CreatedAt:    s.CreatedAt,
+	}
+}
@@ -0,0 +1,81 @@
+package dao
+
+import (
+	"time"
+	"users-api/internal/domain"
+)
+
+// LoginAttempt representa el contador de intentos fallidos de una clave ("user:<id>", "login:<identificador>" o "ip:<ip>")
+type LoginAttempt struct {
+	Clave         string     `gorm:"column:clave;type:varchar(191);primaryKey"`
+	Failures      int        `gorm:"column:failures;not null;default:0"`
+	LastFailureAt time.Time  `gorm:"column:last_failure_at;not null"`
+	LockedUntil   *time.Time `gorm:"column:locked_until"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (a LoginAttempt) ToDomain() domain.LoginAttemptState {
+	return domain.LoginAttemptState{
+		Key:           a.Clave,
+		Failures:      a.Failures,
+		LastFailureAt: a.LastFailureAt,
+		LockedUntil:   a.LockedUntil,
+	}
+}
+
+// LockoutEvent representa un registro de auditoría de bloqueo/desbloqueo de login
+type LockoutEvent struct {
+	ID          uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	Scope       string     `gorm:"column:scope;type:varchar(16);not null"`
+	Subject     string     `gorm:"column:subject;type:varchar(191);not null;index"`
+	UsuarioID   *uint      `gorm:"column:usuario_id;index"`
+	Event       string     `gorm:"column:event;type:varchar(16);not null"`
+	Failures    int        `gorm:"column:failures;not null;default:0"`
+	LockedUntil *time.Time `gorm:"column:locked_until"`
+	IP          string     `gorm:"column:ip;type:varchar(64)"`
+	ActorID     *uint      `gorm:"column:actor_id"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime;index"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (LockoutEvent) TableName() string {
+	return "login_lockout_events"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (e LockoutEvent) ToDomain() domain.LockoutEvent {
+	return domain.LockoutEvent{
+		ID:          e.ID,
+		Scope:       e.Scope,
+		Subject:     e.Subject,
+		UsuarioID:   e.UsuarioID,
+		Event:       e.Event,
+		Failures:    e.Failures,
+		LockedUntil: e.LockedUntil,
+		IP:          e.IP,
+		ActorID:     e.ActorID,
+		CreatedAt:   e.CreatedAt,
+	}
+}
+
+// LockoutEventFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func LockoutEventFromDomain(e domain.LockoutEvent) LockoutEvent {
+	return LockoutEvent{
+		ID:          e.ID,
+		Scope:       e.Scope,
+		Subject:     e.Subject,
+		UsuarioID:   e.UsuarioID,
+		Event:       e.Event,
+		Failures:    e.Failures,
+		LockedUntil: e.LockedUntil,
+		IP:          e.IP,
+		ActorID:     e.ActorID,
+		CreatedAt:   e.CreatedAt,
+	}
+}
@@ -0,0 +1,63 @@
+package dao
+
+import (
+	"encoding/json"
+	"time"
+	"users-api/internal/domain"
+)
+
+// OutboxEvent representa un evento de la transactional outbox en MySQL
+type OutboxEvent struct {
+	ID          uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	EventType   string     `gorm:"column:event_type;type:varchar(50);not null"`
+	Action      string     `gorm:"column:action;type:varchar(50);not null"`
+	AggregateID string     `gorm:"column:aggregate_id;type:varchar(64);not null"`
+	Payload     string     `gorm:"column:payload;type:text;not null"` // JSON con el data del evento
+	Attempts    int        `gorm:"column:attempts;not null;default:0"`
+	LastError   string     `gorm:"column:last_error;type:varchar(500)"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+	PublishedAt *time.Time `gorm:"column:published_at;index"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (e OutboxEvent) ToDomain() domain.OutboxEvent {
+	var data map[string]interface{}
+	_ = json.Unmarshal([]byte(e.Payload), &data)
+
+	return domain.OutboxEvent{
+		ID:          e.ID,
+		EventType:   e.EventType,
+		Action:      e.Action,
+		AggregateID: e.AggregateID,
+		Data:        data,
+		Attempts:    e.Attempts,
+		LastError:   e.LastError,
+		CreatedAt:   e.CreatedAt,
+		PublishedAt: e.PublishedAt,
+	}
+}
+
+// OutboxEventFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func OutboxEventFromDomain(e domain.OutboxEvent) (OutboxEvent, error) {
+	payload, err := json.Marshal(e.Data)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+
+	return OutboxEvent{
+		ID:          e.ID,
+		EventType:   e.EventType,
+		Action:      e.Action,
+		AggregateID: e.AggregateID,
+		Payload:     string(payload),
+		Attempts:    e.Attempts,
+		LastError:   e.LastError,
+		CreatedAt:   e.CreatedAt,
+		PublishedAt: e.PublishedAt,
+	}, nil
+}
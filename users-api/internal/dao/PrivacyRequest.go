@@ -0,0 +1,97 @@
+package dao
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+	"users-api/internal/domain"
+)
+
+// PrivacyRequest representa un pedido de exportación o borrado de datos de un socio
+type PrivacyRequest struct {
+	ID          uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	UsuarioID   uint       `gorm:"column:usuario_id;not null;index"`
+	Tipo        string     `gorm:"column:tipo;type:varchar(16);not null"`
+	Estado      string     `gorm:"column:estado;type:varchar(16);not null;index"`
+	RequestedBy uint       `gorm:"column:requested_by;not null"`
+	Services    string     `gorm:"column:services;type:varchar(255);not null"` // Separados por coma
+	CreatedAt   time.Time  `gorm:"autoCreateTime;index"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime"`
+	CompletedAt *time.Time `gorm:"column:completed_at"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (PrivacyRequest) TableName() string {
+	return "privacy_requests"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (p PrivacyRequest) ToDomain() domain.PrivacyRequest {
+	return domain.PrivacyRequest{
+		ID:          p.ID,
+		UsuarioID:   p.UsuarioID,
+		Tipo:        p.Tipo,
+		Estado:      p.Estado,
+		RequestedBy: p.RequestedBy,
+		Services:    strings.Split(p.Services, ","),
+		CreatedAt:   p.CreatedAt,
+		CompletedAt: p.CompletedAt,
+	}
+}
+
+// PrivacyRequestFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func PrivacyRequestFromDomain(p domain.PrivacyRequest) PrivacyRequest {
+	return PrivacyRequest{
+		ID:          p.ID,
+		UsuarioID:   p.UsuarioID,
+		Tipo:        p.Tipo,
+		Estado:      p.Estado,
+		RequestedBy: p.RequestedBy,
+		Services:    strings.Join(p.Services, ","),
+		CompletedAt: p.CompletedAt,
+	}
+}
+
+// PrivacyRequestAck representa la confirmación de un servicio (una por pedido y servicio)
+type PrivacyRequestAck struct {
+	ID         uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	RequestID  uint      `gorm:"column:request_id;not null;uniqueIndex:idx_privacy_ack_request_service"`
+	Service    string    `gorm:"column:service;type:varchar(64);not null;uniqueIndex:idx_privacy_ack_request_service"`
+	Estado     string    `gorm:"column:estado;type:varchar(16);not null"`
+	Error      string    `gorm:"column:error;type:varchar(500)"`
+	Records    int       `gorm:"column:records;not null;default:0"`
+	Payload    []byte    `gorm:"column:payload;type:longblob"` // Datos exportados (JSON), solo en exportaciones
+	ReceivedAt time.Time `gorm:"column:received_at;not null"`
+
+	Request PrivacyRequest `gorm:"foreignKey:RequestID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (PrivacyRequestAck) TableName() string {
+	return "privacy_request_acks"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (a PrivacyRequestAck) ToDomain() domain.PrivacyAck {
+	return domain.PrivacyAck{
+		Service:    a.Service,
+		Estado:     a.Estado,
+		Error:      a.Error,
+		Records:    a.Records,
+		Data:       json.RawMessage(a.Payload),
+		ReceivedAt: a.ReceivedAt,
+	}
+}
+
+// PrivacyRequestAckFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func PrivacyRequestAckFromDomain(requestID uint, a domain.PrivacyAck) PrivacyRequestAck {
+	return PrivacyRequestAck{
+		RequestID:  requestID,
+		Service:    a.Service,
+		Estado:     a.Estado,
+		Error:      a.Error,
+		Records:    a.Records,
+		Payload:    []byte(a.Data),
+		ReceivedAt: a.ReceivedAt,
+	}
+}
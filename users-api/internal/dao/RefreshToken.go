@@ -0,0 +1,56 @@
+package dao
+
+import (
+	"time"
+	"users-api/internal/domain"
+)
+
+// RefreshToken representa un refresh token persistido en MySQL
+// Solo se guarda el hash SHA-256 del token, nunca el valor en claro
+// Todos los tokens rotados a partir del mismo login comparten FamilyID (la sesión)
+type RefreshToken struct {
+	ID         uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	FamilyID   string     `gorm:"column:family_id;type:char(32);not null;index"`
+	UsuarioID  uint       `gorm:"column:usuario_id;not null;index"`
+	TokenHash  string     `gorm:"column:token_hash;type:char(64);collation:ascii_bin;unique;not null"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at;not null"`
+	UsedAt     *time.Time `gorm:"column:used_at"`        // Se setea al rotar: un segundo uso indica robo
+	ReplacedBy *uint      `gorm:"column:replaced_by_id"` // Token que lo reemplazó en la rotación
+	RevokedAt  *time.Time `gorm:"column:revoked_at;index"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (t RefreshToken) ToDomain() domain.RefreshToken {
+	return domain.RefreshToken{
+		ID:         t.ID,
+		FamilyID:   t.FamilyID,
+		UsuarioID:  t.UsuarioID,
+		TokenHash:  t.TokenHash,
+		ExpiresAt:  t.ExpiresAt,
+		UsedAt:     t.UsedAt,
+		ReplacedBy: t.ReplacedBy,
+		RevokedAt:  t.RevokedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// RefreshTokenFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func RefreshTokenFromDomain(t domain.RefreshToken) RefreshToken {
+	return RefreshToken{
+		ID:         t.ID,
+		FamilyID:   t.FamilyID,
+		UsuarioID:  t.UsuarioID,
+		TokenHash:  t.TokenHash,
+		ExpiresAt:  t.ExpiresAt,
+		UsedAt:     t.UsedAt,
+		ReplacedBy: t.ReplacedBy,
+		RevokedAt:  t.RevokedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
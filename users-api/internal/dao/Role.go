@@ -0,0 +1,97 @@
+package dao
+
+import (
+	"time"
+	"users-api/internal/domain"
+)
+
+// Role representa un rol en MySQL
+type Role struct {
+	ID          uint             `gorm:"column:id_rol;primaryKey;autoIncrement"`
+	Nombre      string           `gorm:"type:varchar(50);unique;not null"`
+	Descripcion string           `gorm:"type:varchar(255)"`
+	BuiltIn     bool             `gorm:"column:built_in;default:false;not null"`
+	Permisos    []RolePermission `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time        `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time        `gorm:"autoUpdateTime"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (Role) TableName() string {
+	return "roles"
+}
+
+// RolePermission representa un permiso otorgado a un rol
+type RolePermission struct {
+	RoleID  uint   `gorm:"column:rol_id;primaryKey"`
+	Permiso string `gorm:"type:varchar(100);primaryKey"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (RolePermission) TableName() string {
+	return "rol_permisos"
+}
+
+// UserRole representa la asignación de un rol a un usuario
+type UserRole struct {
+	ID         uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	UsuarioID  uint      `gorm:"column:usuario_id;not null;index"`
+	RoleID     uint      `gorm:"column:rol_id;not null;index"`
+	Role       Role      `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE"`
+	SucursalID *uint     `gorm:"column:sucursal_id"` // Nullable, referencia lógica (sucursales viven en activities-api)
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (UserRole) TableName() string {
+	return "usuario_roles"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (r Role) ToDomain() domain.Role {
+	permissions := make([]string, len(r.Permisos))
+	for i, p := range r.Permisos {
+		permissions[i] = p.Permiso
+	}
+
+	return domain.Role{
+		ID:          r.ID,
+		Nombre:      r.Nombre,
+		Descripcion: r.Descripcion,
+		BuiltIn:     r.BuiltIn,
+		Permissions: permissions,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+// RoleFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func RoleFromDomain(r domain.Role) Role {
+	permisos := make([]RolePermission, len(r.Permissions))
+	for i, p := range r.Permissions {
+		permisos[i] = RolePermission{RoleID: r.ID, Permiso: p}
+	}
+
+	return Role{
+		ID:          r.ID,
+		Nombre:      r.Nombre,
+		Descripcion: r.Descripcion,
+		BuiltIn:     r.BuiltIn,
+		Permisos:    permisos,
+	}
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio); requiere Role precargado
+func (ur UserRole) ToDomain() domain.UserRole {
+	role := ur.Role.ToDomain()
+
+	return domain.UserRole{
+		ID:          ur.ID,
+		UsuarioID:   ur.UsuarioID,
+		RoleID:      ur.RoleID,
+		Role:        role.Nombre,
+		SucursalID:  ur.SucursalID,
+		Permissions: role.Permissions,
+		CreatedAt:   ur.CreatedAt,
+	}
+}
@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"time"
+	"users-api/internal/domain"
+)
+
+// TwoFactor representa la configuración TOTP de un usuario en MySQL
+type TwoFactor struct {
+	UsuarioID    uint       `gorm:"column:usuario_id;primaryKey"`
+	Secret       string     `gorm:"column:secret;type:varchar(255);not null"`
+	EnabledAt    *time.Time `gorm:"column:enabled_at"`
+	LastUsedStep int64      `gorm:"column:last_used_step;not null;default:0"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (TwoFactor) TableName() string {
+	return "user_two_factor"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (t TwoFactor) ToDomain() domain.TwoFactor {
+	return domain.TwoFactor{
+		UsuarioID:    t.UsuarioID,
+		Secret:       t.Secret,
+		EnabledAt:    t.EnabledAt,
+		LastUsedStep: t.LastUsedStep,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+// RecoveryCode representa un código de recuperación de 2FA (solo se guarda el hash SHA-256)
+type RecoveryCode struct {
+	ID        uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	UsuarioID uint       `gorm:"column:usuario_id;not null;index"`
+	CodeHash  string     `gorm:"column:code_hash;type:char(64);collation:ascii_bin;not null"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (RecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
+
+// LoginChallenge representa un challenge de login en dos pasos en MySQL
+type LoginChallenge struct {
+	ID            uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	UsuarioID     uint       `gorm:"column:usuario_id;not null;index"`
+	Purpose       string     `gorm:"column:purpose;type:varchar(16);not null"`
+	ChallengeHash string     `gorm:"column:challenge_hash;type:char(64);collation:ascii_bin;unique;not null"`
+	Attempts      int        `gorm:"column:attempts;not null;default:0"`
+	ExpiresAt     time.Time  `gorm:"column:expires_at;not null"`
+	UsedAt        *time.Time `gorm:"column:used_at"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (LoginChallenge) TableName() string {
+	return "login_challenges"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (c LoginChallenge) ToDomain() domain.LoginChallenge {
+	return domain.LoginChallenge{
+		ID:            c.ID,
+		UsuarioID:     c.UsuarioID,
+		Purpose:       c.Purpose,
+		ChallengeHash: c.ChallengeHash,
+		Attempts:      c.Attempts,
+		ExpiresAt:     c.ExpiresAt,
+		UsedAt:        c.UsedAt,
+		CreatedAt:     c.CreatedAt,
+	}
+}
+
+// LoginChallengeFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func LoginChallengeFromDomain(c domain.LoginChallenge) LoginChallenge {
+	return LoginChallenge{
+		ID:            c.ID,
+		UsuarioID:     c.UsuarioID,
+		Purpose:       c.Purpose,
+		ChallengeHash: c.ChallengeHash,
+		Attempts:      c.Attempts,
+		ExpiresAt:     c.ExpiresAt,
+		UsedAt:        c.UsedAt,
+		CreatedAt:     c.CreatedAt,
+	}
+}
@@ -3,23 +3,26 @@ package dao
 import (
 	"time"
 	"users-api/internal/domain"
+
+	"gorm.io/gorm"
 )
 
 // User representa el modelo de base de datos con tags de GORM
 // Este modelo está acoplado a MySQL/GORM
 type User struct {
-	ID               uint       `gorm:"column:id_usuario;primaryKey;autoIncrement"`
-	Nombre           string     `gorm:"type:varchar(30);not null"`
-	Apellido         string     `gorm:"type:varchar(30);not null"`
-	Username         string     `gorm:"type:varchar(30);unique;not null;index"`
-	Email            string     `gorm:"type:varchar(100);unique;not null;index"`
-	Password         string     `gorm:"type:char(64);collation:ascii_bin;not null"`
-	IsAdmin          bool       `gorm:"column:is_admin;default:false;not null"`
-	SucursalOrigenID *uint      `gorm:"column:sucursal_origen_id;index"` // Nullable, referencia lógica
-	FechaRegistro    time.Time  `gorm:"column:fecha_registro;type:timestamp;default:CURRENT_TIMESTAMP;not null"`
-	CreatedAt        time.Time  `gorm:"autoCreateTime"`
-	UpdatedAt        time.Time  `gorm:"autoUpdateTime"`
-	DeletedAt        *time.Time `gorm:"index"` // Soft delete
+	ID               uint           `gorm:"column:id_usuario;primaryKey;autoIncrement"`
+	Nombre           string         `gorm:"type:varchar(30);not null"`
+	Apellido         string         `gorm:"type:varchar(30);not null"`
+	Username         string         `gorm:"type:varchar(30);unique;not null;index"`
+	Email            string         `gorm:"type:varchar(100);unique;not null;index"`
+	Password         string         `gorm:"type:varchar(255);collation:ascii_bin;not null"` // Hash codificado (argon2id/bcrypt, o SHA-256 legacy)
+	IsAdmin          bool           `gorm:"column:is_admin;default:false;not null"`
+	SucursalOrigenID *uint          `gorm:"column:sucursal_origen_id;index"` // Nullable, referencia lógica
+	EmailVerifiedAt  *time.Time     `gorm:"column:email_verified_at"`
+	FechaRegistro    time.Time      `gorm:"column:fecha_registro;type:timestamp;default:CURRENT_TIMESTAMP;not null"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"` // Soft delete (GORM excluye los borrados de las consultas)
 }
 
 // TableName especifica el nombre de la tabla en MySQL
@@ -38,6 +41,7 @@ func (u User) ToDomain() domain.User {
 		Password:         u.Password,
 		IsAdmin:          u.IsAdmin,
 		SucursalOrigenID: u.SucursalOrigenID,
+		EmailVerifiedAt:  u.EmailVerifiedAt,
 		FechaRegistro:    u.FechaRegistro,
 		CreatedAt:        u.CreatedAt,
 		UpdatedAt:        u.UpdatedAt,
@@ -55,6 +59,7 @@ func FromDomain(domainUser domain.User) User {
 		Password:         domainUser.Password,
 		IsAdmin:          domainUser.IsAdmin,
 		SucursalOrigenID: domainUser.SucursalOrigenID,
+		EmailVerifiedAt:  domainUser.EmailVerifiedAt,
 		FechaRegistro:    domainUser.FechaRegistro,
 		CreatedAt:        domainUser.CreatedAt,
 		UpdatedAt:        domainUser.UpdatedAt,
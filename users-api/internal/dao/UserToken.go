@@ -0,0 +1,49 @@
+package dao
+
+import (
+	"time"
+	"users-api/internal/domain"
+)
+
+// UserToken representa un token de un solo uso persistido en MySQL
+// Solo se guarda el hash SHA-256 del token, nunca el valor en claro
+type UserToken struct {
+	ID        uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	UsuarioID uint       `gorm:"column:usuario_id;not null;index:idx_user_tokens_usuario_purpose"`
+	Purpose   string     `gorm:"column:purpose;type:varchar(32);not null;index:idx_user_tokens_usuario_purpose"`
+	TokenHash string     `gorm:"column:token_hash;type:char(64);collation:ascii_bin;unique;not null"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
+
+// ToDomain convierte de DAO (MySQL) a Domain (negocio)
+func (t UserToken) ToDomain() domain.UserToken {
+	return domain.UserToken{
+		ID:        t.ID,
+		UsuarioID: t.UsuarioID,
+		Purpose:   t.Purpose,
+		TokenHash: t.TokenHash,
+		ExpiresAt: t.ExpiresAt,
+		UsedAt:    t.UsedAt,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+// UserTokenFromDomain convierte de Domain (negocio) a DAO (MySQL)
+func UserTokenFromDomain(t domain.UserToken) UserToken {
+	return UserToken{
+		ID:        t.ID,
+		UsuarioID: t.UsuarioID,
+		Purpose:   t.Purpose,
+		TokenHash: t.TokenHash,
+		ExpiresAt: t.ExpiresAt,
+		UsedAt:    t.UsedAt,
+		CreatedAt: t.CreatedAt,
+	}
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// Propósitos de los tokens de un solo uso enviados por email
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// UserToken representa un token de un solo uso con vencimiento (reset de password, verificación de email)
+type UserToken struct {
+	ID        uint
+	UsuarioID uint
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// ForgotPasswordRequest representa el body de POST /password/forgot
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest representa el body de POST /password/reset
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+// VerifyEmailRequest representa el body de POST /email/verify
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
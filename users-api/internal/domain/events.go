@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// Eventos del ciclo de vida de un usuario (routing key user.<action> en gym_events)
+const (
+	UserEventType    = "user"
+	UserEventCreated = "created" // Registro, alta por login social
+	UserEventUpdated = "updated" // Perfil, sucursal, is_admin, roles, email verificado o restauración
+	UserEventDeleted = "deleted" // Baja (soft delete) o anonimización
+)
+
+// OutboxEvent es un evento pendiente de publicar en RabbitMQ
+// Se guarda en la misma transacción que el cambio que lo origina y lo publica el relay
+type OutboxEvent struct {
+	ID          uint
+	EventType   string // user, privacy
+	Action      string
+	AggregateID string // ID de la entidad (va como "id" en el sobre del evento)
+	Data        map[string]interface{}
+	Attempts    int
+	LastError   string
+	CreatedAt   time.Time
+	PublishedAt *time.Time
+}
@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+// TokenPurposeOIDCLogin es el código de un solo uso con el que el frontend canjea un login social por tokens
+const TokenPurposeOIDCLogin = "oidc_login"
+
+// Identity vincula una cuenta externa (proveedor OIDC + subject) con un usuario
+type Identity struct {
+	ID          uint       `json:"id"`
+	UsuarioID   uint       `json:"usuario_id"`
+	Provider    string     `json:"provider"`
+	Subject     string     `json:"-"` // "sub" del ID token: identificador estable del usuario en el proveedor
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// ExternalProfile son los datos del usuario verificados en el ID token del proveedor
+type ExternalProfile struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+	Name          string
+}
+
+// OIDCLoginState es el estado de un login social en curso (entre el redirect al proveedor y el callback)
+// El code_verifier de PKCE nunca sale del servidor; solo se guarda el hash del state
+type OIDCLoginState struct {
+	StateHash    string
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+	LinkUserID   *uint  // Si no es nil, el flujo vincula la identidad a este usuario en lugar de iniciar sesión
+	RedirectPath string // Ruta del frontend a la que volver después del login
+	ExpiresAt    time.Time
+	UsedAt       *time.Time
+	CreatedAt    time.Time
+}
+
+// OIDCProviderInfo describe un proveedor configurado (GET /auth/oidc/providers)
+type OIDCProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OIDCCallbackResult indica a dónde redirigir el navegador al terminar el callback
+type OIDCCallbackResult struct {
+	LoginCode    string // Código de un solo uso para POST /auth/oidc/exchange (vacío si fue una vinculación)
+	Linked       bool
+	Provider     string
+	RedirectPath string
+}
+
+// OIDCExchangeRequest representa el body de POST /auth/oidc/exchange
+type OIDCExchangeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
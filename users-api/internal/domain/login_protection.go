@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Alcances del seguimiento de intentos fallidos de login
+const (
+	LoginScopeAccount = "account" // Por cuenta (o por identificador si el usuario no existe)
+	LoginScopeIP      = "ip"      // Por IP de origen
+)
+
+// Eventos de auditoría de bloqueos
+const (
+	LockoutEventLocked   = "locked"
+	LockoutEventUnlocked = "unlocked"
+)
+
+// LoginAttemptState es el estado de intentos fallidos de una clave (cuenta o IP)
+type LoginAttemptState struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// IsLocked indica si la clave está bloqueada en el instante dado
+func (s LoginAttemptState) IsLocked(now time.Time) bool {
+	return s.LockedUntil != nil && now.Before(*s.LockedUntil)
+}
+
+// LockoutEvent es un registro de auditoría de un bloqueo o desbloqueo
+type LockoutEvent struct {
+	ID          uint       `json:"id"`
+	Scope       string     `json:"scope"`
+	Subject     string     `json:"subject"` // ID de usuario, identificador de login o IP según el alcance
+	UsuarioID   *uint      `json:"usuario_id,omitempty"`
+	Event       string     `json:"event"`
+	Failures    int        `json:"failures"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	IP          string     `json:"ip,omitempty"`       // IP del intento que disparó el bloqueo
+	ActorID     *uint      `json:"actor_id,omitempty"` // Admin que desbloqueó
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ListLockoutEventsQuery representa los query params de GET /lockouts
+type ListLockoutEventsQuery struct {
+	UsuarioID *uint  `form:"usuario_id"`
+	IP        string `form:"ip"`
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// LoginThrottledError indica que el login se rechazó sin verificar la contraseña
+// por backoff o bloqueo temporal; RetryAfter es el tiempo a esperar
+type LoginThrottledError struct {
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	seconds := int(e.RetryAfter.Round(time.Second).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	if e.Locked {
+		return fmt.Sprintf("login temporarily locked due to too many failed attempts, retry in %ds", seconds)
+	}
+	return fmt.Sprintf("too many failed login attempts, retry in %ds", seconds)
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Tipos de pedido sobre los datos personales de un socio
+const (
+	PrivacyRequestExport  = "export"  // Exportación de todos sus datos (JSON/ZIP)
+	PrivacyRequestErasure = "erasure" // Derecho al olvido: borrado/anonimización en todos los servicios
+)
+
+// Estados de un pedido y de cada acuse
+const (
+	PrivacyStatusPending   = "pending"   // Esperando acuses de los servicios
+	PrivacyStatusCompleted = "completed" // Todos los servicios terminaron
+	PrivacyStatusFailed    = "failed"    // Algún servicio informó un error (se puede reintentar)
+)
+
+// PrivacyServiceName es el nombre con el que users-api registra su propia parte del pedido
+const PrivacyServiceName = "users-api"
+
+// PrivacyRequest representa un pedido de exportación o borrado de los datos de un socio
+type PrivacyRequest struct {
+	ID          uint         `json:"id"`
+	UsuarioID   uint         `json:"usuario_id"`
+	Tipo        string       `json:"tipo"`
+	Estado      string       `json:"estado"`
+	RequestedBy uint         `json:"requested_by"`
+	Services    []string     `json:"services"` // Servicios que tienen que confirmar (incluye users-api)
+	Acks        []PrivacyAck `json:"acks"`
+	Pending     []string     `json:"pending"` // Servicios que todavía no confirmaron
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+}
+
+// PrivacyAck es la confirmación de un servicio para un pedido
+// Data solo viaja en las exportaciones y no se incluye en el JSON del pedido (se descarga con /export)
+type PrivacyAck struct {
+	Service    string          `json:"service"`
+	Estado     string          `json:"estado"` // completed | failed
+	Error      string          `json:"error,omitempty"`
+	Records    int             `json:"records"` // Registros exportados, borrados o anonimizados
+	Data       json.RawMessage `json:"-"`
+	ReceivedAt time.Time       `json:"received_at"`
+}
+
+// PrivacyRequestCreate representa el pedido de un admin (POST /privacy-requests)
+type PrivacyRequestCreate struct {
+	UsuarioID uint   `json:"usuario_id" binding:"required"`
+	Tipo      string `json:"tipo" binding:"required"`
+}
+
+// ListPrivacyRequestsQuery representa los query params de GET /privacy-requests
+type ListPrivacyRequestsQuery struct {
+	UsuarioID *uint  `form:"usuario_id"`
+	Estado    string `form:"estado"`
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// PrivacyExportBundle es el archivo que se entrega al socio: los datos de cada servicio por nombre
+type PrivacyExportBundle struct {
+	RequestID   uint                       `json:"request_id"`
+	UsuarioID   uint                       `json:"usuario_id"`
+	GeneratedAt time.Time                  `json:"generated_at"`
+	Services    map[string]json.RawMessage `json:"services"`
+}
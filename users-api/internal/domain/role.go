@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Permisos (recurso:acción[:alcance]) emitidos en el claim "permissions" del JWT
+// Cada microservicio verifica los que le corresponden con RequirePermission(...)
+const (
+	PermissionAll = "*" // Todos los permisos (rol admin)
+
+	PermissionUsersRead   = "users:read"
+	PermissionUsersManage = "users:manage"
+	PermissionRolesManage = "roles:manage"
+
+	PermissionPrivacyManage = "privacy:manage" // Exportación y borrado de datos de socios
+
+	PermissionActivitiesManage         = "activities:manage"          // Cualquier actividad
+	PermissionActivitiesManageSucursal = "activities:manage:sucursal" // Solo actividades de las sucursales asignadas
+	PermissionActivitiesUpdateOwn      = "activities:update:own"      // Solo actividades donde es instructor
+
+	PermissionPlansManage = "plans:manage"
+
+	PermissionSubscriptionsRead   = "subscriptions:read"
+	PermissionSubscriptionsManage = "subscriptions:manage"
+
+	PermissionPaymentsRead   = "payments:read"
+	PermissionPaymentsManage = "payments:manage"
+)
+
+// ScopeSucursalSuffix identifica permisos limitados a las sucursales de la asignación del rol
+const ScopeSucursalSuffix = ":sucursal"
+
+// AllPermissions es el catálogo de permisos que se pueden asignar a un rol
+var AllPermissions = []string{
+	PermissionUsersRead,
+	PermissionUsersManage,
+	PermissionRolesManage,
+	PermissionPrivacyManage,
+	PermissionActivitiesManage,
+	PermissionActivitiesManageSucursal,
+	PermissionActivitiesUpdateOwn,
+	PermissionPlansManage,
+	PermissionSubscriptionsRead,
+	PermissionSubscriptionsManage,
+	PermissionPaymentsRead,
+	PermissionPaymentsManage,
+}
+
+// Roles predefinidos (se crean al iniciar users-api si no existen)
+const (
+	RoleAdmin         = "admin"
+	RoleBranchManager = "branch_manager"
+	RoleReceptionist  = "receptionist"
+	RoleInstructor    = "instructor"
+)
+
+// DefaultRoles define los roles predefinidos y sus permisos iniciales
+var DefaultRoles = []Role{
+	{
+		Nombre:      RoleAdmin,
+		Descripcion: "Acceso total",
+		BuiltIn:     true,
+		Permissions: []string{PermissionAll},
+	},
+	{
+		Nombre:      RoleBranchManager,
+		Descripcion: "Gerente de sucursal: gestiona las actividades de sus sucursales",
+		BuiltIn:     true,
+		Permissions: []string{PermissionActivitiesManageSucursal, PermissionUsersRead, PermissionSubscriptionsRead, PermissionPaymentsRead},
+	},
+	{
+		Nombre:      RoleReceptionist,
+		Descripcion: "Recepción: alta de suscripciones y cobros",
+		BuiltIn:     true,
+		Permissions: []string{PermissionUsersRead, PermissionSubscriptionsRead, PermissionSubscriptionsManage, PermissionPaymentsRead, PermissionPaymentsManage},
+	},
+	{
+		Nombre:      RoleInstructor,
+		Descripcion: "Instructor: edita solo sus propias actividades",
+		BuiltIn:     true,
+		Permissions: []string{PermissionActivitiesUpdateOwn},
+	},
+}
+
+// IsKnownPermission indica si el permiso pertenece al catálogo
+func IsKnownPermission(permission string) bool {
+	if permission == PermissionAll {
+		return true
+	}
+	for _, p := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Role representa un rol con su conjunto de permisos
+type Role struct {
+	ID          uint      `json:"id"`
+	Nombre      string    `json:"nombre"`
+	Descripcion string    `json:"descripcion"`
+	BuiltIn     bool      `json:"built_in"` // Los roles predefinidos no se pueden eliminar
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// HasScopedPermissions indica si el rol tiene permisos limitados a sucursales
+func (r Role) HasScopedPermissions() bool {
+	for _, p := range r.Permissions {
+		if strings.HasSuffix(p, ScopeSucursalSuffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleCreate representa los datos para crear un rol
+type RoleCreate struct {
+	Nombre      string   `json:"nombre" binding:"required"`
+	Descripcion string   `json:"descripcion"`
+	Permissions []string `json:"permissions" binding:"required,min=1"`
+}
+
+// RoleUpdate representa los datos para modificar un rol (campos nil no se modifican)
+type RoleUpdate struct {
+	Descripcion *string  `json:"descripcion,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// UserRole representa la asignación de un rol a un usuario, opcionalmente limitada a una sucursal
+type UserRole struct {
+	ID          uint      `json:"id"`
+	UsuarioID   uint      `json:"usuario_id"`
+	RoleID      uint      `json:"role_id"`
+	Role        string    `json:"role"`
+	SucursalID  *uint     `json:"sucursal_id,omitempty"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UserRoleAssign representa el body de POST /users/:id/roles
+type UserRoleAssign struct {
+	Role       string `json:"role" binding:"required"`
+	SucursalID *uint  `json:"sucursal_id,omitempty"` // Requerido si el rol tiene permisos ":sucursal"
+}
+
+// Authorization representa los roles y permisos efectivos de un usuario (claims del JWT)
+type Authorization struct {
+	Roles       []string
+	Permissions []string
+	SucursalIDs []uint // Sucursales donde aplican los permisos ":sucursal"
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// RefreshToken representa un refresh token de una sesión (familia de tokens rotados)
+type RefreshToken struct {
+	ID         uint
+	FamilyID   string
+	UsuarioID  uint
+	TokenHash  string
+	ExpiresAt  time.Time
+	UsedAt     *time.Time
+	ReplacedBy *uint
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// AuthTokens representa el par de tokens entregado al cliente
+type AuthTokens struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // Segundos de vida del access token
+}
+
+// RefreshRequest representa el body de POST /token/refresh
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// SessionStatus representa el estado de revocación de una sesión (consultado por otros microservicios)
+type SessionStatus struct {
+	SessionID string `json:"sid"`
+	Revoked   bool   `json:"revoked"`
+}
@@ -0,0 +1,90 @@
+package domain
+
+import "time"
+
+// Propósitos de los challenges de login en dos pasos
+const (
+	ChallengePurposeVerify = "verify" // El usuario tiene 2FA: debe enviar un código TOTP o de recuperación
+	ChallengePurposeSetup  = "setup"  // La política exige 2FA y el usuario no lo configuró: debe enrolarse
+)
+
+// TwoFactor representa la configuración TOTP de un usuario
+// Secret se guarda cifrado (ver security.SecretBox)
+type TwoFactor struct {
+	UsuarioID    uint
+	Secret       string
+	EnabledAt    *time.Time // nil = enrolamiento iniciado pero no confirmado
+	LastUsedStep int64      // Último paso TOTP aceptado (evita reusar un código)
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// IsEnabled indica si el enrolamiento fue confirmado
+func (t TwoFactor) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
+
+// LoginChallenge es el paso intermedio del login cuando se requiere segundo factor
+// Solo se guarda el hash SHA-256 del challenge
+type LoginChallenge struct {
+	ID            uint
+	UsuarioID     uint
+	Purpose       string
+	ChallengeHash string
+	Attempts      int
+	ExpiresAt     time.Time
+	UsedAt        *time.Time
+	CreatedAt     time.Time
+}
+
+// TwoFactorStatus representa la respuesta de GET /users/me/2fa
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"` // La política lo exige para este usuario (admins)
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetup contiene el secreto a cargar en la app autenticadora
+// ProvisioningURI (otpauth://) se muestra como código QR
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// LoginChallengeResponse es la respuesta de POST /login cuando falta el segundo factor
+type LoginChallengeResponse struct {
+	TwoFactorRequired      bool   `json:"two_factor_required"`
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required"`
+	Challenge              string `json:"challenge"`
+	ExpiresIn              int64  `json:"expires_in"` // Segundos de vida del challenge
+}
+
+// LoginResult es el resultado de Login: tokens completos o un challenge de segundo factor
+type LoginResult struct {
+	User      UserResponse
+	Tokens    AuthTokens
+	Challenge *LoginChallengeResponse
+}
+
+// TwoFactorCodeRequest representa un body con un código TOTP o de recuperación
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableRequest representa el body de POST /users/me/2fa/disable
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// TwoFactorLoginRequest representa el body de POST /login/2fa y /login/2fa/setup/confirm
+type TwoFactorLoginRequest struct {
+	Challenge string `json:"challenge" binding:"required"`
+	Code      string `json:"code" binding:"required"` // Código TOTP o de recuperación
+}
+
+// TwoFactorChallengeRequest representa el body de POST /login/2fa/setup
+type TwoFactorChallengeRequest struct {
+	Challenge string `json:"challenge" binding:"required"`
+}
@@ -5,17 +5,18 @@ import "time"
 // User representa la entidad de negocio Usuario
 // Este modelo es independiente de la base de datos
 type User struct {
-	ID                uint      `json:"id"`
-	Nombre            string    `json:"nombre"`
-	Apellido          string    `json:"apellido"`
-	Username          string    `json:"username"`
-	Email             string    `json:"email"`
-	Password          string    `json:"password,omitempty"` // omitempty para no exponerlo en responses
-	IsAdmin           bool      `json:"is_admin"`
-	SucursalOrigenID  *uint     `json:"sucursal_origen_id,omitempty"` // Nullable
-	FechaRegistro     time.Time `json:"fecha_registro"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID               uint       `json:"id"`
+	Nombre           string     `json:"nombre"`
+	Apellido         string     `json:"apellido"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	Password         string     `json:"password,omitempty"` // omitempty para no exponerlo en responses
+	IsAdmin          bool       `json:"is_admin"`
+	SucursalOrigenID *uint      `json:"sucursal_origen_id,omitempty"` // Nullable
+	EmailVerifiedAt  *time.Time `json:"email_verified_at,omitempty"`  // nil = email sin verificar
+	FechaRegistro    time.Time  `json:"fecha_registro"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
 }
 
 // UserLogin representa las credenciales de login
@@ -34,6 +35,51 @@ type UserRegister struct {
 	SucursalOrigenID *uint  `json:"sucursal_origen_id,omitempty"`
 }
 
+// UserUpdate representa la actualización parcial del perfil propio (PATCH /users/me)
+// Los campos nil no se modifican
+type UserUpdate struct {
+	Nombre           *string `json:"nombre,omitempty"`
+	Apellido         *string `json:"apellido,omitempty"`
+	Username         *string `json:"username,omitempty"`
+	Email            *string `json:"email,omitempty"`
+	SucursalOrigenID *uint   `json:"sucursal_origen_id,omitempty"`
+}
+
+// AdminUserUpdate representa la actualización de un usuario por un administrador (PATCH /users/:id)
+type AdminUserUpdate struct {
+	UserUpdate
+	IsAdmin *bool `json:"is_admin,omitempty"`
+}
+
+// PasswordChange representa el cambio de contraseña del usuario autenticado
+type PasswordChange struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+// ListUsersQuery representa los query params de GET /users (filtros, orden y paginación)
+// Fechas de registro en formato YYYY-MM-DD, ambos extremos inclusive
+type ListUsersQuery struct {
+	IsAdmin          *bool     `form:"is_admin"`
+	SucursalOrigenID *uint     `form:"sucursal_origen_id"`
+	RegisteredFrom   time.Time `form:"registered_from" time_format:"2006-01-02"`
+	RegisteredTo     time.Time `form:"registered_to" time_format:"2006-01-02"`
+	Q                string    `form:"q"` // Texto libre sobre nombre, apellido, username y email
+	Page             int       `form:"page" binding:"omitempty,min=1"`
+	PageSize         int       `form:"page_size" binding:"omitempty,min=1,max=100"`
+	SortBy           string    `form:"sort_by" binding:"omitempty,oneof=id nombre apellido username email fecha_registro"`
+	SortDesc         bool      `form:"sort_desc"`
+}
+
+// PaginatedUsersResponse representa la respuesta paginada de usuarios
+type PaginatedUsersResponse struct {
+	Users      []UserResponse `json:"users"`
+	Total      int            `json:"total"`
+	Page       int            `json:"page"`
+	PageSize   int            `json:"page_size"`
+	TotalPages int            `json:"total_pages"`
+}
+
 // UserResponse representa la respuesta pública del usuario (sin password)
 type UserResponse struct {
 	ID               uint      `json:"id"`
@@ -43,6 +89,7 @@ type UserResponse struct {
 	Email            string    `json:"email"`
 	IsAdmin          bool      `json:"is_admin"`
 	SucursalOrigenID *uint     `json:"sucursal_origen_id,omitempty"`
+	EmailVerified    bool      `json:"email_verified"`
 	FechaRegistro    time.Time `json:"fecha_registro"`
 }
 
@@ -56,6 +103,28 @@ func (u User) ToResponse() UserResponse {
 		Email:            u.Email,
 		IsAdmin:          u.IsAdmin,
 		SucursalOrigenID: u.SucursalOrigenID,
+		EmailVerified:    u.EmailVerifiedAt != nil,
 		FechaRegistro:    u.FechaRegistro,
 	}
 }
+
+// MaxUsersBatchSize es la cantidad máxima de IDs por consulta batch interna
+const MaxUsersBatchSize = 500
+
+// UsersBatchRequest representa el body de POST /internal/users/batch
+type UsersBatchRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=500"`
+}
+
+// UsersBatchResponse representa la respuesta de la consulta batch interna
+// Missing contiene los IDs pedidos que no existen (o fueron dados de baja)
+type UsersBatchResponse struct {
+	Users   []UserResponse `json:"users"`
+	Missing []uint         `json:"missing"`
+}
+
+// UserExistsResponse representa la respuesta de GET /internal/users/:id/exists
+type UserExistsResponse struct {
+	ID     uint `json:"id"`
+	Exists bool `json:"exists"`
+}
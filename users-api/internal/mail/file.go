@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer escribe cada email como archivo .eml (desarrollo/tests, sin servidor de correo)
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer crea el directorio de salida si no existe
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating mail dir %s: %w", dir, err)
+	}
+
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// Send escribe el mensaje en <dir>/<timestamp>-<destinatario>.eml
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	recipient := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), recipient)
+	path := filepath.Join(m.dir, name)
+
+	if err := os.WriteFile(path, buildMessage(m.from, msg), 0o644); err != nil {
+		return fmt.Errorf("error writing email: %w", err)
+	}
+
+	log.Printf("📧 Email to %s written to %s", msg.To, path)
+	return nil
+}
+
+// LogMailer imprime los emails en el log (default en desarrollo)
+type LogMailer struct{}
+
+// NewLogMailer crea un Mailer que solo loguea
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send imprime el mensaje en el log
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("📧 Email to %s | Subject: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
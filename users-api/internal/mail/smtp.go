@@ -0,0 +1,50 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTPMailer envía emails a través de un servidor SMTP
+// net/smtp negocia STARTTLS si el servidor lo soporta (puerto 587)
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer crea un Mailer SMTP; sin usuario no se autentica (ej: relay interno)
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: host + ":" + strconv.Itoa(port),
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send envía el mensaje
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// El envelope sender es solo la dirección (MAIL_FROM puede incluir nombre)
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("invalid MAIL_FROM: %w", err)
+	}
+
+	if err := smtp.SendMail(m.addr, m.auth, sender.Address, []string{msg.To}, buildMessage(m.from, msg)); err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+
+	return nil
+}
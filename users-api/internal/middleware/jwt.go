@@ -9,7 +9,8 @@ import (
 )
 
 // JWTAuthMiddleware valida el token JWT en el header Authorization
-func JWTAuthMiddleware(userService services.UsersService) gin.HandlerFunc {
+// También rechaza tokens cuya sesión fue revocada (logout o reuso de refresh token)
+func JWTAuthMiddleware(sessionsService services.SessionsService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// Obtener header Authorization
 		auth := ctx.GetHeader("Authorization")
@@ -30,7 +31,7 @@ func JWTAuthMiddleware(userService services.UsersService) gin.HandlerFunc {
 		}
 
 		// Validar token
-		tokenClaims, err := userService.ValidateToken(parts[1])
+		tokenClaims, err := sessionsService.ValidateToken(ctx.Request.Context(), parts[1])
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error":   "Invalid or expired token",
@@ -43,10 +44,15 @@ func JWTAuthMiddleware(userService services.UsersService) gin.HandlerFunc {
 		idUser, _ := tokenClaims["id_usuario"].(float64)
 		isAdmin, _ := tokenClaims["is_admin"].(bool)
 		username, _ := tokenClaims["username"].(string)
+		sessionID, _ := tokenClaims["sid"].(string)
 
 		ctx.Set("id_usuario", uint(idUser))
 		ctx.Set("is_admin", isAdmin)
 		ctx.Set("username", username)
+		ctx.Set("sid", sessionID)
+		ctx.Set("roles", claimStrings(tokenClaims["roles"]))
+		ctx.Set("permissions", claimStrings(tokenClaims["permissions"]))
+		ctx.Set("sucursal_ids", claimUints(tokenClaims["sucursal_ids"]))
 
 		ctx.Next()
 	}
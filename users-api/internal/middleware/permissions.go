@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"users-api/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission verifica que el usuario tenga al menos uno de los permisos indicados
+// Debe usarse DESPUÉS de JWTAuthMiddleware
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		for _, permission := range permissions {
+			if HasPermission(ctx, permission) {
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":    "You don't have permission to perform this action",
+			"required": permissions,
+		})
+	}
+}
+
+// HasPermission indica si los claims del token otorgan el permiso
+// is_admin y el permiso "*" otorgan todos los permisos
+func HasPermission(ctx *gin.Context, permission string) bool {
+	if ctx.GetBool("is_admin") {
+		return true
+	}
+	for _, p := range ctx.GetStringSlice("permissions") {
+		if p == domain.PermissionAll || p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// claimStrings convierte un claim array del JWT a []string
+func claimStrings(value interface{}) []string {
+	items, _ := value.([]interface{})
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// claimUints convierte un claim array numérico del JWT a []uint
+func claimUints(value interface{}) []uint {
+	items, _ := value.([]interface{})
+	result := make([]uint, 0, len(items))
+	for _, item := range items {
+		if n, ok := item.(float64); ok && n >= 0 {
+			result = append(result, uint(n))
+		}
+	}
+	return result
+}
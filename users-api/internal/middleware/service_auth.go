@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAuthMiddleware autentica a otros microservicios con HTTP Basic (nombre del servicio y secreto)
+// Las credenciales vienen de SERVICE_CREDENTIALS; sin credenciales configuradas rechaza todo
+func ServiceAuthMiddleware(credentials map[string]string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		service, secret, ok := ctx.Request.BasicAuth()
+		if !ok {
+			ctx.Header("WWW-Authenticate", `Basic realm="users-api internal"`)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Service credentials required",
+			})
+			return
+		}
+
+		expected, exists := credentials[service]
+		if !exists || subtle.ConstantTimeCompare([]byte(secret), []byte(expected)) != 1 {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid service credentials",
+			})
+			return
+		}
+
+		ctx.Set("service_name", service)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,350 @@
+// Package mockprovider es un proveedor OpenID Connect mínimo para desarrollo y tests.
+// Implementa discovery, JWKS, authorization code con PKCE (S256), token y userinfo.
+// No hay contraseñas: la pantalla de autorización pide el email con el que "iniciar sesión",
+// o se saltea con el parámetro login_hint.
+package mockprovider
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"users-api/internal/security"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// codeTTL es la vida de un authorization code emitido por el mock
+const codeTTL = time.Minute
+
+// Config contiene los parámetros del proveedor mock
+type Config struct {
+	Issuer       string // URL base con la que lo ven users-api y el navegador (ej: http://localhost:9999)
+	ClientID     string
+	ClientSecret string
+}
+
+// User es un usuario del proveedor mock
+// Sin datos cargados, cualquier email ingresado se acepta con nombre derivado del email
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+// authorization es un code emitido pendiente de canje
+type authorization struct {
+	user          User
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+// Server es el proveedor mock; implementa http.Handler
+type Server struct {
+	cfg   Config
+	keys  *security.KeySet
+	mux   *http.ServeMux
+	mu    sync.Mutex
+	users map[string]User          // por email
+	codes map[string]authorization // por code
+	// access tokens emitidos -> usuario (para userinfo)
+	accessTokens map[string]User
+}
+
+// New crea el proveedor mock con una clave RS256 efímera
+func New(cfg Config, users ...User) (*Server, error) {
+	keys, err := security.NewEphemeralKeySet(security.SigningAlgRS256)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		cfg:          cfg,
+		keys:         keys,
+		mux:          http.NewServeMux(),
+		users:        make(map[string]User),
+		codes:        make(map[string]authorization),
+		accessTokens: make(map[string]User),
+	}
+	for _, user := range users {
+		s.AddUser(user)
+	}
+
+	s.mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	s.mux.HandleFunc("/jwks", s.jwks)
+	s.mux.HandleFunc("/authorize", s.authorize)
+	s.mux.HandleFunc("/token", s.token)
+	s.mux.HandleFunc("/userinfo", s.userinfo)
+	return s, nil
+}
+
+// AddUser registra (o reemplaza) un usuario del mock
+func (s *Server) AddUser(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user.Email = strings.ToLower(user.Email)
+	if user.Subject == "" {
+		user.Subject = subjectFor(user.Email)
+	}
+	s.users[user.Email] = user
+}
+
+// ServeHTTP implementa http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := strings.TrimRight(s.cfg.Issuer, "/")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"userinfo_endpoint":                     issuer + "/userinfo",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{security.SigningAlgRS256},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post"},
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.keys.JWKS())
+}
+
+// loginPage es la pantalla de "login" del mock: solo pide el email
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Mock OIDC</title></head>
+<body>
+<h1>Mock OIDC provider</h1>
+<form method="get" action="/authorize">
+{{range $k, $v := .Params}}<input type="hidden" name="{{$k}}" value="{{$v}}">
+{{end}}<label>Email <input type="email" name="login_hint" required></label>
+<button type="submit">Sign in</button>
+</form>
+</body></html>`))
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	if q.Get("client_id") != s.cfg.ClientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("response_type") != "code" {
+		redirectError(w, r, redirectURI, q.Get("state"), "unsupported_response_type")
+		return
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		redirectError(w, r, redirectURI, q.Get("state"), "invalid_request")
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(q.Get("login_hint")))
+	if email == "" {
+		params := make(map[string]string)
+		for k := range q {
+			params[k] = q.Get(k)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = loginPage.Execute(w, map[string]interface{}{"Params": params})
+		return
+	}
+
+	code, err := randomToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	s.codes[code] = authorization{
+		user:          s.userFor(email),
+		clientID:      q.Get("client_id"),
+		redirectURI:   redirectURI.String(),
+		nonce:         q.Get("nonce"),
+		codeChallenge: q.Get("code_challenge"),
+		expiresAt:     time.Now().Add(codeTTL),
+	}
+	s.mu.Unlock()
+
+	values := redirectURI.Query()
+	values.Set("code", code)
+	values.Set("state", q.Get("state"))
+	redirectURI.RawQuery = values.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != s.cfg.ClientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(s.cfg.ClientSecret)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="mock-oidc"`)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	auth, exists := s.codes[code]
+	delete(s.codes, code) // un code se canjea una sola vez
+	s.mu.Unlock()
+
+	if !exists || time.Now().After(auth.expiresAt) || auth.clientID != clientID ||
+		auth.redirectURI != r.PostForm.Get("redirect_uri") || !verifyPKCE(r.PostForm.Get("code_verifier"), auth.codeChallenge) {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	idToken, err := s.keys.Sign(jwt.MapClaims{
+		"iss":            strings.TrimRight(s.cfg.Issuer, "/"),
+		"sub":            auth.user.Subject,
+		"aud":            clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          auth.nonce,
+		"email":          auth.user.Email,
+		"email_verified": auth.user.EmailVerified,
+		"given_name":     auth.user.GivenName,
+		"family_name":    auth.user.FamilyName,
+		"name":           strings.TrimSpace(auth.user.GivenName + " " + auth.user.FamilyName),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	accessToken, err := randomToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.mu.Lock()
+	s.accessTokens[accessToken] = auth.user
+	s.mu.Unlock()
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (s *Server) userinfo(w http.ResponseWriter, r *http.Request) {
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	s.mu.Lock()
+	user, ok := s.accessTokens[accessToken]
+	s.mu.Unlock()
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sub":            user.Subject,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"given_name":     user.GivenName,
+		"family_name":    user.FamilyName,
+	})
+}
+
+// userFor devuelve el usuario registrado con ese email o uno derivado del email (verificado)
+// Debe llamarse con s.mu tomado
+func (s *Server) userFor(email string) User {
+	if user, ok := s.users[email]; ok {
+		return user
+	}
+	local := email
+	if at := strings.Index(email, "@"); at > 0 {
+		local = email[:at]
+	}
+	return User{
+		Subject:       subjectFor(email),
+		Email:         email,
+		EmailVerified: true,
+		GivenName:     local,
+		FamilyName:    "Mock",
+	}
+}
+
+// subjectFor deriva un subject estable del email (el mismo email siempre es el mismo usuario)
+func subjectFor(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	return "mock-" + base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// verifyPKCE compara BASE64URL(SHA256(code_verifier)) con el code_challenge
+func verifyPKCE(verifier, challenge string) bool {
+	if verifier == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func redirectError(w http.ResponseWriter, r *http.Request, redirectURI *url.URL, state, code string) {
+	values := redirectURI.Query()
+	values.Set("error", code)
+	values.Set("state", state)
+	redirectURI.RawQuery = values.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
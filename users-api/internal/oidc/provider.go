@@ -0,0 +1,187 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"users-api/internal/config"
+	"users-api/internal/domain"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// DefaultScopes son los scopes pedidos si el proveedor no configura otros
+var DefaultScopes = []string{gooidc.ScopeOpenID, "email", "profile"}
+
+// Provider es un proveedor OpenID Connect configurado (Google, mock local, etc.)
+// El documento de discovery se obtiene en el primer uso y queda cacheado
+type Provider struct {
+	name         string
+	displayName  string
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	httpClient   *http.Client
+
+	mu       sync.Mutex
+	provider *gooidc.Provider
+	verifier *gooidc.IDTokenVerifier
+}
+
+// NewProvider crea un proveedor a partir de su configuración
+// redirectURL es el callback de users-api registrado en el proveedor
+func NewProvider(cfg config.OIDCProviderConfig, redirectURL string) (*Provider, error) {
+	if cfg.Issuer == "" || cfg.ClientID == "" {
+		return nil, fmt.Errorf("oidc provider %q: issuer and client id are required", cfg.Name)
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+	displayName := cfg.DisplayName
+	if displayName == "" {
+		displayName = cfg.Name
+	}
+
+	return &Provider{
+		name:         cfg.Name,
+		displayName:  displayName,
+		issuer:       cfg.Issuer,
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		redirectURL:  redirectURL,
+		scopes:       scopes,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name devuelve el identificador del proveedor (usado en las rutas y en identidades.provider)
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// Info devuelve los datos públicos del proveedor
+func (p *Provider) Info() domain.OIDCProviderInfo {
+	return domain.OIDCProviderInfo{Name: p.name, DisplayName: p.displayName}
+}
+
+// AuthCodeURL arma la URL de autorización con state, nonce y el challenge PKCE (S256)
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	oauthConfig, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return oauthConfig.AuthCodeURL(state,
+		gooidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(codeVerifier),
+	), nil
+}
+
+// Exchange canjea el authorization code (con el code_verifier PKCE), verifica el ID token
+// (firma, issuer, audience, vencimiento y nonce) y devuelve el perfil del usuario
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (domain.ExternalProfile, error) {
+	oauthConfig, verifier, err := p.discover(ctx)
+	if err != nil {
+		return domain.ExternalProfile{}, err
+	}
+
+	ctx = gooidc.ClientContext(ctx, p.httpClient)
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return domain.ExternalProfile{}, fmt.Errorf("error exchanging authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return domain.ExternalProfile{}, errors.New("provider did not return an id token")
+	}
+
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return domain.ExternalProfile{}, fmt.Errorf("invalid id token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return domain.ExternalProfile{}, errors.New("invalid id token: nonce mismatch")
+	}
+
+	var claims profileClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return domain.ExternalProfile{}, fmt.Errorf("invalid id token claims: %w", err)
+	}
+
+	// Algunos proveedores no incluyen el email en el ID token: se pide a userinfo
+	if claims.Email == "" && p.provider.UserInfoEndpoint() != "" {
+		userInfo, err := p.provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+		if err != nil {
+			return domain.ExternalProfile{}, fmt.Errorf("error getting user info: %w", err)
+		}
+		if userInfo.Subject != idToken.Subject {
+			return domain.ExternalProfile{}, errors.New("userinfo subject does not match id token")
+		}
+		if err := userInfo.Claims(&claims); err != nil {
+			return domain.ExternalProfile{}, fmt.Errorf("invalid userinfo claims: %w", err)
+		}
+	}
+
+	return domain.ExternalProfile{
+		Provider:      p.name,
+		Subject:       idToken.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: claims.emailVerified(),
+		GivenName:     claims.GivenName,
+		FamilyName:    claims.FamilyName,
+		Name:          claims.Name,
+	}, nil
+}
+
+// discover obtiene (una sola vez) el documento de discovery y arma el cliente OAuth2 y el verificador
+func (p *Provider) discover(ctx context.Context) (*oauth2.Config, *gooidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider == nil {
+		provider, err := gooidc.NewProvider(gooidc.ClientContext(ctx, p.httpClient), p.issuer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error discovering oidc provider %q: %w", p.name, err)
+		}
+		p.provider = provider
+		p.verifier = provider.Verifier(&gooidc.Config{ClientID: p.clientID})
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.clientID,
+		ClientSecret: p.clientSecret,
+		RedirectURL:  p.redirectURL,
+		Endpoint:     p.provider.Endpoint(),
+		Scopes:       p.scopes,
+	}, p.verifier, nil
+}
+
+// profileClaims son los claims estándar de perfil (ID token o userinfo)
+type profileClaims struct {
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"` // Algunos proveedores lo envían como string
+	GivenName     string      `json:"given_name"`
+	FamilyName    string      `json:"family_name"`
+	Name          string      `json:"name"`
+}
+
+func (c profileClaims) emailVerified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,59 @@
+package oidc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"users-api/internal/config"
+	"users-api/internal/domain"
+)
+
+// providerNameRegex restringe los nombres a algo seguro para rutas y para la columna identidades.provider
+var providerNameRegex = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// Registry contiene los proveedores OIDC habilitados (OIDC_PROVIDERS)
+type Registry struct {
+	providers map[string]*Provider
+	order     []string
+}
+
+// NewRegistry crea los proveedores configurados
+// El callback de cada uno es <RedirectBaseURL>/auth/oidc/<nombre>/callback
+func NewRegistry(cfg config.OIDCConfig) (*Registry, error) {
+	registry := &Registry{providers: make(map[string]*Provider)}
+
+	for _, providerCfg := range cfg.Providers {
+		if !providerNameRegex.MatchString(providerCfg.Name) {
+			return nil, fmt.Errorf("invalid oidc provider name %q (lowercase letters, numbers, - and _)", providerCfg.Name)
+		}
+		if _, exists := registry.providers[providerCfg.Name]; exists {
+			return nil, fmt.Errorf("duplicated oidc provider %q", providerCfg.Name)
+		}
+
+		redirectURL := strings.TrimRight(cfg.RedirectBaseURL, "/") + "/auth/oidc/" + providerCfg.Name + "/callback"
+		provider, err := NewProvider(providerCfg, redirectURL)
+		if err != nil {
+			return nil, err
+		}
+
+		registry.providers[providerCfg.Name] = provider
+		registry.order = append(registry.order, providerCfg.Name)
+	}
+
+	return registry, nil
+}
+
+// Get devuelve un proveedor por nombre
+func (r *Registry) Get(name string) (*Provider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
+
+// List devuelve los proveedores en el orden configurado
+func (r *Registry) List() []domain.OIDCProviderInfo {
+	infos := make([]domain.OIDCProviderInfo, 0, len(r.order))
+	for _, name := range r.order {
+		infos = append(infos, r.providers[name].Info())
+	}
+	return infos
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// IdentitiesRepository define la interfaz del repositorio de identidades externas (login social)
+type IdentitiesRepository interface {
+	Create(ctx context.Context, identity domain.Identity) (domain.Identity, error)
+	GetByProviderSubject(ctx context.Context, provider, subject string) (domain.Identity, error)
+	ListByUser(ctx context.Context, usuarioID uint) ([]domain.Identity, error)
+	TouchLastLogin(ctx context.Context, id uint, email string) error
+	Delete(ctx context.Context, usuarioID, id uint) error
+	DeleteByUser(ctx context.Context, usuarioID uint) (int64, error)
+}
+
+// MySQLIdentitiesRepository implementa IdentitiesRepository usando MySQL/GORM
+type MySQLIdentitiesRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLIdentitiesRepository crea una nueva instancia del repository
+// Comparte la conexión DB con UsersRepository
+func NewMySQLIdentitiesRepository(db *gorm.DB) *MySQLIdentitiesRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.Identity{}); err != nil {
+		log.Fatalf("Error auto-migrating Identity table: %v", err)
+		return nil
+	}
+
+	return &MySQLIdentitiesRepository{
+		db: db,
+	}
+}
+
+// Create vincula una identidad externa a un usuario
+func (r *MySQLIdentitiesRepository) Create(ctx context.Context, identity domain.Identity) (domain.Identity, error) {
+	identityDAO := dao.IdentityFromDomain(identity)
+
+	if err := r.db.WithContext(ctx).Create(&identityDAO).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || contains(err.Error(), "Duplicate entry") {
+			return domain.Identity{}, errors.New("identity already linked to an account")
+		}
+		return domain.Identity{}, fmt.Errorf("error creating identity: %w", err)
+	}
+
+	return identityDAO.ToDomain(), nil
+}
+
+// GetByProviderSubject busca la identidad de un usuario del proveedor
+func (r *MySQLIdentitiesRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (domain.Identity, error) {
+	var identityDAO dao.Identity
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identityDAO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Identity{}, errors.New("identity not found")
+		}
+		return domain.Identity{}, fmt.Errorf("error getting identity: %w", err)
+	}
+
+	return identityDAO.ToDomain(), nil
+}
+
+// ListByUser lista las identidades vinculadas a un usuario
+func (r *MySQLIdentitiesRepository) ListByUser(ctx context.Context, usuarioID uint) ([]domain.Identity, error) {
+	var identityDAOs []dao.Identity
+	err := r.db.WithContext(ctx).
+		Where("usuario_id = ?", usuarioID).
+		Order("id ASC").
+		Find(&identityDAOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing identities: %w", err)
+	}
+
+	identities := make([]domain.Identity, 0, len(identityDAOs))
+	for _, identityDAO := range identityDAOs {
+		identities = append(identities, identityDAO.ToDomain())
+	}
+	return identities, nil
+}
+
+// TouchLastLogin registra el login y actualiza el email informado por el proveedor
+func (r *MySQLIdentitiesRepository) TouchLastLogin(ctx context.Context, id uint, email string) error {
+	err := r.db.WithContext(ctx).
+		Model(&dao.Identity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_login_at": time.Now(),
+			"email":         email,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("error updating identity: %w", err)
+	}
+
+	return nil
+}
+
+// Delete desvincula una identidad del usuario
+func (r *MySQLIdentitiesRepository) Delete(ctx context.Context, usuarioID, id uint) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND usuario_id = ?", id, usuarioID).
+		Delete(&dao.Identity{})
+	if result.Error != nil {
+		return fmt.Errorf("error deleting identity: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("identity not found")
+	}
+
+	return nil
+}
+
+// DeleteByUser borra todas las identidades vinculadas a un usuario y devuelve cuántas eran
+func (r *MySQLIdentitiesRepository) DeleteByUser(ctx context.Context, usuarioID uint) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("usuario_id = ?", usuarioID).
+		Delete(&dao.Identity{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("error deleting identities: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
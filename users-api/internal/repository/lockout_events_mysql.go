@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// LockoutEventsRepository define la interfaz del registro de auditoría de bloqueos de login
+type LockoutEventsRepository interface {
+	Create(ctx context.Context, event domain.LockoutEvent) (domain.LockoutEvent, error)
+	List(ctx context.Context, query domain.ListLockoutEventsQuery) ([]domain.LockoutEvent, error)
+}
+
+// MySQLLockoutEventsRepository implementa LockoutEventsRepository usando MySQL/GORM
+type MySQLLockoutEventsRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLLockoutEventsRepository crea una nueva instancia del repository
+// Comparte la conexión DB con UsersRepository
+func NewMySQLLockoutEventsRepository(db *gorm.DB) *MySQLLockoutEventsRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.LockoutEvent{}); err != nil {
+		log.Fatalf("Error auto-migrating LockoutEvent table: %v", err)
+		return nil
+	}
+
+	return &MySQLLockoutEventsRepository{
+		db: db,
+	}
+}
+
+// Create guarda un evento de auditoría
+func (r *MySQLLockoutEventsRepository) Create(ctx context.Context, event domain.LockoutEvent) (domain.LockoutEvent, error) {
+	eventDAO := dao.LockoutEventFromDomain(event)
+
+	if err := r.db.WithContext(ctx).Create(&eventDAO).Error; err != nil {
+		return domain.LockoutEvent{}, fmt.Errorf("error creating lockout event: %w", err)
+	}
+
+	return eventDAO.ToDomain(), nil
+}
+
+// List devuelve los eventos más recientes primero, filtrando por usuario y/o IP
+func (r *MySQLLockoutEventsRepository) List(ctx context.Context, query domain.ListLockoutEventsQuery) ([]domain.LockoutEvent, error) {
+	db := r.db.WithContext(ctx).Model(&dao.LockoutEvent{})
+	if query.UsuarioID != nil {
+		db = db.Where("usuario_id = ?", *query.UsuarioID)
+	}
+	if query.IP != "" {
+		db = db.Where("ip = ? OR (scope = ? AND subject = ?)", query.IP, domain.LoginScopeIP, query.IP)
+	}
+
+	var eventsDAO []dao.LockoutEvent
+	if err := db.Order("created_at DESC, id DESC").Limit(query.Limit).Find(&eventsDAO).Error; err != nil {
+		return nil, fmt.Errorf("error listing lockout events: %w", err)
+	}
+
+	events := make([]domain.LockoutEvent, 0, len(eventsDAO))
+	for _, e := range eventsDAO {
+		events = append(events, e.ToDomain())
+	}
+
+	return events, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+	"users-api/internal/domain"
+)
+
+// LoginAttemptsStore guarda los intentos fallidos de login por clave (cuenta o IP)
+// Implementaciones: en memoria (una sola instancia) y MySQL (compartido entre réplicas)
+type LoginAttemptsStore interface {
+	// Get devuelve el estado de la clave (estado vacío si no hay intentos registrados)
+	Get(ctx context.Context, key string) (domain.LoginAttemptState, error)
+	// RecordFailure suma un fallo de forma atómica; si el último fallo es más viejo que window el contador vuelve a 1
+	RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (domain.LoginAttemptState, error)
+	// Lock bloquea la clave hasta until y reinicia el contador; devuelve false si ya estaba bloqueada
+	Lock(ctx context.Context, key string, until, now time.Time) (bool, error)
+	// Reset elimina el estado de la clave (login exitoso o desbloqueo manual)
+	Reset(ctx context.Context, key string) error
+}
+
+// MemoryLoginAttemptsStore implementa LoginAttemptsStore en memoria
+// El estado se pierde al reiniciar y no se comparte entre instancias
+type MemoryLoginAttemptsStore struct {
+	mu      sync.Mutex
+	entries map[string]domain.LoginAttemptState
+	window  time.Duration // Último window usado, para la limpieza perezosa
+}
+
+// NewMemoryLoginAttemptsStore crea un store en memoria vacío
+func NewMemoryLoginAttemptsStore() *MemoryLoginAttemptsStore {
+	return &MemoryLoginAttemptsStore{
+		entries: make(map[string]domain.LoginAttemptState),
+	}
+}
+
+// Get devuelve el estado de la clave
+func (s *MemoryLoginAttemptsStore) Get(ctx context.Context, key string) (domain.LoginAttemptState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.entries[key]
+	if !ok {
+		return domain.LoginAttemptState{Key: key}, nil
+	}
+	return state, nil
+}
+
+// RecordFailure suma un fallo a la clave
+func (s *MemoryLoginAttemptsStore) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (domain.LoginAttemptState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.window = window
+	s.purgeExpired(now)
+
+	state, ok := s.entries[key]
+	if !ok || now.Sub(state.LastFailureAt) > window {
+		state = domain.LoginAttemptState{Key: key, LockedUntil: state.LockedUntil}
+	}
+	state.Failures++
+	state.LastFailureAt = now
+	s.entries[key] = state
+
+	return state, nil
+}
+
+// Lock bloquea la clave hasta until
+func (s *MemoryLoginAttemptsStore) Lock(ctx context.Context, key string, until, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.entries[key]
+	if !ok {
+		state = domain.LoginAttemptState{Key: key, LastFailureAt: now}
+	}
+	if state.IsLocked(now) {
+		return false, nil
+	}
+
+	state.Failures = 0
+	state.LockedUntil = &until
+	s.entries[key] = state
+
+	return true, nil
+}
+
+// Reset elimina el estado de la clave
+func (s *MemoryLoginAttemptsStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// purgeExpired elimina las claves sin bloqueo vigente ni fallos dentro de la ventana
+// para que el mapa no crezca indefinidamente (debe llamarse con el lock tomado)
+func (s *MemoryLoginAttemptsStore) purgeExpired(now time.Time) {
+	for key, state := range s.entries {
+		if !state.IsLocked(now) && now.Sub(state.LastFailureAt) > s.window {
+			delete(s.entries, key)
+		}
+	}
+}
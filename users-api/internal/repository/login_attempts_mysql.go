@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// MySQLLoginAttemptsStore implementa LoginAttemptsStore usando MySQL/GORM
+// Permite que varias instancias de users-api compartan los contadores
+type MySQLLoginAttemptsStore struct {
+	db *gorm.DB
+}
+
+// NewMySQLLoginAttemptsStore crea una nueva instancia del store
+// Comparte la conexión DB con UsersRepository
+func NewMySQLLoginAttemptsStore(db *gorm.DB) *MySQLLoginAttemptsStore {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.LoginAttempt{}); err != nil {
+		log.Fatalf("Error auto-migrating LoginAttempt table: %v", err)
+		return nil
+	}
+
+	return &MySQLLoginAttemptsStore{
+		db: db,
+	}
+}
+
+// Get devuelve el estado de la clave
+func (s *MySQLLoginAttemptsStore) Get(ctx context.Context, key string) (domain.LoginAttemptState, error) {
+	var attempt dao.LoginAttempt
+	if err := s.db.WithContext(ctx).Where("clave = ?", key).First(&attempt).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.LoginAttemptState{Key: key}, nil
+		}
+		return domain.LoginAttemptState{}, fmt.Errorf("error getting login attempts: %w", err)
+	}
+
+	return attempt.ToDomain(), nil
+}
+
+// RecordFailure suma un fallo con un único INSERT ... ON DUPLICATE KEY UPDATE
+// (atómico aunque lleguen intentos en paralelo desde varias instancias)
+func (s *MySQLLoginAttemptsStore) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (domain.LoginAttemptState, error) {
+	err := s.db.WithContext(ctx).Exec(
+		`INSERT INTO login_attempts (clave, failures, last_failure_at, updated_at) VALUES (?, 1, ?, ?)
+		 ON DUPLICATE KEY UPDATE
+		   failures = IF(last_failure_at < ?, 1, failures + 1),
+		   last_failure_at = ?,
+		   updated_at = ?`,
+		key, now, now, now.Add(-window), now, now,
+	).Error
+	if err != nil {
+		return domain.LoginAttemptState{}, fmt.Errorf("error recording login failure: %w", err)
+	}
+
+	return s.Get(ctx, key)
+}
+
+// Lock bloquea la clave si no estaba bloqueada (UPDATE condicional: un solo bloqueo por ráfaga)
+func (s *MySQLLoginAttemptsStore) Lock(ctx context.Context, key string, until, now time.Time) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Model(&dao.LoginAttempt{}).
+		Where("clave = ? AND (locked_until IS NULL OR locked_until <= ?)", key, now).
+		Updates(map[string]interface{}{
+			"locked_until": until,
+			"failures":     0,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("error locking login: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Reset elimina el estado de la clave
+func (s *MySQLLoginAttemptsStore) Reset(ctx context.Context, key string) error {
+	if err := s.db.WithContext(ctx).Where("clave = ?", key).Delete(&dao.LoginAttempt{}).Error; err != nil {
+		return fmt.Errorf("error resetting login attempts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// LoginChallengesRepository define la interfaz del repositorio de challenges de login en dos pasos
+type LoginChallengesRepository interface {
+	Create(ctx context.Context, challenge domain.LoginChallenge) (domain.LoginChallenge, error)
+	GetActive(ctx context.Context, challengeHash string) (domain.LoginChallenge, error)
+	IncrementAttempts(ctx context.Context, id uint) error
+	Consume(ctx context.Context, id uint) (bool, error)
+}
+
+// MySQLLoginChallengesRepository implementa LoginChallengesRepository usando MySQL/GORM
+type MySQLLoginChallengesRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLLoginChallengesRepository crea una nueva instancia del repository
+// Comparte la conexión DB con UsersRepository
+func NewMySQLLoginChallengesRepository(db *gorm.DB) *MySQLLoginChallengesRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.LoginChallenge{}); err != nil {
+		log.Fatalf("Error auto-migrating LoginChallenge table: %v", err)
+		return nil
+	}
+
+	return &MySQLLoginChallengesRepository{
+		db: db,
+	}
+}
+
+// Create guarda un nuevo challenge
+func (r *MySQLLoginChallengesRepository) Create(ctx context.Context, challenge domain.LoginChallenge) (domain.LoginChallenge, error) {
+	challengeDAO := dao.LoginChallengeFromDomain(challenge)
+
+	if err := r.db.WithContext(ctx).Create(&challengeDAO).Error; err != nil {
+		return domain.LoginChallenge{}, fmt.Errorf("error creating login challenge: %w", err)
+	}
+
+	return challengeDAO.ToDomain(), nil
+}
+
+// GetActive obtiene un challenge sin usar y vigente por su hash
+func (r *MySQLLoginChallengesRepository) GetActive(ctx context.Context, challengeHash string) (domain.LoginChallenge, error) {
+	var challengeDAO dao.LoginChallenge
+	err := r.db.WithContext(ctx).
+		Where("challenge_hash = ? AND used_at IS NULL AND expires_at > ?", challengeHash, time.Now()).
+		First(&challengeDAO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.LoginChallenge{}, errors.New("invalid or expired challenge")
+		}
+		return domain.LoginChallenge{}, fmt.Errorf("error getting login challenge: %w", err)
+	}
+
+	return challengeDAO.ToDomain(), nil
+}
+
+// IncrementAttempts suma un intento fallido al challenge
+func (r *MySQLLoginChallengesRepository) IncrementAttempts(ctx context.Context, id uint) error {
+	err := r.db.WithContext(ctx).
+		Model(&dao.LoginChallenge{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+	if err != nil {
+		return fmt.Errorf("error updating login challenge: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marca el challenge como usado; devuelve false si ya se había usado
+func (r *MySQLLoginChallengesRepository) Consume(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&dao.LoginChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("error consuming login challenge: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// OIDCStatesRepository define la interfaz del repositorio de logins sociales en curso
+type OIDCStatesRepository interface {
+	Create(ctx context.Context, state domain.OIDCLoginState) error
+	Consume(ctx context.Context, stateHash string) (domain.OIDCLoginState, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+// MySQLOIDCStatesRepository implementa OIDCStatesRepository usando MySQL/GORM
+type MySQLOIDCStatesRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLOIDCStatesRepository crea una nueva instancia del repository
+// Comparte la conexión DB con UsersRepository
+func NewMySQLOIDCStatesRepository(db *gorm.DB) *MySQLOIDCStatesRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.OIDCLoginState{}); err != nil {
+		log.Fatalf("Error auto-migrating OIDCLoginState table: %v", err)
+		return nil
+	}
+
+	return &MySQLOIDCStatesRepository{
+		db: db,
+	}
+}
+
+// Create guarda el estado de un login social que empieza
+func (r *MySQLOIDCStatesRepository) Create(ctx context.Context, state domain.OIDCLoginState) error {
+	stateDAO := dao.OIDCLoginStateFromDomain(state)
+
+	if err := r.db.WithContext(ctx).Create(&stateDAO).Error; err != nil {
+		return fmt.Errorf("error creating oidc state: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marca el state como usado y lo devuelve
+// El UPDATE condicional garantiza que un callback no se pueda repetir
+func (r *MySQLOIDCStatesRepository) Consume(ctx context.Context, stateHash string) (domain.OIDCLoginState, error) {
+	var stateDAO dao.OIDCLoginState
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&dao.OIDCLoginState{}).
+			Where("state_hash = ? AND used_at IS NULL AND expires_at > ?", stateHash, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("invalid or expired state")
+		}
+
+		return tx.Where("state_hash = ?", stateHash).First(&stateDAO).Error
+	})
+	if err != nil {
+		if err.Error() == "invalid or expired state" {
+			return domain.OIDCLoginState{}, err
+		}
+		return domain.OIDCLoginState{}, fmt.Errorf("error consuming oidc state: %w", err)
+	}
+
+	return stateDAO.ToDomain(), nil
+}
+
+// DeleteExpired borra los states vencidos (logins abandonados)
+func (r *MySQLOIDCStatesRepository) DeleteExpired(ctx context.Context) error {
+	err := r.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now()).
+		Delete(&dao.OIDCLoginState{}).Error
+	if err != nil {
+		return fmt.Errorf("error deleting expired oidc states: %w", err)
+	}
+
+	return nil
+}
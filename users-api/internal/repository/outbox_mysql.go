@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository define la interfaz de la transactional outbox
+// Los repositorios que cambian usuarios escriben sus eventos con enqueueUserEvent dentro de
+// su propia transacción; el relay lee los pendientes y los publica en RabbitMQ
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, event domain.OutboxEvent) error
+	RelayPending(ctx context.Context, limit int, publish func(event domain.OutboxEvent) error) (int, error)
+	DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// MySQLOutboxRepository implementa OutboxRepository usando MySQL/GORM
+type MySQLOutboxRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLOutboxRepository crea una nueva instancia del repository
+// Comparte la conexión DB con UsersRepository
+func NewMySQLOutboxRepository(db *gorm.DB) *MySQLOutboxRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.OutboxEvent{}); err != nil {
+		log.Fatalf("Error auto-migrating OutboxEvent table: %v", err)
+		return nil
+	}
+
+	return &MySQLOutboxRepository{
+		db: db,
+	}
+}
+
+// Enqueue guarda un evento fuera de una transacción de negocio (ej: pedidos de privacidad)
+func (r *MySQLOutboxRepository) Enqueue(ctx context.Context, event domain.OutboxEvent) error {
+	return enqueueOutboxEvent(r.db.WithContext(ctx), event)
+}
+
+// RelayPending publica hasta limit eventos pendientes (en orden de creación) y los marca como publicados
+// Las filas se leen con FOR UPDATE SKIP LOCKED dentro de una transacción: si hay varias réplicas,
+// cada evento lo publica una sola y ninguna se saltea un evento bloqueado por otra
+// Se detiene en el primer error de publicación (el evento queda pendiente con el error registrado)
+// para no publicar eventos de un usuario fuera de orden
+func (r *MySQLOutboxRepository) RelayPending(ctx context.Context, limit int, publish func(event domain.OutboxEvent) error) (int, error) {
+	published := 0
+	var publishErr error
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var eventsDAO []dao.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL").
+			Order("id ASC").
+			Limit(limit).
+			Find(&eventsDAO).Error; err != nil {
+			return err
+		}
+
+		for _, eventDAO := range eventsDAO {
+			if err := publish(eventDAO.ToDomain()); err != nil {
+				errMsg := err.Error()
+				if len(errMsg) > 500 {
+					errMsg = errMsg[:500]
+				}
+				if err := tx.Model(&dao.OutboxEvent{}).
+					Where("id = ?", eventDAO.ID).
+					Updates(map[string]interface{}{
+						"attempts":   gorm.Expr("attempts + 1"),
+						"last_error": errMsg,
+					}).Error; err != nil {
+					return err
+				}
+
+				publishErr = fmt.Errorf("error publishing outbox event #%d: %w", eventDAO.ID, err)
+				return nil
+			}
+
+			// Si el commit falla, el evento se vuelve a publicar en la próxima ronda (duplicado, nunca perdido)
+			if err := tx.Model(&dao.OutboxEvent{}).
+				Where("id = ?", eventDAO.ID).
+				Updates(map[string]interface{}{
+					"published_at": time.Now(),
+					"attempts":     gorm.Expr("attempts + 1"),
+					"last_error":   "",
+				}).Error; err != nil {
+				return err
+			}
+			published++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error relaying outbox events: %w", err)
+	}
+
+	return published, publishErr
+}
+
+// DeletePublishedBefore purga los eventos ya publicados más viejos que la fecha indicada
+func (r *MySQLOutboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("published_at IS NOT NULL AND published_at < ?", before).
+		Delete(&dao.OutboxEvent{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("error purging outbox events: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// enqueueOutboxEvent inserta un evento usando la conexión o transacción recibida
+func enqueueOutboxEvent(tx *gorm.DB, event domain.OutboxEvent) error {
+	eventDAO, err := dao.OutboxEventFromDomain(event)
+	if err != nil {
+		return fmt.Errorf("error encoding outbox event: %w", err)
+	}
+
+	if err := tx.Create(&eventDAO).Error; err != nil {
+		return fmt.Errorf("error saving outbox event: %w", err)
+	}
+
+	return nil
+}
+
+// enqueueUserEvent guarda un evento user.<action> con el estado actual del usuario
+// Se llama dentro de la transacción del cambio: si el cambio se revierte, el evento también
+func enqueueUserEvent(tx *gorm.DB, action string, userID uint) error {
+	var userDAO dao.User
+	if err := tx.Unscoped().First(&userDAO, userID).Error; err != nil {
+		return fmt.Errorf("error loading user for event: %w", err)
+	}
+
+	var roles []string
+	if err := tx.Table("usuario_roles").
+		Joins("JOIN roles ON roles.id_rol = usuario_roles.rol_id").
+		Where("usuario_roles.usuario_id = ?", userID).
+		Order("roles.nombre").
+		Distinct().
+		Pluck("roles.nombre", &roles).Error; err != nil {
+		return fmt.Errorf("error loading user roles for event: %w", err)
+	}
+	if roles == nil {
+		roles = []string{}
+	}
+
+	return enqueueOutboxEvent(tx, domain.OutboxEvent{
+		EventType:   domain.UserEventType,
+		Action:      action,
+		AggregateID: strconv.FormatUint(uint64(userID), 10),
+		Data:        userEventData(userDAO, roles),
+	})
+}
+
+// userEventData arma el data del evento (nunca incluye el password)
+func userEventData(user dao.User, roles []string) map[string]interface{} {
+	data := map[string]interface{}{
+		"usuario_id":     strconv.FormatUint(uint64(user.ID), 10),
+		"nombre":         user.Nombre,
+		"apellido":       user.Apellido,
+		"username":       user.Username,
+		"email":          user.Email,
+		"is_admin":       user.IsAdmin,
+		"roles":          roles,
+		"email_verified": user.EmailVerifiedAt != nil,
+		"fecha_registro": user.FechaRegistro,
+		"deleted":        user.DeletedAt.Valid,
+	}
+	if user.SucursalOrigenID != nil {
+		data["sucursal_origen_id"] = strconv.FormatUint(uint64(*user.SucursalOrigenID), 10)
+	}
+
+	return data
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PrivacyRequestsRepository define la interfaz de los pedidos de exportación/borrado de datos y sus acuses
+type PrivacyRequestsRepository interface {
+	Create(ctx context.Context, request domain.PrivacyRequest) (domain.PrivacyRequest, error)
+	GetByID(ctx context.Context, id uint) (domain.PrivacyRequest, error)
+	List(ctx context.Context, query domain.ListPrivacyRequestsQuery) ([]domain.PrivacyRequest, error)
+	SaveAck(ctx context.Context, requestID uint, ack domain.PrivacyAck) error
+	DeleteFailedAcks(ctx context.Context, requestID uint) error
+	UpdateStatus(ctx context.Context, id uint, estado string, completedAt *time.Time) error
+}
+
+// MySQLPrivacyRequestsRepository implementa PrivacyRequestsRepository usando MySQL/GORM
+type MySQLPrivacyRequestsRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLPrivacyRequestsRepository crea una nueva instancia del repository
+// Comparte la conexión DB con UsersRepository
+func NewMySQLPrivacyRequestsRepository(db *gorm.DB) *MySQLPrivacyRequestsRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.PrivacyRequest{}, &dao.PrivacyRequestAck{}); err != nil {
+		log.Fatalf("Error auto-migrating PrivacyRequest tables: %v", err)
+		return nil
+	}
+
+	return &MySQLPrivacyRequestsRepository{
+		db: db,
+	}
+}
+
+// Create guarda un pedido nuevo
+func (r *MySQLPrivacyRequestsRepository) Create(ctx context.Context, request domain.PrivacyRequest) (domain.PrivacyRequest, error) {
+	requestDAO := dao.PrivacyRequestFromDomain(request)
+
+	if err := r.db.WithContext(ctx).Create(&requestDAO).Error; err != nil {
+		return domain.PrivacyRequest{}, fmt.Errorf("error creating privacy request: %w", err)
+	}
+
+	return requestDAO.ToDomain(), nil
+}
+
+// GetByID obtiene un pedido con sus acuses (incluyendo los datos exportados)
+func (r *MySQLPrivacyRequestsRepository) GetByID(ctx context.Context, id uint) (domain.PrivacyRequest, error) {
+	var requestDAO dao.PrivacyRequest
+	if err := r.db.WithContext(ctx).First(&requestDAO, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.PrivacyRequest{}, errors.New("privacy request not found")
+		}
+		return domain.PrivacyRequest{}, fmt.Errorf("error getting privacy request: %w", err)
+	}
+
+	var acksDAO []dao.PrivacyRequestAck
+	if err := r.db.WithContext(ctx).Where("request_id = ?", id).Order("service").Find(&acksDAO).Error; err != nil {
+		return domain.PrivacyRequest{}, fmt.Errorf("error getting privacy request acks: %w", err)
+	}
+
+	request := requestDAO.ToDomain()
+	for _, a := range acksDAO {
+		request.Acks = append(request.Acks, a.ToDomain())
+	}
+	return request, nil
+}
+
+// List devuelve los pedidos más recientes primero, con sus acuses (sin los datos exportados)
+func (r *MySQLPrivacyRequestsRepository) List(ctx context.Context, query domain.ListPrivacyRequestsQuery) ([]domain.PrivacyRequest, error) {
+	db := r.db.WithContext(ctx).Model(&dao.PrivacyRequest{})
+	if query.UsuarioID != nil {
+		db = db.Where("usuario_id = ?", *query.UsuarioID)
+	}
+	if query.Estado != "" {
+		db = db.Where("estado = ?", query.Estado)
+	}
+
+	var requestsDAO []dao.PrivacyRequest
+	if err := db.Order("created_at DESC, id DESC").Limit(query.Limit).Find(&requestsDAO).Error; err != nil {
+		return nil, fmt.Errorf("error listing privacy requests: %w", err)
+	}
+	if len(requestsDAO) == 0 {
+		return []domain.PrivacyRequest{}, nil
+	}
+
+	ids := make([]uint, len(requestsDAO))
+	for i, p := range requestsDAO {
+		ids[i] = p.ID
+	}
+	var acksDAO []dao.PrivacyRequestAck
+	err := r.db.WithContext(ctx).
+		Omit("payload").
+		Where("request_id IN ?", ids).
+		Order("service").
+		Find(&acksDAO).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing privacy request acks: %w", err)
+	}
+	acksByRequest := make(map[uint][]domain.PrivacyAck)
+	for _, a := range acksDAO {
+		acksByRequest[a.RequestID] = append(acksByRequest[a.RequestID], a.ToDomain())
+	}
+
+	requests := make([]domain.PrivacyRequest, 0, len(requestsDAO))
+	for _, p := range requestsDAO {
+		request := p.ToDomain()
+		request.Acks = acksByRequest[p.ID]
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+// SaveAck guarda el acuse de un servicio; si el servicio ya había respondido (reintento), lo reemplaza
+func (r *MySQLPrivacyRequestsRepository) SaveAck(ctx context.Context, requestID uint, ack domain.PrivacyAck) error {
+	ackDAO := dao.PrivacyRequestAckFromDomain(requestID, ack)
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "request_id"}, {Name: "service"}},
+		DoUpdates: clause.AssignmentColumns([]string{"estado", "error", "records", "payload", "received_at"}),
+	}).Create(&ackDAO).Error
+	if err != nil {
+		return fmt.Errorf("error saving privacy request ack: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFailedAcks borra los acuses con error para que el reintento los vuelva a esperar
+func (r *MySQLPrivacyRequestsRepository) DeleteFailedAcks(ctx context.Context, requestID uint) error {
+	err := r.db.WithContext(ctx).
+		Where("request_id = ? AND estado = ?", requestID, domain.PrivacyStatusFailed).
+		Delete(&dao.PrivacyRequestAck{}).Error
+	if err != nil {
+		return fmt.Errorf("error deleting failed privacy request acks: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus actualiza el estado de un pedido
+func (r *MySQLPrivacyRequestsRepository) UpdateStatus(ctx context.Context, id uint, estado string, completedAt *time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&dao.PrivacyRequest{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"estado":       estado,
+			"completed_at": completedAt,
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("error updating privacy request: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("privacy request not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// RolesRepository define la interfaz del repositorio de roles y asignaciones
+type RolesRepository interface {
+	EnsureRoles(ctx context.Context, roles []domain.Role) error
+	List(ctx context.Context) ([]domain.Role, error)
+	GetByID(ctx context.Context, id uint) (domain.Role, error)
+	GetByName(ctx context.Context, nombre string) (domain.Role, error)
+	Create(ctx context.Context, role domain.Role) (domain.Role, error)
+	Update(ctx context.Context, id uint, role domain.Role) (domain.Role, error)
+	Delete(ctx context.Context, id uint) error
+	ListUserRoles(ctx context.Context, usuarioID uint) ([]domain.UserRole, error)
+	ListRoleUsers(ctx context.Context, roleID uint) ([]uint, error)
+	AssignRole(ctx context.Context, assignment domain.UserRole) (domain.UserRole, error)
+	RemoveUserRole(ctx context.Context, usuarioID, assignmentID uint) error
+}
+
+// errRoleAssignmentNotFound se devuelve desde dentro de la transacción de RemoveUserRole
+var errRoleAssignmentNotFound = errors.New("role assignment not found")
+
+// MySQLRolesRepository implementa RolesRepository usando MySQL/GORM
+type MySQLRolesRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLRolesRepository crea una nueva instancia del repository
+// Comparte la conexión DB con UsersRepository
+func NewMySQLRolesRepository(db *gorm.DB) *MySQLRolesRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.Role{}, &dao.RolePermission{}, &dao.UserRole{}); err != nil {
+		log.Fatalf("Error auto-migrating Role tables: %v", err)
+		return nil
+	}
+
+	return &MySQLRolesRepository{
+		db: db,
+	}
+}
+
+// EnsureRoles crea los roles que no existan (no modifica los existentes: sus permisos pueden haberse editado)
+func (r *MySQLRolesRepository) EnsureRoles(ctx context.Context, roles []domain.Role) error {
+	for _, role := range roles {
+		var count int64
+		if err := r.db.WithContext(ctx).Model(&dao.Role{}).Where("nombre = ?", role.Nombre).Count(&count).Error; err != nil {
+			return fmt.Errorf("error checking role %s: %w", role.Nombre, err)
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := r.Create(ctx, role); err != nil {
+			return err
+		}
+		log.Printf("🛡️  Created default role %s", role.Nombre)
+	}
+
+	return nil
+}
+
+// List obtiene todos los roles con sus permisos
+func (r *MySQLRolesRepository) List(ctx context.Context) ([]domain.Role, error) {
+	var rolesDAO []dao.Role
+
+	if err := r.db.WithContext(ctx).Preload("Permisos").Order("id_rol").Find(&rolesDAO).Error; err != nil {
+		return nil, fmt.Errorf("error listing roles: %w", err)
+	}
+
+	roles := make([]domain.Role, len(rolesDAO))
+	for i, roleDAO := range rolesDAO {
+		roles[i] = roleDAO.ToDomain()
+	}
+
+	return roles, nil
+}
+
+// GetByID busca un rol por su ID
+func (r *MySQLRolesRepository) GetByID(ctx context.Context, id uint) (domain.Role, error) {
+	var roleDAO dao.Role
+
+	err := r.db.WithContext(ctx).Preload("Permisos").First(&roleDAO, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Role{}, errors.New("role not found")
+		}
+		return domain.Role{}, fmt.Errorf("error getting role: %w", err)
+	}
+
+	return roleDAO.ToDomain(), nil
+}
+
+// GetByName busca un rol por su nombre
+func (r *MySQLRolesRepository) GetByName(ctx context.Context, nombre string) (domain.Role, error) {
+	var roleDAO dao.Role
+
+	err := r.db.WithContext(ctx).Preload("Permisos").Where("nombre = ?", nombre).First(&roleDAO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Role{}, errors.New("role not found")
+		}
+		return domain.Role{}, fmt.Errorf("error getting role: %w", err)
+	}
+
+	return roleDAO.ToDomain(), nil
+}
+
+// Create inserta un rol con sus permisos
+func (r *MySQLRolesRepository) Create(ctx context.Context, role domain.Role) (domain.Role, error) {
+	roleDAO := dao.RoleFromDomain(role)
+
+	if err := r.db.WithContext(ctx).Create(&roleDAO).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || contains(err.Error(), "Duplicate entry") {
+			return domain.Role{}, errors.New("role already exists")
+		}
+		return domain.Role{}, fmt.Errorf("error creating role: %w", err)
+	}
+
+	return roleDAO.ToDomain(), nil
+}
+
+// Update actualiza la descripción y reemplaza los permisos del rol (en una transacción)
+func (r *MySQLRolesRepository) Update(ctx context.Context, id uint, role domain.Role) (domain.Role, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&dao.Role{}).Where("id_rol = ?", id).Updates(map[string]interface{}{
+			"descripcion": role.Descripcion,
+			"updated_at":  time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("role not found")
+		}
+
+		if err := tx.Where("rol_id = ?", id).Delete(&dao.RolePermission{}).Error; err != nil {
+			return err
+		}
+		for _, p := range role.Permissions {
+			if err := tx.Create(&dao.RolePermission{RoleID: id, Permiso: p}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if err.Error() == "role not found" {
+			return domain.Role{}, err
+		}
+		return domain.Role{}, fmt.Errorf("error updating role: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Delete elimina un rol (las asignaciones y permisos se borran en cascada)
+func (r *MySQLRolesRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&dao.Role{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("error deleting role: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("role not found")
+	}
+
+	return nil
+}
+
+// ListUserRoles obtiene las asignaciones de roles de un usuario (con los permisos de cada rol)
+func (r *MySQLRolesRepository) ListUserRoles(ctx context.Context, usuarioID uint) ([]domain.UserRole, error) {
+	var assignmentsDAO []dao.UserRole
+
+	err := r.db.WithContext(ctx).
+		Preload("Role.Permisos").
+		Where("usuario_id = ?", usuarioID).
+		Order("id").
+		Find(&assignmentsDAO).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing user roles: %w", err)
+	}
+
+	assignments := make([]domain.UserRole, len(assignmentsDAO))
+	for i, a := range assignmentsDAO {
+		assignments[i] = a.ToDomain()
+	}
+
+	return assignments, nil
+}
+
+// ListRoleUsers obtiene los IDs de los usuarios que tienen asignado el rol
+func (r *MySQLRolesRepository) ListRoleUsers(ctx context.Context, roleID uint) ([]uint, error) {
+	var ids []uint
+
+	err := r.db.WithContext(ctx).
+		Model(&dao.UserRole{}).
+		Where("rol_id = ?", roleID).
+		Distinct().
+		Pluck("usuario_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing role users: %w", err)
+	}
+
+	return ids, nil
+}
+
+// AssignRole asigna un rol a un usuario (una asignación por rol y sucursal)
+func (r *MySQLRolesRepository) AssignRole(ctx context.Context, assignment domain.UserRole) (domain.UserRole, error) {
+	// El índice único no alcanza: MySQL permite varios NULL en sucursal_id
+	query := r.db.WithContext(ctx).Model(&dao.UserRole{}).
+		Where("usuario_id = ? AND rol_id = ?", assignment.UsuarioID, assignment.RoleID)
+	if assignment.SucursalID != nil {
+		query = query.Where("sucursal_id = ?", *assignment.SucursalID)
+	} else {
+		query = query.Where("sucursal_id IS NULL")
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return domain.UserRole{}, fmt.Errorf("error checking user role: %w", err)
+	}
+	if count > 0 {
+		return domain.UserRole{}, errors.New("role already assigned")
+	}
+
+	assignmentDAO := dao.UserRole{
+		UsuarioID:  assignment.UsuarioID,
+		RoleID:     assignment.RoleID,
+		SucursalID: assignment.SucursalID,
+	}
+	// La asignación y el evento user.updated se guardan juntos (transactional outbox)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&assignmentDAO).Error; err != nil {
+			return err
+		}
+		return enqueueUserEvent(tx, domain.UserEventUpdated, assignment.UsuarioID)
+	})
+	if err != nil {
+		return domain.UserRole{}, fmt.Errorf("error assigning role: %w", err)
+	}
+
+	if err := r.db.WithContext(ctx).Preload("Role.Permisos").First(&assignmentDAO, assignmentDAO.ID).Error; err != nil {
+		return domain.UserRole{}, fmt.Errorf("error getting user role: %w", err)
+	}
+
+	return assignmentDAO.ToDomain(), nil
+}
+
+// RemoveUserRole elimina una asignación de rol del usuario
+func (r *MySQLRolesRepository) RemoveUserRole(ctx context.Context, usuarioID, assignmentID uint) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Where("id = ? AND usuario_id = ?", assignmentID, usuarioID).
+			Delete(&dao.UserRole{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRoleAssignmentNotFound
+		}
+		return enqueueUserEvent(tx, domain.UserEventUpdated, usuarioID)
+	})
+	if err != nil {
+		if errors.Is(err, errRoleAssignmentNotFound) {
+			return err
+		}
+		return fmt.Errorf("error removing user role: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// ErrRefreshTokenAlreadyUsed indica que el refresh token ya fue rotado (posible robo)
+var ErrRefreshTokenAlreadyUsed = errors.New("refresh token already used")
+
+// SessionsRepository define la interfaz del repositorio de sesiones (refresh tokens)
+type SessionsRepository interface {
+	Create(ctx context.Context, token domain.RefreshToken) (domain.RefreshToken, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (domain.RefreshToken, error)
+	Rotate(ctx context.Context, oldID uint, newToken domain.RefreshToken) (domain.RefreshToken, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, usuarioID uint) error
+	IsFamilyRevoked(ctx context.Context, familyID string) (bool, error)
+}
+
+// MySQLSessionsRepository implementa SessionsRepository usando MySQL/GORM
+type MySQLSessionsRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLSessionsRepository crea una nueva instancia del repository
+// Comparte la conexión DB con UsersRepository
+func NewMySQLSessionsRepository(db *gorm.DB) *MySQLSessionsRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.RefreshToken{}); err != nil {
+		log.Fatalf("Error auto-migrating RefreshToken table: %v", err)
+		return nil
+	}
+
+	return &MySQLSessionsRepository{
+		db: db,
+	}
+}
+
+// Create guarda un nuevo refresh token
+func (r *MySQLSessionsRepository) Create(ctx context.Context, token domain.RefreshToken) (domain.RefreshToken, error) {
+	tokenDAO := dao.RefreshTokenFromDomain(token)
+	tokenDAO.CreatedAt = time.Now()
+
+	if err := r.db.WithContext(ctx).Create(&tokenDAO).Error; err != nil {
+		return domain.RefreshToken{}, fmt.Errorf("error creating refresh token: %w", err)
+	}
+
+	return tokenDAO.ToDomain(), nil
+}
+
+// GetByTokenHash busca un refresh token por el hash de su valor
+func (r *MySQLSessionsRepository) GetByTokenHash(ctx context.Context, tokenHash string) (domain.RefreshToken, error) {
+	var tokenDAO dao.RefreshToken
+
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&tokenDAO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.RefreshToken{}, errors.New("refresh token not found")
+		}
+		return domain.RefreshToken{}, fmt.Errorf("error getting refresh token: %w", err)
+	}
+
+	return tokenDAO.ToDomain(), nil
+}
+
+// Rotate marca el token viejo como usado y crea su reemplazo en una transacción
+// El UPDATE condicional garantiza que dos requests concurrentes no puedan rotar el mismo token
+func (r *MySQLSessionsRepository) Rotate(ctx context.Context, oldID uint, newToken domain.RefreshToken) (domain.RefreshToken, error) {
+	newDAO := dao.RefreshTokenFromDomain(newToken)
+	newDAO.CreatedAt = time.Now()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&dao.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", oldID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenAlreadyUsed
+		}
+
+		if err := tx.Create(&newDAO).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&dao.RefreshToken{}).
+			Where("id = ?", oldID).
+			Update("replaced_by_id", newDAO.ID).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenAlreadyUsed) {
+			return domain.RefreshToken{}, err
+		}
+		return domain.RefreshToken{}, fmt.Errorf("error rotating refresh token: %w", err)
+	}
+
+	return newDAO.ToDomain(), nil
+}
+
+// RevokeFamily revoca todos los tokens de una sesión
+func (r *MySQLSessionsRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	err := r.db.WithContext(ctx).
+		Model(&dao.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAllForUser revoca todas las sesiones de un usuario
+func (r *MySQLSessionsRepository) RevokeAllForUser(ctx context.Context, usuarioID uint) error {
+	err := r.db.WithContext(ctx).
+		Model(&dao.RefreshToken{}).
+		Where("usuario_id = ? AND revoked_at IS NULL", usuarioID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("error revoking user sessions: %w", err)
+	}
+
+	return nil
+}
+
+// IsFamilyRevoked indica si la sesión fue revocada
+func (r *MySQLSessionsRepository) IsFamilyRevoked(ctx context.Context, familyID string) (bool, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Model(&dao.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NOT NULL", familyID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("error checking session: %w", err)
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TwoFactorRepository define la interfaz del repositorio de 2FA (secretos TOTP y códigos de recuperación)
+type TwoFactorRepository interface {
+	Get(ctx context.Context, usuarioID uint) (domain.TwoFactor, error)
+	SavePending(ctx context.Context, usuarioID uint, secret string) error
+	Enable(ctx context.Context, usuarioID uint, step int64, recoveryCodeHashes []string) error
+	UseStep(ctx context.Context, usuarioID uint, step int64) (bool, error)
+	Delete(ctx context.Context, usuarioID uint) error
+	ReplaceRecoveryCodes(ctx context.Context, usuarioID uint, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, usuarioID uint, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, usuarioID uint) (int64, error)
+}
+
+// MySQLTwoFactorRepository implementa TwoFactorRepository usando MySQL/GORM
+type MySQLTwoFactorRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLTwoFactorRepository crea una nueva instancia del repository
+// Comparte la conexión DB con UsersRepository
+func NewMySQLTwoFactorRepository(db *gorm.DB) *MySQLTwoFactorRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.TwoFactor{}, &dao.RecoveryCode{}); err != nil {
+		log.Fatalf("Error auto-migrating TwoFactor tables: %v", err)
+		return nil
+	}
+
+	return &MySQLTwoFactorRepository{
+		db: db,
+	}
+}
+
+// Get obtiene la configuración 2FA de un usuario
+func (r *MySQLTwoFactorRepository) Get(ctx context.Context, usuarioID uint) (domain.TwoFactor, error) {
+	var twoFactorDAO dao.TwoFactor
+	if err := r.db.WithContext(ctx).First(&twoFactorDAO, "usuario_id = ?", usuarioID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.TwoFactor{}, errors.New("two factor not configured")
+		}
+		return domain.TwoFactor{}, fmt.Errorf("error getting two factor: %w", err)
+	}
+
+	return twoFactorDAO.ToDomain(), nil
+}
+
+// SavePending guarda (o reemplaza) un secreto sin confirmar
+func (r *MySQLTwoFactorRepository) SavePending(ctx context.Context, usuarioID uint, secret string) error {
+	twoFactorDAO := dao.TwoFactor{UsuarioID: usuarioID, Secret: secret}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "usuario_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"secret":         secret,
+			"enabled_at":     nil,
+			"last_used_step": 0,
+			"updated_at":     time.Now(),
+		}),
+	}).Create(&twoFactorDAO).Error
+	if err != nil {
+		return fmt.Errorf("error saving two factor secret: %w", err)
+	}
+
+	return nil
+}
+
+// Enable confirma el enrolamiento y guarda los códigos de recuperación en una transacción
+func (r *MySQLTwoFactorRepository) Enable(ctx context.Context, usuarioID uint, step int64, recoveryCodeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&dao.TwoFactor{}).
+			Where("usuario_id = ? AND enabled_at IS NULL", usuarioID).
+			Updates(map[string]interface{}{
+				"enabled_at":     time.Now(),
+				"last_used_step": step,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("error enabling two factor: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("two factor setup not started")
+		}
+
+		return replaceRecoveryCodes(tx, usuarioID, recoveryCodeHashes)
+	})
+}
+
+// UseStep registra el paso TOTP usado; devuelve false si ya se usó ese paso o uno posterior
+// (UPDATE condicional: un código no puede usarse dos veces aunque lleguen requests en paralelo)
+func (r *MySQLTwoFactorRepository) UseStep(ctx context.Context, usuarioID uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&dao.TwoFactor{}).
+		Where("usuario_id = ? AND last_used_step < ?", usuarioID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, fmt.Errorf("error updating totp step: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Delete elimina la configuración 2FA y los códigos de recuperación
+func (r *MySQLTwoFactorRepository) Delete(ctx context.Context, usuarioID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("usuario_id = ?", usuarioID).Delete(&dao.RecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("error deleting recovery codes: %w", err)
+		}
+		if err := tx.Where("usuario_id = ?", usuarioID).Delete(&dao.TwoFactor{}).Error; err != nil {
+			return fmt.Errorf("error deleting two factor: %w", err)
+		}
+		return nil
+	})
+}
+
+// ReplaceRecoveryCodes invalida los códigos anteriores y guarda los nuevos
+func (r *MySQLTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, usuarioID uint, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, usuarioID, codeHashes)
+	})
+}
+
+// UseRecoveryCode marca un código de recuperación como usado (un solo uso)
+func (r *MySQLTwoFactorRepository) UseRecoveryCode(ctx context.Context, usuarioID uint, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&dao.RecoveryCode{}).
+		Where("usuario_id = ? AND code_hash = ? AND used_at IS NULL", usuarioID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("error using recovery code: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// CountRecoveryCodes cuenta los códigos de recuperación sin usar
+func (r *MySQLTwoFactorRepository) CountRecoveryCodes(ctx context.Context, usuarioID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&dao.RecoveryCode{}).
+		Where("usuario_id = ? AND used_at IS NULL", usuarioID).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("error counting recovery codes: %w", err)
+	}
+
+	return count, nil
+}
+
+// replaceRecoveryCodes borra los códigos del usuario e inserta los nuevos dentro de tx
+func replaceRecoveryCodes(tx *gorm.DB, usuarioID uint, codeHashes []string) error {
+	if err := tx.Where("usuario_id = ?", usuarioID).Delete(&dao.RecoveryCode{}).Error; err != nil {
+		return fmt.Errorf("error deleting recovery codes: %w", err)
+	}
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	codes := make([]dao.RecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, dao.RecoveryCode{UsuarioID: usuarioID, CodeHash: hash})
+	}
+	if err := tx.Create(&codes).Error; err != nil {
+		return fmt.Errorf("error creating recovery codes: %w", err)
+	}
+
+	return nil
+}
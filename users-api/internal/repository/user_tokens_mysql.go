@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// UserTokensRepository define la interfaz del repositorio de tokens de un solo uso
+type UserTokensRepository interface {
+	Create(ctx context.Context, token domain.UserToken) (domain.UserToken, error)
+	Consume(ctx context.Context, purpose, tokenHash string) (domain.UserToken, error)
+	InvalidatePending(ctx context.Context, usuarioID uint, purpose string) error
+}
+
+// MySQLUserTokensRepository implementa UserTokensRepository usando MySQL/GORM
+type MySQLUserTokensRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLUserTokensRepository crea una nueva instancia del repository
+// Comparte la conexión DB con UsersRepository
+func NewMySQLUserTokensRepository(db *gorm.DB) *MySQLUserTokensRepository {
+	// Auto-migration
+	if err := db.AutoMigrate(&dao.UserToken{}); err != nil {
+		log.Fatalf("Error auto-migrating UserToken table: %v", err)
+		return nil
+	}
+
+	return &MySQLUserTokensRepository{
+		db: db,
+	}
+}
+
+// Create guarda un nuevo token
+func (r *MySQLUserTokensRepository) Create(ctx context.Context, token domain.UserToken) (domain.UserToken, error) {
+	tokenDAO := dao.UserTokenFromDomain(token)
+	tokenDAO.CreatedAt = time.Now()
+
+	if err := r.db.WithContext(ctx).Create(&tokenDAO).Error; err != nil {
+		return domain.UserToken{}, fmt.Errorf("error creating token: %w", err)
+	}
+
+	return tokenDAO.ToDomain(), nil
+}
+
+// Consume marca el token como usado y lo devuelve
+// El UPDATE condicional garantiza un único uso aunque lleguen requests en paralelo
+func (r *MySQLUserTokensRepository) Consume(ctx context.Context, purpose, tokenHash string) (domain.UserToken, error) {
+	var tokenDAO dao.UserToken
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&dao.UserToken{}).
+			Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("invalid or expired token")
+		}
+
+		return tx.Where("token_hash = ?", tokenHash).First(&tokenDAO).Error
+	})
+	if err != nil {
+		if err.Error() == "invalid or expired token" {
+			return domain.UserToken{}, err
+		}
+		return domain.UserToken{}, fmt.Errorf("error consuming token: %w", err)
+	}
+
+	return tokenDAO.ToDomain(), nil
+}
+
+// InvalidatePending invalida los tokens sin usar de un usuario (al emitir uno nuevo solo vale el último)
+func (r *MySQLUserTokensRepository) InvalidatePending(ctx context.Context, usuarioID uint, purpose string) error {
+	err := r.db.WithContext(ctx).
+		Model(&dao.UserToken{}).
+		Where("usuario_id = ? AND purpose = ? AND used_at IS NULL", usuarioID, purpose).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("error invalidating tokens: %w", err)
+	}
+
+	return nil
+}
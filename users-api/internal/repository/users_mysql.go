@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 	"users-api/internal/config"
 	"users-api/internal/dao"
@@ -20,19 +21,49 @@ import (
 type UsersRepository interface {
 	Create(ctx context.Context, user domain.User) (domain.User, error)
 	GetByID(ctx context.Context, id uint) (domain.User, error)
+	GetByIDWithDeleted(ctx context.Context, id uint) (domain.User, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]domain.User, error)
 	GetByUsername(ctx context.Context, username string) (domain.User, error)
 	GetByEmail(ctx context.Context, email string) (domain.User, error)
 	GetByUsernameOrEmail(ctx context.Context, usernameOrEmail string) (domain.User, error)
-	List(ctx context.Context) ([]domain.User, error)
+	List(ctx context.Context, query domain.ListUsersQuery) ([]domain.User, int64, error)
 	Update(ctx context.Context, id uint, user domain.User) (domain.User, error)
+	UpdatePassword(ctx context.Context, id uint, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id uint) error
 	Delete(ctx context.Context, id uint) error
+	Restore(ctx context.Context, id uint) (domain.User, error)
+	Anonymize(ctx context.Context, id uint) error
 }
 
+// Errores que se devuelven desde dentro de una transacción (se comparan con errors.Is)
+var (
+	errUserNotFound        = errors.New("user not found")
+	errDeletedUserNotFound = errors.New("deleted user not found")
+)
+
+// userSortColumns mapea los valores de sort_by a columnas de la tabla
+var userSortColumns = map[string]string{
+	"id":             "id_usuario",
+	"nombre":         "nombre",
+	"apellido":       "apellido",
+	"username":       "username",
+	"email":          "email",
+	"fecha_registro": "fecha_registro",
+}
+
+// likeEscaper escapa los comodines de LIKE en el texto buscado
+var likeEscaper = strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+
 // MySQLUsersRepository implementa UsersRepository usando MySQL/GORM
 type MySQLUsersRepository struct {
 	db *gorm.DB
 }
 
+// GetDB retorna la conexión de base de datos para compartir con otros repositorios
+func (r *MySQLUsersRepository) GetDB() *gorm.DB {
+	return r.db
+}
+
 // NewMySQLUsersRepository crea una nueva instancia del repository
 func NewMySQLUsersRepository(cfg config.MySQLConfig) *MySQLUsersRepository {
 	// Construir DSN (Data Source Name)
@@ -68,6 +99,17 @@ func NewMySQLUsersRepository(cfg config.MySQLConfig) *MySQLUsersRepository {
 		log.Fatalf("Error auto-migrating User table: %v", err)
 		return nil
 	}*/
+
+	// Ampliar la columna password: los hashes argon2id/bcrypt no entran en char(64)
+	if err := migratePasswordColumn(db); err != nil {
+		log.Fatalf("Error migrating password column: %v", err)
+		return nil
+	}
+	// Columnas nuevas que el esquema legacy no tiene (soft delete, verificación de email)
+	if err := migrateMissingColumns(db); err != nil {
+		log.Fatalf("Error migrating usuarios columns: %v", err)
+		return nil
+	}
 	log.Println("✅ Connected to MySQL successfully")
 
 	return &MySQLUsersRepository{
@@ -82,8 +124,14 @@ func (r *MySQLUsersRepository) Create(ctx context.Context, user domain.User) (do
 	userDAO.CreatedAt = time.Now()
 	userDAO.UpdatedAt = time.Now()
 
-	// Insertar en DB
-	if err := r.db.WithContext(ctx).Create(&userDAO).Error; err != nil {
+	// Insertar en DB junto con el evento user.created (transactional outbox)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&userDAO).Error; err != nil {
+			return err
+		}
+		return enqueueUserEvent(tx, domain.UserEventCreated, userDAO.ID)
+	})
+	if err != nil {
 		// Manejar errores de duplicados (username o email ya existe)
 		if errors.Is(err, gorm.ErrDuplicatedKey) ||
 			(err.Error() != "" && (contains(err.Error(), "username") ||
@@ -112,6 +160,36 @@ func (r *MySQLUsersRepository) GetByID(ctx context.Context, id uint) (domain.Use
 	return userDAO.ToDomain(), nil
 }
 
+// GetByIDWithDeleted busca un usuario por ID incluyendo los dados de baja (soft delete)
+func (r *MySQLUsersRepository) GetByIDWithDeleted(ctx context.Context, id uint) (domain.User, error) {
+	var userDAO dao.User
+
+	err := r.db.WithContext(ctx).Unscoped().First(&userDAO, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.User{}, errors.New("user not found")
+		}
+		return domain.User{}, fmt.Errorf("error getting user by ID: %w", err)
+	}
+
+	return userDAO.ToDomain(), nil
+}
+
+// GetByIDs busca varios usuarios por ID (los inexistentes se omiten)
+func (r *MySQLUsersRepository) GetByIDs(ctx context.Context, ids []uint) ([]domain.User, error) {
+	var userDAOs []dao.User
+
+	if err := r.db.WithContext(ctx).Where("id_usuario IN ?", ids).Find(&userDAOs).Error; err != nil {
+		return nil, fmt.Errorf("error getting users by IDs: %w", err)
+	}
+
+	users := make([]domain.User, 0, len(userDAOs))
+	for _, userDAO := range userDAOs {
+		users = append(users, userDAO.ToDomain())
+	}
+	return users, nil
+}
+
 // GetByUsername busca un usuario por su username
 func (r *MySQLUsersRepository) GetByUsername(ctx context.Context, username string) (domain.User, error) {
 	var userDAO dao.User
@@ -160,12 +238,55 @@ func (r *MySQLUsersRepository) GetByUsernameOrEmail(ctx context.Context, usernam
 	return userDAO.ToDomain(), nil
 }
 
-// List obtiene todos los usuarios
-func (r *MySQLUsersRepository) List(ctx context.Context) ([]domain.User, error) {
-	var usersDAO []dao.User
+// List obtiene una página de usuarios filtrada y ordenada, junto al total de coincidencias
+// Page y PageSize deben venir normalizados por el service
+func (r *MySQLUsersRepository) List(ctx context.Context, query domain.ListUsersQuery) ([]domain.User, int64, error) {
+	db := r.db.WithContext(ctx).Model(&dao.User{})
+
+	if query.IsAdmin != nil {
+		db = db.Where("is_admin = ?", *query.IsAdmin)
+	}
+	if query.SucursalOrigenID != nil {
+		db = db.Where("sucursal_origen_id = ?", *query.SucursalOrigenID)
+	}
+	if !query.RegisteredFrom.IsZero() {
+		db = db.Where("fecha_registro >= ?", query.RegisteredFrom)
+	}
+	if !query.RegisteredTo.IsZero() {
+		// Fecha inclusive: hasta el inicio del día siguiente
+		db = db.Where("fecha_registro < ?", query.RegisteredTo.AddDate(0, 0, 1))
+	}
+	if q := strings.TrimSpace(query.Q); q != "" {
+		pattern := "%" + likeEscaper.Replace(q) + "%"
+		db = db.Where("(nombre LIKE ? OR apellido LIKE ? OR username LIKE ? OR email LIKE ?)", pattern, pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("error counting users: %w", err)
+	}
+
+	// Columna de orden (whitelist) + id como desempate para que la paginación sea estable
+	column, ok := userSortColumns[query.SortBy]
+	if !ok {
+		column = "id_usuario"
+	}
+	direction := "ASC"
+	if query.SortDesc {
+		direction = "DESC"
+	}
+	order := column + " " + direction
+	if column != "id_usuario" {
+		order += ", id_usuario " + direction
+	}
 
-	if err := r.db.WithContext(ctx).Find(&usersDAO).Error; err != nil {
-		return nil, fmt.Errorf("error listing users: %w", err)
+	var usersDAO []dao.User
+	err := db.Order(order).
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
+		Find(&usersDAO).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing users: %w", err)
 	}
 
 	// Convertir de DAO a Domain
@@ -174,33 +295,56 @@ func (r *MySQLUsersRepository) List(ctx context.Context) ([]domain.User, error)
 		users[i] = userDAO.ToDomain()
 	}
 
-	return users, nil
+	return users, total, nil
 }
 
-// Update actualiza un usuario existente
+// Update actualiza los datos de perfil de un usuario existente
+// Se listan las columnas explícitamente para poder guardar valores cero (ej: is_admin = false)
+// y para no tocar nunca el password (ver UpdatePassword)
 func (r *MySQLUsersRepository) Update(ctx context.Context, id uint, user domain.User) (domain.User, error) {
 	userDAO := dao.FromDomain(user)
 	userDAO.ID = id
 	userDAO.UpdatedAt = time.Now()
 
-	// Actualizar en DB
-	result := r.db.WithContext(ctx).Model(&userDAO).Updates(userDAO)
-	if result.Error != nil {
-		return domain.User{}, fmt.Errorf("error updating user: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return domain.User{}, errors.New("user not found")
+	// Actualizar en DB junto con el evento user.updated (transactional outbox)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Model(&userDAO).
+			Select("nombre", "apellido", "username", "email", "email_verified_at", "is_admin", "sucursal_origen_id", "updated_at").
+			Updates(&userDAO)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errUserNotFound
+		}
+		return enqueueUserEvent(tx, domain.UserEventUpdated, id)
+	})
+	if err != nil {
+		if errors.Is(err, errUserNotFound) {
+			return domain.User{}, err
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) || contains(err.Error(), "Duplicate entry") {
+			return domain.User{}, errors.New("username or email already exists")
+		}
+		return domain.User{}, fmt.Errorf("error updating user: %w", err)
 	}
 
 	// Obtener el usuario actualizado
 	return r.GetByID(ctx, id)
 }
 
-// Delete elimina un usuario (soft delete)
-func (r *MySQLUsersRepository) Delete(ctx context.Context, id uint) error {
-	result := r.db.WithContext(ctx).Delete(&dao.User{}, id)
+// UpdatePassword reemplaza únicamente el hash de la contraseña
+func (r *MySQLUsersRepository) UpdatePassword(ctx context.Context, id uint, passwordHash string) error {
+	result := r.db.WithContext(ctx).
+		Model(&dao.User{}).
+		Where("id_usuario = ?", id).
+		Updates(map[string]interface{}{
+			"password":   passwordHash,
+			"updated_at": time.Now(),
+		})
 	if result.Error != nil {
-		return fmt.Errorf("error deleting user: %w", result.Error)
+		return fmt.Errorf("error updating password: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
 		return errors.New("user not found")
@@ -209,6 +353,166 @@ func (r *MySQLUsersRepository) Delete(ctx context.Context, id uint) error {
 	return nil
 }
 
+// MarkEmailVerified registra que el usuario verificó su email
+func (r *MySQLUsersRepository) MarkEmailVerified(ctx context.Context, id uint) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Model(&dao.User{}).
+			Where("id_usuario = ? AND email_verified_at IS NULL", id).
+			Update("email_verified_at", time.Now())
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return enqueueUserEvent(tx, domain.UserEventUpdated, id)
+	})
+	if err != nil {
+		return fmt.Errorf("error verifying email: %w", err)
+	}
+
+	return nil
+}
+
+// Delete elimina un usuario (soft delete)
+func (r *MySQLUsersRepository) Delete(ctx context.Context, id uint) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&dao.User{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errUserNotFound
+		}
+		return enqueueUserEvent(tx, domain.UserEventDeleted, id)
+	})
+	if err != nil {
+		if errors.Is(err, errUserNotFound) {
+			return err
+		}
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+
+	return nil
+}
+
+// Restore revierte el soft delete de un usuario
+func (r *MySQLUsersRepository) Restore(ctx context.Context, id uint) (domain.User, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Unscoped().
+			Model(&dao.User{}).
+			Where("id_usuario = ? AND deleted_at IS NOT NULL", id).
+			Updates(map[string]interface{}{
+				"deleted_at": nil,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errDeletedUserNotFound
+		}
+		// Vuelve a existir: los consumidores lo tratan como un alta
+		return enqueueUserEvent(tx, domain.UserEventCreated, id)
+	})
+	if err != nil {
+		if errors.Is(err, errDeletedUserNotFound) {
+			return domain.User{}, err
+		}
+		return domain.User{}, fmt.Errorf("error restoring user: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Anonymize reemplaza los datos personales del usuario por valores genéricos y lo da de baja
+// La fila se conserva (el ID sigue siendo válido como referencia en otros servicios),
+// pero username y email quedan libres y no se puede volver a iniciar sesión
+func (r *MySQLUsersRepository) Anonymize(ctx context.Context, id uint) error {
+	now := time.Now()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Unscoped().
+			Model(&dao.User{}).
+			Where("id_usuario = ?", id).
+			Updates(map[string]interface{}{
+				"nombre":             "Usuario",
+				"apellido":           "Eliminado",
+				"username":           fmt.Sprintf("deleted-%d", id),
+				"email":              fmt.Sprintf("deleted-%d@anonymized.invalid", id),
+				"password":           "",
+				"is_admin":           false,
+				"sucursal_origen_id": nil,
+				"email_verified_at":  nil,
+				"updated_at":         now,
+				"deleted_at":         gorm.Expr("COALESCE(deleted_at, ?)", now),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errUserNotFound
+		}
+		// El evento lleva los datos ya anonimizados: los consumidores pueden reemplazar los que tengan
+		return enqueueUserEvent(tx, domain.UserEventDeleted, id)
+	})
+	if err != nil {
+		if errors.Is(err, errUserNotFound) {
+			return err
+		}
+		return fmt.Errorf("error anonymizing user: %w", err)
+	}
+
+	return nil
+}
+
+// migratePasswordColumn amplía usuarios.password si todavía es char(64) (esquema legacy SHA-256)
+func migratePasswordColumn(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&dao.User{}) {
+		return nil
+	}
+
+	columnTypes, err := db.Migrator().ColumnTypes(&dao.User{})
+	if err != nil {
+		return err
+	}
+
+	for _, column := range columnTypes {
+		if column.Name() != "password" {
+			continue
+		}
+		if length, ok := column.Length(); ok && length >= 255 {
+			return nil
+		}
+		return db.Migrator().AlterColumn(&dao.User{}, "Password")
+	}
+
+	return nil
+}
+
+// migrateMissingColumns agrega a usuarios las columnas que no existan en una tabla creada con el esquema legacy
+func migrateMissingColumns(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&dao.User{}) {
+		return nil
+	}
+
+	if !db.Migrator().HasColumn(&dao.User{}, "DeletedAt") {
+		if err := db.Migrator().AddColumn(&dao.User{}, "DeletedAt"); err != nil {
+			return err
+		}
+		if err := db.Migrator().CreateIndex(&dao.User{}, "DeletedAt"); err != nil {
+			return err
+		}
+	}
+
+	if !db.Migrator().HasColumn(&dao.User{}, "EmailVerifiedAt") {
+		if err := db.Migrator().AddColumn(&dao.User{}, "EmailVerifiedAt"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || containsHelper(s, substr)))
@@ -0,0 +1,212 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Algoritmos de firma JWT soportados
+const (
+	SigningAlgRS256 = "RS256"
+	SigningAlgEdDSA = "EdDSA"
+)
+
+// SigningKey representa una clave privada de firma identificada por kid
+type SigningKey struct {
+	KID       string
+	Algorithm string
+	Private   crypto.Signer
+}
+
+// Public devuelve la clave pública correspondiente
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// method devuelve el método de firma jwt correspondiente al algoritmo
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == SigningAlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// KeySet contiene todas las claves vigentes: la activa firma, todas verifican y se publican en el JWKS
+// Rotación: agregar una nueva clave, marcarla activa y retirar la anterior cuando expiren sus tokens
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// LoadKeySet carga las claves privadas PEM de dir (una por archivo, kid = nombre sin extensión)
+// Si activeKID está vacío se usa el último kid en orden alfabético (ej: nombres con fecha)
+// Si dir está vacío genera una clave efímera con defaultAlg (solo para desarrollo)
+func LoadKeySet(dir, activeKID, defaultAlg string) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*SigningKey)}
+
+	if dir == "" {
+		ephemeral, err := NewEphemeralKeySet(defaultAlg)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("⚠️  JWT_KEYS_DIR not set: using ephemeral %s key %s (tokens won't survive restarts)", ephemeral.active.Algorithm, ephemeral.active.KID)
+		return ephemeral, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing key files: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no *.pem keys found in %s", dir)
+	}
+	sort.Strings(files)
+
+	var kids []string
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		key, err := loadSigningKey(file, kid)
+		if err != nil {
+			return nil, err
+		}
+		ks.keys[kid] = key
+		kids = append(kids, kid)
+	}
+
+	if activeKID == "" {
+		activeKID = kids[len(kids)-1]
+	}
+	active, ok := ks.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("active key %q not found in %s", activeKID, dir)
+	}
+	ks.active = active
+
+	log.Printf("🔑 Loaded %d JWT signing key(s), active kid: %s (%s)", len(kids), active.KID, active.Algorithm)
+	return ks, nil
+}
+
+// NewEphemeralKeySet genera un KeySet con una única clave en memoria (desarrollo y proveedor OIDC mock)
+func NewEphemeralKeySet(alg string) (*KeySet, error) {
+	key, err := generateSigningKey(alg)
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{
+		active: key,
+		keys:   map[string]*SigningKey{key.KID: key},
+	}, nil
+}
+
+// Sign firma los claims con la clave activa e incluye el kid en el header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.method(), claims)
+	token.Header["kid"] = ks.active.KID
+	return token.SignedString(ks.active.Private)
+}
+
+// Keyfunc resuelve la clave pública de verificación según el kid del token
+func (ks *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %q", kid)
+	}
+	if t.Method.Alg() != key.method().Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+	}
+	return key.Public(), nil
+}
+
+// JWKS devuelve las claves públicas en formato JSON Web Key Set
+func (ks *KeySet) JWKS() JWKS {
+	kids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := JWKS{Keys: make([]JWK, 0, len(kids))}
+	for _, kid := range kids {
+		set.Keys = append(set.Keys, publicJWK(ks.keys[kid]))
+	}
+	return set
+}
+
+// loadSigningKey lee una clave privada PEM (PKCS#8 o PKCS#1 para RSA)
+func loadSigningKey(file, kid string) (*SigningKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading key %s: %w", file, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM in %s", file)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q in %s", block.Type, file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing key %s: %w", file, err)
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA key %s must be at least 2048 bits", file)
+		}
+		return &SigningKey{KID: kid, Algorithm: SigningAlgRS256, Private: key}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{KID: kid, Algorithm: SigningAlgEdDSA, Private: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type in %s (use RSA or Ed25519)", file)
+	}
+}
+
+// generateSigningKey genera una clave efímera para desarrollo
+func generateSigningKey(alg string) (*SigningKey, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	kid := "ephemeral-" + hex.EncodeToString(suffix)
+
+	switch alg {
+	case SigningAlgEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("error generating Ed25519 key: %w", err)
+		}
+		return &SigningKey{KID: kid, Algorithm: SigningAlgEdDSA, Private: private}, nil
+	case "", SigningAlgRS256:
+		private, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, fmt.Errorf("error generating RSA key: %w", err)
+		}
+		return &SigningKey{KID: kid, Algorithm: SigningAlgRS256, Private: private}, nil
+	default:
+		return nil, errors.New("unsupported JWT signing algorithm: " + alg)
+	}
+}
@@ -0,0 +1,108 @@
+package security
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// TestKeySetKeyfunc verifica que solo se aceptan tokens con un kid conocido y firmados con el algoritmo de esa clave
+func TestKeySetKeyfunc(t *testing.T) {
+	rsaSet, err := NewEphemeralKeySet(SigningAlgRS256)
+	if err != nil {
+		t.Fatalf("error generando clave RSA: %v", err)
+	}
+	edSet, err := NewEphemeralKeySet(SigningAlgEdDSA)
+	if err != nil {
+		t.Fatalf("error generando clave Ed25519: %v", err)
+	}
+	// Set con las dos claves (como durante una rotación), la RSA activa
+	ks := &KeySet{
+		active: rsaSet.active,
+		keys: map[string]*SigningKey{
+			rsaSet.active.KID: rsaSet.active,
+			edSet.active.KID:  edSet.active,
+		},
+	}
+	other, err := NewEphemeralKeySet(SigningAlgRS256)
+	if err != nil {
+		t.Fatalf("error generando clave RSA: %v", err)
+	}
+
+	claims := jwt.MapClaims{"sub": "1"}
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("error firmando token: %v", err)
+		}
+		return signed
+	}
+
+	activeToken, err := ks.Sign(claims)
+	if err != nil {
+		t.Fatalf("error firmando con la clave activa: %v", err)
+	}
+	rsaPublicDER, err := x509.MarshalPKIXPublicKey(rsaSet.active.Public())
+	if err != nil {
+		t.Fatalf("error serializando la clave pública: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"clave activa", activeToken, false},
+		{"clave anterior Ed25519", sign(jwt.SigningMethodEdDSA, edSet.active.KID, edSet.active.Private), false},
+		{"kid desconocido", sign(jwt.SigningMethodRS256, other.active.KID, other.active.Private), true},
+		{"sin kid", sign(jwt.SigningMethodRS256, "", rsaSet.active.Private), true},
+		{"kid conocido firmado por otra clave", sign(jwt.SigningMethodRS256, rsaSet.active.KID, other.active.Private), true},
+		{"kid RSA firmado con EdDSA", sign(jwt.SigningMethodEdDSA, rsaSet.active.KID, edSet.active.Private), true},
+		{"kid Ed25519 firmado con RS256", sign(jwt.SigningMethodRS256, edSet.active.KID, rsaSet.active.Private), true},
+		{"HS256 con la clave pública como secreto", sign(jwt.SigningMethodHS256, rsaSet.active.KID, rsaPublicDER), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwt.Parse(tt.token, ks.Keyfunc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestKeySetJWKS verifica que el JWKS publica todas las claves vigentes con su algoritmo
+func TestKeySetJWKS(t *testing.T) {
+	rsaSet, err := NewEphemeralKeySet(SigningAlgRS256)
+	if err != nil {
+		t.Fatalf("error generando clave RSA: %v", err)
+	}
+	edSet, err := NewEphemeralKeySet(SigningAlgEdDSA)
+	if err != nil {
+		t.Fatalf("error generando clave Ed25519: %v", err)
+	}
+	ks := &KeySet{
+		active: rsaSet.active,
+		keys: map[string]*SigningKey{
+			rsaSet.active.KID: rsaSet.active,
+			edSet.active.KID:  edSet.active,
+		},
+	}
+
+	got := map[string]JWK{}
+	for _, jwk := range ks.JWKS().Keys {
+		got[jwk.Kid] = jwk
+	}
+	if jwk := got[rsaSet.active.KID]; jwk.Kty != "RSA" || jwk.Alg != SigningAlgRS256 || jwk.N == "" || jwk.E == "" {
+		t.Fatalf("JWK RSA inválida: %+v", jwk)
+	}
+	if jwk := got[edSet.active.KID]; jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.Alg != SigningAlgEdDSA || jwk.X == "" {
+		t.Fatalf("JWK Ed25519 inválida: %+v", jwk)
+	}
+}
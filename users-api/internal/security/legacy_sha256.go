@@ -0,0 +1,41 @@
+package security
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"regexp"
+)
+
+// legacySHA256Regex reconoce el hex digest de 64 caracteres usado por el proyecto original
+var legacySHA256Regex = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// LegacySHA256Hasher verifica hashes SHA-256 sin salt (proyecto original y BDD/dummy.sql)
+// Solo existe para migrar usuarios existentes: nunca genera hashes nuevos
+type LegacySHA256Hasher struct{}
+
+// Hash no está soportado: los hashes nuevos deben usar argon2id o bcrypt
+func (LegacySHA256Hasher) Hash(password string) (string, error) {
+	return "", errors.New("legacy sha256 hashing is not supported for new passwords")
+}
+
+// Verify compara el digest SHA-256 en tiempo constante
+func (LegacySHA256Hasher) Verify(password, encoded string) (bool, error) {
+	expected, err := hex.DecodeString(encoded)
+	if err != nil {
+		return false, ErrUnknownHashFormat
+	}
+	sum := sha256.Sum256([]byte(password))
+	return subtle.ConstantTimeCompare(sum[:], expected) == 1, nil
+}
+
+// NeedsRehash siempre es true: todo hash legacy debe migrarse
+func (LegacySHA256Hasher) NeedsRehash(encoded string) bool {
+	return true
+}
+
+// Matches indica si el valor codificado es un hex digest SHA-256
+func (LegacySHA256Hasher) Matches(encoded string) bool {
+	return legacySHA256Regex.MatchString(encoded)
+}
@@ -0,0 +1,87 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Algoritmos de hashing soportados
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmSHA256   = "sha256" // Legacy: solo verificación, nunca se usa para hashear
+)
+
+// ErrUnknownHashFormat se devuelve cuando el valor almacenado no corresponde a ningún algoritmo conocido
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// PasswordHasher define la interfaz para hashear y verificar contraseñas
+// Permite cambiar el algoritmo (argon2id, bcrypt) sin afectar el servicio
+type PasswordHasher interface {
+	// Hash devuelve el valor codificado a guardar (incluye algoritmo, parámetros y salt)
+	Hash(password string) (string, error)
+	// Verify compara una contraseña en texto plano con un valor codificado
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash indica si el valor codificado debe regenerarse con el algoritmo/parámetros actuales
+	NeedsRehash(encoded string) bool
+}
+
+// algorithmHasher es implementado por cada algoritmo concreto
+type algorithmHasher interface {
+	PasswordHasher
+	// Matches indica si el valor codificado pertenece a este algoritmo
+	Matches(encoded string) bool
+}
+
+// MultiPasswordHasher hashea con el algoritmo preferido y verifica cualquier formato conocido
+// (incluyendo los hashes SHA-256 legacy del proyecto original)
+type MultiPasswordHasher struct {
+	preferred algorithmHasher
+	verifiers []algorithmHasher
+}
+
+// NewPasswordHasher crea el hasher según el algoritmo configurado
+// bcryptCost solo se usa cuando el algoritmo es bcrypt
+func NewPasswordHasher(algorithm string, bcryptCost int) (*MultiPasswordHasher, error) {
+	argon := NewArgon2idHasher(DefaultArgon2idParams)
+	bcryptHasher := NewBcryptHasher(bcryptCost)
+
+	var preferred algorithmHasher
+	switch strings.ToLower(algorithm) {
+	case "", AlgorithmArgon2id:
+		preferred = argon
+	case AlgorithmBcrypt:
+		preferred = bcryptHasher
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm: %s", algorithm)
+	}
+
+	return &MultiPasswordHasher{
+		preferred: preferred,
+		verifiers: []algorithmHasher{argon, bcryptHasher, LegacySHA256Hasher{}},
+	}, nil
+}
+
+// Hash hashea la contraseña con el algoritmo preferido
+func (h *MultiPasswordHasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify detecta el algoritmo del valor codificado y verifica la contraseña
+func (h *MultiPasswordHasher) Verify(password, encoded string) (bool, error) {
+	for _, v := range h.verifiers {
+		if v.Matches(encoded) {
+			return v.Verify(password, encoded)
+		}
+	}
+	return false, ErrUnknownHashFormat
+}
+
+// NeedsRehash es true si el valor fue generado con otro algoritmo o con parámetros desactualizados
+func (h *MultiPasswordHasher) NeedsRehash(encoded string) bool {
+	if !h.preferred.Matches(encoded) {
+		return true
+	}
+	return h.preferred.NeedsRehash(encoded)
+}
@@ -0,0 +1,95 @@
+package security
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestMultiPasswordHasher verifica que se aceptan los tres formatos y que todo lo que no es el algoritmo
+// y los parámetros actuales se marca para rehashear
+func TestMultiPasswordHasher(t *testing.T) {
+	argonHasher, err := NewPasswordHasher(AlgorithmArgon2id, bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("error creando hasher argon2id: %v", err)
+	}
+	bcryptHasher, err := NewPasswordHasher(AlgorithmBcrypt, bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("error creando hasher bcrypt: %v", err)
+	}
+
+	argonHash, err := argonHasher.Hash("secreto")
+	if err != nil {
+		t.Fatalf("error hasheando con argon2id: %v", err)
+	}
+	weakArgon := DefaultArgon2idParams
+	weakArgon.Memory = 8 * 1024
+	weakArgonHash, err := NewArgon2idHasher(weakArgon).Hash("secreto")
+	if err != nil {
+		t.Fatalf("error hasheando con argon2id débil: %v", err)
+	}
+	bcryptHash, err := bcryptHasher.Hash("secreto")
+	if err != nil {
+		t.Fatalf("error hasheando con bcrypt: %v", err)
+	}
+	bcryptOtherCost, err := bcrypt.GenerateFromPassword([]byte("secreto"), bcrypt.MinCost+1)
+	if err != nil {
+		t.Fatalf("error hasheando con bcrypt: %v", err)
+	}
+	// sha256("password") como lo guardaba el proyecto original (BDD/dummy.sql)
+	const legacyHash = "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8"
+
+	tests := []struct {
+		name        string
+		hasher      *MultiPasswordHasher
+		password    string
+		encoded     string
+		wantOK      bool
+		wantErr     error
+		needsRehash bool
+	}{
+		{"argon2id correcta", argonHasher, "secreto", argonHash, true, nil, false},
+		{"argon2id incorrecta", argonHasher, "otra", argonHash, false, nil, false},
+		{"argon2id con parámetros viejos", argonHasher, "secreto", weakArgonHash, true, nil, true},
+		{"bcrypt con argon2id preferido", argonHasher, "secreto", bcryptHash, true, nil, true},
+		{"bcrypt incorrecta", argonHasher, "otra", bcryptHash, false, nil, true},
+		{"bcrypt preferido", bcryptHasher, "secreto", bcryptHash, true, nil, false},
+		{"bcrypt con otro costo", bcryptHasher, "secreto", string(bcryptOtherCost), true, nil, true},
+		{"argon2id con bcrypt preferido", bcryptHasher, "secreto", argonHash, true, nil, true},
+		{"sha256 legacy", argonHasher, "password", legacyHash, true, nil, true},
+		{"sha256 legacy en mayúsculas", argonHasher, "password", "5E884898DA28047151D0E56F8DC6292773603D0D6AABBDD62A11EF721D1542D8", true, nil, true},
+		{"sha256 legacy incorrecta", argonHasher, "Password", legacyHash, false, nil, true},
+		{"formato desconocido", argonHasher, "secreto", "$1$abc$def", false, ErrUnknownHashFormat, true},
+		{"vacío", argonHasher, "", "", false, ErrUnknownHashFormat, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := tt.hasher.Verify(tt.password, tt.encoded)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("Verify() = %v, se esperaba %v", ok, tt.wantOK)
+			}
+			if got := tt.hasher.NeedsRehash(tt.encoded); got != tt.needsRehash {
+				t.Fatalf("NeedsRehash() = %v, se esperaba %v", got, tt.needsRehash)
+			}
+		})
+	}
+}
+
+// TestNewPasswordHasherUnsupported verifica que un algoritmo desconocido se rechaza al configurar
+func TestNewPasswordHasherUnsupported(t *testing.T) {
+	if _, err := NewPasswordHasher("md5", 0); err == nil {
+		t.Fatal("se esperaba error para un algoritmo no soportado")
+	}
+}
+
+// TestLegacySHA256HashUnsupported verifica que el hasher legacy nunca genera hashes nuevos
+func TestLegacySHA256HashUnsupported(t *testing.T) {
+	if _, err := (LegacySHA256Hasher{}).Hash("password"); err == nil {
+		t.Fatal("el hasher legacy no debería generar hashes")
+	}
+}
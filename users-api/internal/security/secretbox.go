@@ -0,0 +1,88 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealedPrefix marca los valores cifrados (permite convivir con valores en claro previos)
+const sealedPrefix = "v1:"
+
+// SecretBox cifra secretos en reposo (ej: secretos TOTP) con AES-256-GCM
+// Sin clave configurada guarda los valores en claro (solo desarrollo)
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox crea el SecretBox con una clave de 32 bytes codificada en base64
+// Una clave vacía deshabilita el cifrado
+func NewSecretBox(encodedKey string) (*SecretBox, error) {
+	if encodedKey == "" {
+		return &SecretBox{}, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretBox{aead: aead}, nil
+}
+
+// Enabled indica si hay una clave de cifrado configurada
+func (b *SecretBox) Enabled() bool {
+	return b.aead != nil
+}
+
+// Seal cifra el valor (nonce aleatorio + ciphertext en base64)
+func (b *SecretBox) Seal(plain string) (string, error) {
+	if b.aead == nil {
+		return plain, nil
+	}
+
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return sealedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open descifra un valor generado por Seal (los valores sin prefijo se devuelven tal cual)
+func (b *SecretBox) Open(value string) (string, error) {
+	if !strings.HasPrefix(value, sealedPrefix) {
+		return value, nil
+	}
+	if b.aead == nil {
+		return "", errors.New("encrypted value found but no encryption key is configured")
+	}
+
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, sealedPrefix))
+	if err != nil || len(raw) < b.aead.NonceSize() {
+		return "", errors.New("invalid encrypted value")
+	}
+	nonce, ciphertext := raw[:b.aead.NonceSize()], raw[b.aead.NonceSize():]
+	plain, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("could not decrypt value (wrong key?)")
+	}
+
+	return string(plain), nil
+}
@@ -0,0 +1,71 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret es el secreto SHA-1 de los vectores del apéndice B de RFC 6238 ("12345678901234567890" en base32)
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// TestValidateTOTPRFC6238 verifica los vectores de RFC 6238 (últimos 6 dígitos de los códigos de 8)
+func TestValidateTOTPRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfc6238Secret, tt.code, time.Unix(tt.unix, 0), 0)
+			if !ok {
+				t.Fatalf("ValidateTOTP(%d) rechazó el código %s", tt.unix, tt.code)
+			}
+			if want := tt.unix / TOTPPeriod; step != want {
+				t.Fatalf("step = %d, se esperaba %d", step, want)
+			}
+		})
+	}
+}
+
+// TestValidateTOTPSkew verifica la tolerancia de reloj y los códigos mal formados
+func TestValidateTOTPSkew(t *testing.T) {
+	// 287082 es el código del paso 1 (T = 30..59)
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		unix     int64
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{"paso actual", rfc6238Secret, "287082", 45, 0, 1, true},
+		{"paso anterior sin tolerancia", rfc6238Secret, "287082", 75, 0, 0, false},
+		{"paso anterior con tolerancia", rfc6238Secret, "287082", 75, 1, 1, true},
+		{"paso siguiente con tolerancia", rfc6238Secret, "287082", 15, 1, 1, true},
+		{"dos pasos atrás", rfc6238Secret, "287082", 105, 1, 0, false},
+		{"espacios alrededor", rfc6238Secret, " 287082 ", 45, 0, 1, true},
+		{"secreto en minúsculas", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "287082", 45, 0, 1, true},
+		{"código incorrecto", rfc6238Secret, "287083", 45, 1, 0, false},
+		{"código de 8 dígitos", rfc6238Secret, "94287082", 45, 1, 0, false},
+		{"código corto", rfc6238Secret, "28708", 45, 1, 0, false},
+		{"secreto inválido", "no-es-base32!", "287082", 45, 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, time.Unix(tt.unix, 0), tt.skew)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("ValidateTOTP() = (%d, %v), se esperaba (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+	"users-api/internal/domain"
+	"users-api/internal/mail"
+	"users-api/internal/repository"
+	"users-api/internal/security"
+)
+
+// AccountService define la interfaz del servicio de recuperación de contraseña y verificación de email
+type AccountService interface {
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, req domain.ResetPasswordRequest) error
+	SendEmailVerification(ctx context.Context, userID uint) error
+	VerifyEmail(ctx context.Context, token string) error
+}
+
+// AccountServiceImpl implementa AccountService
+type AccountServiceImpl struct {
+	usersRepo            repository.UsersRepository
+	tokensRepo           repository.UserTokensRepository
+	hasher               security.PasswordHasher
+	sessions             SessionsService
+	mailer               mail.Mailer
+	appURL               string
+	passwordResetTTL     time.Duration
+	emailVerificationTTL time.Duration
+}
+
+// NewAccountService crea una nueva instancia del servicio
+// Dependency Injection: recibe los repositories, el hasher, el servicio de sesiones y el Mailer
+func NewAccountService(usersRepo repository.UsersRepository, tokensRepo repository.UserTokensRepository, hasher security.PasswordHasher, sessions SessionsService, mailer mail.Mailer, appURL string, passwordResetTTL, emailVerificationTTL time.Duration) *AccountServiceImpl {
+	return &AccountServiceImpl{
+		usersRepo:            usersRepo,
+		tokensRepo:           tokensRepo,
+		hasher:               hasher,
+		sessions:             sessions,
+		mailer:               mailer,
+		appURL:               appURL,
+		passwordResetTTL:     passwordResetTTL,
+		emailVerificationTTL: emailVerificationTTL,
+	}
+}
+
+// RequestPasswordReset envía un link de reset al email si pertenece a un usuario
+// No informa si el email existe (evita enumeración de cuentas)
+func (s *AccountServiceImpl) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.usersRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if err.Error() == "user not found" {
+			log.Printf("🔎 Password reset requested for unknown email")
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.issueToken(ctx, user.ID, domain.TokenPurposePasswordReset, s.passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.link("/reset-password", token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Restablecer tu contraseña",
+		Body: fmt.Sprintf("Hola %s,\n\nRecibimos un pedido para restablecer tu contraseña. "+
+			"Ingresá al siguiente link para elegir una nueva (vence en %s):\n\n%s\n\n"+
+			"Si no lo pediste, ignorá este email: tu contraseña no cambió.\n",
+			user.Nombre, s.passwordResetTTL, link),
+	})
+}
+
+// ResetPassword consume el token, guarda la nueva contraseña y revoca todas las sesiones
+// Haber recibido el link también prueba que el email es del usuario
+func (s *AccountServiceImpl) ResetPassword(ctx context.Context, req domain.ResetPasswordRequest) error {
+	if err := validatePassword(req.NewPassword); err != nil {
+		return err
+	}
+
+	token, err := s.tokensRepo.Consume(ctx, domain.TokenPurposePasswordReset, hashToken(req.Token))
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+
+	if err := s.usersRepo.UpdatePassword(ctx, token.UsuarioID, hashedPassword); err != nil {
+		return err
+	}
+
+	if err := s.sessions.RevokeAllForUser(ctx, token.UsuarioID); err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	if err := s.usersRepo.MarkEmailVerified(ctx, token.UsuarioID); err != nil {
+		log.Printf("⚠️  Error marking email verified for user %d: %v", token.UsuarioID, err)
+	}
+
+	log.Printf("🔐 Password reset for user %d", token.UsuarioID)
+	return nil
+}
+
+// SendEmailVerification envía el link de verificación al email actual del usuario
+func (s *AccountServiceImpl) SendEmailVerification(ctx context.Context, userID uint) error {
+	user, err := s.usersRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return errors.New("email already verified")
+	}
+
+	token, err := s.issueToken(ctx, user.ID, domain.TokenPurposeEmailVerification, s.emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.link("/verify-email", token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verificá tu email",
+		Body: fmt.Sprintf("Hola %s,\n\nConfirmá tu dirección de email ingresando al siguiente link (vence en %s):\n\n%s\n",
+			user.Nombre, s.emailVerificationTTL, link),
+	})
+}
+
+// VerifyEmail consume el token de verificación y marca el email como verificado
+func (s *AccountServiceImpl) VerifyEmail(ctx context.Context, tokenValue string) error {
+	token, err := s.tokensRepo.Consume(ctx, domain.TokenPurposeEmailVerification, hashToken(tokenValue))
+	if err != nil {
+		return err
+	}
+
+	return s.usersRepo.MarkEmailVerified(ctx, token.UsuarioID)
+}
+
+// issueToken invalida los tokens pendientes del mismo propósito y emite uno nuevo
+func (s *AccountServiceImpl) issueToken(ctx context.Context, userID uint, purpose string, ttl time.Duration) (string, error) {
+	if err := s.tokensRepo.InvalidatePending(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("error generating token: %w", err)
+	}
+
+	_, err = s.tokensRepo.Create(ctx, domain.UserToken{
+		UsuarioID: userID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// link arma la URL del frontend con el token como query param
+func (s *AccountServiceImpl) link(path, token string) string {
+	return s.appURL + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+)
+
+// EventPublisher - Interface para publicar eventos en el exchange compartido (abstrae RabbitMQ)
+// El routing key es <eventType>.<action> (ej: privacy.erasure_requested)
+type EventPublisher interface {
+	PublishEvent(eventType, action, id string, data map[string]interface{}) error
+}
+
+// OutboxEventPublisher implementa EventPublisher guardando el evento en la outbox de MySQL
+// El relay lo publica cuando RabbitMQ esté disponible, así no se pierde aunque esté caído
+type OutboxEventPublisher struct {
+	outbox repository.OutboxRepository
+}
+
+// NewOutboxEventPublisher crea una nueva instancia del publisher
+func NewOutboxEventPublisher(outbox repository.OutboxRepository) *OutboxEventPublisher {
+	return &OutboxEventPublisher{
+		outbox: outbox,
+	}
+}
+
+// PublishEvent encola el evento en la outbox
+func (p *OutboxEventPublisher) PublishEvent(eventType, action, id string, data map[string]interface{}) error {
+	return p.outbox.Enqueue(context.Background(), domain.OutboxEvent{
+		EventType:   eventType,
+		Action:      action,
+		AggregateID: id,
+		Data:        data,
+	})
+}
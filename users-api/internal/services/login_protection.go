@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+)
+
+// LoginProtectionPolicy define los umbrales de la protección contra fuerza bruta
+type LoginProtectionPolicy struct {
+	MaxAccountFailures int           // Fallos por cuenta antes del bloqueo
+	MaxIPFailures      int           // Fallos por IP antes del bloqueo
+	FailureWindow      time.Duration // Un fallo más viejo que esto reinicia el contador
+	LockoutDuration    time.Duration // Duración del bloqueo temporal
+	BackoffBase        time.Duration // Espera tras el primer fallo de una cuenta (se duplica con cada fallo)
+	BackoffMax         time.Duration // Tope de la espera entre intentos
+}
+
+// LoginProtectionService limita los intentos de login por cuenta y por IP
+type LoginProtectionService interface {
+	// Check devuelve *domain.LoginThrottledError si el intento debe rechazarse sin verificar la contraseña
+	Check(ctx context.Context, usuarioID *uint, identifier, ip string) error
+	RecordFailure(ctx context.Context, usuarioID *uint, identifier, ip string)
+	RecordSuccess(ctx context.Context, usuarioID *uint, identifier string)
+	UnlockUser(ctx context.Context, actorID, usuarioID uint) error
+	UnlockIP(ctx context.Context, actorID uint, ip string) error
+	ListEvents(ctx context.Context, query domain.ListLockoutEventsQuery) ([]domain.LockoutEvent, error)
+}
+
+// LoginProtectionServiceImpl implementa LoginProtectionService
+type LoginProtectionServiceImpl struct {
+	store     repository.LoginAttemptsStore
+	events    repository.LockoutEventsRepository
+	usersRepo repository.UsersRepository
+	policy    LoginProtectionPolicy
+}
+
+// loginSubject identifica la clave del store y el sujeto que se registra en la auditoría
+type loginSubject struct {
+	scope     string
+	key       string
+	subject   string
+	usuarioID *uint
+}
+
+// NewLoginProtectionService crea una nueva instancia del servicio
+// Dependency Injection: recibe el store de intentos (memoria o MySQL) y el registro de auditoría
+func NewLoginProtectionService(store repository.LoginAttemptsStore, events repository.LockoutEventsRepository, usersRepo repository.UsersRepository, policy LoginProtectionPolicy) *LoginProtectionServiceImpl {
+	return &LoginProtectionServiceImpl{
+		store:     store,
+		events:    events,
+		usersRepo: usersRepo,
+		policy:    policy,
+	}
+}
+
+// Check rechaza el intento si la cuenta o la IP están bloqueadas, o si la cuenta
+// todavía está dentro de la espera exponencial del último fallo
+func (s *LoginProtectionServiceImpl) Check(ctx context.Context, usuarioID *uint, identifier, ip string) error {
+	now := time.Now()
+
+	account := accountSubject(usuarioID, identifier)
+	state, err := s.store.Get(ctx, account.key)
+	if err != nil {
+		// Si el store no responde no se bloquea el login (la contraseña se sigue verificando)
+		log.Printf("⚠️  Could not check login attempts for %s: %v", account.key, err)
+	} else {
+		if state.IsLocked(now) {
+			return &domain.LoginThrottledError{Locked: true, RetryAfter: state.LockedUntil.Sub(now)}
+		}
+		if wait := s.backoff(state.Failures) - now.Sub(state.LastFailureAt); state.Failures > 0 && wait > 0 {
+			return &domain.LoginThrottledError{RetryAfter: wait}
+		}
+	}
+
+	// Por IP solo hay bloqueo: varios socios pueden compartir la IP del gimnasio
+	if ip != "" {
+		ipState, err := s.store.Get(ctx, ipSubject(ip).key)
+		if err != nil {
+			log.Printf("⚠️  Could not check login attempts for ip %s: %v", ip, err)
+		} else if ipState.IsLocked(now) {
+			return &domain.LoginThrottledError{Locked: true, RetryAfter: ipState.LockedUntil.Sub(now)}
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure registra un fallo para la cuenta y la IP y las bloquea al llegar al umbral
+func (s *LoginProtectionServiceImpl) RecordFailure(ctx context.Context, usuarioID *uint, identifier, ip string) {
+	now := time.Now()
+
+	s.recordFailure(ctx, accountSubject(usuarioID, identifier), s.policy.MaxAccountFailures, ip, now)
+	if ip != "" {
+		s.recordFailure(ctx, ipSubject(ip), s.policy.MaxIPFailures, ip, now)
+	}
+}
+
+// RecordSuccess reinicia el contador de la cuenta (el de la IP no: un login válido no debe
+// habilitar a quien prueba contraseñas de otras cuentas desde la misma IP)
+func (s *LoginProtectionServiceImpl) RecordSuccess(ctx context.Context, usuarioID *uint, identifier string) {
+	account := accountSubject(usuarioID, identifier)
+	if err := s.store.Reset(ctx, account.key); err != nil {
+		log.Printf("⚠️  Could not reset login attempts for %s: %v", account.key, err)
+	}
+}
+
+// UnlockUser desbloquea manualmente una cuenta (acción de admin, queda auditada)
+func (s *LoginProtectionServiceImpl) UnlockUser(ctx context.Context, actorID, usuarioID uint) error {
+	if _, err := s.usersRepo.GetByID(ctx, usuarioID); err != nil {
+		return err
+	}
+
+	return s.unlock(ctx, actorID, accountSubject(&usuarioID, ""))
+}
+
+// UnlockIP desbloquea manualmente una IP (acción de admin, queda auditada)
+func (s *LoginProtectionServiceImpl) UnlockIP(ctx context.Context, actorID uint, ip string) error {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return errors.New("invalid ip address")
+	}
+
+	return s.unlock(ctx, actorID, ipSubject(parsed.String()))
+}
+
+// ListEvents devuelve la auditoría de bloqueos (más recientes primero)
+func (s *LoginProtectionServiceImpl) ListEvents(ctx context.Context, query domain.ListLockoutEventsQuery) ([]domain.LockoutEvent, error) {
+	if query.Limit < 1 {
+		query.Limit = 100
+	}
+
+	return s.events.List(ctx, query)
+}
+
+// recordFailure suma el fallo y, si se alcanzó el umbral, bloquea la clave y lo audita
+func (s *LoginProtectionServiceImpl) recordFailure(ctx context.Context, subject loginSubject, maxFailures int, ip string, now time.Time) {
+	state, err := s.store.RecordFailure(ctx, subject.key, now, s.policy.FailureWindow)
+	if err != nil {
+		log.Printf("⚠️  Could not record login failure for %s: %v", subject.key, err)
+		return
+	}
+	if maxFailures <= 0 || state.Failures < maxFailures {
+		return
+	}
+
+	until := now.Add(s.policy.LockoutDuration)
+	locked, err := s.store.Lock(ctx, subject.key, until, now)
+	if err != nil {
+		log.Printf("⚠️  Could not lock %s: %v", subject.key, err)
+		return
+	}
+	if !locked {
+		// Otro intento concurrente ya la bloqueó (y lo auditó)
+		return
+	}
+
+	log.Printf("🔒 Login locked for %s %s until %s after %d failed attempts", subject.scope, subject.subject, until.Format(time.RFC3339), state.Failures)
+	s.audit(ctx, domain.LockoutEvent{
+		Scope:       subject.scope,
+		Subject:     subject.subject,
+		UsuarioID:   subject.usuarioID,
+		Event:       domain.LockoutEventLocked,
+		Failures:    state.Failures,
+		LockedUntil: &until,
+		IP:          ip,
+	})
+}
+
+// unlock elimina el estado de la clave y registra quién la desbloqueó
+func (s *LoginProtectionServiceImpl) unlock(ctx context.Context, actorID uint, subject loginSubject) error {
+	state, err := s.store.Get(ctx, subject.key)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Reset(ctx, subject.key); err != nil {
+		return err
+	}
+
+	log.Printf("🔓 Login unlocked for %s %s by user %d", subject.scope, subject.subject, actorID)
+	s.audit(ctx, domain.LockoutEvent{
+		Scope:       subject.scope,
+		Subject:     subject.subject,
+		UsuarioID:   subject.usuarioID,
+		Event:       domain.LockoutEventUnlocked,
+		Failures:    state.Failures,
+		LockedUntil: state.LockedUntil,
+		ActorID:     &actorID,
+	})
+
+	return nil
+}
+
+// audit guarda el evento; un error de auditoría no interrumpe el login
+func (s *LoginProtectionServiceImpl) audit(ctx context.Context, event domain.LockoutEvent) {
+	if _, err := s.events.Create(ctx, event); err != nil {
+		log.Printf("⚠️  Could not save lockout event: %v", err)
+	}
+}
+
+// backoff calcula la espera exigida tras n fallos consecutivos: base, 2*base, 4*base... hasta BackoffMax
+func (s *LoginProtectionServiceImpl) backoff(failures int) time.Duration {
+	if failures <= 0 || s.policy.BackoffBase <= 0 {
+		return 0
+	}
+
+	wait := s.policy.BackoffBase
+	for i := 1; i < failures; i++ {
+		wait *= 2
+		if s.policy.BackoffMax > 0 && wait >= s.policy.BackoffMax {
+			return s.policy.BackoffMax
+		}
+	}
+	if s.policy.BackoffMax > 0 && wait > s.policy.BackoffMax {
+		return s.policy.BackoffMax
+	}
+	return wait
+}
+
+// accountSubject arma la clave de la cuenta: por ID si el usuario existe, por identificador si no
+// (así los intentos contra usuarios inexistentes se limitan igual y no revelan si la cuenta existe)
+func accountSubject(usuarioID *uint, identifier string) loginSubject {
+	if usuarioID != nil {
+		id := fmt.Sprint(*usuarioID)
+		return loginSubject{scope: domain.LoginScopeAccount, key: "user:" + id, subject: id, usuarioID: usuarioID}
+	}
+
+	normalized := strings.ToLower(strings.TrimSpace(identifier))
+	if len(normalized) > 150 {
+		normalized = normalized[:150] // La clave es varchar(191)
+	}
+	return loginSubject{scope: domain.LoginScopeAccount, key: "login:" + normalized, subject: normalized}
+}
+
+// ipSubject arma la clave de una IP de origen
+func ipSubject(ip string) loginSubject {
+	return loginSubject{scope: domain.LoginScopeIP, key: "ip:" + ip, subject: ip}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+)
+
+// fakeLockoutEvents guarda en memoria los eventos de auditoría
+type fakeLockoutEvents struct {
+	events []domain.LockoutEvent
+}
+
+func (f *fakeLockoutEvents) Create(ctx context.Context, event domain.LockoutEvent) (domain.LockoutEvent, error) {
+	f.events = append(f.events, event)
+	return event, nil
+}
+
+func (f *fakeLockoutEvents) List(ctx context.Context, query domain.ListLockoutEventsQuery) ([]domain.LockoutEvent, error) {
+	return f.events, nil
+}
+
+// TestLoginProtectionBackoff verifica la espera exponencial y su tope
+func TestLoginProtectionBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     time.Duration
+		max      time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{"sin fallos", time.Second, 30 * time.Second, 0, 0},
+		{"primer fallo", time.Second, 30 * time.Second, 1, time.Second},
+		{"segundo fallo", time.Second, 30 * time.Second, 2, 2 * time.Second},
+		{"quinto fallo", time.Second, 30 * time.Second, 5, 16 * time.Second},
+		{"llega al tope", time.Second, 30 * time.Second, 6, 30 * time.Second},
+		{"muchos fallos no desbordan", time.Second, 30 * time.Second, 200, 30 * time.Second},
+		{"sin tope", time.Second, 0, 4, 8 * time.Second},
+		{"backoff desactivado", 0, 30 * time.Second, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewLoginProtectionService(nil, nil, nil, LoginProtectionPolicy{BackoffBase: tt.base, BackoffMax: tt.max})
+			if got := s.backoff(tt.failures); got != tt.want {
+				t.Fatalf("backoff(%d) = %s, se esperaba %s", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoginProtectionRecordFailure verifica el bloqueo por cuenta y por IP contra el store en memoria
+func TestLoginProtectionRecordFailure(t *testing.T) {
+	policy := LoginProtectionPolicy{
+		MaxAccountFailures: 3,
+		MaxIPFailures:      5,
+		FailureWindow:      15 * time.Minute,
+		LockoutDuration:    15 * time.Minute,
+	}
+	ctx := context.Background()
+	userID := uint(7)
+
+	tests := []struct {
+		name       string
+		failures   func(s *LoginProtectionServiceImpl)
+		checkUser  *uint
+		checkLogin string
+		wantLocked bool
+		wantEvents int
+	}{
+		{
+			name: "debajo del umbral de la cuenta",
+			failures: func(s *LoginProtectionServiceImpl) {
+				for i := 0; i < 2; i++ {
+					s.RecordFailure(ctx, &userID, "socio", "10.0.0.1")
+				}
+			},
+			checkUser: &userID,
+		},
+		{
+			name: "bloqueo de la cuenta al llegar al umbral",
+			failures: func(s *LoginProtectionServiceImpl) {
+				for i := 0; i < 3; i++ {
+					s.RecordFailure(ctx, &userID, "socio", "10.0.0.1")
+				}
+			},
+			checkUser:  &userID,
+			wantLocked: true,
+			wantEvents: 1,
+		},
+		{
+			name: "usuario inexistente se limita por identificador",
+			failures: func(s *LoginProtectionServiceImpl) {
+				for _, login := range []string{"Nadie", " nadie ", "NADIE"} {
+					s.RecordFailure(ctx, nil, login, "")
+				}
+			},
+			checkLogin: "nadie",
+			wantLocked: true,
+			wantEvents: 1,
+		},
+		{
+			name: "bloqueo de la IP probando varias cuentas",
+			failures: func(s *LoginProtectionServiceImpl) {
+				for _, login := range []string{"a", "b", "c", "d", "e"} {
+					s.RecordFailure(ctx, nil, login, "10.0.0.1")
+				}
+			},
+			checkLogin: "f",
+			wantLocked: true,
+			wantEvents: 1,
+		},
+		{
+			name: "un login exitoso no desbloquea la IP",
+			failures: func(s *LoginProtectionServiceImpl) {
+				for _, login := range []string{"a", "b", "c", "d", "e"} {
+					s.RecordFailure(ctx, nil, login, "10.0.0.1")
+				}
+				s.RecordSuccess(ctx, &userID, "socio")
+			},
+			checkUser:  &userID,
+			wantLocked: true,
+			wantEvents: 1,
+		},
+		{
+			name: "un login exitoso reinicia la cuenta",
+			failures: func(s *LoginProtectionServiceImpl) {
+				for i := 0; i < 2; i++ {
+					s.RecordFailure(ctx, &userID, "socio", "10.0.0.1")
+				}
+				s.RecordSuccess(ctx, &userID, "socio")
+				s.RecordFailure(ctx, &userID, "socio", "10.0.0.1")
+			},
+			checkUser: &userID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := &fakeLockoutEvents{}
+			s := NewLoginProtectionService(repository.NewMemoryLoginAttemptsStore(), events, nil, policy)
+			tt.failures(s)
+
+			err := s.Check(ctx, tt.checkUser, tt.checkLogin, "10.0.0.1")
+			var throttled *domain.LoginThrottledError
+			if tt.wantLocked {
+				if !errors.As(err, &throttled) || !throttled.Locked {
+					t.Fatalf("Check() = %v, se esperaba bloqueo", err)
+				}
+				if throttled.RetryAfter <= 0 || throttled.RetryAfter > policy.LockoutDuration {
+					t.Fatalf("RetryAfter = %s, se esperaba hasta %s", throttled.RetryAfter, policy.LockoutDuration)
+				}
+			} else if err != nil {
+				t.Fatalf("Check() = %v, se esperaba nil", err)
+			}
+			if len(events.events) != tt.wantEvents {
+				t.Fatalf("se auditaron %d eventos, se esperaban %d", len(events.events), tt.wantEvents)
+			}
+		})
+	}
+}
+
+// TestLoginProtectionCheckBackoff verifica que después de un fallo se exige la espera antes del próximo intento
+func TestLoginProtectionCheckBackoff(t *testing.T) {
+	ctx := context.Background()
+	userID := uint(7)
+	s := NewLoginProtectionService(repository.NewMemoryLoginAttemptsStore(), &fakeLockoutEvents{}, nil, LoginProtectionPolicy{
+		MaxAccountFailures: 10,
+		FailureWindow:      15 * time.Minute,
+		LockoutDuration:    15 * time.Minute,
+		BackoffBase:        time.Minute,
+		BackoffMax:         10 * time.Minute,
+	})
+
+	s.RecordFailure(ctx, &userID, "socio", "")
+	s.RecordFailure(ctx, &userID, "socio", "")
+
+	var throttled *domain.LoginThrottledError
+	if err := s.Check(ctx, &userID, "socio", ""); !errors.As(err, &throttled) || throttled.Locked {
+		t.Fatalf("Check() = %v, se esperaba backoff sin bloqueo", err)
+	}
+	if throttled.RetryAfter <= time.Minute || throttled.RetryAfter > 2*time.Minute {
+		t.Fatalf("RetryAfter = %s, se esperaba hasta 2m", throttled.RetryAfter)
+	}
+}
@@ -0,0 +1,492 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"users-api/internal/domain"
+	"users-api/internal/oidc"
+	"users-api/internal/repository"
+
+	"golang.org/x/oauth2"
+)
+
+// Códigos de error que el callback envía al frontend (?error=...)
+const (
+	OIDCErrorAccessDenied     = "access_denied"      // El usuario canceló en el proveedor
+	OIDCErrorInvalidState     = "invalid_state"      // State vencido, repetido o de otro proveedor
+	OIDCErrorEmailNotVerified = "email_not_verified" // El proveedor no garantiza el email
+	OIDCErrorAccountExists    = "account_exists"     // Ya hay una cuenta con ese email: vincular desde el perfil
+	OIDCErrorAccountNotFound  = "account_not_found"  // Sin cuenta y sin auto-provisioning
+	OIDCErrorAlreadyLinked    = "already_linked"     // La identidad pertenece a otro usuario
+	OIDCErrorLoginFailed      = "login_failed"
+)
+
+// usernameInvalidChars son los caracteres que validateUsername no acepta
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// OIDCPolicy define el comportamiento del login social
+type OIDCPolicy struct {
+	FrontendCallbackURL string        // Página del frontend que recibe ?code= o ?error=
+	StateTTL            time.Duration // Tiempo máximo entre el redirect al proveedor y el callback
+	LoginCodeTTL        time.Duration // Vida del código de un solo uso que el frontend canjea por tokens
+	AutoProvision       bool          // Crear la cuenta en el primer login si no existe
+}
+
+// OIDCService define la interfaz del servicio de login social (OpenID Connect)
+type OIDCService interface {
+	Providers() []domain.OIDCProviderInfo
+	StartLogin(ctx context.Context, provider, redirectPath string) (string, error)
+	StartLink(ctx context.Context, userID uint, provider string) (string, error)
+	HandleCallback(ctx context.Context, provider, code, state string) (domain.OIDCCallbackResult, error)
+	CallbackRedirectURL(result domain.OIDCCallbackResult, err error) string
+	Exchange(ctx context.Context, code string) (domain.LoginResult, error)
+	ListIdentities(ctx context.Context, userID uint) ([]domain.Identity, error)
+	Unlink(ctx context.Context, userID, identityID uint) error
+}
+
+// OIDCServiceImpl implementa OIDCService
+type OIDCServiceImpl struct {
+	usersRepo  repository.UsersRepository
+	identities repository.IdentitiesRepository
+	states     repository.OIDCStatesRepository
+	tokens     repository.UserTokensRepository
+	providers  *oidc.Registry
+	sessions   SessionsService
+	twoFactor  TwoFactorService
+	policy     OIDCPolicy
+}
+
+// NewOIDCService crea una nueva instancia del servicio
+// Dependency Injection: recibe los repositories, los proveedores configurados y los servicios de sesiones y segundo factor
+func NewOIDCService(usersRepo repository.UsersRepository, identities repository.IdentitiesRepository, states repository.OIDCStatesRepository, tokens repository.UserTokensRepository, providers *oidc.Registry, sessions SessionsService, twoFactor TwoFactorService, policy OIDCPolicy) *OIDCServiceImpl {
+	return &OIDCServiceImpl{
+		usersRepo:  usersRepo,
+		identities: identities,
+		states:     states,
+		tokens:     tokens,
+		providers:  providers,
+		sessions:   sessions,
+		twoFactor:  twoFactor,
+		policy:     policy,
+	}
+}
+
+// Providers lista los proveedores habilitados
+func (s *OIDCServiceImpl) Providers() []domain.OIDCProviderInfo {
+	return s.providers.List()
+}
+
+// StartLogin inicia el login social y devuelve la URL del proveedor a la que redirigir el navegador
+// redirectPath es una ruta relativa del frontend a la que volver al terminar (opcional)
+func (s *OIDCServiceImpl) StartLogin(ctx context.Context, provider, redirectPath string) (string, error) {
+	if redirectPath != "" && !isSafeRedirectPath(redirectPath) {
+		return "", errors.New("redirect must be a relative path")
+	}
+	return s.start(ctx, provider, nil, redirectPath)
+}
+
+// StartLink inicia la vinculación de una identidad externa a la cuenta del usuario autenticado
+func (s *OIDCServiceImpl) StartLink(ctx context.Context, userID uint, provider string) (string, error) {
+	if _, err := s.usersRepo.GetByID(ctx, userID); err != nil {
+		return "", err
+	}
+	return s.start(ctx, provider, &userID, "")
+}
+
+// start guarda el state (con el code_verifier PKCE y el nonce) y arma la URL de autorización
+func (s *OIDCServiceImpl) start(ctx context.Context, providerName string, linkUserID *uint, redirectPath string) (string, error) {
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return "", errors.New("oidc provider not found")
+	}
+
+	state, stateHash, err := newOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("error generating state: %w", err)
+	}
+	nonce, err := randomHex(16)
+	if err != nil {
+		return "", fmt.Errorf("error generating nonce: %w", err)
+	}
+	codeVerifier := oauth2.GenerateVerifier()
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return "", err
+	}
+
+	// Limpieza de logins abandonados (no bloquea el login si falla)
+	if err := s.states.DeleteExpired(ctx); err != nil {
+		log.Printf("⚠️  Could not delete expired oidc states: %v", err)
+	}
+
+	err = s.states.Create(ctx, domain.OIDCLoginState{
+		StateHash:    stateHash,
+		Provider:     provider.Name(),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		LinkUserID:   linkUserID,
+		RedirectPath: redirectPath,
+		ExpiresAt:    time.Now().Add(s.policy.StateTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return authURL, nil
+}
+
+// HandleCallback procesa la vuelta del proveedor: valida el state, canjea el code con PKCE,
+// verifica el ID token y resuelve (o crea) el usuario. Devuelve un código de login de un solo uso
+func (s *OIDCServiceImpl) HandleCallback(ctx context.Context, providerName, code, state string) (domain.OIDCCallbackResult, error) {
+	if state == "" || code == "" {
+		return domain.OIDCCallbackResult{}, errors.New("invalid or expired state")
+	}
+
+	loginState, err := s.states.Consume(ctx, hashToken(state))
+	if err != nil {
+		return domain.OIDCCallbackResult{}, err
+	}
+	if loginState.Provider != providerName {
+		return domain.OIDCCallbackResult{}, errors.New("invalid or expired state")
+	}
+
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return domain.OIDCCallbackResult{}, errors.New("oidc provider not found")
+	}
+
+	profile, err := provider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		return domain.OIDCCallbackResult{}, err
+	}
+
+	result := domain.OIDCCallbackResult{Provider: providerName, RedirectPath: loginState.RedirectPath}
+
+	// Vinculación desde el perfil: no inicia sesión
+	if loginState.LinkUserID != nil {
+		if err := s.link(ctx, *loginState.LinkUserID, profile); err != nil {
+			return domain.OIDCCallbackResult{}, err
+		}
+		result.Linked = true
+		return result, nil
+	}
+
+	user, err := s.resolveUser(ctx, profile)
+	if err != nil {
+		return domain.OIDCCallbackResult{}, err
+	}
+
+	loginCode, loginCodeHash, err := newOpaqueToken()
+	if err != nil {
+		return domain.OIDCCallbackResult{}, fmt.Errorf("error generating login code: %w", err)
+	}
+	_, err = s.tokens.Create(ctx, domain.UserToken{
+		UsuarioID: user.ID,
+		Purpose:   domain.TokenPurposeOIDCLogin,
+		TokenHash: loginCodeHash,
+		ExpiresAt: time.Now().Add(s.policy.LoginCodeTTL),
+	})
+	if err != nil {
+		return domain.OIDCCallbackResult{}, err
+	}
+
+	result.LoginCode = loginCode
+	return result, nil
+}
+
+// CallbackRedirectURL arma la URL del frontend a la que vuelve el navegador
+// Los tokens nunca viajan en la URL: el frontend canjea el código con POST /auth/oidc/exchange
+func (s *OIDCServiceImpl) CallbackRedirectURL(result domain.OIDCCallbackResult, err error) string {
+	values := url.Values{}
+	switch {
+	case err != nil:
+		values.Set("error", oidcErrorCode(err))
+	case result.Linked:
+		values.Set("linked", result.Provider)
+	default:
+		values.Set("code", result.LoginCode)
+	}
+	if result.Provider != "" {
+		values.Set("provider", result.Provider)
+	}
+	if result.RedirectPath != "" {
+		values.Set("redirect", result.RedirectPath)
+	}
+
+	separator := "?"
+	if strings.Contains(s.policy.FrontendCallbackURL, "?") {
+		separator = "&"
+	}
+	return s.policy.FrontendCallbackURL + separator + values.Encode()
+}
+
+// Exchange canjea el código de login por los tokens (o por un challenge si el usuario tiene 2FA)
+func (s *OIDCServiceImpl) Exchange(ctx context.Context, code string) (domain.LoginResult, error) {
+	token, err := s.tokens.Consume(ctx, domain.TokenPurposeOIDCLogin, hashToken(code))
+	if err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	user, err := s.usersRepo.GetByID(ctx, token.UsuarioID)
+	if err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	// El login social reemplaza a la contraseña, no al segundo factor
+	challenge, err := s.twoFactor.StartLogin(ctx, user)
+	if err != nil {
+		return domain.LoginResult{}, fmt.Errorf("error starting two factor login: %w", err)
+	}
+	if challenge != nil {
+		return domain.LoginResult{Challenge: challenge}, nil
+	}
+
+	tokens, err := s.sessions.IssueTokens(ctx, user)
+	if err != nil {
+		return domain.LoginResult{}, fmt.Errorf("error generating token: %w", err)
+	}
+
+	return domain.LoginResult{User: user.ToResponse(), Tokens: tokens}, nil
+}
+
+// ListIdentities lista las identidades externas vinculadas al usuario
+func (s *OIDCServiceImpl) ListIdentities(ctx context.Context, userID uint) ([]domain.Identity, error) {
+	return s.identities.ListByUser(ctx, userID)
+}
+
+// Unlink desvincula una identidad externa
+// No permite dejar la cuenta sin forma de iniciar sesión (sin contraseña y sin otra identidad)
+func (s *OIDCServiceImpl) Unlink(ctx context.Context, userID, identityID uint) error {
+	user, err := s.usersRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Password == "" {
+		identities, err := s.identities.ListByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if len(identities) <= 1 {
+			return errors.New("cannot unlink the only sign-in method: set a password first (POST /password/forgot)")
+		}
+	}
+
+	return s.identities.Delete(ctx, userID, identityID)
+}
+
+// resolveUser busca el usuario de la identidad externa; si no existe lo vincula por email o lo crea
+func (s *OIDCServiceImpl) resolveUser(ctx context.Context, profile domain.ExternalProfile) (domain.User, error) {
+	identity, err := s.identities.GetByProviderSubject(ctx, profile.Provider, profile.Subject)
+	if err == nil {
+		user, err := s.usersRepo.GetByID(ctx, identity.UsuarioID)
+		if err != nil {
+			return domain.User{}, err
+		}
+		if err := s.identities.TouchLastLogin(ctx, identity.ID, profile.Email); err != nil {
+			log.Printf("⚠️  Could not update identity %d: %v", identity.ID, err)
+		}
+		return user, nil
+	}
+	if err.Error() != "identity not found" {
+		return domain.User{}, err
+	}
+
+	// Primera vez con esta identidad: se necesita un email garantizado por el proveedor
+	if profile.Email == "" || !profile.EmailVerified {
+		return domain.User{}, errors.New("email not verified by provider")
+	}
+
+	user, err := s.usersRepo.GetByEmail(ctx, profile.Email)
+	switch {
+	case err == nil:
+		// Solo se vincula automáticamente si la cuenta local también verificó ese email;
+		// si no, alguien pudo registrarse con un email ajeno para quedarse con la cuenta
+		if user.EmailVerifiedAt == nil {
+			return domain.User{}, errors.New("an account with this email already exists")
+		}
+	case err.Error() == "user not found":
+		if !s.policy.AutoProvision {
+			return domain.User{}, errors.New("account not found")
+		}
+		user, err = s.provision(ctx, profile)
+		if err != nil {
+			return domain.User{}, err
+		}
+	default:
+		return domain.User{}, err
+	}
+
+	now := time.Now()
+	_, err = s.identities.Create(ctx, domain.Identity{
+		UsuarioID:   user.ID,
+		Provider:    profile.Provider,
+		Subject:     profile.Subject,
+		Email:       profile.Email,
+		LastLoginAt: &now,
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+// link vincula la identidad a un usuario existente (flujo iniciado desde el perfil)
+func (s *OIDCServiceImpl) link(ctx context.Context, userID uint, profile domain.ExternalProfile) error {
+	existing, err := s.identities.GetByProviderSubject(ctx, profile.Provider, profile.Subject)
+	if err == nil {
+		if existing.UsuarioID == userID {
+			return nil
+		}
+		return errors.New("identity already linked to an account")
+	}
+	if err.Error() != "identity not found" {
+		return err
+	}
+
+	_, err = s.identities.Create(ctx, domain.Identity{
+		UsuarioID: userID,
+		Provider:  profile.Provider,
+		Subject:   profile.Subject,
+		Email:     profile.Email,
+	})
+	return err
+}
+
+// provision crea la cuenta de un socio en su primer login social
+// No tiene contraseña: puede definir una con el flujo de recuperación
+func (s *OIDCServiceImpl) provision(ctx context.Context, profile domain.ExternalProfile) (domain.User, error) {
+	nombre, apellido := profileNames(profile)
+	verifiedAt := time.Now()
+
+	var lastErr error
+	for attempt := 0; attempt < 5; attempt++ {
+		username, err := s.availableUsername(ctx, profile.Email, attempt)
+		if err != nil {
+			return domain.User{}, err
+		}
+
+		user, err := s.usersRepo.Create(ctx, domain.User{
+			Nombre:          nombre,
+			Apellido:        apellido,
+			Username:        username,
+			Email:           profile.Email,
+			Password:        "", // Sin contraseña: el login con contraseña siempre falla
+			IsAdmin:         false,
+			EmailVerifiedAt: &verifiedAt,
+		})
+		if err == nil {
+			log.Printf("👤 Provisioned user %d (%s) from %s login", user.ID, user.Username, profile.Provider)
+			return user, nil
+		}
+		// Carrera con otro registro del mismo username: reintentar con otro sufijo
+		if err.Error() != "username or email already exists" {
+			return domain.User{}, err
+		}
+		lastErr = err
+		if _, emailErr := s.usersRepo.GetByEmail(ctx, profile.Email); emailErr == nil {
+			return domain.User{}, errors.New("an account with this email already exists")
+		}
+	}
+
+	return domain.User{}, fmt.Errorf("error provisioning user: %w", lastErr)
+}
+
+// availableUsername deriva un username válido (ver validateUsername) de la parte local del email
+func (s *OIDCServiceImpl) availableUsername(ctx context.Context, email string, attempt int) (string, error) {
+	base := email
+	if at := strings.Index(email, "@"); at > 0 {
+		base = email[:at]
+	}
+	base = usernameInvalidChars.ReplaceAllString(base, "")
+	if len(base) > 24 {
+		base = base[:24]
+	}
+	for len(base) < 3 {
+		base += "_"
+	}
+
+	candidate := base
+	for i := 0; i < 10; i++ {
+		if attempt > 0 || i > 0 {
+			n, err := rand.Int(rand.Reader, big.NewInt(100000))
+			if err != nil {
+				return "", err
+			}
+			candidate = fmt.Sprintf("%s-%d", base, n.Int64())
+		}
+		if _, err := s.usersRepo.GetByUsername(ctx, candidate); err != nil {
+			if err.Error() == "user not found" {
+				return candidate, nil
+			}
+			return "", err
+		}
+	}
+
+	return "", errors.New("could not generate a unique username")
+}
+
+// profileNames obtiene nombre y apellido del perfil (recortados al largo de las columnas)
+func profileNames(profile domain.ExternalProfile) (string, string) {
+	nombre, apellido := strings.TrimSpace(profile.GivenName), strings.TrimSpace(profile.FamilyName)
+	if nombre == "" && profile.Name != "" {
+		parts := strings.SplitN(strings.TrimSpace(profile.Name), " ", 2)
+		nombre = parts[0]
+		if apellido == "" && len(parts) == 2 {
+			apellido = strings.TrimSpace(parts[1])
+		}
+	}
+	if nombre == "" {
+		nombre = strings.SplitN(profile.Email, "@", 2)[0]
+	}
+	return truncateRunes(nombre, 30), truncateRunes(apellido, 30)
+}
+
+// truncateRunes recorta s a n caracteres sin partir caracteres UTF-8
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// isSafeRedirectPath acepta solo rutas relativas del propio frontend (evita open redirects)
+func isSafeRedirectPath(path string) bool {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.Contains(path, `\`) {
+		return false
+	}
+	parsed, err := url.Parse(path)
+	return err == nil && parsed.Scheme == "" && parsed.Host == "" && len(path) <= 255
+}
+
+// oidcErrorCode traduce un error del callback al código que recibe el frontend
+func oidcErrorCode(err error) string {
+	msg := err.Error()
+	switch {
+	case msg == "access denied":
+		return OIDCErrorAccessDenied
+	case msg == "invalid or expired state":
+		return OIDCErrorInvalidState
+	case msg == "email not verified by provider":
+		return OIDCErrorEmailNotVerified
+	case msg == "an account with this email already exists":
+		return OIDCErrorAccountExists
+	case msg == "account not found" || msg == "user not found":
+		return OIDCErrorAccountNotFound
+	case msg == "identity already linked to an account":
+		return OIDCErrorAlreadyLinked
+	default:
+		return OIDCErrorLoginFailed
+	}
+}
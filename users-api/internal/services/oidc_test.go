@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"users-api/internal/config"
+	"users-api/internal/domain"
+	"users-api/internal/oidc"
+	"users-api/internal/oidc/mockprovider"
+	"users-api/internal/repository"
+)
+
+// fakeOIDCStates guarda los states en memoria con la misma semántica de un solo uso que MySQL
+type fakeOIDCStates struct {
+	mu     sync.Mutex
+	states map[string]domain.OIDCLoginState
+}
+
+func (f *fakeOIDCStates) Create(ctx context.Context, state domain.OIDCLoginState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.states[state.StateHash] = state
+	return nil
+}
+
+func (f *fakeOIDCStates) Consume(ctx context.Context, stateHash string) (domain.OIDCLoginState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	state, ok := f.states[stateHash]
+	if !ok || state.UsedAt != nil || time.Now().After(state.ExpiresAt) {
+		return domain.OIDCLoginState{}, errors.New("invalid or expired state")
+	}
+	now := time.Now()
+	state.UsedAt = &now
+	f.states[stateHash] = state
+	return state, nil
+}
+
+func (f *fakeOIDCStates) DeleteExpired(ctx context.Context) error {
+	return nil
+}
+
+// tamperNonce cambia el nonce guardado de todos los states (simula un ID token emitido para otro login)
+func (f *fakeOIDCStates) tamperNonce() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for hash, state := range f.states {
+		state.Nonce = "otro-nonce"
+		f.states[hash] = state
+	}
+}
+
+// fakeIdentities guarda las identidades externas en memoria
+type fakeIdentities struct {
+	repository.IdentitiesRepository
+	identities []domain.Identity
+}
+
+func (f *fakeIdentities) Create(ctx context.Context, identity domain.Identity) (domain.Identity, error) {
+	identity.ID = uint(len(f.identities) + 1)
+	f.identities = append(f.identities, identity)
+	return identity, nil
+}
+
+func (f *fakeIdentities) GetByProviderSubject(ctx context.Context, provider, subject string) (domain.Identity, error) {
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return domain.Identity{}, errors.New("identity not found")
+}
+
+func (f *fakeIdentities) TouchLastLogin(ctx context.Context, id uint, email string) error {
+	return nil
+}
+
+// fakeOIDCUsers guarda los usuarios en memoria (solo lo que usa el login social)
+type fakeOIDCUsers struct {
+	repository.UsersRepository
+	users []domain.User
+}
+
+func (f *fakeOIDCUsers) Create(ctx context.Context, user domain.User) (domain.User, error) {
+	user.ID = uint(len(f.users) + 1)
+	f.users = append(f.users, user)
+	return user, nil
+}
+
+func (f *fakeOIDCUsers) GetByID(ctx context.Context, id uint) (domain.User, error) {
+	for _, user := range f.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return domain.User{}, errors.New("user not found")
+}
+
+func (f *fakeOIDCUsers) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return domain.User{}, errors.New("user not found")
+}
+
+func (f *fakeOIDCUsers) GetByUsername(ctx context.Context, username string) (domain.User, error) {
+	for _, user := range f.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return domain.User{}, errors.New("user not found")
+}
+
+// fakeUserTokens guarda los códigos de login emitidos
+type fakeUserTokens struct {
+	repository.UserTokensRepository
+	tokens []domain.UserToken
+}
+
+func (f *fakeUserTokens) Create(ctx context.Context, token domain.UserToken) (domain.UserToken, error) {
+	f.tokens = append(f.tokens, token)
+	return token, nil
+}
+
+// oidcTestEnv levanta el proveedor mock con httptest y arma el servicio contra repositories en memoria
+type oidcTestEnv struct {
+	service *OIDCServiceImpl
+	mock    *mockprovider.Server
+	states  *fakeOIDCStates
+	users   *fakeOIDCUsers
+	tokens  *fakeUserTokens
+}
+
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	t.Helper()
+
+	var mock *mockprovider.Server
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mock.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	mock, err := mockprovider.New(mockprovider.Config{Issuer: srv.URL, ClientID: "gym", ClientSecret: "secret"})
+	if err != nil {
+		t.Fatalf("error creando el proveedor mock: %v", err)
+	}
+	registry, err := oidc.NewRegistry(config.OIDCConfig{
+		Providers:       []config.OIDCProviderConfig{{Name: "mock", Issuer: srv.URL, ClientID: "gym", ClientSecret: "secret"}},
+		RedirectBaseURL: "http://users-api.test",
+	})
+	if err != nil {
+		t.Fatalf("error creando el registry: %v", err)
+	}
+
+	env := &oidcTestEnv{
+		mock:   mock,
+		states: &fakeOIDCStates{states: make(map[string]domain.OIDCLoginState)},
+		users:  &fakeOIDCUsers{},
+		tokens: &fakeUserTokens{},
+	}
+	env.service = NewOIDCService(env.users, &fakeIdentities{}, env.states, env.tokens, registry, nil, nil, OIDCPolicy{
+		FrontendCallbackURL: "http://frontend.test/auth/callback",
+		StateTTL:            10 * time.Minute,
+		LoginCodeTTL:        time.Minute,
+		AutoProvision:       true,
+	})
+	return env
+}
+
+// authorize sigue la URL de autorización como el navegador (con login_hint para saltear la pantalla del mock)
+// y devuelve el code y el state con los que el proveedor redirige al callback
+func (e *oidcTestEnv) authorize(t *testing.T, email string) (string, string) {
+	t.Helper()
+
+	authURL, err := e.service.StartLogin(context.Background(), "mock", "")
+	if err != nil {
+		t.Fatalf("StartLogin() error = %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL + "&login_hint=" + url.QueryEscape(email))
+	if err != nil {
+		t.Fatalf("error pidiendo la autorización: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("autorización respondió %d, se esperaba 302", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("Location inválida: %v", err)
+	}
+	if !strings.HasPrefix(location.String(), "http://users-api.test/auth/oidc/mock/callback") {
+		t.Fatalf("redirect a %s, se esperaba el callback de users-api", location)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+// TestOIDCCallbackRoundTrip recorre el flujo completo contra el proveedor mock:
+// login con state de un solo uso, nonce verificado y email_verified exigido para crear la cuenta
+func TestOIDCCallbackRoundTrip(t *testing.T) {
+	tests := []struct {
+		name          string
+		user          mockprovider.User
+		setup         func(e *oidcTestEnv)
+		replay        bool
+		wantErrCode   string
+		wantErrSubstr string
+	}{
+		{
+			name: "login y alta del socio",
+			user: mockprovider.User{Email: "socio@gym.test", EmailVerified: true, GivenName: "Ana", FamilyName: "Pérez"},
+		},
+		{
+			name:        "state repetido",
+			user:        mockprovider.User{Email: "socio@gym.test", EmailVerified: true},
+			replay:      true,
+			wantErrCode: OIDCErrorInvalidState,
+		},
+		{
+			name:          "nonce distinto",
+			user:          mockprovider.User{Email: "socio@gym.test", EmailVerified: true},
+			setup:         func(e *oidcTestEnv) { e.states.tamperNonce() },
+			wantErrCode:   OIDCErrorLoginFailed,
+			wantErrSubstr: "nonce mismatch",
+		},
+		{
+			name:        "email sin verificar",
+			user:        mockprovider.User{Email: "socio@gym.test", EmailVerified: false},
+			wantErrCode: OIDCErrorEmailNotVerified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t)
+			env.mock.AddUser(tt.user)
+			ctx := context.Background()
+
+			code, state := env.authorize(t, tt.user.Email)
+			if tt.setup != nil {
+				tt.setup(env)
+			}
+
+			result, err := env.service.HandleCallback(ctx, "mock", code, state)
+			if tt.replay {
+				if err != nil {
+					t.Fatalf("primer callback: %v", err)
+				}
+				result, err = env.service.HandleCallback(ctx, "mock", code, state)
+			}
+
+			if tt.wantErrCode != "" {
+				if err == nil {
+					t.Fatalf("HandleCallback() sin error, se esperaba %s", tt.wantErrCode)
+				}
+				if got := oidcErrorCode(err); got != tt.wantErrCode {
+					t.Fatalf("código de error = %s (%v), se esperaba %s", got, err, tt.wantErrCode)
+				}
+				if tt.wantErrSubstr != "" && !strings.Contains(err.Error(), tt.wantErrSubstr) {
+					t.Fatalf("error = %v, se esperaba que contenga %q", err, tt.wantErrSubstr)
+				}
+				if !tt.replay && len(env.users.users) != 0 {
+					t.Fatalf("se crearon %d usuarios con un login rechazado", len(env.users.users))
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("HandleCallback() error = %v", err)
+			}
+			if result.LoginCode == "" || len(env.tokens.tokens) != 1 {
+				t.Fatalf("se esperaba un código de login, result = %+v", result)
+			}
+			if len(env.users.users) != 1 || env.users.users[0].Email != tt.user.Email || env.users.users[0].Nombre != tt.user.GivenName {
+				t.Fatalf("usuarios creados = %+v", env.users.users)
+			}
+		})
+	}
+}
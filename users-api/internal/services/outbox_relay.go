@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+)
+
+// OutboxRelayPolicy configura el relay de la outbox
+type OutboxRelayPolicy struct {
+	PollInterval time.Duration // Cada cuánto se buscan eventos pendientes
+	BatchSize    int           // Máximo de eventos por ronda
+	Retention    time.Duration // Cuánto se conservan los eventos ya publicados
+}
+
+// OutboxRelay publica en RabbitMQ los eventos guardados en la outbox, en orden de creación
+// Si la publicación falla, el evento queda pendiente y se reintenta en la próxima ronda
+// (entrega at-least-once: los consumidores deben tolerar duplicados)
+type OutboxRelay struct {
+	outbox    repository.OutboxRepository
+	publisher EventPublisher
+	policy    OutboxRelayPolicy
+}
+
+// NewOutboxRelay crea una nueva instancia del relay
+func NewOutboxRelay(outbox repository.OutboxRepository, publisher EventPublisher, policy OutboxRelayPolicy) *OutboxRelay {
+	if policy.PollInterval <= 0 {
+		policy.PollInterval = 2 * time.Second
+	}
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = 100
+	}
+
+	return &OutboxRelay{
+		outbox:    outbox,
+		publisher: publisher,
+		policy:    policy,
+	}
+}
+
+// Start inicia el relay en una goroutine hasta que se cancele el contexto
+func (r *OutboxRelay) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.policy.PollInterval)
+		defer ticker.Stop()
+
+		var lastPurge time.Time
+		failing := false
+		for {
+			// Vaciar la outbox de a lotes antes de esperar al próximo tick
+			for {
+				published, err := r.RelayPending(ctx)
+				// Se loguea solo el cambio de estado para no repetir el error en cada ronda
+				if err != nil && !failing {
+					log.Printf("❌ Error relaying outbox (retrying every %s): %v", r.policy.PollInterval, err)
+				} else if err == nil && failing {
+					log.Println("✅ Outbox relay resumed")
+				}
+				failing = err != nil
+				if err != nil || published < r.policy.BatchSize {
+					break
+				}
+			}
+
+			if r.policy.Retention > 0 && time.Since(lastPurge) > time.Hour {
+				r.purge(ctx)
+				lastPurge = time.Now()
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	log.Printf("📮 Outbox relay started (every %s, batch %d)", r.policy.PollInterval, r.policy.BatchSize)
+}
+
+// RelayPending publica un lote de eventos pendientes y devuelve cuántos publicó
+// El repository bloquea las filas del lote, así que varias réplicas pueden correr el relay a la vez
+func (r *OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	return r.outbox.RelayPending(ctx, r.policy.BatchSize, func(event domain.OutboxEvent) error {
+		return r.publisher.PublishEvent(event.EventType, event.Action, event.AggregateID, event.Data)
+	})
+}
+
+// purge borra los eventos publicados más viejos que la retención
+func (r *OutboxRelay) purge(ctx context.Context) {
+	deleted, err := r.outbox.DeletePublishedBefore(ctx, time.Now().Add(-r.policy.Retention))
+	if err != nil {
+		log.Printf("❌ Error purging outbox: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("🧹 Outbox: %d published events purged", deleted)
+	}
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+)
+
+// PrivacyEventType es el tipo de los eventos del flujo de privacidad en el exchange compartido
+// users-api publica privacy.<tipo>_requested y cada servicio responde privacy.<tipo>_completed
+const PrivacyEventType = "privacy"
+
+// PrivacyService define la interfaz del servicio de exportación y borrado de datos de socios
+type PrivacyService interface {
+	CreateRequest(ctx context.Context, actorID uint, create domain.PrivacyRequestCreate) (domain.PrivacyRequest, error)
+	GetRequest(ctx context.Context, id uint) (domain.PrivacyRequest, error)
+	ListRequests(ctx context.Context, query domain.ListPrivacyRequestsQuery) ([]domain.PrivacyRequest, error)
+	RetryRequest(ctx context.Context, id uint) (domain.PrivacyRequest, error)
+	HandleAck(ctx context.Context, requestID uint, ack domain.PrivacyAck) error
+	ExportBundle(ctx context.Context, id uint) (domain.PrivacyExportBundle, error)
+}
+
+// PrivacyServiceImpl implementa PrivacyService
+type PrivacyServiceImpl struct {
+	requests   repository.PrivacyRequestsRepository
+	usersRepo  repository.UsersRepository
+	rolesRepo  repository.RolesRepository
+	identities repository.IdentitiesRepository
+	twoFactor  repository.TwoFactorRepository
+	tokens     repository.UserTokensRepository
+	lockouts   repository.LockoutEventsRepository
+	sessions   SessionsService
+	publisher  EventPublisher
+	services   []string // Servicios remotos que tienen que confirmar cada pedido
+}
+
+// NewPrivacyService crea una nueva instancia del servicio
+// Dependency Injection: recibe los repositories con datos del socio, el servicio de sesiones,
+// el publicador de eventos (nil = sin RabbitMQ) y los servicios remotos que participan
+func NewPrivacyService(
+	requests repository.PrivacyRequestsRepository,
+	usersRepo repository.UsersRepository,
+	rolesRepo repository.RolesRepository,
+	identities repository.IdentitiesRepository,
+	twoFactor repository.TwoFactorRepository,
+	tokens repository.UserTokensRepository,
+	lockouts repository.LockoutEventsRepository,
+	sessions SessionsService,
+	publisher EventPublisher,
+	services []string,
+) *PrivacyServiceImpl {
+	return &PrivacyServiceImpl{
+		requests:   requests,
+		usersRepo:  usersRepo,
+		rolesRepo:  rolesRepo,
+		identities: identities,
+		twoFactor:  twoFactor,
+		tokens:     tokens,
+		lockouts:   lockouts,
+		sessions:   sessions,
+		publisher:  publisher,
+		services:   services,
+	}
+}
+
+// CreateRequest registra un pedido, resuelve la parte de users-api y lo propaga al resto de los servicios
+func (s *PrivacyServiceImpl) CreateRequest(ctx context.Context, actorID uint, create domain.PrivacyRequestCreate) (domain.PrivacyRequest, error) {
+	if create.Tipo != domain.PrivacyRequestExport && create.Tipo != domain.PrivacyRequestErasure {
+		return domain.PrivacyRequest{}, errors.New("tipo must be export or erasure")
+	}
+	if create.Tipo == domain.PrivacyRequestErasure && create.UsuarioID == actorID {
+		return domain.PrivacyRequest{}, errors.New("cannot erase your own account")
+	}
+
+	// Incluye usuarios dados de baja: el pedido puede llegar después del DELETE /users/me
+	if _, err := s.usersRepo.GetByIDWithDeleted(ctx, create.UsuarioID); err != nil {
+		return domain.PrivacyRequest{}, err
+	}
+
+	request, err := s.requests.Create(ctx, domain.PrivacyRequest{
+		UsuarioID:   create.UsuarioID,
+		Tipo:        create.Tipo,
+		Estado:      domain.PrivacyStatusPending,
+		RequestedBy: actorID,
+		Services:    append([]string{domain.PrivacyServiceName}, s.services...),
+	})
+	if err != nil {
+		return domain.PrivacyRequest{}, err
+	}
+
+	if err := s.requests.SaveAck(ctx, request.ID, s.processLocal(ctx, request)); err != nil {
+		return domain.PrivacyRequest{}, err
+	}
+	s.publish(request)
+
+	log.Printf("🔐 Privacy request #%d (%s) for user %d created by user %d", request.ID, request.Tipo, request.UsuarioID, actorID)
+	return s.refreshStatus(ctx, request.ID)
+}
+
+// GetRequest obtiene un pedido con el estado de cada servicio
+func (s *PrivacyServiceImpl) GetRequest(ctx context.Context, id uint) (domain.PrivacyRequest, error) {
+	request, err := s.requests.GetByID(ctx, id)
+	if err != nil {
+		return domain.PrivacyRequest{}, err
+	}
+	request.Pending = pendingServices(request)
+	return request, nil
+}
+
+// ListRequests lista los pedidos más recientes
+func (s *PrivacyServiceImpl) ListRequests(ctx context.Context, query domain.ListPrivacyRequestsQuery) ([]domain.PrivacyRequest, error) {
+	if query.Limit <= 0 || query.Limit > 500 {
+		query.Limit = 100
+	}
+	if query.Estado != "" && query.Estado != domain.PrivacyStatusPending &&
+		query.Estado != domain.PrivacyStatusCompleted && query.Estado != domain.PrivacyStatusFailed {
+		return nil, errors.New("estado must be pending, completed or failed")
+	}
+
+	requests, err := s.requests.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range requests {
+		requests[i].Pending = pendingServices(requests[i])
+	}
+	return requests, nil
+}
+
+// RetryRequest vuelve a publicar un pedido sin terminar (servicio caído o que respondió con error)
+// Los servicios que ya confirmaron lo procesan de nuevo: exportar y borrar son idempotentes
+func (s *PrivacyServiceImpl) RetryRequest(ctx context.Context, id uint) (domain.PrivacyRequest, error) {
+	request, err := s.requests.GetByID(ctx, id)
+	if err != nil {
+		return domain.PrivacyRequest{}, err
+	}
+	if request.Estado == domain.PrivacyStatusCompleted {
+		return domain.PrivacyRequest{}, errors.New("privacy request already completed")
+	}
+
+	if err := s.requests.DeleteFailedAcks(ctx, id); err != nil {
+		return domain.PrivacyRequest{}, err
+	}
+	if ack, ok := findAck(request, domain.PrivacyServiceName); !ok || ack.Estado == domain.PrivacyStatusFailed {
+		if err := s.requests.SaveAck(ctx, id, s.processLocal(ctx, request)); err != nil {
+			return domain.PrivacyRequest{}, err
+		}
+	}
+	s.publish(request)
+
+	log.Printf("🔁 Privacy request #%d republished", id)
+	return s.refreshStatus(ctx, id)
+}
+
+// HandleAck registra la confirmación de un servicio y actualiza el estado del pedido
+func (s *PrivacyServiceImpl) HandleAck(ctx context.Context, requestID uint, ack domain.PrivacyAck) error {
+	request, err := s.requests.GetByID(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	if !containsString(request.Services, ack.Service) {
+		return fmt.Errorf("service %q is not part of privacy request #%d", ack.Service, requestID)
+	}
+	if ack.Estado != domain.PrivacyStatusCompleted {
+		ack.Estado = domain.PrivacyStatusFailed
+	}
+	if request.Tipo != domain.PrivacyRequestExport {
+		ack.Data = nil
+	}
+	if ack.ReceivedAt.IsZero() {
+		ack.ReceivedAt = time.Now()
+	}
+
+	if err := s.requests.SaveAck(ctx, requestID, ack); err != nil {
+		return err
+	}
+
+	request, err = s.refreshStatus(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	log.Printf("📥 Privacy request #%d: %s %s (%s)", requestID, ack.Service, ack.Estado, request.Estado)
+	return nil
+}
+
+// ExportBundle arma el paquete con los datos de todos los servicios de una exportación terminada
+func (s *PrivacyServiceImpl) ExportBundle(ctx context.Context, id uint) (domain.PrivacyExportBundle, error) {
+	request, err := s.requests.GetByID(ctx, id)
+	if err != nil {
+		return domain.PrivacyExportBundle{}, err
+	}
+	if request.Tipo != domain.PrivacyRequestExport {
+		return domain.PrivacyExportBundle{}, errors.New("privacy request is not an export")
+	}
+	if request.Estado != domain.PrivacyStatusCompleted {
+		return domain.PrivacyExportBundle{}, errors.New("export is not ready yet")
+	}
+
+	bundle := domain.PrivacyExportBundle{
+		RequestID:   request.ID,
+		UsuarioID:   request.UsuarioID,
+		GeneratedAt: time.Now(),
+		Services:    make(map[string]json.RawMessage),
+	}
+	for _, ack := range request.Acks {
+		data := ack.Data
+		if len(data) == 0 {
+			data = json.RawMessage("{}")
+		}
+		bundle.Services[ack.Service] = data
+	}
+	return bundle, nil
+}
+
+// processLocal resuelve la parte del pedido que corresponde a users-api
+func (s *PrivacyServiceImpl) processLocal(ctx context.Context, request domain.PrivacyRequest) domain.PrivacyAck {
+	ack := domain.PrivacyAck{
+		Service:    domain.PrivacyServiceName,
+		Estado:     domain.PrivacyStatusCompleted,
+		ReceivedAt: time.Now(),
+	}
+
+	var err error
+	if request.Tipo == domain.PrivacyRequestExport {
+		ack.Data, ack.Records, err = s.exportLocal(ctx, request.UsuarioID)
+	} else {
+		ack.Records, err = s.eraseLocal(ctx, request.UsuarioID)
+	}
+	if err != nil {
+		log.Printf("❌ Privacy request #%d failed in users-api: %v", request.ID, err)
+		ack.Estado = domain.PrivacyStatusFailed
+		ack.Error = err.Error()
+		ack.Data = nil
+	}
+	return ack
+}
+
+// exportLocal reúne los datos del socio guardados en users-api (sin secretos: contraseña, TOTP, tokens)
+func (s *PrivacyServiceImpl) exportLocal(ctx context.Context, userID uint) (json.RawMessage, int, error) {
+	user, err := s.usersRepo.GetByIDWithDeleted(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	user.Password = ""
+
+	roles, err := s.rolesRepo.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	identities, err := s.identities.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	lockouts, err := s.lockouts.List(ctx, domain.ListLockoutEventsQuery{UsuarioID: &userID, Limit: 500})
+	if err != nil {
+		return nil, 0, err
+	}
+	twoFactorEnabled := false
+	if twoFactor, err := s.twoFactor.Get(ctx, userID); err == nil {
+		twoFactorEnabled = twoFactor.EnabledAt != nil
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"user":               user,
+		"roles":              roles,
+		"identities":         identities,
+		"two_factor_enabled": twoFactorEnabled,
+		"lockout_events":     lockouts,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("error encoding user data: %w", err)
+	}
+	return data, 1 + len(roles) + len(identities) + len(lockouts), nil
+}
+
+// eraseLocal cierra las sesiones, borra credenciales y vínculos y anonimiza la fila del usuario
+// Los eventos de auditoría de bloqueos se conservan (referencian solo el ID)
+func (s *PrivacyServiceImpl) eraseLocal(ctx context.Context, userID uint) (int, error) {
+	if err := s.sessions.RevokeAllForUser(ctx, userID); err != nil {
+		return 0, err
+	}
+	for _, purpose := range []string{domain.TokenPurposePasswordReset, domain.TokenPurposeEmailVerification, domain.TokenPurposeOIDCLogin} {
+		if err := s.tokens.InvalidatePending(ctx, userID, purpose); err != nil {
+			return 0, err
+		}
+	}
+	if err := s.twoFactor.Delete(ctx, userID); err != nil {
+		return 0, err
+	}
+	identities, err := s.identities.DeleteByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	roles, err := s.rolesRepo.ListUserRoles(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	for _, role := range roles {
+		if err := s.rolesRepo.RemoveUserRole(ctx, userID, role.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := s.usersRepo.Anonymize(ctx, userID); err != nil {
+		return 0, err
+	}
+	return 1 + int(identities) + len(roles), nil
+}
+
+// publish envía el pedido al resto de los servicios
+// El publisher escribe en la outbox: si RabbitMQ no está disponible se publica cuando vuelva
+func (s *PrivacyServiceImpl) publish(request domain.PrivacyRequest) {
+	err := s.publisher.PublishEvent(PrivacyEventType, request.Tipo+"_requested", strconv.FormatUint(uint64(request.ID), 10), map[string]interface{}{
+		"usuario_id": strconv.FormatUint(uint64(request.UsuarioID), 10),
+	})
+	if err != nil {
+		log.Printf("⚠️  Privacy request #%d not published: %v", request.ID, err)
+	}
+}
+
+// refreshStatus recalcula el estado del pedido a partir de los acuses recibidos
+func (s *PrivacyServiceImpl) refreshStatus(ctx context.Context, id uint) (domain.PrivacyRequest, error) {
+	request, err := s.requests.GetByID(ctx, id)
+	if err != nil {
+		return domain.PrivacyRequest{}, err
+	}
+	request.Pending = pendingServices(request)
+
+	estado := domain.PrivacyStatusPending
+	for _, ack := range request.Acks {
+		if ack.Estado == domain.PrivacyStatusFailed {
+			estado = domain.PrivacyStatusFailed
+		}
+	}
+	if estado == domain.PrivacyStatusPending && len(request.Pending) == 0 {
+		estado = domain.PrivacyStatusCompleted
+	}
+	if estado == request.Estado {
+		return request, nil
+	}
+
+	var completedAt *time.Time
+	if estado == domain.PrivacyStatusCompleted {
+		now := time.Now()
+		completedAt = &now
+	}
+	if err := s.requests.UpdateStatus(ctx, id, estado, completedAt); err != nil {
+		return domain.PrivacyRequest{}, err
+	}
+	request.Estado = estado
+	request.CompletedAt = completedAt
+	return request, nil
+}
+
+// pendingServices devuelve los servicios que todavía no confirmaron el pedido
+func pendingServices(request domain.PrivacyRequest) []string {
+	pending := []string{}
+	for _, service := range request.Services {
+		if _, ok := findAck(request, service); !ok {
+			pending = append(pending, service)
+		}
+	}
+	return pending
+}
+
+func findAck(request domain.PrivacyRequest, service string) (domain.PrivacyAck, bool) {
+	for _, ack := range request.Acks {
+		if ack.Service == service {
+			return ack, true
+		}
+	}
+	return domain.PrivacyAck{}, false
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
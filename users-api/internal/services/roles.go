@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+)
+
+// RolesService define la interfaz del servicio de roles y permisos
+type RolesService interface {
+	SeedDefaultRoles(ctx context.Context) error
+	ListRoles(ctx context.Context) ([]domain.Role, error)
+	CreateRole(ctx context.Context, roleCreate domain.RoleCreate) (domain.Role, error)
+	UpdateRole(ctx context.Context, id uint, roleUpdate domain.RoleUpdate) (domain.Role, error)
+	DeleteRole(ctx context.Context, id uint) error
+	ListUserRoles(ctx context.Context, userID uint) ([]domain.UserRole, error)
+	AssignRole(ctx context.Context, userID uint, assign domain.UserRoleAssign) (domain.UserRole, error)
+	RemoveUserRole(ctx context.Context, userID, assignmentID uint) error
+}
+
+// RolesServiceImpl implementa RolesService
+type RolesServiceImpl struct {
+	rolesRepo repository.RolesRepository
+	usersRepo repository.UsersRepository
+	sessions  SessionsService
+}
+
+// NewRolesService crea una nueva instancia del servicio
+// Dependency Injection: recibe los repositories y el servicio de sesiones (para revocar tokens con permisos viejos)
+func NewRolesService(rolesRepo repository.RolesRepository, usersRepo repository.UsersRepository, sessions SessionsService) *RolesServiceImpl {
+	return &RolesServiceImpl{
+		rolesRepo: rolesRepo,
+		usersRepo: usersRepo,
+		sessions:  sessions,
+	}
+}
+
+// SeedDefaultRoles crea los roles predefinidos que falten
+func (s *RolesServiceImpl) SeedDefaultRoles(ctx context.Context) error {
+	return s.rolesRepo.EnsureRoles(ctx, domain.DefaultRoles)
+}
+
+// ListRoles obtiene todos los roles
+func (s *RolesServiceImpl) ListRoles(ctx context.Context) ([]domain.Role, error) {
+	return s.rolesRepo.List(ctx)
+}
+
+// CreateRole crea un rol personalizado
+func (s *RolesServiceImpl) CreateRole(ctx context.Context, roleCreate domain.RoleCreate) (domain.Role, error) {
+	nombre := strings.TrimSpace(roleCreate.Nombre)
+	if nombre == "" {
+		return domain.Role{}, errors.New("nombre is required")
+	}
+	if len(nombre) > 50 {
+		return domain.Role{}, errors.New("nombre must be at most 50 characters")
+	}
+
+	permissions, err := normalizePermissions(roleCreate.Permissions)
+	if err != nil {
+		return domain.Role{}, err
+	}
+
+	return s.rolesRepo.Create(ctx, domain.Role{
+		Nombre:      nombre,
+		Descripcion: roleCreate.Descripcion,
+		Permissions: permissions,
+	})
+}
+
+// UpdateRole modifica la descripción y/o los permisos de un rol
+// Los usuarios con ese rol deben volver a loguearse para recibir los permisos nuevos
+func (s *RolesServiceImpl) UpdateRole(ctx context.Context, id uint, roleUpdate domain.RoleUpdate) (domain.Role, error) {
+	role, err := s.rolesRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Role{}, err
+	}
+
+	if roleUpdate.Descripcion != nil {
+		role.Descripcion = *roleUpdate.Descripcion
+	}
+
+	permissionsChanged := false
+	if roleUpdate.Permissions != nil {
+		if role.Nombre == domain.RoleAdmin {
+			return domain.Role{}, errors.New("cannot modify admin role permissions")
+		}
+		permissions, err := normalizePermissions(roleUpdate.Permissions)
+		if err != nil {
+			return domain.Role{}, err
+		}
+		role.Permissions = permissions
+		permissionsChanged = true
+	}
+
+	updated, err := s.rolesRepo.Update(ctx, id, role)
+	if err != nil {
+		return domain.Role{}, err
+	}
+
+	if permissionsChanged {
+		s.revokeRoleSessions(ctx, id)
+	}
+
+	return updated, nil
+}
+
+// DeleteRole elimina un rol personalizado (los predefinidos no se pueden eliminar)
+func (s *RolesServiceImpl) DeleteRole(ctx context.Context, id uint) error {
+	role, err := s.rolesRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return errors.New("cannot delete built-in role")
+	}
+
+	// Obtener los usuarios antes de que se borren las asignaciones en cascada
+	userIDs, err := s.rolesRepo.ListRoleUsers(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.rolesRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		s.revokeUserSessions(ctx, userID)
+	}
+
+	return nil
+}
+
+// ListUserRoles obtiene los roles asignados a un usuario
+func (s *RolesServiceImpl) ListUserRoles(ctx context.Context, userID uint) ([]domain.UserRole, error) {
+	if _, err := s.usersRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.rolesRepo.ListUserRoles(ctx, userID)
+}
+
+// AssignRole asigna un rol a un usuario; los roles con permisos ":sucursal" requieren sucursal_id
+func (s *RolesServiceImpl) AssignRole(ctx context.Context, userID uint, assign domain.UserRoleAssign) (domain.UserRole, error) {
+	if _, err := s.usersRepo.GetByID(ctx, userID); err != nil {
+		return domain.UserRole{}, err
+	}
+
+	role, err := s.rolesRepo.GetByName(ctx, assign.Role)
+	if err != nil {
+		return domain.UserRole{}, err
+	}
+
+	if role.HasScopedPermissions() && assign.SucursalID == nil {
+		return domain.UserRole{}, fmt.Errorf("sucursal_id is required for role %s", role.Nombre)
+	}
+
+	assignment, err := s.rolesRepo.AssignRole(ctx, domain.UserRole{
+		UsuarioID:  userID,
+		RoleID:     role.ID,
+		SucursalID: assign.SucursalID,
+	})
+	if err != nil {
+		return domain.UserRole{}, err
+	}
+
+	s.revokeUserSessions(ctx, userID)
+	return assignment, nil
+}
+
+// RemoveUserRole quita una asignación de rol
+func (s *RolesServiceImpl) RemoveUserRole(ctx context.Context, userID, assignmentID uint) error {
+	if err := s.rolesRepo.RemoveUserRole(ctx, userID, assignmentID); err != nil {
+		return err
+	}
+
+	s.revokeUserSessions(ctx, userID)
+	return nil
+}
+
+// revokeRoleSessions revoca las sesiones de todos los usuarios con el rol
+func (s *RolesServiceImpl) revokeRoleSessions(ctx context.Context, roleID uint) {
+	userIDs, err := s.rolesRepo.ListRoleUsers(ctx, roleID)
+	if err != nil {
+		log.Printf("⚠️  Error listing users of role %d: %v", roleID, err)
+		return
+	}
+	for _, userID := range userIDs {
+		s.revokeUserSessions(ctx, userID)
+	}
+}
+
+// revokeUserSessions fuerza un nuevo login para que el JWT refleje los permisos actuales
+func (s *RolesServiceImpl) revokeUserSessions(ctx context.Context, userID uint) {
+	if err := s.sessions.RevokeAllForUser(ctx, userID); err != nil {
+		log.Printf("⚠️  Error revoking sessions for user %d after role change: %v", userID, err)
+	}
+}
+
+// resolveAuthorization calcula los roles, permisos y sucursales efectivos de un usuario
+// is_admin se mantiene como atajo del rol admin (usuarios previos a RBAC)
+func resolveAuthorization(ctx context.Context, rolesRepo repository.RolesRepository, user domain.User) (domain.Authorization, error) {
+	assignments, err := rolesRepo.ListUserRoles(ctx, user.ID)
+	if err != nil {
+		return domain.Authorization{}, err
+	}
+
+	roles := make(map[string]bool)
+	permissions := make(map[string]bool)
+	sucursales := make(map[uint]bool)
+
+	if user.IsAdmin {
+		roles[domain.RoleAdmin] = true
+		permissions[domain.PermissionAll] = true
+	}
+
+	for _, a := range assignments {
+		roles[a.Role] = true
+		for _, p := range a.Permissions {
+			permissions[p] = true
+		}
+		if a.SucursalID != nil {
+			sucursales[*a.SucursalID] = true
+		}
+	}
+
+	auth := domain.Authorization{
+		Roles:       sortedKeys(roles),
+		Permissions: sortedKeys(permissions),
+		SucursalIDs: make([]uint, 0, len(sucursales)),
+	}
+	for id := range sucursales {
+		auth.SucursalIDs = append(auth.SucursalIDs, id)
+	}
+	sort.Slice(auth.SucursalIDs, func(i, j int) bool { return auth.SucursalIDs[i] < auth.SucursalIDs[j] })
+
+	return auth, nil
+}
+
+// normalizePermissions valida contra el catálogo y elimina duplicados
+func normalizePermissions(permissions []string) ([]string, error) {
+	set := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		p = strings.TrimSpace(p)
+		if !domain.IsKnownPermission(p) {
+			return nil, fmt.Errorf("invalid permission: %s", p)
+		}
+		set[p] = true
+	}
+	if len(set) == 0 {
+		return nil, errors.New("permissions is required")
+	}
+
+	return sortedKeys(set), nil
+}
+
+// sortedKeys devuelve las claves del set ordenadas
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+	"users-api/internal/security"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// SessionsService define la interfaz del servicio de sesiones (access + refresh tokens)
+type SessionsService interface {
+	IssueTokens(ctx context.Context, user domain.User) (domain.AuthTokens, error)
+	Refresh(ctx context.Context, refreshToken string) (domain.UserResponse, domain.AuthTokens, error)
+	Logout(ctx context.Context, sessionID string) error
+	RevokeAllForUser(ctx context.Context, userID uint) error
+	ValidateToken(ctx context.Context, tokenString string) (jwt.MapClaims, error)
+	GetSessionStatus(ctx context.Context, sessionID string) (domain.SessionStatus, error)
+	JWKS() security.JWKS
+}
+
+// SessionsServiceImpl implementa SessionsService
+type SessionsServiceImpl struct {
+	sessionsRepo    repository.SessionsRepository
+	usersRepo       repository.UsersRepository
+	rolesRepo       repository.RolesRepository
+	keys            *security.KeySet
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+}
+
+// NewSessionsService crea una nueva instancia del servicio
+// Dependency Injection: recibe los repositories de sesiones, usuarios y roles y las claves de firma
+func NewSessionsService(sessionsRepo repository.SessionsRepository, usersRepo repository.UsersRepository, rolesRepo repository.RolesRepository, keys *security.KeySet, accessTokenTTL, refreshTokenTTL time.Duration) *SessionsServiceImpl {
+	return &SessionsServiceImpl{
+		sessionsRepo:    sessionsRepo,
+		usersRepo:       usersRepo,
+		rolesRepo:       rolesRepo,
+		keys:            keys,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+// IssueTokens inicia una nueva sesión (familia de refresh tokens) para el usuario
+func (s *SessionsServiceImpl) IssueTokens(ctx context.Context, user domain.User) (domain.AuthTokens, error) {
+	familyID, err := randomHex(16)
+	if err != nil {
+		return domain.AuthTokens{}, fmt.Errorf("error generating session id: %w", err)
+	}
+
+	refreshToken, refreshHash, err := s.newRefreshToken()
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	_, err = s.sessionsRepo.Create(ctx, domain.RefreshToken{
+		FamilyID:  familyID,
+		UsuarioID: user.ID,
+		TokenHash: refreshHash,
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
+	})
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	accessToken, err := s.generateAccessToken(ctx, user, familyID)
+	if err != nil {
+		return domain.AuthTokens{}, fmt.Errorf("error generating token: %w", err)
+	}
+
+	return domain.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// Refresh rota el refresh token y emite un nuevo access token para la misma sesión
+// Si el token ya había sido usado se revoca toda la sesión (detección de reuso)
+// El token nuevo hereda el vencimiento del anterior: la sesión dura como máximo refreshTokenTTL desde el login
+func (s *SessionsServiceImpl) Refresh(ctx context.Context, refreshToken string) (domain.UserResponse, domain.AuthTokens, error) {
+	stored, err := s.sessionsRepo.GetByTokenHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, errors.New("invalid refresh token")
+	}
+
+	if stored.RevokedAt != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, errors.New("session revoked")
+	}
+	if stored.UsedAt != nil {
+		s.revokeOnReuse(ctx, stored)
+		return domain.UserResponse{}, domain.AuthTokens{}, errors.New("refresh token reuse detected")
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return domain.UserResponse{}, domain.AuthTokens{}, errors.New("refresh token expired")
+	}
+
+	// Releer el usuario para que el nuevo token refleje cambios (ej: is_admin, roles)
+	user, err := s.usersRepo.GetByID(ctx, stored.UsuarioID)
+	if err != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, errors.New("invalid refresh token")
+	}
+
+	newRefreshToken, newRefreshHash, err := s.newRefreshToken()
+	if err != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, err
+	}
+
+	_, err = s.sessionsRepo.Rotate(ctx, stored.ID, domain.RefreshToken{
+		FamilyID:  stored.FamilyID,
+		UsuarioID: stored.UsuarioID,
+		TokenHash: newRefreshHash,
+		ExpiresAt: stored.ExpiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenAlreadyUsed) {
+			// Otro request rotó el mismo token en paralelo: tratarlo como reuso
+			s.revokeOnReuse(ctx, stored)
+			return domain.UserResponse{}, domain.AuthTokens{}, errors.New("refresh token reuse detected")
+		}
+		return domain.UserResponse{}, domain.AuthTokens{}, err
+	}
+
+	accessToken, err := s.generateAccessToken(ctx, user, stored.FamilyID)
+	if err != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, fmt.Errorf("error generating token: %w", err)
+	}
+
+	return user.ToResponse(), domain.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// Logout revoca la sesión completa (todos los refresh tokens de la familia)
+func (s *SessionsServiceImpl) Logout(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return errors.New("token has no session")
+	}
+	return s.sessionsRepo.RevokeFamily(ctx, sessionID)
+}
+
+// RevokeAllForUser revoca todas las sesiones del usuario (cambio de contraseña, baja, etc.)
+func (s *SessionsServiceImpl) RevokeAllForUser(ctx context.Context, userID uint) error {
+	return s.sessionsRepo.RevokeAllForUser(ctx, userID)
+}
+
+// ValidateToken valida un token JWT, verifica que su sesión no esté revocada y devuelve los claims
+func (s *SessionsServiceImpl) ValidateToken(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	// Keyfunc resuelve la clave pública por kid y valida que el algoritmo coincida
+	token, err := jwt.Parse(tokenString, s.keys.Keyfunc)
+
+	if err != nil {
+		return nil, fmt.Errorf("error parsing token: %w", err)
+	}
+
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+
+	// Tokens emitidos antes de existir sesiones no tienen "sid" y no son revocables
+	if sid, _ := claims["sid"].(string); sid != "" {
+		revoked, err := s.sessionsRepo.IsFamilyRevoked(ctx, sid)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, errors.New("session revoked")
+		}
+	}
+
+	return claims, nil
+}
+
+// GetSessionStatus indica si una sesión fue revocada (usado por otros microservicios)
+func (s *SessionsServiceImpl) GetSessionStatus(ctx context.Context, sessionID string) (domain.SessionStatus, error) {
+	revoked, err := s.sessionsRepo.IsFamilyRevoked(ctx, sessionID)
+	if err != nil {
+		return domain.SessionStatus{}, err
+	}
+
+	return domain.SessionStatus{SessionID: sessionID, Revoked: revoked}, nil
+}
+
+// JWKS devuelve las claves públicas de verificación para otros microservicios
+func (s *SessionsServiceImpl) JWKS() security.JWKS {
+	return s.keys.JWKS()
+}
+
+// generateAccessToken genera un token JWT para el usuario asociado a la sesión
+// Incluye roles, permisos y sucursales para que cada microservicio autorice sin consultar users-api
+func (s *SessionsServiceImpl) generateAccessToken(ctx context.Context, user domain.User, sessionID string) (string, error) {
+	auth, err := resolveAuthorization(ctx, s.rolesRepo, user)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":          "gym-management-system",
+		"iat":          now.Unix(),
+		"exp":          now.Add(s.accessTokenTTL).Unix(),
+		"sid":          sessionID,
+		"username":     user.Username,
+		"id_usuario":   user.ID,
+		"is_admin":     user.IsAdmin,
+		"roles":        auth.Roles,
+		"permissions":  auth.Permissions,
+		"sucursal_ids": auth.SucursalIDs,
+	}
+
+	return s.keys.Sign(claims)
+}
+
+// newRefreshToken genera un refresh token opaco y su hash para persistir
+func (s *SessionsServiceImpl) newRefreshToken() (string, string, error) {
+	token, hash, err := newOpaqueToken()
+	if err != nil {
+		return "", "", fmt.Errorf("error generating refresh token: %w", err)
+	}
+	return token, hash, nil
+}
+
+// revokeOnReuse revoca la sesión cuando se presenta un refresh token ya rotado
+func (s *SessionsServiceImpl) revokeOnReuse(ctx context.Context, stored domain.RefreshToken) {
+	log.Printf("🚨 Refresh token reuse detected (user %d, session %s): revoking session", stored.UsuarioID, stored.FamilyID)
+	if err := s.sessionsRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+		log.Printf("❌ Error revoking session %s: %v", stored.FamilyID, err)
+	}
+}
+
+// newOpaqueToken genera un token aleatorio de 256 bits (base64url) y su hash
+func newOpaqueToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+// hashToken devuelve el hash SHA-256 (hex) de un token opaco
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex devuelve n bytes aleatorios codificados en hex
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
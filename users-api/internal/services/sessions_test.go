@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+	"users-api/internal/security"
+)
+
+// fakeSessions guarda los refresh tokens en memoria
+type fakeSessions struct {
+	repository.SessionsRepository
+	tokens []domain.RefreshToken
+}
+
+func (f *fakeSessions) Create(ctx context.Context, token domain.RefreshToken) (domain.RefreshToken, error) {
+	token.ID = uint(len(f.tokens) + 1)
+	f.tokens = append(f.tokens, token)
+	return token, nil
+}
+
+func (f *fakeSessions) GetByTokenHash(ctx context.Context, tokenHash string) (domain.RefreshToken, error) {
+	for _, token := range f.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return domain.RefreshToken{}, errors.New("refresh token not found")
+}
+
+func (f *fakeSessions) Rotate(ctx context.Context, oldID uint, newToken domain.RefreshToken) (domain.RefreshToken, error) {
+	now := time.Now()
+	f.tokens[oldID-1].UsedAt = &now
+	return f.Create(ctx, newToken)
+}
+
+// fakeSessionUsers devuelve siempre el mismo usuario
+type fakeSessionUsers struct {
+	repository.UsersRepository
+	user domain.User
+}
+
+func (f *fakeSessionUsers) GetByID(ctx context.Context, id uint) (domain.User, error) {
+	return f.user, nil
+}
+
+// fakeRoles no asigna roles extra
+type fakeRoles struct {
+	repository.RolesRepository
+}
+
+func (fakeRoles) ListUserRoles(ctx context.Context, usuarioID uint) ([]domain.UserRole, error) {
+	return nil, nil
+}
+
+// TestSessionsRefreshKeepsFamilyExpiry verifica que rotar el refresh token no extiende la sesión
+func TestSessionsRefreshKeepsFamilyExpiry(t *testing.T) {
+	keys, err := security.NewEphemeralKeySet(security.SigningAlgEdDSA)
+	if err != nil {
+		t.Fatalf("error generando claves: %v", err)
+	}
+	sessions := &fakeSessions{}
+	user := domain.User{ID: 1, Username: "socio"}
+	s := NewSessionsService(sessions, &fakeSessionUsers{user: user}, fakeRoles{}, keys, time.Minute, time.Hour)
+
+	ctx := context.Background()
+	tokens, err := s.IssueTokens(ctx, user)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	familyExpiry := sessions.tokens[0].ExpiresAt
+
+	refreshToken := tokens.RefreshToken
+	for i := 1; i <= 3; i++ {
+		_, rotated, err := s.Refresh(ctx, refreshToken)
+		if err != nil {
+			t.Fatalf("rotación %d: Refresh() error = %v", i, err)
+		}
+		refreshToken = rotated.RefreshToken
+
+		last := sessions.tokens[len(sessions.tokens)-1]
+		if !last.ExpiresAt.Equal(familyExpiry) || last.FamilyID != sessions.tokens[0].FamilyID {
+			t.Fatalf("rotación %d: expires_at = %s (familia %s), se esperaba %s (familia %s)",
+				i, last.ExpiresAt, last.FamilyID, familyExpiry, sessions.tokens[0].FamilyID)
+		}
+	}
+
+	// Al llegar al vencimiento de la familia el último token ya no rota
+	for i := range sessions.tokens {
+		sessions.tokens[i].ExpiresAt = time.Now().Add(-time.Second)
+	}
+	if _, _, err := s.Refresh(ctx, refreshToken); err == nil || err.Error() != "refresh token expired" {
+		t.Fatalf("Refresh() error = %v, se esperaba refresh token expired", err)
+	}
+}
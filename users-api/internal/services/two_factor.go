@@ -0,0 +1,494 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+	"users-api/internal/security"
+)
+
+// recoveryCodesCount es la cantidad de códigos de recuperación que se entregan al activar 2FA
+const recoveryCodesCount = 10
+
+// recoveryCodeAlphabet evita caracteres ambiguos (0/o, 1/l/i)
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// TwoFactorPolicy define la política de segundo factor
+type TwoFactorPolicy struct {
+	RequiredForAdmins    bool          // Exige 2FA a todo usuario con IsAdmin
+	Issuer               string        // Nombre que muestra la app autenticadora
+	ChallengeTTL         time.Duration // Vida del challenge entre el password y el código
+	MaxChallengeAttempts int           // Códigos incorrectos permitidos por challenge
+}
+
+// TwoFactorService define la interfaz del servicio de autenticación en dos pasos (TOTP)
+type TwoFactorService interface {
+	Status(ctx context.Context, userID uint) (domain.TwoFactorStatus, error)
+	BeginSetup(ctx context.Context, userID uint) (domain.TwoFactorSetup, error)
+	Enable(ctx context.Context, userID uint, code string) ([]string, error)
+	Disable(ctx context.Context, userID uint, req domain.TwoFactorDisableRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error)
+	Reset(ctx context.Context, actorID, userID uint) error
+
+	// Login en dos pasos
+	StartLogin(ctx context.Context, user domain.User) (*domain.LoginChallengeResponse, error)
+	CompleteLogin(ctx context.Context, req domain.TwoFactorLoginRequest, clientIP string) (domain.UserResponse, domain.AuthTokens, error)
+	BeginChallengeSetup(ctx context.Context, challenge string) (domain.TwoFactorSetup, error)
+	ConfirmChallengeSetup(ctx context.Context, req domain.TwoFactorLoginRequest, clientIP string) (domain.UserResponse, domain.AuthTokens, []string, error)
+}
+
+// TwoFactorServiceImpl implementa TwoFactorService
+type TwoFactorServiceImpl struct {
+	usersRepo  repository.UsersRepository
+	repo       repository.TwoFactorRepository
+	challenges repository.LoginChallengesRepository
+	hasher     security.PasswordHasher
+	sessions   SessionsService
+	protection LoginProtectionService
+	secrets    *security.SecretBox
+	policy     TwoFactorPolicy
+}
+
+// NewTwoFactorService crea una nueva instancia del servicio
+// Dependency Injection: recibe los repositories, el hasher, los servicios de sesiones y
+// protección de login, y el SecretBox que cifra los secretos TOTP en reposo
+func NewTwoFactorService(usersRepo repository.UsersRepository, repo repository.TwoFactorRepository, challenges repository.LoginChallengesRepository, hasher security.PasswordHasher, sessions SessionsService, protection LoginProtectionService, secrets *security.SecretBox, policy TwoFactorPolicy) *TwoFactorServiceImpl {
+	return &TwoFactorServiceImpl{
+		usersRepo:  usersRepo,
+		repo:       repo,
+		challenges: challenges,
+		hasher:     hasher,
+		sessions:   sessions,
+		protection: protection,
+		secrets:    secrets,
+		policy:     policy,
+	}
+}
+
+// Status devuelve si el usuario tiene 2FA activo y si la política se lo exige
+func (s *TwoFactorServiceImpl) Status(ctx context.Context, userID uint) (domain.TwoFactorStatus, error) {
+	user, err := s.usersRepo.GetByID(ctx, userID)
+	if err != nil {
+		return domain.TwoFactorStatus{}, err
+	}
+
+	status := domain.TwoFactorStatus{Required: s.required(user)}
+
+	twoFactor, err := s.getEnabled(ctx, userID)
+	if err != nil {
+		if err.Error() == "two factor not enabled" {
+			return status, nil
+		}
+		return domain.TwoFactorStatus{}, err
+	}
+
+	remaining, err := s.repo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return domain.TwoFactorStatus{}, err
+	}
+
+	status.Enabled = true
+	status.EnabledAt = twoFactor.EnabledAt
+	status.RecoveryCodesRemaining = remaining
+	return status, nil
+}
+
+// BeginSetup genera un secreto nuevo sin confirmar; 2FA se activa recién con Enable
+func (s *TwoFactorServiceImpl) BeginSetup(ctx context.Context, userID uint) (domain.TwoFactorSetup, error) {
+	user, err := s.usersRepo.GetByID(ctx, userID)
+	if err != nil {
+		return domain.TwoFactorSetup{}, err
+	}
+
+	return s.beginSetup(ctx, user)
+}
+
+// Enable confirma el enrolamiento con un código de la app y devuelve los códigos de recuperación
+// Los códigos se muestran una única vez: solo se guarda su hash
+func (s *TwoFactorServiceImpl) Enable(ctx context.Context, userID uint, code string) ([]string, error) {
+	step, err := s.verifyPendingCode(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Enable(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔐 Two factor enabled for user %d", userID)
+	return codes, nil
+}
+
+// Disable desactiva 2FA; requiere contraseña y un código vigente
+// No se permite si la política lo exige para el usuario (admins)
+func (s *TwoFactorServiceImpl) Disable(ctx context.Context, userID uint, req domain.TwoFactorDisableRequest) error {
+	user, err := s.usersRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.required(user) {
+		return errors.New("two factor is required for admin accounts")
+	}
+
+	ok, err := s.hasher.Verify(req.Password, user.Password)
+	if err != nil || !ok {
+		return errors.New("current password is incorrect")
+	}
+
+	twoFactor, err := s.getEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyCode(ctx, twoFactor, req.Code); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	log.Printf("🔓 Two factor disabled for user %d", userID)
+	return nil
+}
+
+// RegenerateRecoveryCodes reemplaza los códigos de recuperación (los anteriores dejan de valer)
+func (s *TwoFactorServiceImpl) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	twoFactor, err := s.getEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyCode(ctx, twoFactor, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Reset elimina el 2FA de un usuario que perdió su dispositivo y sus códigos (acción de admin)
+// Revoca sus sesiones: el próximo login vuelve a pedir enrolamiento si la política lo exige
+func (s *TwoFactorServiceImpl) Reset(ctx context.Context, actorID, userID uint) error {
+	if _, err := s.usersRepo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.sessions.RevokeAllForUser(ctx, userID); err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	log.Printf("🔓 Two factor reset for user %d by user %d", userID, actorID)
+	return nil
+}
+
+// StartLogin decide si el login (con contraseña ya verificada) necesita un segundo paso
+// Devuelve nil si se pueden emitir los tokens directamente
+func (s *TwoFactorServiceImpl) StartLogin(ctx context.Context, user domain.User) (*domain.LoginChallengeResponse, error) {
+	_, err := s.getEnabled(ctx, user.ID)
+	switch {
+	case err == nil:
+		return s.newChallenge(ctx, user.ID, domain.ChallengePurposeVerify)
+	case err.Error() != "two factor not enabled":
+		return nil, err
+	case s.required(user):
+		return s.newChallenge(ctx, user.ID, domain.ChallengePurposeSetup)
+	default:
+		return nil, nil
+	}
+}
+
+// CompleteLogin verifica el código TOTP (o de recuperación) del challenge y emite los tokens
+func (s *TwoFactorServiceImpl) CompleteLogin(ctx context.Context, req domain.TwoFactorLoginRequest, clientIP string) (domain.UserResponse, domain.AuthTokens, error) {
+	challenge, user, err := s.loadChallenge(ctx, req.Challenge, domain.ChallengePurposeVerify, clientIP)
+	if err != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, err
+	}
+
+	twoFactor, err := s.getEnabled(ctx, user.ID)
+	if err != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, errors.New("invalid or expired challenge")
+	}
+	if err := s.verifyCode(ctx, twoFactor, req.Code); err != nil {
+		s.recordChallengeFailure(ctx, challenge, user, clientIP)
+		return domain.UserResponse{}, domain.AuthTokens{}, err
+	}
+
+	tokens, err := s.finishLogin(ctx, challenge, user)
+	if err != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, err
+	}
+
+	return user.ToResponse(), tokens, nil
+}
+
+// BeginChallengeSetup genera el secreto para un usuario al que la política le exige 2FA
+// y todavía no lo configuró (no tiene token para usar POST /users/me/2fa/setup)
+func (s *TwoFactorServiceImpl) BeginChallengeSetup(ctx context.Context, challenge string) (domain.TwoFactorSetup, error) {
+	_, user, err := s.loadChallenge(ctx, challenge, domain.ChallengePurposeSetup, "")
+	if err != nil {
+		return domain.TwoFactorSetup{}, err
+	}
+
+	return s.beginSetup(ctx, user)
+}
+
+// ConfirmChallengeSetup activa 2FA con el primer código y completa el login
+func (s *TwoFactorServiceImpl) ConfirmChallengeSetup(ctx context.Context, req domain.TwoFactorLoginRequest, clientIP string) (domain.UserResponse, domain.AuthTokens, []string, error) {
+	challenge, user, err := s.loadChallenge(ctx, req.Challenge, domain.ChallengePurposeSetup, clientIP)
+	if err != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, nil, err
+	}
+
+	step, err := s.verifyPendingCode(ctx, user.ID, req.Code)
+	if err != nil {
+		if err.Error() == "invalid two factor code" {
+			s.recordChallengeFailure(ctx, challenge, user, clientIP)
+		}
+		return domain.UserResponse{}, domain.AuthTokens{}, nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, nil, err
+	}
+	if err := s.repo.Enable(ctx, user.ID, step, hashes); err != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, nil, err
+	}
+	log.Printf("🔐 Two factor enabled for user %d (required by policy)", user.ID)
+
+	tokens, err := s.finishLogin(ctx, challenge, user)
+	if err != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, nil, err
+	}
+
+	return user.ToResponse(), tokens, codes, nil
+}
+
+// required indica si la política exige 2FA al usuario
+func (s *TwoFactorServiceImpl) required(user domain.User) bool {
+	return s.policy.RequiredForAdmins && user.IsAdmin
+}
+
+// getEnabled obtiene la configuración 2FA confirmada del usuario
+func (s *TwoFactorServiceImpl) getEnabled(ctx context.Context, userID uint) (domain.TwoFactor, error) {
+	twoFactor, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		if err.Error() == "two factor not configured" {
+			return domain.TwoFactor{}, errors.New("two factor not enabled")
+		}
+		return domain.TwoFactor{}, err
+	}
+	if !twoFactor.IsEnabled() {
+		return domain.TwoFactor{}, errors.New("two factor not enabled")
+	}
+
+	return twoFactor, nil
+}
+
+// beginSetup guarda un secreto pendiente y arma la URI de provisión
+func (s *TwoFactorServiceImpl) beginSetup(ctx context.Context, user domain.User) (domain.TwoFactorSetup, error) {
+	if _, err := s.getEnabled(ctx, user.ID); err == nil {
+		return domain.TwoFactorSetup{}, errors.New("two factor already enabled")
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		return domain.TwoFactorSetup{}, err
+	}
+	sealed, err := s.secrets.Seal(secret)
+	if err != nil {
+		return domain.TwoFactorSetup{}, fmt.Errorf("error encrypting totp secret: %w", err)
+	}
+	if err := s.repo.SavePending(ctx, user.ID, sealed); err != nil {
+		return domain.TwoFactorSetup{}, err
+	}
+
+	return domain.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: security.TOTPProvisioningURI(s.policy.Issuer, user.Email, secret),
+	}, nil
+}
+
+// verifyPendingCode valida el primer código contra el secreto pendiente de confirmar
+func (s *TwoFactorServiceImpl) verifyPendingCode(ctx context.Context, userID uint, code string) (int64, error) {
+	twoFactor, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		if err.Error() == "two factor not configured" {
+			return 0, errors.New("two factor setup not started")
+		}
+		return 0, err
+	}
+	if twoFactor.IsEnabled() {
+		return 0, errors.New("two factor already enabled")
+	}
+
+	secret, err := s.secrets.Open(twoFactor.Secret)
+	if err != nil {
+		return 0, fmt.Errorf("error decrypting totp secret: %w", err)
+	}
+	step, ok := security.ValidateTOTP(secret, code, time.Now(), 1)
+	if !ok {
+		return 0, errors.New("invalid two factor code")
+	}
+
+	return step, nil
+}
+
+// verifyCode acepta un código TOTP de 6 dígitos (una sola vez por paso) o un código de recuperación
+func (s *TwoFactorServiceImpl) verifyCode(ctx context.Context, twoFactor domain.TwoFactor, code string) error {
+	code = strings.TrimSpace(code)
+
+	if len(code) == security.TOTPDigits {
+		secret, err := s.secrets.Open(twoFactor.Secret)
+		if err != nil {
+			return fmt.Errorf("error decrypting totp secret: %w", err)
+		}
+		step, ok := security.ValidateTOTP(secret, code, time.Now(), 1)
+		if !ok {
+			return errors.New("invalid two factor code")
+		}
+		fresh, err := s.repo.UseStep(ctx, twoFactor.UsuarioID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return errors.New("invalid two factor code")
+		}
+		return nil
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, twoFactor.UsuarioID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.New("invalid two factor code")
+	}
+
+	log.Printf("🔑 Recovery code used by user %d", twoFactor.UsuarioID)
+	return nil
+}
+
+// newChallenge crea un challenge de un solo uso para el segundo paso del login
+func (s *TwoFactorServiceImpl) newChallenge(ctx context.Context, userID uint, purpose string) (*domain.LoginChallengeResponse, error) {
+	value, hash, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("error generating challenge: %w", err)
+	}
+
+	_, err = s.challenges.Create(ctx, domain.LoginChallenge{
+		UsuarioID:     userID,
+		Purpose:       purpose,
+		ChallengeHash: hash,
+		ExpiresAt:     time.Now().Add(s.policy.ChallengeTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.LoginChallengeResponse{
+		TwoFactorRequired:      purpose == domain.ChallengePurposeVerify,
+		TwoFactorSetupRequired: purpose == domain.ChallengePurposeSetup,
+		Challenge:              value,
+		ExpiresIn:              int64(s.policy.ChallengeTTL.Seconds()),
+	}, nil
+}
+
+// loadChallenge valida el challenge (vigente, del propósito esperado y con intentos disponibles)
+// y aplica la protección contra fuerza bruta de la cuenta si se conoce la IP
+func (s *TwoFactorServiceImpl) loadChallenge(ctx context.Context, value, purpose, clientIP string) (domain.LoginChallenge, domain.User, error) {
+	challenge, err := s.challenges.GetActive(ctx, hashToken(value))
+	if err != nil {
+		return domain.LoginChallenge{}, domain.User{}, err
+	}
+	if challenge.Purpose != purpose || challenge.Attempts >= s.policy.MaxChallengeAttempts {
+		return domain.LoginChallenge{}, domain.User{}, errors.New("invalid or expired challenge")
+	}
+
+	if clientIP != "" {
+		if err := s.protection.Check(ctx, &challenge.UsuarioID, "", clientIP); err != nil {
+			return domain.LoginChallenge{}, domain.User{}, err
+		}
+	}
+
+	user, err := s.usersRepo.GetByID(ctx, challenge.UsuarioID)
+	if err != nil {
+		return domain.LoginChallenge{}, domain.User{}, errors.New("invalid or expired challenge")
+	}
+
+	return challenge, user, nil
+}
+
+// recordChallengeFailure cuenta el código incorrecto en el challenge y en la protección de login
+func (s *TwoFactorServiceImpl) recordChallengeFailure(ctx context.Context, challenge domain.LoginChallenge, user domain.User, clientIP string) {
+	if err := s.challenges.IncrementAttempts(ctx, challenge.ID); err != nil {
+		log.Printf("⚠️  Could not update login challenge %d: %v", challenge.ID, err)
+	}
+	s.protection.RecordFailure(ctx, &user.ID, "", clientIP)
+}
+
+// finishLogin consume el challenge (un solo uso) y emite los tokens
+func (s *TwoFactorServiceImpl) finishLogin(ctx context.Context, challenge domain.LoginChallenge, user domain.User) (domain.AuthTokens, error) {
+	consumed, err := s.challenges.Consume(ctx, challenge.ID)
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+	if !consumed {
+		return domain.AuthTokens{}, errors.New("invalid or expired challenge")
+	}
+
+	s.protection.RecordSuccess(ctx, &user.ID, "")
+
+	tokens, err := s.sessions.IssueTokens(ctx, user)
+	if err != nil {
+		return domain.AuthTokens{}, fmt.Errorf("error generating token: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// generateRecoveryCodes genera los códigos de recuperación (formato xxxxx-xxxxx) y sus hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+
+	buf := make([]byte, 10)
+	for i := 0; i < recoveryCodesCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("error generating recovery codes: %w", err)
+		}
+		raw := make([]byte, len(buf))
+		for j, b := range buf {
+			raw[j] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+		}
+		code := string(raw[:5]) + "-" + string(raw[5:])
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode ignora mayúsculas, guiones y espacios al comparar códigos de recuperación
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
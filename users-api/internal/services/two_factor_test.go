@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+	"users-api/internal/domain"
+	"users-api/internal/security"
+)
+
+// fakeTwoFactorRepository guarda el último paso usado como la tabla two_factor
+type fakeTwoFactorRepository struct {
+	lastUsedStep int64
+}
+
+func (f *fakeTwoFactorRepository) Get(ctx context.Context, usuarioID uint) (domain.TwoFactor, error) {
+	return domain.TwoFactor{}, nil
+}
+
+func (f *fakeTwoFactorRepository) SavePending(ctx context.Context, usuarioID uint, secret string) error {
+	return nil
+}
+
+func (f *fakeTwoFactorRepository) Enable(ctx context.Context, usuarioID uint, step int64, recoveryCodeHashes []string) error {
+	return nil
+}
+
+// UseStep acepta el paso solo si es posterior al último usado (igual que el UPDATE condicional de MySQL)
+func (f *fakeTwoFactorRepository) UseStep(ctx context.Context, usuarioID uint, step int64) (bool, error) {
+	if step <= f.lastUsedStep {
+		return false, nil
+	}
+	f.lastUsedStep = step
+	return true, nil
+}
+
+func (f *fakeTwoFactorRepository) Delete(ctx context.Context, usuarioID uint) error {
+	return nil
+}
+
+func (f *fakeTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, usuarioID uint, codeHashes []string) error {
+	return nil
+}
+
+func (f *fakeTwoFactorRepository) UseRecoveryCode(ctx context.Context, usuarioID uint, codeHash string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeTwoFactorRepository) CountRecoveryCodes(ctx context.Context, usuarioID uint) (int64, error) {
+	return 0, nil
+}
+
+// totpAt calcula el código TOTP de un paso (RFC 6238, SHA-1, 6 dígitos) como lo haría la app autenticadora
+func totpAt(t *testing.T, secret string, step int64) string {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secreto inválido: %v", err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+// TestTwoFactorVerifyCodeStepReuse verifica que un código TOTP válido se acepta una sola vez
+// y que un código de un paso anterior al último usado se rechaza
+func TestTwoFactorVerifyCodeStepReuse(t *testing.T) {
+	secrets, err := security.NewSecretBox("")
+	if err != nil {
+		t.Fatalf("error creando SecretBox: %v", err)
+	}
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("error generando secreto: %v", err)
+	}
+	// Evitar que el paso cambie a mitad del test
+	if time.Now().Unix()%security.TOTPPeriod >= security.TOTPPeriod-2 {
+		time.Sleep(3 * time.Second)
+	}
+	current := time.Now().Unix() / security.TOTPPeriod
+	twoFactor := domain.TwoFactor{UsuarioID: 1, Secret: secret}
+
+	tests := []struct {
+		name    string
+		code    string
+		wantErr bool
+	}{
+		{"código del paso anterior", totpAt(t, secret, current-1), false},
+		{"mismo código otra vez", totpAt(t, secret, current-1), true},
+		{"código del paso actual", totpAt(t, secret, current), false},
+		{"código anterior después del actual", totpAt(t, secret, current-1), true},
+		{"código actual reusado", totpAt(t, secret, current), true},
+	}
+
+	repo := &fakeTwoFactorRepository{}
+	s := NewTwoFactorService(nil, repo, nil, nil, nil, nil, secrets, TwoFactorPolicy{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.verifyCode(context.Background(), twoFactor, tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyCode() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,52 +2,67 @@ package services
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
 	"regexp"
 	"strings"
-	"time"
 	"users-api/internal/domain"
 	"users-api/internal/repository"
-
-	"github.com/golang-jwt/jwt/v4"
+	"users-api/internal/security"
 )
 
 // UsersService define la interfaz del servicio de usuarios
 type UsersService interface {
-	Register(ctx context.Context, userReg domain.UserRegister) (domain.UserResponse, string, error)
-	Login(ctx context.Context, credentials domain.UserLogin) (domain.UserResponse, string, error)
+	Register(ctx context.Context, userReg domain.UserRegister) (domain.UserResponse, domain.AuthTokens, error)
+	Login(ctx context.Context, credentials domain.UserLogin, clientIP string) (domain.LoginResult, error)
 	GetByID(ctx context.Context, id uint) (domain.UserResponse, error)
-	List(ctx context.Context) ([]domain.UserResponse, error)
-	ValidateToken(tokenString string) (jwt.MapClaims, error)
+	GetByIDs(ctx context.Context, ids []uint) (domain.UsersBatchResponse, error)
+	Exists(ctx context.Context, id uint) (bool, error)
+	List(ctx context.Context, query domain.ListUsersQuery) (domain.PaginatedUsersResponse, error)
+	UpdateProfile(ctx context.Context, id uint, update domain.UserUpdate) (domain.UserResponse, error)
+	AdminUpdate(ctx context.Context, actorID, id uint, update domain.AdminUserUpdate) (domain.UserResponse, error)
+	ChangePassword(ctx context.Context, id uint, change domain.PasswordChange) (domain.AuthTokens, error)
+	Delete(ctx context.Context, id uint) error
+	Restore(ctx context.Context, id uint) (domain.UserResponse, error)
 }
 
 // UsersServiceImpl implementa UsersService
 type UsersServiceImpl struct {
 	repository repository.UsersRepository
-	jwtSecret  string
+	hasher     security.PasswordHasher
+	sessions   SessionsService
+	accounts   AccountService
+	protection LoginProtectionService
+	twoFactor  TwoFactorService
 }
 
 // NewUsersService crea una nueva instancia del servicio
-// Dependency Injection: recibe el repository como parámetro
-func NewUsersService(repo repository.UsersRepository, jwtSecret string) *UsersServiceImpl {
+// Dependency Injection: recibe el repository, el hasher de contraseñas y los servicios de sesiones,
+// cuentas, protección de login y segundo factor
+func NewUsersService(repo repository.UsersRepository, hasher security.PasswordHasher, sessions SessionsService, accounts AccountService, protection LoginProtectionService, twoFactor TwoFactorService) *UsersServiceImpl {
 	return &UsersServiceImpl{
 		repository: repo,
-		jwtSecret:  jwtSecret,
+		hasher:     hasher,
+		sessions:   sessions,
+		accounts:   accounts,
+		protection: protection,
+		twoFactor:  twoFactor,
 	}
 }
 
 // Register registra un nuevo usuario
-func (s *UsersServiceImpl) Register(ctx context.Context, userReg domain.UserRegister) (domain.UserResponse, string, error) {
+func (s *UsersServiceImpl) Register(ctx context.Context, userReg domain.UserRegister) (domain.UserResponse, domain.AuthTokens, error) {
 	// Validaciones de negocio
 	if err := s.validateUserRegistration(userReg); err != nil {
-		return domain.UserResponse{}, "", err
+		return domain.UserResponse{}, domain.AuthTokens{}, err
 	}
 
 	// Hashear password
-	hashedPassword := s.hashPassword(userReg.Password)
+	hashedPassword, err := s.hasher.Hash(userReg.Password)
+	if err != nil {
+		return domain.UserResponse{}, domain.AuthTokens{}, fmt.Errorf("error hashing password: %w", err)
+	}
 
 	// Crear domain user
 	user := domain.User{
@@ -63,39 +78,69 @@ func (s *UsersServiceImpl) Register(ctx context.Context, userReg domain.UserRegi
 	// Guardar en repository
 	createdUser, err := s.repository.Create(ctx, user)
 	if err != nil {
-		return domain.UserResponse{}, "", fmt.Errorf("error creating user: %w", err)
+		return domain.UserResponse{}, domain.AuthTokens{}, fmt.Errorf("error creating user: %w", err)
 	}
 
-	// Generar token JWT
-	token, err := s.generateToken(createdUser)
+	// Enviar el link de verificación de email (un fallo no impide el registro: puede reenviarse)
+	s.sendEmailVerification(ctx, createdUser.ID)
+
+	// Iniciar sesión: access token JWT + refresh token
+	tokens, err := s.sessions.IssueTokens(ctx, createdUser)
 	if err != nil {
-		return domain.UserResponse{}, "", fmt.Errorf("error generating token: %w", err)
+		return domain.UserResponse{}, domain.AuthTokens{}, fmt.Errorf("error generating token: %w", err)
 	}
 
-	return createdUser.ToResponse(), token, nil
+	return createdUser.ToResponse(), tokens, nil
 }
 
-// Login autentica un usuario y devuelve un token JWT
-func (s *UsersServiceImpl) Login(ctx context.Context, credentials domain.UserLogin) (domain.UserResponse, string, error) {
+// Login autentica un usuario y devuelve un token JWT junto a un refresh token
+// Si el usuario tiene 2FA (o la política se lo exige) devuelve un challenge en lugar de los tokens
+// clientIP se usa para limitar intentos fallidos por IP (protección contra fuerza bruta)
+func (s *UsersServiceImpl) Login(ctx context.Context, credentials domain.UserLogin, clientIP string) (domain.LoginResult, error) {
 	// Buscar usuario por username o email
-	user, err := s.repository.GetByUsernameOrEmail(ctx, credentials.UsernameOrEmail)
-	if err != nil {
-		return domain.UserResponse{}, "", errors.New("invalid credentials")
+	user, lookupErr := s.repository.GetByUsernameOrEmail(ctx, credentials.UsernameOrEmail)
+	var usuarioID *uint
+	if lookupErr == nil {
+		usuarioID = &user.ID
+	}
+
+	// Rechazar sin verificar la contraseña si la cuenta o la IP están en backoff o bloqueadas
+	if err := s.protection.Check(ctx, usuarioID, credentials.UsernameOrEmail, clientIP); err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	if lookupErr != nil {
+		s.protection.RecordFailure(ctx, nil, credentials.UsernameOrEmail, clientIP)
+		return domain.LoginResult{}, errors.New("invalid credentials")
 	}
 
-	// Verificar password
-	hashedPassword := s.hashPassword(credentials.Password)
-	if user.Password != hashedPassword {
-		return domain.UserResponse{}, "", errors.New("invalid credentials")
+	// Verificar password (detecta el algoritmo: argon2id, bcrypt o SHA-256 legacy)
+	ok, err := s.hasher.Verify(credentials.Password, user.Password)
+	if err != nil || !ok {
+		s.protection.RecordFailure(ctx, usuarioID, credentials.UsernameOrEmail, clientIP)
+		return domain.LoginResult{}, errors.New("invalid credentials")
 	}
 
-	// Generar token JWT
-	token, err := s.generateToken(user)
+	// Migrar hashes legacy o con parámetros desactualizados
+	s.rehashIfNeeded(ctx, user, credentials.Password)
+
+	// Segundo factor: el contador de fallos se reinicia recién cuando se completa el login
+	challenge, err := s.twoFactor.StartLogin(ctx, user)
+	if err != nil {
+		return domain.LoginResult{}, fmt.Errorf("error starting two factor login: %w", err)
+	}
+	if challenge != nil {
+		return domain.LoginResult{Challenge: challenge}, nil
+	}
+	s.protection.RecordSuccess(ctx, usuarioID, credentials.UsernameOrEmail)
+
+	// Iniciar sesión: access token JWT + refresh token
+	tokens, err := s.sessions.IssueTokens(ctx, user)
 	if err != nil {
-		return domain.UserResponse{}, "", fmt.Errorf("error generating token: %w", err)
+		return domain.LoginResult{}, fmt.Errorf("error generating token: %w", err)
 	}
 
-	return user.ToResponse(), token, nil
+	return domain.LoginResult{User: user.ToResponse(), Tokens: tokens}, nil
 }
 
 // GetByID obtiene un usuario por su ID
@@ -108,11 +153,78 @@ func (s *UsersServiceImpl) GetByID(ctx context.Context, id uint) (domain.UserRes
 	return user.ToResponse(), nil
 }
 
-// List obtiene todos los usuarios
-func (s *UsersServiceImpl) List(ctx context.Context) ([]domain.UserResponse, error) {
-	users, err := s.repository.List(ctx)
+// GetByIDs obtiene varios usuarios en una sola consulta (API interna entre servicios)
+// Respeta el orden de los IDs pedidos, ignora duplicados y reporta los que no existen
+func (s *UsersServiceImpl) GetByIDs(ctx context.Context, ids []uint) (domain.UsersBatchResponse, error) {
+	if len(ids) == 0 {
+		return domain.UsersBatchResponse{}, errors.New("ids is required")
+	}
+	if len(ids) > domain.MaxUsersBatchSize {
+		return domain.UsersBatchResponse{}, fmt.Errorf("ids must contain at most %d elements", domain.MaxUsersBatchSize)
+	}
+
+	unique := make([]uint, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	users, err := s.repository.GetByIDs(ctx, unique)
+	if err != nil {
+		return domain.UsersBatchResponse{}, err
+	}
+
+	byID := make(map[uint]domain.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	response := domain.UsersBatchResponse{
+		Users:   make([]domain.UserResponse, 0, len(users)),
+		Missing: []uint{},
+	}
+	for _, id := range unique {
+		if user, ok := byID[id]; ok {
+			response.Users = append(response.Users, user.ToResponse())
+		} else {
+			response.Missing = append(response.Missing, id)
+		}
+	}
+
+	return response, nil
+}
+
+// Exists indica si existe un usuario activo con ese ID
+func (s *UsersServiceImpl) Exists(ctx context.Context, id uint) (bool, error) {
+	if _, err := s.repository.GetByID(ctx, id); err != nil {
+		if err.Error() == "user not found" {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// List obtiene una página de usuarios según los filtros
+func (s *UsersServiceImpl) List(ctx context.Context, query domain.ListUsersQuery) (domain.PaginatedUsersResponse, error) {
+	if !query.RegisteredFrom.IsZero() && !query.RegisteredTo.IsZero() && query.RegisteredFrom.After(query.RegisteredTo) {
+		return domain.PaginatedUsersResponse{}, errors.New("registered_from must be before registered_to")
+	}
+
+	// Normalizar paginación
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = 20
+	}
+
+	users, total, err := s.repository.List(ctx, query)
 	if err != nil {
-		return nil, err
+		return domain.PaginatedUsersResponse{}, err
 	}
 
 	// Convertir a UserResponse
@@ -121,112 +233,301 @@ func (s *UsersServiceImpl) List(ctx context.Context) ([]domain.UserResponse, err
 		responses[i] = user.ToResponse()
 	}
 
-	return responses, nil
+	totalPages := int(total) / query.PageSize
+	if int(total)%query.PageSize > 0 {
+		totalPages++
+	}
+
+	return domain.PaginatedUsersResponse{
+		Users:      responses,
+		Total:      int(total),
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: totalPages,
+	}, nil
 }
 
-// ValidateToken valida un token JWT y devuelve los claims
-func (s *UsersServiceImpl) ValidateToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		// Validar que el método de firma es HMAC
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+// UpdateProfile actualiza los datos del perfil propio (no permite cambiar is_admin ni password)
+func (s *UsersServiceImpl) UpdateProfile(ctx context.Context, id uint, update domain.UserUpdate) (domain.UserResponse, error) {
+	user, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return domain.UserResponse{}, err
+	}
+
+	emailChanged, err := applyUserUpdate(&user, update)
+	if err != nil {
+		return domain.UserResponse{}, err
+	}
+
+	updated, err := s.repository.Update(ctx, id, user)
+	if err != nil {
+		return domain.UserResponse{}, err
+	}
+
+	if emailChanged {
+		s.sendEmailVerification(ctx, id)
+	}
+
+	return updated.ToResponse(), nil
+}
+
+// AdminUpdate actualiza cualquier usuario, incluido el flag is_admin
+// Un admin no puede quitarse su propio rol (evita quedar sin administradores por error)
+func (s *UsersServiceImpl) AdminUpdate(ctx context.Context, actorID, id uint, update domain.AdminUserUpdate) (domain.UserResponse, error) {
+	user, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return domain.UserResponse{}, err
+	}
+
+	emailChanged, err := applyUserUpdate(&user, update.UserUpdate)
+	if err != nil {
+		return domain.UserResponse{}, err
+	}
+
+	roleChanged := false
+	if update.IsAdmin != nil && *update.IsAdmin != user.IsAdmin {
+		if actorID == id && !*update.IsAdmin {
+			return domain.UserResponse{}, errors.New("cannot remove your own admin role")
+		}
+		user.IsAdmin = *update.IsAdmin
+		roleChanged = true
+	}
+
+	updated, err := s.repository.Update(ctx, id, user)
+	if err != nil {
+		return domain.UserResponse{}, err
+	}
+
+	// Los access tokens vigentes llevan el is_admin anterior: forzar un nuevo login
+	if roleChanged {
+		if err := s.sessions.RevokeAllForUser(ctx, id); err != nil {
+			log.Printf("⚠️  Error revoking sessions for user %d after role change: %v", id, err)
 		}
-		return []byte(s.jwtSecret), nil
-	})
+	}
+
+	if emailChanged {
+		s.sendEmailVerification(ctx, id)
+	}
+
+	return updated.ToResponse(), nil
+}
 
+// ChangePassword cambia la contraseña verificando la actual
+// Revoca todas las sesiones del usuario y devuelve tokens de una sesión nueva
+func (s *UsersServiceImpl) ChangePassword(ctx context.Context, id uint, change domain.PasswordChange) (domain.AuthTokens, error) {
+	user, err := s.repository.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing token: %w", err)
+		return domain.AuthTokens{}, err
 	}
 
-	if !token.Valid {
-		return nil, errors.New("invalid token")
+	ok, err := s.hasher.Verify(change.CurrentPassword, user.Password)
+	if err != nil || !ok {
+		return domain.AuthTokens{}, errors.New("current password is incorrect")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, errors.New("invalid token claims")
+	if change.NewPassword == change.CurrentPassword {
+		return domain.AuthTokens{}, errors.New("new password must be different from the current one")
+	}
+	if err := validatePassword(change.NewPassword); err != nil {
+		return domain.AuthTokens{}, err
 	}
 
-	return claims, nil
+	hashedPassword, err := s.hasher.Hash(change.NewPassword)
+	if err != nil {
+		return domain.AuthTokens{}, fmt.Errorf("error hashing password: %w", err)
+	}
+
+	if err := s.repository.UpdatePassword(ctx, id, hashedPassword); err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	// Cerrar las sesiones abiertas con la contraseña anterior
+	if err := s.sessions.RevokeAllForUser(ctx, id); err != nil {
+		return domain.AuthTokens{}, fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	tokens, err := s.sessions.IssueTokens(ctx, user)
+	if err != nil {
+		return domain.AuthTokens{}, fmt.Errorf("error generating token: %w", err)
+	}
+
+	return tokens, nil
 }
 
-// generateToken genera un token JWT para el usuario
-func (s *UsersServiceImpl) generateToken(user domain.User) (string, error) {
-	claims := jwt.MapClaims{
-		"iss":        "gym-management-system",
-		"exp":        time.Now().Add(30 * time.Minute).Unix(), // Expira en 30 minutos
-		"username":   user.Username,
-		"id_usuario": user.ID,
-		"is_admin":   user.IsAdmin,
+// Delete da de baja un usuario (soft delete) y revoca sus sesiones
+func (s *UsersServiceImpl) Delete(ctx context.Context, id uint) error {
+	if err := s.repository.Delete(ctx, id); err != nil {
+		return err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
+	if err := s.sessions.RevokeAllForUser(ctx, id); err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	return nil
+}
+
+// Restore reactiva un usuario dado de baja
+func (s *UsersServiceImpl) Restore(ctx context.Context, id uint) (domain.UserResponse, error) {
+	user, err := s.repository.Restore(ctx, id)
+	if err != nil {
+		return domain.UserResponse{}, err
+	}
+
+	return user.ToResponse(), nil
+}
+
+// sendEmailVerification envía el link de verificación sin propagar errores (se puede reenviar)
+func (s *UsersServiceImpl) sendEmailVerification(ctx context.Context, userID uint) {
+	if err := s.accounts.SendEmailVerification(ctx, userID); err != nil {
+		log.Printf("⚠️  Error sending email verification to user %d: %v", userID, err)
+	}
 }
 
-// hashPassword hashea una contraseña usando SHA256
-func (s *UsersServiceImpl) hashPassword(password string) string {
-	hash := sha256.Sum256([]byte(password))
-	return hex.EncodeToString(hash[:])
+// rehashIfNeeded regenera el hash con el algoritmo actual luego de un login exitoso
+// Un error acá no debe impedir el login: el usuario se migrará en el próximo intento
+func (s *UsersServiceImpl) rehashIfNeeded(ctx context.Context, user domain.User, password string) {
+	if !s.hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	newHash, err := s.hasher.Hash(password)
+	if err != nil {
+		log.Printf("⚠️  Error rehashing password for user %d: %v", user.ID, err)
+		return
+	}
+
+	if err := s.repository.UpdatePassword(ctx, user.ID, newHash); err != nil {
+		log.Printf("⚠️  Error upgrading password hash for user %d: %v", user.ID, err)
+		return
+	}
+
+	log.Printf("🔐 Password hash upgraded for user %d", user.ID)
 }
 
 // validateUserRegistration valida los datos de registro
 func (s *UsersServiceImpl) validateUserRegistration(userReg domain.UserRegister) error {
-	// Validar nombre
-	if strings.TrimSpace(userReg.Nombre) == "" {
+	if err := validateNombre(userReg.Nombre); err != nil {
+		return err
+	}
+	if err := validateApellido(userReg.Apellido); err != nil {
+		return err
+	}
+	if err := validateUsername(userReg.Username); err != nil {
+		return err
+	}
+	if err := validateEmail(userReg.Email); err != nil {
+		return err
+	}
+	return validatePassword(userReg.Password)
+}
+
+// applyUserUpdate aplica los campos presentes de update sobre user, con las mismas reglas que el registro
+// Indica si cambió el email (en ese caso vuelve a quedar sin verificar)
+func applyUserUpdate(user *domain.User, update domain.UserUpdate) (bool, error) {
+	if update.Nombre != nil {
+		if err := validateNombre(*update.Nombre); err != nil {
+			return false, err
+		}
+		user.Nombre = *update.Nombre
+	}
+	if update.Apellido != nil {
+		if err := validateApellido(*update.Apellido); err != nil {
+			return false, err
+		}
+		user.Apellido = *update.Apellido
+	}
+	if update.Username != nil {
+		if err := validateUsername(*update.Username); err != nil {
+			return false, err
+		}
+		user.Username = *update.Username
+	}
+	emailChanged := false
+	if update.Email != nil {
+		if err := validateEmail(*update.Email); err != nil {
+			return false, err
+		}
+		if *update.Email != user.Email {
+			user.Email = *update.Email
+			user.EmailVerifiedAt = nil
+			emailChanged = true
+		}
+	}
+	if update.SucursalOrigenID != nil {
+		user.SucursalOrigenID = update.SucursalOrigenID
+	}
+
+	return emailChanged, nil
+}
+
+// validateNombre valida el nombre
+func validateNombre(nombre string) error {
+	if strings.TrimSpace(nombre) == "" {
 		return errors.New("nombre is required")
 	}
-	if len(userReg.Nombre) > 30 {
+	if len(nombre) > 30 {
 		return errors.New("nombre must be at most 30 characters")
 	}
+	return nil
+}
 
-	// Validar apellido
-	if strings.TrimSpace(userReg.Apellido) == "" {
+// validateApellido valida el apellido
+func validateApellido(apellido string) error {
+	if strings.TrimSpace(apellido) == "" {
 		return errors.New("apellido is required")
 	}
-	if len(userReg.Apellido) > 30 {
+	if len(apellido) > 30 {
 		return errors.New("apellido must be at most 30 characters")
 	}
+	return nil
+}
 
-	// Validar username
-	if strings.TrimSpace(userReg.Username) == "" {
+// validateUsername valida el username
+func validateUsername(username string) error {
+	if strings.TrimSpace(username) == "" {
 		return errors.New("username is required")
 	}
-	if len(userReg.Username) < 3 || len(userReg.Username) > 30 {
+	if len(username) < 3 || len(username) > 30 {
 		return errors.New("username must be between 3 and 30 characters")
 	}
 	// Username solo puede contener letras, números, guiones y guiones bajos
 	usernameRegex := regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
-	if !usernameRegex.MatchString(userReg.Username) {
+	if !usernameRegex.MatchString(username) {
 		return errors.New("username can only contain letters, numbers, hyphens and underscores")
 	}
+	return nil
+}
 
-	// Validar email
-	if strings.TrimSpace(userReg.Email) == "" {
+// validateEmail valida el email
+func validateEmail(email string) error {
+	if strings.TrimSpace(email) == "" {
 		return errors.New("email is required")
 	}
 	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(userReg.Email) {
+	if !emailRegex.MatchString(email) {
 		return errors.New("invalid email format")
 	}
+	return nil
+}
 
-	// Validar password
-	if strings.TrimSpace(userReg.Password) == "" {
+// validatePassword valida la fortaleza de la contraseña
+func validatePassword(password string) error {
+	if strings.TrimSpace(password) == "" {
 		return errors.New("password is required")
 	}
-	if len(userReg.Password) < 8 {
+	if len(password) < 8 {
 		return errors.New("password must be at least 8 characters")
 	}
 	// Password debe tener al menos una letra mayúscula, una minúscula y un número
-	if !regexp.MustCompile(`[A-Z]`).MatchString(userReg.Password) {
+	if !regexp.MustCompile(`[A-Z]`).MatchString(password) {
 		return errors.New("password must contain at least one uppercase letter")
 	}
-	if !regexp.MustCompile(`[a-z]`).MatchString(userReg.Password) {
+	if !regexp.MustCompile(`[a-z]`).MatchString(password) {
 		return errors.New("password must contain at least one lowercase letter")
 	}
-	if !regexp.MustCompile(`[0-9]`).MatchString(userReg.Password) {
+	if !regexp.MustCompile(`[0-9]`).MatchString(password) {
 		return errors.New("password must contain at least one number")
 	}
-
 	return nil
 }
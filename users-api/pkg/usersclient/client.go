@@ -0,0 +1,185 @@
+package usersclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxBatchSize es la cantidad máxima de IDs que acepta users-api por llamada batch
+// GetUsers parte pedidos más grandes en varias llamadas
+const MaxBatchSize = 500
+
+// ErrNotFound se devuelve cuando el usuario no existe o fue dado de baja
+var ErrNotFound = errors.New("user not found")
+
+// User es el usuario tal como lo devuelve users-api
+type User struct {
+	ID               uint      `json:"id"`
+	Nombre           string    `json:"nombre"`
+	Apellido         string    `json:"apellido"`
+	Username         string    `json:"username"`
+	Email            string    `json:"email"`
+	IsAdmin          bool      `json:"is_admin"`
+	SucursalOrigenID *uint     `json:"sucursal_origen_id,omitempty"`
+	EmailVerified    bool      `json:"email_verified"`
+	FechaRegistro    time.Time `json:"fecha_registro"`
+}
+
+// BatchResult es el resultado de GetUsers
+// Users respeta el orden de los IDs pedidos; Missing son los IDs que no existen
+type BatchResult struct {
+	Users   []User `json:"users"`
+	Missing []uint `json:"missing"`
+}
+
+// APIError es una respuesta de error de users-api
+type APIError struct {
+	StatusCode int
+	Message    string `json:"error"`
+	Details    string `json:"details"`
+}
+
+func (e *APIError) Error() string {
+	if e.Details != "" {
+		return fmt.Sprintf("users-api: status %d: %s: %s", e.StatusCode, e.Message, e.Details)
+	}
+	return fmt.Sprintf("users-api: status %d: %s", e.StatusCode, e.Message)
+}
+
+// Config contiene los parámetros del cliente
+type Config struct {
+	BaseURL       string        // Ej: http://users-api:8080
+	ServiceName   string        // Nombre del servicio registrado en SERVICE_CREDENTIALS de users-api
+	ServiceSecret string        // Secreto del servicio
+	Timeout       time.Duration // Timeout por request (default 5s); se ignora si se pasa HTTPClient
+	HTTPClient    *http.Client  // Opcional
+}
+
+// Client consulta la API interna de users-api
+// Es seguro para uso concurrente
+type Client struct {
+	baseURL       string
+	serviceName   string
+	serviceSecret string
+	httpClient    *http.Client
+}
+
+// New crea un cliente de users-api
+func New(cfg Config) *Client {
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		httpClient = &http.Client{Timeout: timeout}
+	}
+
+	return &Client{
+		baseURL:       strings.TrimRight(cfg.BaseURL, "/"),
+		serviceName:   cfg.ServiceName,
+		serviceSecret: cfg.ServiceSecret,
+		httpClient:    httpClient,
+	}
+}
+
+// GetUser obtiene un usuario por ID. Devuelve ErrNotFound si no existe
+func (c *Client) GetUser(ctx context.Context, id uint) (User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodGet, "/internal/users/"+strconv.FormatUint(uint64(id), 10), nil, &user); err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+// GetUsers obtiene varios usuarios por ID en la menor cantidad de llamadas posible
+func (c *Client) GetUsers(ctx context.Context, ids []uint) (BatchResult, error) {
+	result := BatchResult{Users: []User{}, Missing: []uint{}}
+
+	for start := 0; start < len(ids); start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		var page BatchResult
+		body := map[string][]uint{"ids": ids[start:end]}
+		if err := c.do(ctx, http.MethodPost, "/internal/users/batch", body, &page); err != nil {
+			return BatchResult{}, err
+		}
+		result.Users = append(result.Users, page.Users...)
+		result.Missing = append(result.Missing, page.Missing...)
+	}
+
+	return result, nil
+}
+
+// Exists indica si existe un usuario activo con ese ID
+func (c *Client) Exists(ctx context.Context, id uint) (bool, error) {
+	var response struct {
+		Exists bool `json:"exists"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/internal/users/"+strconv.FormatUint(uint64(id), 10)+"/exists", nil, &response); err != nil {
+		return false, err
+	}
+	return response.Exists, nil
+}
+
+// do ejecuta el request autenticado y decodifica la respuesta JSON en out
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error encoding request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	endpoint, err := url.JoinPath(c.baseURL, path)
+	if err != nil {
+		return fmt.Errorf("invalid users-api URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.SetBasicAuth(c.serviceName, c.serviceSecret)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling users-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding users-api response: %w", err)
+	}
+	return nil
+}
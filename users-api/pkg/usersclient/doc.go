@@ -0,0 +1,7 @@
+// Package usersclient reúne los clientes Go de users-api que comparten los demás microservicios,
+// en lugar de que cada uno arme las llamadas HTTP a mano (se importa con
+// replace => ../users-api/pkg/usersclient en el go.mod de cada servicio).
+//
+// Client consulta la API interna de users-api (/internal/users); se autentica con
+// credenciales de servicio (HTTP Basic), configuradas en users-api con SERVICE_CREDENTIALS.
+package usersclient
@@ -0,0 +1,5 @@
+module users-api/pkg/usersclient
+
+go 1.22
+
+require github.com/golang-jwt/jwt/v4 v4.5.2
@@ -0,0 +1,167 @@
+package usersclient
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// jwksMinRefreshInterval limita la frecuencia de descargas del JWKS
+// (evita que tokens con kid inventado o una caída de users-api generen un request cada vez)
+const jwksMinRefreshInterval = 30 * time.Second
+
+// errJWKSRecentlyFetched indica que se omitió la descarga por jwksMinRefreshInterval
+var errJWKSRecentlyFetched = errors.New("JWKS fetched recently")
+
+// JWKSKeyResolver resuelve las claves públicas de firma publicadas por users-api
+// en /.well-known/jwks.json. Keyfunc sirve como jwt.Keyfunc en el middleware JWT de cada
+// servicio: con solo las claves públicas se pueden verificar tokens pero nunca emitirlos
+type JWKSKeyResolver struct {
+	url      string
+	client   *http.Client
+	cacheTTL time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]jwksKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+type jwksKey struct {
+	alg    string
+	public interface{}
+}
+
+type jwkDocument struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Alg string `json:"alg"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+	} `json:"keys"`
+}
+
+// NewJWKSKeyResolver crea el resolver para la URL base de users-api
+func NewJWKSKeyResolver(usersAPIURL string, cacheTTL time.Duration) *JWKSKeyResolver {
+	return &JWKSKeyResolver{
+		url:      usersAPIURL + "/.well-known/jwks.json",
+		client:   &http.Client{Timeout: 5 * time.Second},
+		cacheTTL: cacheTTL,
+		keys:     make(map[string]jwksKey),
+	}
+}
+
+// Keyfunc devuelve la clave pública para el kid del token
+// Refresca el JWKS cuando el cache venció o cuando aparece un kid nuevo (rotación)
+func (r *JWKSKeyResolver) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, fmt.Errorf("token has no kid")
+	}
+
+	key, found, stale := r.lookup(kid)
+	if !found || stale {
+		err := r.refresh(context.Background())
+		if err != nil && !errors.Is(err, errJWKSRecentlyFetched) {
+			// Si users-api no responde se mantienen las claves cacheadas
+			log.Printf("⚠️  Could not refresh JWKS: %v", err)
+		}
+		key, found, _ = r.lookup(kid)
+		if !found {
+			return nil, fmt.Errorf("unknown key id: %q", kid)
+		}
+	}
+
+	if token.Method.Alg() != key.alg {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.public, nil
+}
+
+// lookup busca un kid en el cache
+func (r *JWKSKeyResolver) lookup(kid string) (jwksKey, bool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[kid]
+	return key, ok, time.Since(r.fetchedAt) > r.cacheTTL
+}
+
+// refresh descarga el JWKS y reemplaza el cache (como máximo una vez por jwksMinRefreshInterval)
+func (r *JWKSKeyResolver) refresh(ctx context.Context) error {
+	r.mu.Lock()
+	if time.Since(r.lastAttempt) < jwksMinRefreshInterval {
+		r.mu.Unlock()
+		return errJWKSRecentlyFetched
+	}
+	r.lastAttempt = time.Now()
+	r.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating JWKS request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error fetching JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+
+	var doc jwkDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("error decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]jwksKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		switch {
+		case k.Kty == "RSA" && k.Alg == "RS256":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil {
+				log.Printf("⚠️  Skipping invalid RSA JWK %s", k.Kid)
+				continue
+			}
+			keys[k.Kid] = jwksKey{alg: k.Alg, public: &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}}
+		case k.Kty == "OKP" && k.Crv == "Ed25519" && k.Alg == "EdDSA":
+			x, err := base64.RawURLEncoding.DecodeString(k.X)
+			if err != nil || len(x) != ed25519.PublicKeySize {
+				log.Printf("⚠️  Skipping invalid Ed25519 JWK %s", k.Kid)
+				continue
+			}
+			keys[k.Kid] = jwksKey{alg: k.Alg, public: ed25519.PublicKey(x)}
+		default:
+			log.Printf("⚠️  Skipping unsupported JWK %s (kty=%s, alg=%s)", k.Kid, k.Kty, k.Alg)
+		}
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.fetchedAt = time.Now()
+	r.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package usersclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// SessionChecker consulta a users-api si una sesión fue revocada
+// Lo usa el middleware JWT de cada servicio, con un cache en memoria para no
+// consultar users-api en cada request
+type SessionChecker struct {
+	baseURL  string
+	client   *http.Client
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]sessionCacheEntry
+}
+
+type sessionCacheEntry struct {
+	revoked   bool
+	expiresAt time.Time
+}
+
+type sessionStatusResponse struct {
+	SessionID string `json:"sid"`
+	Revoked   bool   `json:"revoked"`
+}
+
+// NewSessionChecker crea el cliente con el TTL de cache indicado
+func NewSessionChecker(baseURL string, cacheTTL time.Duration) *SessionChecker {
+	return &SessionChecker{
+		baseURL:  baseURL,
+		client:   &http.Client{Timeout: 3 * time.Second},
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]sessionCacheEntry),
+	}
+}
+
+// IsRevoked devuelve el estado cacheado o consulta GET /sessions/:sid/status
+func (c *SessionChecker) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.cache[sessionID]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.revoked, nil
+	}
+
+	endpoint := fmt.Sprintf("%s/sessions/%s/status", c.baseURL, url.PathEscape(sessionID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("error calling users-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("users-api returned status %d", resp.StatusCode)
+	}
+
+	var status sessionStatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return false, fmt.Errorf("error decoding session status: %w", err)
+	}
+
+	c.mu.Lock()
+	// Limpieza perezosa para que el cache no crezca indefinidamente
+	for sid, e := range c.cache {
+		if now.After(e.expiresAt) {
+			delete(c.cache, sid)
+		}
+	}
+	c.cache[sessionID] = sessionCacheEntry{revoked: status.Revoked, expiresAt: now.Add(c.cacheTTL)}
+	c.mu.Unlock()
+
+	return status.Revoked, nil
+}